@@ -5,14 +5,32 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	"google.golang.org/grpc"
 )
 
 var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
 
+// APITokenPrefix is the prefix of personal access tokens, which distinguishes them from JWTs
+const APITokenPrefix = "rwpat_"
+
+// APITokenVerifier resolves a personal access token into its owner and scopes
+type APITokenVerifier interface {
+	VerifyAPIToken(token string) (userID uint, scopes []string, err error)
+}
+
+var apiTokenVerifier APITokenVerifier
+
+// SetAPITokenVerifier sets the verifier of personal access tokens.
+// Personal access tokens are rejected until it's set.
+func SetAPITokenVerifier(v APITokenVerifier) {
+	apiTokenVerifier = v
+}
+
 type claims struct {
 	UserID uint `json:"user_id"`
 	jwt.StandardClaims
@@ -46,13 +64,18 @@ func generateToken(id uint, now time.Time) (string, error) {
 	return t, nil
 }
 
-// GetUserID gets user id string from request context
+// GetUserID gets user id string from request context.
+// The token is either a JWT or a personal access token having the scope the called method needs.
 func GetUserID(ctx context.Context) (uint, error) {
 	tokenString, err := grpc_auth.AuthFromMD(ctx, "Token")
 	if err != nil {
 		return 0, err
 	}
 
+	if strings.HasPrefix(tokenString, APITokenPrefix) {
+		return getUserIDFromAPIToken(ctx, tokenString)
+	}
+
 	token, err := jwt.ParseWithClaims(tokenString, &claims{}, func(token *jwt.Token) (interface{}, error) {
 		return jwtSecret, nil
 	})
@@ -81,3 +104,26 @@ func GetUserID(ctx context.Context) (uint, error) {
 
 	return c.UserID, nil
 }
+
+func getUserIDFromAPIToken(ctx context.Context, token string) (uint, error) {
+	if apiTokenVerifier == nil {
+		return 0, errors.New("personal access tokens are not supported")
+	}
+
+	method, _ := grpc.Method(ctx)
+	scope, ok := methodScopes[method]
+	if !ok {
+		return 0, fmt.Errorf("personal access tokens are not allowed for %q", method)
+	}
+
+	userID, scopes, err := apiTokenVerifier.VerifyAPIToken(token)
+	if err != nil {
+		return 0, fmt.Errorf("invalid token: %w", err)
+	}
+
+	if !hasScope(scopes, scope) {
+		return 0, fmt.Errorf("token does not have %q scope", scope)
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,93 @@
+package auth
+
+// Scopes of personal access tokens
+const (
+	ScopeRead          = "read"
+	ScopeArticlesWrite = "articles:write"
+	ScopeCommentsWrite = "comments:write"
+	ScopeProfilesWrite = "profiles:write"
+)
+
+// Scopes are all of the scopes a personal access token can have
+var Scopes = []string{
+	ScopeRead,
+	ScopeArticlesWrite,
+	ScopeCommentsWrite,
+	ScopeProfilesWrite,
+}
+
+// methodScopes maps grpc methods to the scope a personal access token needs to call them.
+// Methods not listed here, like account settings or token management, need a JWT.
+var methodScopes = map[string]string{
+	"/article.Articles/GetArticle":                  ScopeRead,
+	"/article.Articles/GetArticleStats":             ScopeRead,
+	"/article.Articles/GetRelatedArticles":          ScopeRead,
+	"/article.Articles/SearchArticles":              ScopeRead,
+	"/article.Articles/GetArticles":                 ScopeRead,
+	"/article.Articles/GetFeedArticles":             ScopeRead,
+	"/article.Articles/GetTags":                     ScopeRead,
+	"/article.Articles/GetTrendingTags":             ScopeRead,
+	"/article.Articles/GetComments":                 ScopeRead,
+	"/article.Articles/ListMyDrafts":                ScopeRead,
+	"/article.Articles/ListArticleRevisions":        ScopeRead,
+	"/article.Articles/GetArticleRevision":          ScopeRead,
+	"/article.Articles/DiffArticleRevisions":        ScopeRead,
+	"/user.Users/SearchProfiles":                    ScopeRead,
+	"/user.Users/ShowProfile":                       ScopeRead,
+	"/user.Users/ListFollowers":                     ScopeRead,
+	"/user.Users/ListFollowing":                     ScopeRead,
+	"/user.Users/ListBlockedUsers":                  ScopeRead,
+	"/user.Users/ListMutedUsers":                    ScopeRead,
+	"/notification.Notifications/ListNotifications": ScopeRead,
+	"/notification.Notifications/GetUnreadCount":    ScopeRead,
+	"/event.Events/Subscribe":                       ScopeRead,
+	"/readinglist.ReadingLists/ListReadingLists":    ScopeRead,
+	"/readinglist.ReadingLists/GetReadingList":      ScopeRead,
+
+	"/article.Articles/CreateArticle":          ScopeArticlesWrite,
+	"/article.Articles/UpdateArticle":          ScopeArticlesWrite,
+	"/article.Articles/DeleteArticle":          ScopeArticlesWrite,
+	"/article.Articles/PublishArticle":         ScopeArticlesWrite,
+	"/article.Articles/UnpublishArticle":       ScopeArticlesWrite,
+	"/article.Articles/ScheduleArticle":        ScopeArticlesWrite,
+	"/article.Articles/UnscheduleArticle":      ScopeArticlesWrite,
+	"/article.Articles/RestoreArticleRevision": ScopeArticlesWrite,
+	"/article.Articles/FavoriteArticle":        ScopeArticlesWrite,
+	"/article.Articles/UnfavoriteArticle":      ScopeArticlesWrite,
+
+	"/readinglist.ReadingLists/AddToReadingList":      ScopeArticlesWrite,
+	"/readinglist.ReadingLists/RemoveFromReadingList": ScopeArticlesWrite,
+	"/readinglist.ReadingLists/MoveInReadingList":     ScopeArticlesWrite,
+
+	"/article.Articles/CreateComment": ScopeCommentsWrite,
+	"/article.Articles/UpdateComment": ScopeCommentsWrite,
+	"/article.Articles/DeleteComment": ScopeCommentsWrite,
+
+	"/user.Users/FollowUser":   ScopeProfilesWrite,
+	"/user.Users/UnfollowUser": ScopeProfilesWrite,
+	"/user.Users/BlockUser":    ScopeProfilesWrite,
+	"/user.Users/UnblockUser":  ScopeProfilesWrite,
+	"/user.Users/MuteUser":     ScopeProfilesWrite,
+	"/user.Users/UnmuteUser":   ScopeProfilesWrite,
+
+	"/notification.Notifications/MarkRead": ScopeProfilesWrite,
+}
+
+// IsValidScope returns whether s is a known scope
+func IsValidScope(s string) bool {
+	for _, scope := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func hasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
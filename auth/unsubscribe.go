@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// UnsubscribeTokenPrefix is the prefix of tokens of unsubscribe links
+const UnsubscribeTokenPrefix = "rwunsub_"
+
+// GenerateUnsubscribeToken generates the token of one-click unsubscribe links
+// in emails to the user. It doesn't expire and can't be used to sign in.
+func GenerateUnsubscribeToken(id uint) string {
+	s := strconv.FormatUint(uint64(id), 10)
+	return fmt.Sprintf("%s%s.%s", UnsubscribeTokenPrefix, s, unsubscribeMAC(s))
+}
+
+// ParseUnsubscribeToken returns the id of the user the token was generated for
+func ParseUnsubscribeToken(token string) (uint, error) {
+	parts := strings.SplitN(strings.TrimPrefix(token, UnsubscribeTokenPrefix), ".", 2)
+	if !strings.HasPrefix(token, UnsubscribeTokenPrefix) || len(parts) != 2 {
+		return 0, errors.New("malformed unsubscribe token")
+	}
+
+	if !hmac.Equal([]byte(parts[1]), []byte(unsubscribeMAC(parts[0]))) {
+		return 0, errors.New("invalid unsubscribe token")
+	}
+
+	id, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("malformed unsubscribe token: %w", err)
+	}
+
+	return uint(id), nil
+}
+
+func unsubscribeMAC(id string) string {
+	m := hmac.New(sha256.New, jwtSecret)
+	m.Write([]byte("unsubscribe:" + id))
+	return hex.EncodeToString(m.Sum(nil))
+}
@@ -130,15 +130,55 @@ func DropTestDB(d *gorm.DB) error {
 
 // AutoMigrate is a wrapper of (*gorm.DB).AutoMigrate
 func AutoMigrate(db *gorm.DB) error {
+	// follow counters were added later, so existing follows are counted once
+	recount := !db.Dialect().HasColumn("users", "followers_count")
+	// publish times were too, so articles out of draft are taken as published
+	// when they were created
+	backfillPublished := !db.Dialect().HasColumn("articles", "published_at")
+
 	err := db.AutoMigrate(
 		&model.User{},
 		&model.Article{},
 		&model.Tag{},
 		&model.Comment{},
+		&model.Identity{},
+		&model.APIToken{},
+		&model.Report{},
+		&model.ModerationAction{},
+		&model.CommentRevision{},
+		&model.ArticleRevision{},
+		&model.Notification{},
+		&model.NotificationActor{},
+		&model.Webhook{},
+		&model.WebhookDelivery{},
+		&model.OutboxEvent{},
+		&model.OutboxReceipt{},
+		&model.ReadingList{},
+		&model.ReadingListItem{},
+		&model.ArticleDailyStat{},
+		&model.ArticleTrend{},
 	).Error
 	if err != nil {
 		return err
 	}
+
+	if recount {
+		err = db.Exec("UPDATE users SET " +
+			"followers_count = (SELECT COUNT(*) FROM follows WHERE follows.to_user_id = users.id), " +
+			"following_count = (SELECT COUNT(*) FROM follows WHERE follows.from_user_id = users.id)").Error
+		if err != nil {
+			return err
+		}
+	}
+
+	if backfillPublished {
+		err = db.Exec("UPDATE articles SET published_at = created_at WHERE status IN (?)",
+			[]string{model.ArticlePublished, model.ArticleUnlisted, model.ArticleArchived}).Error
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,278 @@
+// Package diff computes line based differences between texts
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Kind is the kind of an edit operation
+type Kind int
+
+// Kinds of edit operations
+const (
+	Equal Kind = iota
+	Delete
+	Insert
+)
+
+// Op is an edit operation on a line
+type Op struct {
+	Kind Kind
+	Line string
+}
+
+// Lines returns a shortest edit script turning a into b, computed with the
+// linear space variant of the Myers algorithm. Within a run of changes,
+// deletions come before insertions.
+func Lines(a, b []string) []Op {
+	max := len(a) + len(b)
+	d := differ{
+		a:   a,
+		b:   b,
+		ops: make([]Op, 0, max),
+		vf:  make([]int, 2*max+2),
+		vb:  make([]int, 2*max+2),
+	}
+	d.diff(0, len(a), 0, len(b))
+
+	// move deletions before insertions in each run of changes
+	for i := 0; i < len(d.ops); {
+		if d.ops[i].Kind == Equal {
+			i++
+			continue
+		}
+		j := i
+		for j < len(d.ops) && d.ops[j].Kind != Equal {
+			j++
+		}
+		run := d.ops[i:j]
+		sort.SliceStable(run, func(x, y int) bool {
+			return run[x].Kind == Delete && run[y].Kind == Insert
+		})
+		i = j
+	}
+
+	return d.ops
+}
+
+// differ holds the state of Lines. vf and vb are the furthest reaching
+// forward and backward paths by diagonal, reused by every middle snake search.
+type differ struct {
+	a, b   []string
+	ops    []Op
+	vf, vb []int
+}
+
+// diff appends the edit script turning a[aLo:aHi] into b[bLo:bHi]
+func (d *differ) diff(aLo, aHi, bLo, bHi int) {
+	// common prefix and suffix don't need the search
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		d.ops = append(d.ops, Op{Equal, d.a[aLo]})
+		aLo++
+		bLo++
+	}
+	suf := 0
+	for aLo < aHi-suf && bLo < bHi-suf && d.a[aHi-1-suf] == d.b[bHi-1-suf] {
+		suf++
+	}
+	aHi, bHi = aHi-suf, bHi-suf
+
+	switch {
+	case aLo == aHi:
+		for _, l := range d.b[bLo:bHi] {
+			d.ops = append(d.ops, Op{Insert, l})
+		}
+	case bLo == bHi:
+		for _, l := range d.a[aLo:aHi] {
+			d.ops = append(d.ops, Op{Delete, l})
+		}
+	default:
+		x, y, u, v := d.middleSnake(aLo, aHi, bLo, bHi)
+		d.diff(aLo, x, bLo, y)
+		for _, l := range d.a[x:u] {
+			d.ops = append(d.ops, Op{Equal, l})
+		}
+		d.diff(u, aHi, v, bHi)
+	}
+
+	for _, l := range d.a[aHi : aHi+suf] {
+		d.ops = append(d.ops, Op{Equal, l})
+	}
+}
+
+// middleSnake returns the start (x, y) and the end (u, v) of the snake in the
+// middle of a shortest edit script turning a[aLo:aHi] into b[bLo:bHi], by
+// searching from both ends until the paths overlap. The ranges must not be
+// empty and must differ at both ends, so that the snake splits them.
+func (d *differ) middleSnake(aLo, aHi, bLo, bHi int) (x, y, u, v int) {
+	a, b := d.a[aLo:aHi], d.b[bLo:bHi]
+	n, m := len(a), len(b)
+	delta := n - m
+	odd := delta%2 != 0
+
+	// vf[off+k] is the furthest x on diagonal k = x-y from the start,
+	// vb[off+k] the furthest distance from the end on diagonal k of the
+	// reversed texts
+	off := n + m
+	d.vf[off+1], d.vb[off+1] = 0, 0
+
+	for D := 0; D <= (n+m+1)/2; D++ {
+		for k := -D; k <= D; k += 2 {
+			var x int
+			if k == -D || (k != D && d.vf[off+k-1] < d.vf[off+k+1]) {
+				x = d.vf[off+k+1]
+			} else {
+				x = d.vf[off+k-1] + 1
+			}
+			y := x - k
+			x0, y0 := x, y
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			d.vf[off+k] = x
+
+			// the backward paths are one round behind
+			if kb := delta - k; odd && kb >= -(D-1) && kb <= D-1 && x+d.vb[off+kb] >= n {
+				return aLo + x0, bLo + y0, aLo + x, bLo + y
+			}
+		}
+
+		for k := -D; k <= D; k += 2 {
+			var x int
+			if k == -D || (k != D && d.vb[off+k-1] < d.vb[off+k+1]) {
+				x = d.vb[off+k+1]
+			} else {
+				x = d.vb[off+k-1] + 1
+			}
+			y := x - k
+			x0, y0 := x, y
+			for x < n && y < m && a[n-1-x] == b[m-1-y] {
+				x++
+				y++
+			}
+			d.vb[off+k] = x
+
+			if kf := delta - k; !odd && kf >= -D && kf <= D && x+d.vf[off+kf] >= n {
+				return aHi - x, bHi - y, aHi - x0, bHi - y0
+			}
+		}
+	}
+
+	// unreachable: the paths overlap once D reaches half of the edits
+	panic("diff: no middle snake")
+}
+
+// Unified returns the difference between a and b in the unified format with
+// n lines of context. It returns an empty string if a and b are equal.
+func Unified(fromName, toName, a, b string, n int) string {
+	ops := Lines(splitLines(a), splitLines(b))
+
+	var sb strings.Builder
+	for i := 0; i < len(ops); {
+		// find next change
+		for i < len(ops) && ops[i].Kind == Equal {
+			i++
+		}
+		if i == len(ops) {
+			break
+		}
+
+		if sb.Len() == 0 {
+			fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+		}
+
+		// extend the hunk while changes are within 2n lines of each other
+		start := i - n
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(ops) {
+			if ops[end].Kind != Equal {
+				end++
+				continue
+			}
+			j := end
+			for j < len(ops) && ops[j].Kind == Equal {
+				j++
+			}
+			if j == len(ops) || j-end > 2*n {
+				end += min(n, j-end)
+				break
+			}
+			end = j
+		}
+
+		writeHunk(&sb, ops, start, end)
+		i = end
+	}
+
+	return sb.String()
+}
+
+// writeHunk writes ops[start:end] as a hunk
+func writeHunk(sb *strings.Builder, ops []Op, start, end int) {
+	// line numbers before the hunk
+	var aLine, bLine int
+	for _, op := range ops[:start] {
+		if op.Kind != Insert {
+			aLine++
+		}
+		if op.Kind != Delete {
+			bLine++
+		}
+	}
+
+	var aLen, bLen int
+	for _, op := range ops[start:end] {
+		if op.Kind != Insert {
+			aLen++
+		}
+		if op.Kind != Delete {
+			bLen++
+		}
+	}
+
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(aLine, aLen), hunkRange(bLine, bLen))
+	for _, op := range ops[start:end] {
+		switch op.Kind {
+		case Equal:
+			sb.WriteString(" ")
+		case Delete:
+			sb.WriteString("-")
+		case Insert:
+			sb.WriteString("+")
+		}
+		sb.WriteString(op.Line)
+		sb.WriteString("\n")
+	}
+}
+
+// hunkRange formats a range of lines like GNU diff does
+func hunkRange(before, n int) string {
+	switch n {
+	case 0:
+		return fmt.Sprintf("%d,0", before)
+	case 1:
+		return fmt.Sprintf("%d", before+1)
+	default:
+		return fmt.Sprintf("%d,%d", before+1, n)
+	}
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,160 @@
+package diff
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		title string
+		a, b  string
+	}{
+		{"equal", "a b c", "a b c"},
+		{"empty to text", "", "a b"},
+		{"text to empty", "a b", ""},
+		{"insert", "a c", "a b c"},
+		{"delete", "a b c", "a c"},
+		{"replace", "a b c", "a x c"},
+		{"mixed", "a b c a b b a", "c b a b a c"},
+	}
+
+	for _, tt := range tests {
+		a, b := strings.Fields(tt.a), strings.Fields(tt.b)
+		ops := Lines(a, b)
+
+		// applying the script must reproduce both sides
+		gotA, gotB := []string{}, []string{}
+		for _, op := range ops {
+			if op.Kind != Insert {
+				gotA = append(gotA, op.Line)
+			}
+			if op.Kind != Delete {
+				gotB = append(gotB, op.Line)
+			}
+		}
+		assert.Equal(t, a, gotA, tt.title)
+		assert.Equal(t, b, gotB, tt.title)
+	}
+
+	// shortest edit script of the example in the Myers paper
+	ops := Lines(strings.Fields("a b c a b b a"), strings.Fields("c b a b a c"))
+	edits := 0
+	for _, op := range ops {
+		if op.Kind != Equal {
+			edits++
+		}
+	}
+	assert.Equal(t, 5, edits)
+}
+
+// apply returns both sides of an edit script
+func apply(ops []Op) (a, b []string) {
+	a, b = []string{}, []string{}
+	for _, op := range ops {
+		if op.Kind != Insert {
+			a = append(a, op.Line)
+		}
+		if op.Kind != Delete {
+			b = append(b, op.Line)
+		}
+	}
+	return a, b
+}
+
+// lcs returns the length of the longest common subsequence of a and b
+func lcs(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			switch {
+			case a[i] == b[j]:
+				cur[j+1] = prev[j] + 1
+			case prev[j+1] > cur[j]:
+				cur[j+1] = prev[j+1]
+			default:
+				cur[j+1] = cur[j]
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func TestLinesShortest(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	random := func() []string {
+		ls := make([]string, r.Intn(30))
+		for i := range ls {
+			ls[i] = string(rune('a' + r.Intn(4)))
+		}
+		return ls
+	}
+
+	for i := 0; i < 500; i++ {
+		a, b := random(), random()
+		ops := Lines(a, b)
+
+		gotA, gotB := apply(ops)
+		assert.Equal(t, append([]string{}, a...), gotA)
+		assert.Equal(t, append([]string{}, b...), gotB)
+		assert.Equal(t, len(a)+len(b)-lcs(a, b), len(ops), "edits of %v and %v", a, b)
+	}
+}
+
+func TestLinesLarge(t *testing.T) {
+	a := make([]string, 4000)
+	b := make([]string, 4000)
+	for i := range a {
+		a[i] = fmt.Sprintf("a%d", i)
+		b[i] = fmt.Sprintf("b%d", i)
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	ops := Lines(a, b)
+	runtime.ReadMemStats(&after)
+
+	assert.Len(t, ops, len(a)+len(b))
+	gotA, gotB := apply(ops)
+	assert.Equal(t, a, gotA)
+	assert.Equal(t, b, gotB)
+
+	// memory is linear in the size of the texts
+	assert.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(10<<20))
+}
+
+func TestUnified(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n"
+	b := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n15\n16\n"
+
+	want := `--- a
++++ b
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+@@ -11,5 +11,5 @@
+ 11
+ 12
+ 13
+-14
+ 15
++16
+`
+	assert.Equal(t, want, Unified("a", "b", a, b, 3))
+
+	assert.Equal(t, "", Unified("a", "b", a, a, 3))
+
+	assert.Equal(t, "--- a\n+++ b\n@@ -0,0 +1 @@\n+new\n", Unified("a", "b", "", "new", 3))
+}
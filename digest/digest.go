@@ -0,0 +1,80 @@
+// Package digest composes the email digests of followed authors' articles
+package digest
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+
+	"github.com/raahii/golang-grpc-realworld-example/mail"
+)
+
+//go:embed templates
+var templates embed.FS
+
+var (
+	textTemplate = texttemplate.Must(texttemplate.ParseFS(templates, "templates/digest.txt.tmpl"))
+	htmlTemplate = htmltemplate.Must(htmltemplate.ParseFS(templates, "templates/digest.html.tmpl"))
+)
+
+// Article is an article listed in a digest
+type Article struct {
+	Title          string
+	Description    string
+	Author         string
+	URL            string
+	FavoritesCount int32
+}
+
+// Digest is the content of a digest email
+type Digest struct {
+	Username       string
+	Frequency      string // daily or weekly
+	Articles       []Article
+	UnsubscribeURL string
+}
+
+// Compose renders the digest into a message with plain text and HTML bodies.
+// The sender and recipient are left to the caller.
+func Compose(d *Digest) (*mail.Message, error) {
+	data := struct {
+		*Digest
+		Subject string
+		Period  string
+	}{
+		Digest:  d,
+		Subject: subject(d),
+		Period:  "today",
+	}
+	if d.Frequency == "weekly" {
+		data.Period = "this week"
+	}
+
+	var text, html bytes.Buffer
+	if err := textTemplate.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("failed to render text: %w", err)
+	}
+	if err := htmlTemplate.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("failed to render html: %w", err)
+	}
+
+	return &mail.Message{
+		Subject: data.Subject,
+		Text:    text.String(),
+		HTML:    html.String(),
+		// one-click unsubscribe of RFC 8058
+		Headers: map[string]string{
+			"List-Unsubscribe":      "<" + d.UnsubscribeURL + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+	}, nil
+}
+
+func subject(d *Digest) string {
+	if len(d.Articles) == 1 {
+		return fmt.Sprintf("Your %s digest: %s", d.Frequency, d.Articles[0].Title)
+	}
+	return fmt.Sprintf("Your %s digest: %s and %d more", d.Frequency, d.Articles[0].Title, len(d.Articles)-1)
+}
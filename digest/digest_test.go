@@ -0,0 +1,51 @@
+package digest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompose(t *testing.T) {
+	d := &Digest{
+		Username:  "foo",
+		Frequency: "weekly",
+		Articles: []Article{
+			{
+				Title:          "Tips & <tricks>",
+				Description:    "how to",
+				Author:         "bar",
+				URL:            "http://localhost:3000/articles/1",
+				FavoritesCount: 2,
+			},
+			{Title: "second", Author: "baz", URL: "http://localhost:3000/articles/2"},
+		},
+		UnsubscribeURL: "http://localhost:3000/digest/unsubscribe?token=t",
+	}
+
+	m, err := Compose(d)
+	if err != nil {
+		t.Fatalf("compose expected to succeed, but failed. %v", err)
+	}
+
+	assert.Equal(t, "Your weekly digest: Tips & <tricks> and 1 more", m.Subject)
+	assert.Equal(t, "<http://localhost:3000/digest/unsubscribe?token=t>", m.Headers["List-Unsubscribe"])
+
+	assert.Contains(t, m.Text, "top 2 new articles from the people you follow this week")
+	assert.Contains(t, m.Text, "Tips & <tricks>\nby bar · 2 favorites\nhow to\nhttp://localhost:3000/articles/1\n")
+	assert.Contains(t, m.Text, "second\nby baz\nhttp://localhost:3000/articles/2\n")
+	assert.Contains(t, m.Text, "Unsubscribe: http://localhost:3000/digest/unsubscribe?token=t")
+
+	assert.Contains(t, m.HTML, `<a href="http://localhost:3000/articles/1" style="color: #5cb85c; text-decoration: none;">Tips &amp; &lt;tricks&gt;</a>`)
+	assert.NotContains(t, m.HTML, "<tricks>")
+	assert.Contains(t, m.HTML, `href="http://localhost:3000/digest/unsubscribe?token=t"`)
+
+	d.Frequency = "daily"
+	d.Articles = d.Articles[1:]
+	m, err = Compose(d)
+	if err != nil {
+		t.Fatalf("compose expected to succeed, but failed. %v", err)
+	}
+	assert.Equal(t, "Your daily digest: second", m.Subject)
+	assert.Contains(t, m.Text, "Here is the new article from the people you follow today.")
+}
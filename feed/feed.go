@@ -0,0 +1,199 @@
+// Package feed renders article lists as RSS 2.0 and Atom documents
+package feed
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"time"
+)
+
+// Feed is a list of articles
+type Feed struct {
+	Title       string
+	Description string
+	Link        string // the page listing the articles
+	Self        string // the URL of the feed document
+	Items       []Item
+}
+
+// Item is an article in a feed
+type Item struct {
+	Title      string
+	Link       string
+	Summary    string // plain text
+	Content    string // HTML
+	Author     string
+	Categories []string
+	Published  time.Time
+	Updated    time.Time
+}
+
+// Updated returns the time the newest item was updated, or the zero time
+// when there are no items
+func (f *Feed) Updated() time.Time {
+	var t time.Time
+	for _, it := range f.Items {
+		if it.Updated.After(t) {
+			t = it.Updated
+		}
+	}
+	return t
+}
+
+type rss struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	AtomNS    string     `xml:"xmlns:atom,attr"`
+	ContentNS string     `xml:"xmlns:content,attr"`
+	DCNS      string     `xml:"xmlns:dc,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	Description string   `xml:"description,omitempty"`
+	Content     cdata    `xml:"content:encoded"`
+	Creator     string   `xml:"dc:creator,omitempty"`
+	Categories  []string `xml:"category"`
+	PubDate     string   `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type cdata struct {
+	Value string `xml:",cdata"`
+}
+
+// RSS renders f as an RSS 2.0 document. The HTML of items is in content:encoded.
+func RSS(f *Feed) ([]byte, error) {
+	doc := rss{
+		Version:   "2.0",
+		AtomNS:    "http://www.w3.org/2005/Atom",
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		DCNS:      "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:       f.Title,
+			Link:        f.Link,
+			Description: f.Description,
+			AtomLink:    atomLink{Href: f.Self, Rel: "self", Type: "application/rss+xml"},
+		},
+	}
+	if t := f.Updated(); !t.IsZero() {
+		doc.Channel.LastBuildDate = t.UTC().Format(time.RFC1123Z)
+	}
+
+	// readers take descriptions as HTML, and summaries are plain text
+	for _, it := range f.Items {
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       it.Title,
+			Link:        it.Link,
+			GUID:        rssGUID{IsPermaLink: true, Value: it.Link},
+			Description: html.EscapeString(it.Summary),
+			Content:     cdata{it.Content},
+			Creator:     it.Author,
+			Categories:  it.Categories,
+			PubDate:     it.Published.UTC().Format(time.RFC1123Z),
+		})
+	}
+
+	return marshal(doc)
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     atomPerson     `xml:"author"`
+	Categories []atomCategory `xml:"category"`
+	Summary    *atomText      `xml:"summary,omitempty"`
+	Content    atomText       `xml:"content"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// Atom renders f as an Atom document. Item links are used as entry ids.
+func Atom(f *Feed) ([]byte, error) {
+	doc := atomFeed{
+		ID:       f.Self,
+		Title:    f.Title,
+		Subtitle: f.Description,
+		Updated:  f.Updated().UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: f.Self, Rel: "self", Type: "application/atom+xml"},
+			{Href: f.Link, Rel: "alternate", Type: "text/html"},
+		},
+	}
+
+	for _, it := range f.Items {
+		e := atomEntry{
+			ID:        it.Link,
+			Title:     it.Title,
+			Link:      atomLink{Href: it.Link, Rel: "alternate", Type: "text/html"},
+			Published: it.Published.UTC().Format(time.RFC3339),
+			Updated:   it.Updated.UTC().Format(time.RFC3339),
+			Author:    atomPerson{Name: it.Author},
+			Content:   atomText{Type: "html", Value: it.Content},
+		}
+		if it.Summary != "" {
+			e.Summary = &atomText{Type: "text", Value: it.Summary}
+		}
+		for _, c := range it.Categories {
+			e.Categories = append(e.Categories, atomCategory{Term: c})
+		}
+		doc.Entries = append(doc.Entries, e)
+	}
+
+	return marshal(doc)
+}
+
+func marshal(v interface{}) ([]byte, error) {
+	b, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal feed: %w", err)
+	}
+	return append([]byte(xml.Header), b...), nil
+}
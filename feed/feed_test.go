@@ -0,0 +1,198 @@
+package feed
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testFeed() *Feed {
+	published := time.Date(2020, 4, 1, 9, 0, 0, 0, time.UTC)
+	return &Feed{
+		Title:       "Conduit: #go",
+		Description: "Articles tagged go",
+		Link:        "http://localhost:3000/?tag=go",
+		Self:        "http://localhost:3000/feeds/tags/go.rss",
+		Items: []Item{
+			{
+				Title:      "Generics & <you>",
+				Link:       "http://localhost:3000/articles/2",
+				Summary:    "what changes",
+				Content:    "<p>It's <strong>here</strong>]]></p>",
+				Author:     "foo",
+				Categories: []string{"go", "generics"},
+				Published:  published.Add(time.Hour),
+				Updated:    published.Add(2 * time.Hour),
+			},
+			{
+				Title:     "Hello",
+				Link:      "http://localhost:3000/articles/1",
+				Content:   "<p>hi</p>",
+				Author:    "bar",
+				Published: published,
+				Updated:   published,
+			},
+		},
+	}
+}
+
+func TestRSS(t *testing.T) {
+	b, err := RSS(testFeed())
+	if err != nil {
+		t.Fatalf("rss expected to succeed, but failed. %v", err)
+	}
+
+	var doc struct {
+		Channel struct {
+			Title         string `xml:"title"`
+			LastBuildDate string `xml:"lastBuildDate"`
+			Items         []struct {
+				Title      string   `xml:"title"`
+				GUID       string   `xml:"guid"`
+				Content    string   `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+				Creator    string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+				Categories []string `xml:"category"`
+				PubDate    string   `xml:"pubDate"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	if err := xml.Unmarshal(b, &doc); err != nil {
+		t.Fatalf("failed to parse rss: %v\n%s", err, b)
+	}
+
+	assert.Equal(t, "Conduit: #go", doc.Channel.Title)
+	assert.Equal(t, "Wed, 01 Apr 2020 11:00:00 +0000", doc.Channel.LastBuildDate)
+	if assert.Len(t, doc.Channel.Items, 2) {
+		it := doc.Channel.Items[0]
+		assert.Equal(t, "Generics & <you>", it.Title)
+		assert.Equal(t, "http://localhost:3000/articles/2", it.GUID)
+		assert.Equal(t, "<p>It's <strong>here</strong>]]></p>", it.Content)
+		assert.Equal(t, "foo", it.Creator)
+		assert.Equal(t, []string{"go", "generics"}, it.Categories)
+		assert.Equal(t, "Wed, 01 Apr 2020 10:00:00 +0000", it.PubDate)
+	}
+}
+
+func TestRSSEscapesSummary(t *testing.T) {
+	f := testFeed()
+	f.Items[0].Summary = "<img src=x onerror=alert(1)> & more"
+
+	b, err := RSS(f)
+	if err != nil {
+		t.Fatalf("rss expected to succeed, but failed. %v", err)
+	}
+
+	var doc struct {
+		Channel struct {
+			Items []struct {
+				Description string `xml:"description"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	if err := xml.Unmarshal(b, &doc); err != nil {
+		t.Fatalf("failed to parse rss: %v\n%s", err, b)
+	}
+
+	// readers decode the XML and render the description as HTML
+	if assert.Len(t, doc.Channel.Items, 2) {
+		assert.Equal(t, "&lt;img src=x onerror=alert(1)&gt; &amp; more", doc.Channel.Items[0].Description)
+	}
+}
+
+func TestAtom(t *testing.T) {
+	b, err := Atom(testFeed())
+	if err != nil {
+		t.Fatalf("atom expected to succeed, but failed. %v", err)
+	}
+
+	var doc struct {
+		XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+		ID      string   `xml:"id"`
+		Updated string   `xml:"updated"`
+		Entries []struct {
+			ID      string `xml:"id"`
+			Author  string `xml:"author>name"`
+			Summary string `xml:"summary"`
+			Content struct {
+				Type  string `xml:"type,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"content"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(b, &doc); err != nil {
+		t.Fatalf("failed to parse atom: %v\n%s", err, b)
+	}
+
+	assert.Equal(t, "http://localhost:3000/feeds/tags/go.rss", doc.ID)
+	assert.Equal(t, "2020-04-01T11:00:00Z", doc.Updated)
+	if assert.Len(t, doc.Entries, 2) {
+		e := doc.Entries[0]
+		assert.Equal(t, "http://localhost:3000/articles/2", e.ID)
+		assert.Equal(t, "foo", e.Author)
+		assert.Equal(t, "what changes", e.Summary)
+		assert.Equal(t, "html", e.Content.Type)
+		assert.Equal(t, "<p>It's <strong>here</strong>]]></p>", e.Content.Value)
+	}
+}
+
+func TestServe(t *testing.T) {
+	f := testFeed()
+	serve := func(header http.Header) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/feeds/tags/go.atom", nil)
+		for k := range header {
+			r.Header.Set(k, header.Get(k))
+		}
+		w := httptest.NewRecorder()
+		Serve(w, r, f, Formats[".atom"])
+		return w
+	}
+
+	w := serve(nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/atom+xml; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "Wed, 01 Apr 2020 11:00:00 GMT", w.Header().Get("Last-Modified"))
+	etag := w.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	tests := []struct {
+		title  string
+		header http.Header
+		code   int
+	}{
+		{
+			"same etag: not modified",
+			http.Header{"If-None-Match": {etag}},
+			http.StatusNotModified,
+		},
+		{
+			"not modified since: not modified",
+			http.Header{"If-Modified-Since": {"Wed, 01 Apr 2020 11:00:00 GMT"}},
+			http.StatusNotModified,
+		},
+		{
+			"other etag: ok",
+			http.Header{"If-None-Match": {`"stale"`}},
+			http.StatusOK,
+		},
+		{
+			"modified since: ok",
+			http.Header{"If-Modified-Since": {"Wed, 01 Apr 2020 10:00:00 GMT"}},
+			http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		w := serve(tt.header)
+		assert.Equal(t, tt.code, w.Code, tt.title)
+	}
+
+	// the etag changes with the content
+	f.Items = f.Items[1:]
+	w = serve(http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+}
@@ -0,0 +1,43 @@
+package feed
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+)
+
+// Format is a feed document format
+type Format struct {
+	ContentType string
+	Render      func(*Feed) ([]byte, error)
+}
+
+// Formats are the feed formats by the file extension of feed URLs
+var Formats = map[string]Format{
+	".rss":  {ContentType: "application/rss+xml; charset=utf-8", Render: RSS},
+	".atom": {ContentType: "application/atom+xml; charset=utf-8", Render: Atom},
+}
+
+// Serve writes f rendered in the format. The ETag is a hash of the document
+// and Last-Modified is the time the newest item was updated, so that feed
+// readers polling with If-None-Match or If-Modified-Since get 304 Not Modified
+// until the feed changes.
+func Serve(w http.ResponseWriter, r *http.Request, f *Feed, format Format) {
+	b, err := format.Render(f)
+	if err != nil {
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	sum := sha256.Sum256(b)
+	w.Header().Set("Content-Type", format.ContentType)
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	if w.Header().Get("Cache-Control") == "" {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+
+	// ServeContent checks the preconditions and leaves Last-Modified out for
+	// the zero time
+	http.ServeContent(w, r, "", f.Updated(), bytes.NewReader(b))
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"google.golang.org/grpc/status"
+
+	gw "github.com/raahii/golang-grpc-realworld-example/proto"
+)
+
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Unsubscribe</title></head>
+<body style="font-family: sans-serif;">
+{{if .Done}}<p>You have been unsubscribed from the email digest.</p>
+{{else}}<form method="post">
+<p>Stop receiving the email digest?</p>
+<input type="hidden" name="token" value="{{.Token}}">
+<button type="submit">Unsubscribe</button>
+</form>
+{{end}}</body>
+</html>
+`))
+
+// unsubscribeHandler serves the unsubscribe links of digest emails.
+// Mail clients supporting one-click unsubscribe POST to the link directly;
+// opening the link shows a confirmation form instead, so that link scanners
+// don't unsubscribe anyone.
+func unsubscribeHandler(client gw.UsersClient) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+		data := struct {
+			Token string
+			Done  bool
+		}{Token: token}
+
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			if t := r.PostFormValue("token"); t != "" {
+				token = t
+			}
+
+			_, err := client.Unsubscribe(r.Context(), &gw.UnsubscribeRequest{Token: token})
+			if err != nil {
+				s := status.Convert(err)
+				http.Error(w, s.Message(), runtime.HTTPStatusFromCode(s.Code()))
+				return
+			}
+			data.Done = true
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		unsubscribePage.Execute(w, data)
+	})
+}
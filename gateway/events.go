@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	gw "github.com/raahii/golang-grpc-realworld-example/proto"
+)
+
+// eventsHandler serves the event stream as Server-Sent Events.
+// EventSource sends the id of the last received event in Last-Event-ID when it
+// reconnects; other clients can pass lastEventId in the query instead.
+// Articles to watch for new comments are given by ?watch={slug}&watch={slug}.
+func eventsHandler(client gw.EventsClient) http.Handler {
+	m := &runtime.JSONPb{OrigName: true, EmitDefaults: true}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+			return
+		}
+
+		ctx := r.Context()
+		if a := r.Header.Get("Authorization"); a != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "authorization", a)
+		}
+
+		lastID := r.Header.Get("Last-Event-ID")
+		if lastID == "" {
+			lastID = r.URL.Query().Get("lastEventId")
+		}
+
+		stream, err := client.Subscribe(ctx, &gw.SubscribeRequest{
+			LastEventId: lastID,
+			Watch:       r.URL.Query()["watch"],
+		})
+		if err != nil {
+			s := status.Convert(err)
+			http.Error(w, s.Message(), runtime.HTTPStatusFromCode(s.Code()))
+			return
+		}
+
+		// the server sends an event as soon as the subscription is accepted,
+		// so errors can still be told by the status code
+		e, err := stream.Recv()
+		if err != nil {
+			s := status.Convert(err)
+			http.Error(w, s.Message(), runtime.HTTPStatusFromCode(s.Code()))
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		for {
+			if err := writeEvent(w, m, e); err != nil {
+				return
+			}
+			flusher.Flush()
+
+			e, err = stream.Recv()
+			if err == io.EOF || ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				// EventSource reconnects with Last-Event-ID after the stream ends
+				fmt.Fprintf(w, "event: error\ndata: %s\n\n", status.Convert(err).Message())
+				flusher.Flush()
+				return
+			}
+		}
+	})
+}
+
+// writeEvent writes e in the text/event-stream format.
+// Heartbeats are written as comments, which EventSource ignores.
+func writeEvent(w io.Writer, m runtime.Marshaler, e *gw.Event) error {
+	if e.GetType() == "heartbeat" {
+		_, err := io.WriteString(w, ": heartbeat\n\n")
+		return err
+	}
+
+	b, err := m.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	if e.GetId() != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", e.GetId()); err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.GetType(), b)
+	return err
+}
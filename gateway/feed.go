@@ -0,0 +1,127 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/raahii/golang-grpc-realworld-example/auth"
+	"github.com/raahii/golang-grpc-realworld-example/feed"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	gw "github.com/raahii/golang-grpc-realworld-example/proto"
+)
+
+const defaultPublicURL = "http://localhost:3000"
+
+// publicURL reads $PUBLIC_URL, the URL of the gateway linked from feeds
+func publicURL() string {
+	if u := os.Getenv("PUBLIC_URL"); u != "" {
+		return strings.TrimSuffix(u, "/")
+	}
+	return defaultPublicURL
+}
+
+// feedsHandler serves the article lists as RSS and Atom documents:
+//
+//	/feeds/articles.rss                    all articles
+//	/feeds/tags/{tag}.rss                  articles with the tag
+//	/feeds/profiles/{username}.rss         articles of the user
+//	/feeds/personal.rss?token=rwpat_...    articles of the users the token owner follows
+//
+// and the same with .atom. The personal feed takes a personal access token
+// with the read scope in the query, since feed readers can't send headers.
+func feedsHandler(client gw.ArticlesClient) http.Handler {
+	base := publicURL()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		ext := path.Ext(r.URL.Path)
+		format, ok := feed.Formats[ext]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/feeds/"), ext)
+
+		f := &feed.Feed{Self: base + r.URL.Path}
+		var (
+			resp *gw.ArticlesResponse
+			err  error
+		)
+		switch dir, key := path.Split(name); {
+		case name == "articles":
+			f.Title = "Conduit"
+			f.Description = "Recent articles"
+			f.Link = base + "/articles"
+			resp, err = client.GetArticles(r.Context(), &gw.GetArticlesRequest{Sort: model.SortNewest})
+		case dir == "tags/" && key != "":
+			f.Title = "Conduit: #" + key
+			f.Description = "Recent articles tagged " + key
+			f.Link = base + "/articles?tag=" + url.QueryEscape(key)
+			resp, err = client.GetArticles(r.Context(), &gw.GetArticlesRequest{Tag: key, Sort: model.SortNewest})
+		case dir == "profiles/" && key != "":
+			f.Title = "Conduit: " + key
+			f.Description = "Recent articles by " + key
+			f.Link = base + "/profiles/" + url.PathEscape(key)
+			resp, err = client.GetArticles(r.Context(), &gw.GetArticlesRequest{Author: key, Sort: model.SortNewest})
+		case name == "personal":
+			token := r.URL.Query().Get("token")
+			if !strings.HasPrefix(token, auth.APITokenPrefix) {
+				http.Error(w, "a personal access token with the read scope is required", http.StatusUnauthorized)
+				return
+			}
+			ctx := metadata.AppendToOutgoingContext(r.Context(), "authorization", "Token "+token)
+
+			f.Title = "Conduit: your feed"
+			f.Description = "Recent articles of the people you follow"
+			f.Link = base + "/articles/feed"
+			resp, err = client.GetFeedArticles(ctx, &gw.GetFeedArticlesRequest{})
+			w.Header().Set("Cache-Control", "private, no-cache")
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			s := status.Convert(err)
+			http.Error(w, s.Message(), runtime.HTTPStatusFromCode(s.Code()))
+			return
+		}
+
+		for _, a := range resp.GetArticles() {
+			f.Items = append(f.Items, feedItem(base, a))
+		}
+
+		feed.Serve(w, r, f, format)
+	})
+}
+
+// feedItem converts an article into a feed item with its rendered body
+func feedItem(base string, a *gw.Article) feed.Item {
+	publishedAt := a.GetPublishedAt()
+	if publishedAt == "" {
+		publishedAt = a.GetCreatedAt()
+	}
+	published, _ := time.Parse(model.ISO8601, publishedAt)
+	updated, _ := time.Parse(model.ISO8601, a.GetUpdatedAt())
+
+	return feed.Item{
+		Title:      a.GetTitle(),
+		Link:       base + "/articles/" + url.PathEscape(a.GetSlug()),
+		Summary:    a.GetExcerpt(),
+		Content:    a.GetBodyHtml(),
+		Author:     a.GetAuthor().GetUsername(),
+		Categories: a.GetTagList(),
+		Published:  published,
+		Updated:    updated,
+	}
+}
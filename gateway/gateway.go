@@ -9,7 +9,9 @@ import (
 	"github.com/grpc-ecosystem/grpc-gateway/runtime"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
 
+	"github.com/raahii/golang-grpc-realworld-example/oidc"
 	gw "github.com/raahii/golang-grpc-realworld-example/proto"
 )
 
@@ -41,8 +43,81 @@ func run() error {
 		return err
 	}
 
+	// admin
+	err = gw.RegisterAdminHandlerFromEndpoint(ctx, mux, *echoEndpoint, opts)
+	if err != nil {
+		return err
+	}
+
+	// notifications
+	err = gw.RegisterNotificationsHandlerFromEndpoint(ctx, mux, *echoEndpoint, opts)
+	if err != nil {
+		return err
+	}
+
+	// webhooks
+	err = gw.RegisterWebhooksHandlerFromEndpoint(ctx, mux, *echoEndpoint, opts)
+	if err != nil {
+		return err
+	}
+
+	// reading lists
+	err = gw.RegisterReadingListsHandlerFromEndpoint(ctx, mux, *echoEndpoint, opts)
+	if err != nil {
+		return err
+	}
+
+	conn, err := grpc.DialContext(ctx, *echoEndpoint, opts...)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	root := http.NewServeMux()
+	root.Handle("/", mux)
+
+	// server-sent events
+	root.Handle("/events", eventsHandler(gw.NewEventsClient(conn)))
+
+	// RSS and Atom feeds
+	root.Handle("/feeds/", feedsHandler(gw.NewArticlesClient(conn)))
+
+	// one-click unsubscribe links of digest emails
+	root.Handle("/digest/unsubscribe", unsubscribeHandler(gw.NewUsersClient(conn)))
+
+	// external login
+	if idp := oidc.NewProviderFromEnv(); idp != nil {
+		root.Handle("/oauth/login", idp.LoginHandler())
+		root.Handle("/oauth/callback", idp.CallbackHandler(oidcLogin(gw.NewUsersClient(conn))))
+	}
+
 	log.Println("starting gateway server on port 3000")
-	return http.ListenAndServe(":3000", mux)
+	return http.ListenAndServe(":3000", root)
+}
+
+// oidcLogin passes the ID token to the grpc server and writes the signed in user
+func oidcLogin(client gw.UsersClient) oidc.LoginFunc {
+	m := &runtime.JSONPb{OrigName: true, EmitDefaults: true}
+	return func(w http.ResponseWriter, r *http.Request, rawIDToken, nonce string) {
+		resp, err := client.LoginOIDC(r.Context(), &gw.LoginOIDCRequest{
+			IdToken: rawIDToken,
+			Nonce:   nonce,
+		})
+		if err != nil {
+			s := status.Convert(err)
+			http.Error(w, s.Message(), runtime.HTTPStatusFromCode(s.Code()))
+			return
+		}
+
+		b, err := m.Marshal(resp)
+		if err != nil {
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", m.ContentType())
+		w.Write(b)
+	}
 }
 
 func main() {
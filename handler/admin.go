@@ -0,0 +1,412 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/raahii/golang-grpc-realworld-example/auth"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	"github.com/raahii/golang-grpc-realworld-example/policy"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// currentModerator returns current user if the user can moderate
+func (h *Handler) currentModerator(ctx context.Context) (*model.User, error) {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		msg := "unauthenticated"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Errorf(codes.Unauthenticated, msg)
+	}
+
+	currentUser, err := h.us.GetByID(userID)
+	if err != nil {
+		msg := "not user found"
+		err = fmt.Errorf("token is valid but the user not found: %w", err)
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.NotFound, msg)
+	}
+
+	if !policy.CanModerate(currentUser) {
+		msg := fmt.Sprintf("user(id=%d) attempted to use admin api", currentUser.ID)
+		h.logger.Error().Msg(msg)
+		return nil, status.Error(codes.PermissionDenied, "forbidden")
+	}
+
+	return currentUser, nil
+}
+
+// recordAction adds a decision to the moderation log. The decision has
+// already been applied, so failures are only logged.
+func (h *Handler) recordAction(a *model.ModerationAction) {
+	if err := h.as.CreateModerationAction(a); err != nil {
+		h.logger.Error().Err(err).Msg("failed to record moderation action")
+	}
+}
+
+// ListUsers lists users for moderators
+func (h *Handler) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.AdminUsersResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("list users")
+
+	currentUser, err := h.currentModerator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !policy.CanListUsers(currentUser) {
+		h.logger.Error().Msgf("user(id=%d) attempted to list users", currentUser.ID)
+		return nil, status.Error(codes.PermissionDenied, "forbidden")
+	}
+
+	limitQuery := pageLimit(req.GetLimit())
+
+	us, count, err := h.us.GetUsers(req.GetRole(), req.GetStatus(), limitQuery, req.GetOffset())
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to search users in the database")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	pus := make([]*pb.AdminUser, 0, len(us))
+	for _, u := range us {
+		pus = append(pus, u.ProtoAdminUser())
+	}
+
+	return &pb.AdminUsersResponse{Users: pus, UsersCount: int32(count)}, nil
+}
+
+// SuspendUser suspends a user for days, or indefinitely when days is zero
+func (h *Handler) SuspendUser(ctx context.Context, req *pb.SuspendUserRequest) (*pb.AdminUserResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("suspend user")
+
+	currentUser, err := h.currentModerator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.GetDays() < 0 {
+		msg := "days must not be negative"
+		h.logger.Error().Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, msg)
+	}
+
+	requestUser, err := h.us.GetByUsername(req.GetUsername())
+	if err != nil {
+		msg := "user was not found"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.NotFound, msg)
+	}
+
+	if !policy.CanSuspend(currentUser, requestUser) {
+		h.logger.Error().Msgf("user(id=%d) attempted to suspend user(id=%d)",
+			currentUser.ID, requestUser.ID)
+		return nil, status.Error(codes.PermissionDenied, "forbidden")
+	}
+
+	if policy.IsBanned(requestUser) {
+		msg := "user is banned"
+		h.logger.Error().Msg(msg)
+		return nil, status.Error(codes.FailedPrecondition, msg)
+	}
+
+	requestUser.Status = model.StatusSuspended
+	requestUser.SuspendedUntil = nil
+	if days := req.GetDays(); days > 0 {
+		t := time.Now().AddDate(0, 0, int(days))
+		requestUser.SuspendedUntil = &t
+	}
+
+	if err := h.us.UpdateStatus(requestUser); err != nil {
+		msg := "failed to suspend user"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, msg)
+	}
+	h.indexUser(requestUser)
+
+	return &pb.AdminUserResponse{User: requestUser.ProtoAdminUser()}, nil
+}
+
+// BanUser bans a user permanently
+func (h *Handler) BanUser(ctx context.Context, req *pb.BanUserRequest) (*pb.AdminUserResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("ban user")
+
+	currentUser, err := h.currentModerator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	requestUser, err := h.us.GetByUsername(req.GetUsername())
+	if err != nil {
+		msg := "user was not found"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.NotFound, msg)
+	}
+
+	if !policy.CanBan(currentUser, requestUser) {
+		h.logger.Error().Msgf("user(id=%d) attempted to ban user(id=%d)",
+			currentUser.ID, requestUser.ID)
+		return nil, status.Error(codes.PermissionDenied, "forbidden")
+	}
+
+	requestUser.Status = model.StatusBanned
+	requestUser.SuspendedUntil = nil
+	if err := h.us.UpdateStatus(requestUser); err != nil {
+		msg := "failed to ban user"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, msg)
+	}
+	h.indexUser(requestUser)
+
+	return &pb.AdminUserResponse{User: requestUser.ProtoAdminUser()}, nil
+}
+
+// ReinstateUser lifts a suspension or a ban
+func (h *Handler) ReinstateUser(ctx context.Context, req *pb.ReinstateUserRequest) (*pb.AdminUserResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("reinstate user")
+
+	currentUser, err := h.currentModerator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	requestUser, err := h.us.GetByUsername(req.GetUsername())
+	if err != nil {
+		msg := "user was not found"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.NotFound, msg)
+	}
+
+	// lifting a ban needs the same privilege as banning
+	allowed := policy.CanSuspend(currentUser, requestUser)
+	if policy.IsBanned(requestUser) {
+		allowed = policy.CanBan(currentUser, requestUser)
+	}
+
+	if !allowed {
+		h.logger.Error().Msgf("user(id=%d) attempted to reinstate user(id=%d)",
+			currentUser.ID, requestUser.ID)
+		return nil, status.Error(codes.PermissionDenied, "forbidden")
+	}
+
+	requestUser.Status = model.StatusActive
+	requestUser.SuspendedUntil = nil
+	if err := h.us.UpdateStatus(requestUser); err != nil {
+		msg := "failed to reinstate user"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, msg)
+	}
+	h.indexUser(requestUser)
+
+	return &pb.AdminUserResponse{User: requestUser.ProtoAdminUser()}, nil
+}
+
+// SetUserRole changes the role of a user
+func (h *Handler) SetUserRole(ctx context.Context, req *pb.SetUserRoleRequest) (*pb.AdminUserResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("set user role")
+
+	currentUser, err := h.currentModerator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !policy.IsValidRole(req.GetRole()) {
+		msg := fmt.Sprintf("unknown role: %q", req.GetRole())
+		h.logger.Error().Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, msg)
+	}
+
+	requestUser, err := h.us.GetByUsername(req.GetUsername())
+	if err != nil {
+		msg := "user was not found"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.NotFound, msg)
+	}
+
+	if !policy.CanAssignRole(currentUser, requestUser) {
+		h.logger.Error().Msgf("user(id=%d) attempted to change role of user(id=%d)",
+			currentUser.ID, requestUser.ID)
+		return nil, status.Error(codes.PermissionDenied, "forbidden")
+	}
+
+	requestUser.Role = req.GetRole()
+	if err := h.us.UpdateRole(requestUser); err != nil {
+		msg := "failed to change role"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, msg)
+	}
+
+	return &pb.AdminUserResponse{User: requestUser.ProtoAdminUser()}, nil
+}
+
+// RemoveArticle deletes an article of any user
+func (h *Handler) RemoveArticle(ctx context.Context, req *pb.RemoveArticleRequest) (*pb.Empty, error) {
+	h.logger.Info().Interface("req", req).Msg("remove article")
+
+	currentUser, err := h.currentModerator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	slug := req.GetSlug()
+	articleID, err := strconv.Atoi(slug)
+	if err != nil {
+		msg := fmt.Sprintf("cannot convert slug (%s) into integer", slug)
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, "invalid article id")
+	}
+
+	article, err := h.as.GetByID(uint(articleID))
+	if err != nil {
+		msg := fmt.Sprintf("requested article (slug=%d) not found", articleID)
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, "invalid article id")
+	}
+
+	if !policy.CanDeleteArticle(currentUser, article) {
+		h.logger.Error().Msgf("user(id=%d) attempted to remove article(id=%d)",
+			currentUser.ID, article.ID)
+		return nil, status.Error(codes.PermissionDenied, "forbidden")
+	}
+
+	if err := h.as.Delete(article); err != nil {
+		msg := "failed to delete article"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, msg)
+	}
+	h.unindexArticle(article.ID)
+
+	h.logger.Info().Msgf("user(id=%d) removed article(id=%d)", currentUser.ID, article.ID)
+	h.recordAction(&model.ModerationAction{
+		ModeratorID: currentUser.ID,
+		Action:      model.ActionRemove,
+		ContentType: model.ContentArticle,
+		ArticleID:   article.ID,
+	})
+
+	return &pb.Empty{}, nil
+}
+
+// RemoveComment deletes a comment of any user
+func (h *Handler) RemoveComment(ctx context.Context, req *pb.RemoveCommentRequest) (*pb.Empty, error) {
+	h.logger.Info().Interface("req", req).Msg("remove comment")
+
+	currentUser, err := h.currentModerator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	commentID, err := strconv.Atoi(req.GetId())
+	if err != nil {
+		msg := fmt.Sprintf("cannot convert id (%s) into integer", req.GetId())
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, "invalid comment id")
+	}
+
+	comment, err := h.as.GetCommentByID(uint(commentID))
+	if err != nil {
+		msg := "failed to get comment"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, msg)
+	}
+
+	if req.GetSlug() != fmt.Sprintf("%d", comment.ArticleID) {
+		msg := "the comment is not in the article"
+		h.logger.Error().Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, msg)
+	}
+
+	if !policy.CanDeleteComment(currentUser, comment) {
+		h.logger.Error().Msgf("user(id=%d) attempted to remove comment(id=%d)",
+			currentUser.ID, comment.ID)
+		return nil, status.Error(codes.PermissionDenied, "forbidden")
+	}
+
+	if err := h.as.DeleteComment(comment); err != nil {
+		msg := "failed to delete comment"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, msg)
+	}
+
+	h.logger.Info().Msgf("user(id=%d) removed comment(id=%d)", currentUser.ID, comment.ID)
+	h.recordAction(&model.ModerationAction{
+		ModeratorID: currentUser.ID,
+		Action:      model.ActionRemove,
+		ContentType: model.ContentComment,
+		ArticleID:   comment.ArticleID,
+		CommentID:   comment.ID,
+	})
+
+	return &pb.Empty{}, nil
+}
+
+// ListCommentRevisions returns previous versions of an edited comment
+func (h *Handler) ListCommentRevisions(ctx context.Context, req *pb.ListCommentRevisionsRequest) (*pb.CommentRevisionsResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("list comment revisions")
+
+	if _, err := h.currentModerator(ctx); err != nil {
+		return nil, err
+	}
+
+	commentID, err := strconv.Atoi(req.GetId())
+	if err != nil {
+		msg := fmt.Sprintf("cannot convert id (%s) into integer", req.GetId())
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, "invalid comment id")
+	}
+
+	comment, err := h.as.GetCommentByID(uint(commentID))
+	if err != nil {
+		msg := "failed to get comment"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, msg)
+	}
+
+	if req.GetSlug() != fmt.Sprintf("%d", comment.ArticleID) {
+		msg := "the comment is not in the article"
+		h.logger.Error().Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, msg)
+	}
+
+	rs, err := h.as.GetCommentRevisions(comment)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to get comment revisions")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	prs := make([]*pb.CommentRevision, 0, len(rs))
+	for _, r := range rs {
+		prs = append(prs, r.ProtoCommentRevision())
+	}
+
+	return &pb.CommentRevisionsResponse{Revisions: prs}, nil
+}
+
+// GetModerationQueue returns content to be reviewed by moderators
+func (h *Handler) GetModerationQueue(ctx context.Context, req *pb.GetModerationQueueRequest) (*pb.ModerationQueueResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("get moderation queue")
+
+	if _, err := h.currentModerator(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := h.checkPage(req.GetLimit(), req.GetOffset()); err != nil {
+		return nil, err
+	}
+	limitQuery := pageLimit(req.GetLimit())
+
+	items, err := h.as.GetModerationQueue(limitQuery, req.GetOffset())
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to get moderation queue")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	pis := make([]*pb.ModerationItem, 0, len(items))
+	for _, i := range items {
+		pis = append(pis, i.ProtoModerationItem())
+	}
+
+	return &pb.ModerationQueueResponse{Items: pis}, nil
+}
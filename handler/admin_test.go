@@ -0,0 +1,575 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/raahii/golang-grpc-realworld-example/auth"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func createAdminTestUsers(t *testing.T, h *Handler) (admin, moderator, user model.User) {
+	admin = model.User{
+		Username: "admin",
+		Email:    "admin@example.com",
+		Password: "secret",
+		Role:     model.RoleAdmin,
+	}
+
+	moderator = model.User{
+		Username: "moderator",
+		Email:    "moderator@example.com",
+		Password: "secret",
+		Role:     model.RoleModerator,
+	}
+
+	user = model.User{
+		Username: "foo",
+		Email:    "foo@example.com",
+		Password: "secret",
+	}
+
+	for _, u := range []*model.User{&admin, &moderator, &user} {
+		if err := h.us.Create(u); err != nil {
+			t.Fatalf("failed to create initial user record: %v", err)
+		}
+	}
+
+	return admin, moderator, user
+}
+
+func TestListUsers(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+
+	admin, moderator, user := createAdminTestUsers(t, h)
+
+	tests := []struct {
+		title    string
+		reqUser  *model.User
+		req      *pb.ListUsersRequest
+		expected []string
+		hasError bool
+	}{
+		{
+			"list all users by moderator: success",
+			&moderator,
+			&pb.ListUsersRequest{},
+			[]string{admin.Username, moderator.Username, user.Username},
+			false,
+		},
+		{
+			"list moderators by admin: success",
+			&admin,
+			&pb.ListUsersRequest{Role: model.RoleModerator},
+			[]string{moderator.Username},
+			false,
+		},
+		{
+			"list users by user: failed",
+			&user,
+			&pb.ListUsersRequest{},
+			nil,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		token, err := auth.GenerateToken(tt.reqUser.ID)
+		if err != nil {
+			t.Error(err)
+		}
+
+		resp, err := h.ListUsers(ctxWithToken(context.Background(), token), tt.req)
+		if tt.hasError {
+			if err == nil {
+				t.Errorf("%q expected to fail, but succeeded.", tt.title)
+				t.FailNow()
+			}
+			continue
+		}
+
+		if !tt.hasError && err != nil {
+			t.Errorf("%q expected to succeed, but failed. %v", tt.title, err)
+			t.FailNow()
+		}
+
+		usernames := make([]string, 0, len(resp.GetUsers()))
+		for _, u := range resp.GetUsers() {
+			usernames = append(usernames, u.GetUsername())
+		}
+		assert.Equal(t, tt.expected, usernames)
+		assert.Equal(t, int32(len(tt.expected)), resp.GetUsersCount())
+	}
+}
+
+func TestSuspendUser(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+
+	admin, moderator, user := createAdminTestUsers(t, h)
+
+	tests := []struct {
+		title    string
+		reqUser  *model.User
+		req      *pb.SuspendUserRequest
+		hasError bool
+	}{
+		{
+			"suspend admin by moderator: failed",
+			&moderator,
+			&pb.SuspendUserRequest{Username: admin.Username},
+			true,
+		},
+		{
+			"suspend moderator by user: failed",
+			&user,
+			&pb.SuspendUserRequest{Username: moderator.Username},
+			true,
+		},
+		{
+			"suspend user by moderator: success",
+			&moderator,
+			&pb.SuspendUserRequest{Username: user.Username, Days: 7},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		token, err := auth.GenerateToken(tt.reqUser.ID)
+		if err != nil {
+			t.Error(err)
+		}
+
+		resp, err := h.SuspendUser(ctxWithToken(context.Background(), token), tt.req)
+		if tt.hasError {
+			if err == nil {
+				t.Errorf("%q expected to fail, but succeeded.", tt.title)
+				t.FailNow()
+			}
+			continue
+		}
+
+		if !tt.hasError && err != nil {
+			t.Errorf("%q expected to succeed, but failed. %v", tt.title, err)
+			t.FailNow()
+		}
+
+		assert.Equal(t, model.StatusSuspended, resp.GetUser().GetStatus())
+		assert.NotEmpty(t, resp.GetUser().GetSuspendedUntil())
+	}
+
+	// suspended user cannot write articles
+	token, err := auth.GenerateToken(user.ID)
+	if err != nil {
+		t.Error(err)
+	}
+
+	_, err = h.CreateArticle(ctxWithToken(context.Background(), token), &pb.CreateAritcleRequest{
+		Article: &pb.CreateAritcleRequest_Article{
+			Title:   "awesome post!",
+			Body:    "awesome content!",
+			TagList: []string{"foo"},
+		},
+	})
+	assert.Error(t, err)
+}
+
+func TestSuspendedUserCannotWrite(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+
+	_, _, user := createAdminTestUsers(t, h)
+	barUser := model.User{Username: "bar", Email: "bar@example.com", Password: "secret"}
+	if err := h.us.Create(&barUser); err != nil {
+		t.Fatalf("failed to create initial user record: %v", err)
+	}
+
+	token, err := auth.GenerateToken(user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := ctxWithToken(context.Background(), token)
+
+	// things of the user made before the suspension
+	own := model.Article{Title: "own", Body: "body", Author: user}
+	other := model.Article{Title: "other", Body: "body", Author: barUser}
+	for _, a := range []*model.Article{&own, &other} {
+		if err := h.as.Create(a); err != nil {
+			t.Fatalf("failed to create initial article record: %v", err)
+		}
+	}
+	ownSlug, otherSlug := fmt.Sprintf("%d", own.ID), fmt.Sprintf("%d", other.ID)
+
+	list, err := h.CreateReadingList(ctx, &pb.CreateReadingListRequest{
+		ReadingList: &pb.CreateReadingListRequest_ReadingList{Name: "later"},
+	})
+	if err != nil {
+		t.Fatalf("create reading list expected to succeed, but failed. %v", err)
+	}
+	listID := list.GetReadingList().GetId()
+
+	hook, err := h.CreateWebhook(ctx, &pb.CreateWebhookRequest{
+		Webhook: &pb.CreateWebhookRequest_Webhook{Url: "https://example.com/hook", Events: []string{model.EventUserFollowed}},
+	})
+	if err != nil {
+		t.Fatalf("create webhook expected to succeed, but failed. %v", err)
+	}
+	hookID := hook.GetWebhook().GetId()
+
+	user.Status = model.StatusSuspended
+	if err := h.us.UpdateStatus(&user); err != nil {
+		t.Fatalf("failed to suspend user: %v", err)
+	}
+
+	tests := []struct {
+		title string
+		call  func() error
+	}{
+		{"create article", func() error {
+			_, err := h.CreateArticle(ctx, &pb.CreateAritcleRequest{
+				Article: &pb.CreateAritcleRequest_Article{Title: "title", Body: "body"},
+			})
+			return err
+		}},
+		{"update article", func() error {
+			_, err := h.UpdateArticle(ctx, &pb.UpdateArticleRequest{
+				Article: &pb.UpdateArticleRequest_Article{Slug: ownSlug, Body: "changed"},
+			})
+			return err
+		}},
+		{"restore article revision", func() error {
+			_, err := h.RestoreArticleRevision(ctx, &pb.RestoreArticleRevisionRequest{Slug: ownSlug, Number: 1})
+			return err
+		}},
+		{"favorite article", func() error {
+			_, err := h.FavoriteArticle(ctx, &pb.FavoriteArticleRequest{Slug: otherSlug})
+			return err
+		}},
+		{"unfavorite article", func() error {
+			_, err := h.UnfavoriteArticle(ctx, &pb.UnfavoriteArticleRequest{Slug: otherSlug})
+			return err
+		}},
+		{"create comment", func() error {
+			_, err := h.CreateComment(ctx, &pb.CreateCommentRequest{
+				Slug: otherSlug, Comment: &pb.CreateCommentRequest_Comment{Body: "comment"},
+			})
+			return err
+		}},
+		{"follow user", func() error {
+			_, err := h.FollowUser(ctx, &pb.FollowRequest{Username: barUser.Username})
+			return err
+		}},
+		{"unfollow user", func() error {
+			_, err := h.UnfollowUser(ctx, &pb.UnfollowRequest{Username: barUser.Username})
+			return err
+		}},
+		{"block user", func() error {
+			_, err := h.BlockUser(ctx, &pb.BlockRequest{Username: barUser.Username})
+			return err
+		}},
+		{"mute user", func() error {
+			_, err := h.MuteUser(ctx, &pb.MuteRequest{Username: barUser.Username})
+			return err
+		}},
+		{"create reading list", func() error {
+			_, err := h.CreateReadingList(ctx, &pb.CreateReadingListRequest{
+				ReadingList: &pb.CreateReadingListRequest_ReadingList{Name: "another"},
+			})
+			return err
+		}},
+		{"update reading list", func() error {
+			_, err := h.UpdateReadingList(ctx, &pb.UpdateReadingListRequest{
+				ReadingList: &pb.UpdateReadingListRequest_ReadingList{Id: listID, Name: "renamed"},
+			})
+			return err
+		}},
+		{"add to reading list", func() error {
+			_, err := h.AddToReadingList(ctx, &pb.AddToReadingListRequest{Id: listID, Slug: otherSlug})
+			return err
+		}},
+		{"share reading list", func() error {
+			_, err := h.ShareReadingList(ctx, &pb.ShareReadingListRequest{Id: listID})
+			return err
+		}},
+		{"create webhook", func() error {
+			_, err := h.CreateWebhook(ctx, &pb.CreateWebhookRequest{
+				Webhook: &pb.CreateWebhookRequest_Webhook{Url: "https://example.com/other", Events: []string{model.EventUserFollowed}},
+			})
+			return err
+		}},
+		{"delete webhook", func() error {
+			_, err := h.DeleteWebhook(ctx, &pb.DeleteWebhookRequest{Id: hookID})
+			return err
+		}},
+		{"create api token", func() error {
+			_, err := h.CreateAPIToken(ctx, &pb.CreateAPITokenRequest{
+				ApiToken: &pb.CreateAPITokenRequest_APIToken{Name: "ci", Scopes: []string{auth.ScopeRead}},
+			})
+			return err
+		}},
+		{"update user", func() error {
+			_, err := h.UpdateUser(ctx, &pb.UpdateUserRequest{User: &pb.UpdateUserRequest_User{Bio: "changed"}})
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, codes.PermissionDenied, status.Code(tt.call()), tt.title)
+	}
+
+	// reading is still allowed
+	_, err = h.GetReadingList(ctx, &pb.GetReadingListRequest{Id: listID})
+	assert.NoError(t, err)
+	_, err = h.ListWebhooks(ctx, &pb.Empty{})
+	assert.NoError(t, err)
+}
+
+func TestBanUser(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+
+	admin, moderator, user := createAdminTestUsers(t, h)
+	if err := user.HashPassword(); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.us.Update(&user); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		title    string
+		reqUser  *model.User
+		req      *pb.BanUserRequest
+		hasError bool
+	}{
+		{
+			"ban user by moderator: failed",
+			&moderator,
+			&pb.BanUserRequest{Username: user.Username},
+			true,
+		},
+		{
+			"ban user by admin: success",
+			&admin,
+			&pb.BanUserRequest{Username: user.Username},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		token, err := auth.GenerateToken(tt.reqUser.ID)
+		if err != nil {
+			t.Error(err)
+		}
+
+		resp, err := h.BanUser(ctxWithToken(context.Background(), token), tt.req)
+		if tt.hasError {
+			if err == nil {
+				t.Errorf("%q expected to fail, but succeeded.", tt.title)
+				t.FailNow()
+			}
+			continue
+		}
+
+		if !tt.hasError && err != nil {
+			t.Errorf("%q expected to succeed, but failed. %v", tt.title, err)
+			t.FailNow()
+		}
+
+		assert.Equal(t, model.StatusBanned, resp.GetUser().GetStatus())
+	}
+
+	// banned user cannot login
+	_, err := h.LoginUser(context.Background(), &pb.LoginUserRequest{
+		User: &pb.LoginUserRequest_User{
+			Email:    user.Email,
+			Password: "secret",
+		},
+	})
+	assert.Error(t, err)
+
+	// reinstated user can login again
+	token, err := auth.GenerateToken(admin.ID)
+	if err != nil {
+		t.Error(err)
+	}
+
+	resp, err := h.ReinstateUser(ctxWithToken(context.Background(), token), &pb.ReinstateUserRequest{
+		Username: user.Username,
+	})
+	if err != nil {
+		t.Fatalf("reinstate user expected to succeed, but failed. %v", err)
+	}
+	assert.Equal(t, model.StatusActive, resp.GetUser().GetStatus())
+
+	_, err = h.LoginUser(context.Background(), &pb.LoginUserRequest{
+		User: &pb.LoginUserRequest_User{
+			Email:    user.Email,
+			Password: "secret",
+		},
+	})
+	assert.NoError(t, err)
+}
+
+func TestSetUserRole(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+
+	admin, moderator, user := createAdminTestUsers(t, h)
+
+	tests := []struct {
+		title    string
+		reqUser  *model.User
+		req      *pb.SetUserRoleRequest
+		hasError bool
+	}{
+		{
+			"promote user by moderator: failed",
+			&moderator,
+			&pb.SetUserRoleRequest{Username: user.Username, Role: model.RoleModerator},
+			true,
+		},
+		{
+			"set unknown role: failed",
+			&admin,
+			&pb.SetUserRoleRequest{Username: user.Username, Role: "owner"},
+			true,
+		},
+		{
+			"promote user by admin: success",
+			&admin,
+			&pb.SetUserRoleRequest{Username: user.Username, Role: model.RoleModerator},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		token, err := auth.GenerateToken(tt.reqUser.ID)
+		if err != nil {
+			t.Error(err)
+		}
+
+		resp, err := h.SetUserRole(ctxWithToken(context.Background(), token), tt.req)
+		if tt.hasError {
+			if err == nil {
+				t.Errorf("%q expected to fail, but succeeded.", tt.title)
+				t.FailNow()
+			}
+			continue
+		}
+
+		if !tt.hasError && err != nil {
+			t.Errorf("%q expected to succeed, but failed. %v", tt.title, err)
+			t.FailNow()
+		}
+
+		assert.Equal(t, tt.req.GetRole(), resp.GetUser().GetRole())
+	}
+}
+
+func TestRemoveArticleAndComment(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+
+	_, moderator, user := createAdminTestUsers(t, h)
+
+	awesomeArticle := model.Article{
+		Title:       "awesome post!",
+		Description: "awesome description!",
+		Body:        "awesome content!",
+		Tags:        []model.Tag{{Name: "hoge"}},
+		Author:      user,
+	}
+
+	if err := h.as.Create(&awesomeArticle); err != nil {
+		t.Fatalf("failed to create initial article record: %v", err)
+	}
+
+	comment := model.Comment{
+		Body:      "f***",
+		Author:    user,
+		ArticleID: awesomeArticle.ID,
+	}
+	if err := h.as.CreateComment(&comment); err != nil {
+		t.Fatalf("failed to create initial article comment: %v", err)
+	}
+
+	userToken, err := auth.GenerateToken(user.ID)
+	if err != nil {
+		t.Error(err)
+	}
+
+	moderatorToken, err := auth.GenerateToken(moderator.ID)
+	if err != nil {
+		t.Error(err)
+	}
+
+	// unreported content is left out of the queue
+	fine := model.Comment{
+		Body:      "nice post",
+		Author:    user,
+		ArticleID: awesomeArticle.ID,
+	}
+	if err := h.as.CreateComment(&fine); err != nil {
+		t.Fatalf("failed to create initial article comment: %v", err)
+	}
+
+	reports := []model.Report{
+		{ContentType: model.ContentArticle, ArticleID: awesomeArticle.ID},
+		{ContentType: model.ContentComment, ArticleID: awesomeArticle.ID, CommentID: comment.ID},
+	}
+	for _, r := range reports {
+		r.ReporterID = moderator.ID
+		r.Reason = "spam"
+		if _, err := h.as.CreateReport(&r, 0); err != nil {
+			t.Fatalf("failed to create initial report: %v", err)
+		}
+	}
+
+	// the queue shows the newest content first
+	resp, err := h.GetModerationQueue(ctxWithToken(context.Background(), moderatorToken), &pb.GetModerationQueueRequest{})
+	if err != nil {
+		t.Fatalf("get moderation queue expected to succeed, but failed. %v", err)
+	}
+	if assert.Len(t, resp.GetItems(), 2) {
+		assert.Equal(t, model.ContentComment, resp.GetItems()[0].GetType())
+		assert.Equal(t, fmt.Sprintf("%d", comment.ID), resp.GetItems()[0].GetCommentId())
+		assert.Equal(t, model.ContentArticle, resp.GetItems()[1].GetType())
+	}
+
+	resp, err = h.GetModerationQueue(ctxWithToken(context.Background(), moderatorToken), &pb.GetModerationQueueRequest{Limit: 1, Offset: 1})
+	if assert.NoError(t, err) && assert.Len(t, resp.GetItems(), 1) {
+		assert.Equal(t, model.ContentArticle, resp.GetItems()[0].GetType())
+	}
+
+	_, err = h.GetModerationQueue(ctxWithToken(context.Background(), moderatorToken), &pb.GetModerationQueueRequest{Offset: -1})
+	assert.Error(t, err)
+
+	_, err = h.GetModerationQueue(ctxWithToken(context.Background(), userToken), &pb.GetModerationQueueRequest{})
+	assert.Error(t, err)
+
+	removeComment := &pb.RemoveCommentRequest{
+		Slug: fmt.Sprintf("%d", awesomeArticle.ID),
+		Id:   fmt.Sprintf("%d", comment.ID),
+	}
+
+	_, err = h.RemoveComment(ctxWithToken(context.Background(), userToken), removeComment)
+	assert.Error(t, err, "users cannot use admin api even for their own comments")
+
+	_, err = h.RemoveComment(ctxWithToken(context.Background(), moderatorToken), removeComment)
+	assert.NoError(t, err)
+
+	_, err = h.RemoveArticle(ctxWithToken(context.Background(), moderatorToken), &pb.RemoveArticleRequest{
+		Slug: fmt.Sprintf("%d", awesomeArticle.ID),
+	})
+	assert.NoError(t, err)
+
+	_, err = h.as.GetByID(awesomeArticle.ID)
+	assert.Error(t, err)
+}
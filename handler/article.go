@@ -7,6 +7,7 @@ import (
 
 	"github.com/raahii/golang-grpc-realworld-example/auth"
 	"github.com/raahii/golang-grpc-realworld-example/model"
+	"github.com/raahii/golang-grpc-realworld-example/policy"
 	pb "github.com/raahii/golang-grpc-realworld-example/proto"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -28,6 +29,10 @@ func (h *Handler) CreateArticle(ctx context.Context, req *pb.CreateAritcleReques
 		return nil, status.Error(codes.NotFound, "user not found")
 	}
 
+	if err := h.checkWrite(currentUser, "create article"); err != nil {
+		return nil, err
+	}
+
 	ra := req.GetArticle()
 	tags := make([]model.Tag, 0, len(ra.GetTagList()))
 	for _, t := range ra.GetTagList() {
@@ -40,6 +45,11 @@ func (h *Handler) CreateArticle(ctx context.Context, req *pb.CreateAritcleReques
 		Body:        ra.GetBody(),
 		Author:      *currentUser,
 		Tags:        tags,
+		Status:      ra.GetStatus(),
+	}
+
+	if article.Status == "" {
+		article.Status = model.ArticlePublished
 	}
 
 	err = article.Validate()
@@ -55,6 +65,8 @@ func (h *Handler) CreateArticle(ctx context.Context, req *pb.CreateAritcleReques
 		h.logger.Error().Err(err).Msg(msg)
 		return nil, status.Error(codes.Canceled, msg)
 	}
+	h.indexArticle(&article)
+	h.relay.Wake()
 
 	// get whether the article is current user's favorite
 	favorited := true
@@ -94,6 +106,12 @@ func (h *Handler) GetArticle(ctx context.Context, req *pb.GetArticleRequest) (*p
 	// get current user if exists
 	userID, err := auth.GetUserID(ctx)
 	if err != nil {
+		if !policy.CanViewArticle(nil, article) {
+			h.logger.Error().Msgf("guest attempted to get hidden article(id=%d)", article.ID)
+			return nil, status.Error(codes.NotFound, "article not found")
+		}
+		h.recordView(ctx, article, nil)
+
 		pa := article.ProtoArticle(false)
 		pa.Author = article.Author.ProtoProfile(false)
 		return &pb.ArticleResponse{Article: pa}, nil
@@ -106,6 +124,12 @@ func (h *Handler) GetArticle(ctx context.Context, req *pb.GetArticleRequest) (*p
 		return nil, status.Error(codes.NotFound, msg)
 	}
 
+	if !policy.CanViewArticle(currentUser, article) {
+		h.logger.Error().Msgf("user(id=%d) attempted to get hidden article(id=%d)", currentUser.ID, article.ID)
+		return nil, status.Error(codes.NotFound, "article not found")
+	}
+	h.recordView(ctx, article, currentUser)
+
 	// get whether the article is current user's favorite
 	favorited, err := h.as.IsFavorited(article, currentUser)
 	if err != nil {
@@ -115,6 +139,15 @@ func (h *Handler) GetArticle(ctx context.Context, req *pb.GetArticleRequest) (*p
 	}
 	pa := article.ProtoArticle(favorited)
 
+	// get whether the article is in current user's reading lists
+	bookmarked, err := h.as.IsBookmarked(article, currentUser)
+	if err != nil {
+		msg := "failed to get bookmarked status"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+	pa.Bookmarked = bookmarked
+
 	// get whether current user follows article author
 	following, err := h.us.IsFollowing(currentUser, &article.Author)
 	if err != nil {
@@ -136,6 +169,14 @@ func (h *Handler) GetArticles(ctx context.Context, req *pb.GetArticlesRequest) (
 		limitQuery = 20
 	}
 
+	switch req.GetSort() {
+	case "", model.SortNewest, model.SortFavorited, model.SortCommented, model.SortTrending:
+	default:
+		msg := fmt.Sprintf("unknown sort (%s)", req.GetSort())
+		h.logger.Error().Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, msg)
+	}
+
 	var favoritedBy *model.User
 	if req.GetFavorited() != "" {
 		var err error
@@ -147,12 +188,6 @@ func (h *Handler) GetArticles(ctx context.Context, req *pb.GetArticlesRequest) (
 		}
 	}
 
-	as, err := h.as.GetArticles(req.GetTag(), req.GetAuthor(), favoritedBy, limitQuery, req.GetOffset())
-	if err != nil {
-		h.logger.Error().Err(err).Msg("failed to search articles in the database")
-		return nil, status.Error(codes.Aborted, "internal server error")
-	}
-
 	var currentUser *model.User
 	userID, err := auth.GetUserID(ctx)
 	if err == nil {
@@ -163,6 +198,18 @@ func (h *Handler) GetArticles(ctx context.Context, req *pb.GetArticlesRequest) (
 		}
 	}
 
+	mutedUserIDs, err := h.us.GetMutedUserIDs(currentUser)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to get muted user ids")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	as, err := h.as.GetArticles(req.GetTag(), req.GetAuthor(), favoritedBy, mutedUserIDs, req.GetSort(), limitQuery, req.GetOffset())
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to search articles in the database")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
 	pas := make([]*pb.Article, 0, len(as))
 	for _, a := range as {
 		// get whether the article is current user's favorite
@@ -174,6 +221,15 @@ func (h *Handler) GetArticles(ctx context.Context, req *pb.GetArticlesRequest) (
 		}
 		pa := a.ProtoArticle(favorited)
 
+		// get whether the article is in current user's reading lists
+		bookmarked, err := h.as.IsBookmarked(&a, currentUser)
+		if err != nil {
+			msg := "failed to get bookmarked status"
+			h.logger.Error().Err(err).Msg(msg)
+			return nil, status.Error(codes.Aborted, "internal server error")
+		}
+		pa.Bookmarked = bookmarked
+
 		// get whether current user follows article author
 		following, err := h.us.IsFollowing(currentUser, &a.Author)
 		if err != nil {
@@ -205,11 +261,10 @@ func (h *Handler) GetFeedArticles(ctx context.Context, req *pb.GetFeedArticlesRe
 		return nil, status.Error(codes.NotFound, "user not found")
 	}
 
-	userIDs, err := h.us.GetFollowingUserIDs(currentUser)
+	userIDs, err := h.feedUserIDs(currentUser)
 	if err != nil {
-		msg := fmt.Sprintf("failed to get following user ids of user %d", currentUser.ID)
-		h.logger.Error().Err(err).Msg(msg)
-		return nil, status.Error(codes.NotFound, "internal server error")
+		h.logger.Error().Err(err).Msgf("failed to get feed users of user %d", currentUser.ID)
+		return nil, status.Error(codes.Aborted, "internal server error")
 	}
 
 	limitQuery := req.GetLimit()
@@ -235,6 +290,15 @@ func (h *Handler) GetFeedArticles(ctx context.Context, req *pb.GetFeedArticlesRe
 		}
 		pa := a.ProtoArticle(favorited)
 
+		// get whether the article is in current user's reading lists
+		bookmarked, err := h.as.IsBookmarked(&a, currentUser)
+		if err != nil {
+			msg := "failed to get bookmarked status"
+			h.logger.Error().Err(err).Msg(msg)
+			return nil, status.Error(codes.Aborted, "internal server error")
+		}
+		pa.Bookmarked = bookmarked
+
 		// get whether current user follows article author
 		following, err := h.us.IsFollowing(currentUser, &a.Author)
 		if err != nil {
@@ -269,6 +333,10 @@ func (h *Handler) UpdateArticle(ctx context.Context, req *pb.UpdateArticleReques
 		return nil, status.Error(codes.NotFound, msg)
 	}
 
+	if err := h.checkWrite(currentUser, "update article"); err != nil {
+		return nil, err
+	}
+
 	slug := req.GetArticle().GetSlug()
 	articleID, err := strconv.Atoi(slug)
 	if err != nil {
@@ -284,7 +352,7 @@ func (h *Handler) UpdateArticle(ctx context.Context, req *pb.UpdateArticleReques
 		return nil, status.Error(codes.InvalidArgument, "invalid article id")
 	}
 
-	if article.Author.ID != currentUser.ID {
+	if !policy.CanUpdateArticle(currentUser, article) {
 		msg := fmt.Sprintf("user(id=%d) attempted to update other user's article(id=%d)",
 			currentUser.ID, article.ID)
 		h.logger.Error().Err(err).Msg(msg)
@@ -304,15 +372,26 @@ func (h *Handler) UpdateArticle(ctx context.Context, req *pb.UpdateArticleReques
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	if err := h.as.Update(article); err != nil {
+	if err := h.as.Update(article, currentUser.ID); err != nil {
 		h.logger.Error().Err(err).Msg("failed to update article")
 		return nil, status.Error(codes.InvalidArgument, "internal server error")
 	}
+	h.indexArticle(article)
+	h.relay.Wake()
 
 	// get whether the article is current user's favorite
 	favorited := true
 	pa := article.ProtoArticle(favorited)
 
+	// get whether the article is in current user's reading lists
+	bookmarked, err := h.as.IsBookmarked(article, currentUser)
+	if err != nil {
+		msg := "failed to get bookmarked status"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+	pa.Bookmarked = bookmarked
+
 	// get whether current user follows article author
 	following, err := h.us.IsFollowing(currentUser, &article.Author)
 	if err != nil {
@@ -359,8 +438,8 @@ func (h *Handler) DeleteArticle(ctx context.Context, req *pb.DeleteArticleReques
 		return nil, status.Error(codes.InvalidArgument, "invalid article id")
 	}
 
-	if article.Author.ID != currentUser.ID {
-		msg := fmt.Sprintf("user(id=%d) attempted to update other user's article(id=%d)",
+	if !policy.CanDeleteArticle(currentUser, article) {
+		msg := fmt.Sprintf("user(id=%d) attempted to delete other user's article(id=%d)",
 			currentUser.ID, article.ID)
 		h.logger.Error().Err(err).Msg(msg)
 		return nil, status.Errorf(codes.Unauthenticated, "forbidden")
@@ -371,6 +450,7 @@ func (h *Handler) DeleteArticle(ctx context.Context, req *pb.DeleteArticleReques
 		h.logger.Error().Err(err).Msg(msg)
 		return nil, status.Errorf(codes.Unauthenticated, msg)
 	}
+	h.unindexArticle(article.ID)
 
 	return &pb.Empty{}, nil
 }
@@ -394,6 +474,10 @@ func (h *Handler) FavoriteArticle(ctx context.Context, req *pb.FavoriteArticleRe
 		return nil, status.Error(codes.NotFound, msg)
 	}
 
+	if err := h.checkWrite(currentUser, "favorite article"); err != nil {
+		return nil, err
+	}
+
 	slug := req.GetSlug()
 	articleID, err := strconv.Atoi(slug)
 	if err != nil {
@@ -409,16 +493,31 @@ func (h *Handler) FavoriteArticle(ctx context.Context, req *pb.FavoriteArticleRe
 		return nil, status.Error(codes.InvalidArgument, "invalid article id")
 	}
 
+	if !policy.CanViewArticle(currentUser, article) {
+		h.logger.Error().Msgf("user(id=%d) attempted to favorite hidden article(id=%d)", currentUser.ID, article.ID)
+		return nil, status.Error(codes.NotFound, "article not found")
+	}
+
 	err = h.as.AddFavorite(article, currentUser)
 	if err != nil {
 		msg := "failed to add favorite"
 		h.logger.Error().Err(err).Msg(msg)
 		return nil, status.Error(codes.InvalidArgument, msg)
 	}
+	h.relay.Wake()
 
 	// get whether current user follows article author
 	favorited := true
 	pa := article.ProtoArticle(favorited)
+
+	// get whether the article is in current user's reading lists
+	bookmarked, err := h.as.IsBookmarked(article, currentUser)
+	if err != nil {
+		msg := "failed to get bookmarked status"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+	pa.Bookmarked = bookmarked
 	following, err := h.us.IsFollowing(currentUser, &article.Author)
 	if err != nil {
 		msg := "failed to get following status"
@@ -449,6 +548,10 @@ func (h *Handler) UnfavoriteArticle(ctx context.Context, req *pb.UnfavoriteArtic
 		return nil, status.Error(codes.NotFound, msg)
 	}
 
+	if err := h.checkWrite(currentUser, "unfavorite article"); err != nil {
+		return nil, err
+	}
+
 	slug := req.GetSlug()
 	articleID, err := strconv.Atoi(slug)
 	if err != nil {
@@ -464,6 +567,11 @@ func (h *Handler) UnfavoriteArticle(ctx context.Context, req *pb.UnfavoriteArtic
 		return nil, status.Error(codes.InvalidArgument, "invalid article id")
 	}
 
+	if !policy.CanViewArticle(currentUser, article) {
+		h.logger.Error().Msgf("user(id=%d) attempted to unfavorite hidden article(id=%d)", currentUser.ID, article.ID)
+		return nil, status.Error(codes.NotFound, "article not found")
+	}
+
 	err = h.as.DeleteFavorite(article, currentUser)
 	if err != nil {
 		msg := "failed to remove favorite"
@@ -474,6 +582,15 @@ func (h *Handler) UnfavoriteArticle(ctx context.Context, req *pb.UnfavoriteArtic
 	// get whether current user follows article author
 	favorited := false
 	pa := article.ProtoArticle(favorited)
+
+	// get whether the article is in current user's reading lists
+	bookmarked, err := h.as.IsBookmarked(article, currentUser)
+	if err != nil {
+		msg := "failed to get bookmarked status"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+	pa.Bookmarked = bookmarked
 	following, err := h.us.IsFollowing(currentUser, &article.Author)
 	if err != nil {
 		msg := "failed to get following status"
@@ -484,3 +601,181 @@ func (h *Handler) UnfavoriteArticle(ctx context.Context, req *pb.UnfavoriteArtic
 
 	return &pb.ArticleResponse{Article: pa}, nil
 }
+
+// PublishArticle publishes an article
+func (h *Handler) PublishArticle(ctx context.Context, req *pb.PublishArticleRequest) (*pb.ArticleResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("publish article")
+
+	return h.changeArticleStatus(ctx, req.GetSlug(), model.ArticlePublished)
+}
+
+// UnpublishArticle turns an article back into a draft, or makes it unlisted or archived
+func (h *Handler) UnpublishArticle(ctx context.Context, req *pb.UnpublishArticleRequest) (*pb.ArticleResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("unpublish article")
+
+	s := req.GetStatus()
+	switch s {
+	case "":
+		s = model.ArticleDraft
+	case model.ArticleDraft, model.ArticleUnlisted, model.ArticleArchived:
+	default:
+		msg := fmt.Sprintf("invalid status: %q", s)
+		h.logger.Error().Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, msg)
+	}
+
+	return h.changeArticleStatus(ctx, req.GetSlug(), s)
+}
+
+// changeArticleStatus changes the status of an article of current user
+func (h *Handler) changeArticleStatus(ctx context.Context, slug, s string) (*pb.ArticleResponse, error) {
+	currentUser, article, err := h.ownArticle(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.as.UpdateStatus(article, s); err != nil {
+		msg := "failed to change status of article"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, msg)
+	}
+	h.indexArticle(article)
+	h.relay.Wake()
+
+	return h.ownArticleResponse(currentUser, article)
+}
+
+// ownArticle returns current user and the article identified by slug,
+// failing unless current user can update the article
+func (h *Handler) ownArticle(ctx context.Context, slug string) (*model.User, *model.Article, error) {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("unauthenticated")
+		return nil, nil, status.Errorf(codes.Unauthenticated, "unauthenticated")
+	}
+
+	currentUser, err := h.us.GetByID(userID)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("current user not found")
+		return nil, nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	articleID, err := strconv.Atoi(slug)
+	if err != nil {
+		msg := fmt.Sprintf("cannot convert slug (%s) into integer", slug)
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, nil, status.Error(codes.InvalidArgument, "invalid article id")
+	}
+
+	article, err := h.as.GetByID(uint(articleID))
+	if err != nil {
+		msg := fmt.Sprintf("requested article (slug=%d) not found", articleID)
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, nil, status.Error(codes.InvalidArgument, "invalid article id")
+	}
+
+	if !policy.CanUpdateArticle(currentUser, article) {
+		h.logger.Error().Msgf("user(id=%d) attempted to modify article(id=%d)",
+			currentUser.ID, article.ID)
+		return nil, nil, status.Error(codes.PermissionDenied, "forbidden")
+	}
+
+	return currentUser, article, nil
+}
+
+// ownArticleResponse builds the response for an article returned by ownArticle
+func (h *Handler) ownArticleResponse(currentUser *model.User, article *model.Article) (*pb.ArticleResponse, error) {
+	favorited, err := h.as.IsFavorited(article, currentUser)
+	if err != nil {
+		msg := "failed to get favorited status"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	pa := article.ProtoArticle(favorited)
+
+	// get whether the article is in current user's reading lists
+	bookmarked, err := h.as.IsBookmarked(article, currentUser)
+	if err != nil {
+		msg := "failed to get bookmarked status"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+	pa.Bookmarked = bookmarked
+	pa.Author = article.Author.ProtoProfile(false)
+
+	return &pb.ArticleResponse{Article: pa}, nil
+}
+
+// ListMyDrafts lists drafts of current user
+func (h *Handler) ListMyDrafts(ctx context.Context, req *pb.ListMyDraftsRequest) (*pb.ArticlesResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("list my drafts")
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("unauthenticated")
+		return nil, status.Errorf(codes.Unauthenticated, "unauthenticated")
+	}
+
+	currentUser, err := h.us.GetByID(userID)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("current user not found")
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	if err := h.checkPage(req.GetLimit(), req.GetOffset()); err != nil {
+		return nil, err
+	}
+	limitQuery := pageLimit(req.GetLimit())
+
+	as, err := h.as.GetDrafts(currentUser, limitQuery, req.GetOffset())
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to search drafts in the database")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	pas := make([]*pb.Article, 0, len(as))
+	for _, a := range as {
+		pa := a.ProtoArticle(false)
+		pa.Author = currentUser.ProtoProfile(false)
+		pas = append(pas, pa)
+	}
+
+	return &pb.ArticlesResponse{Articles: pas, ArticlesCount: int32(len(pas))}, nil
+}
+
+// feedUserIDs returns ids of the users whose articles are in the feed of u,
+// which are the ones u follows and doesn't mute
+func (h *Handler) feedUserIDs(u *model.User) ([]uint, error) {
+	userIDs, err := h.us.GetFollowingUserIDs(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get following user ids: %w", err)
+	}
+
+	mutedUserIDs, err := h.us.GetMutedUserIDs(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get muted user ids: %w", err)
+	}
+
+	return excludeIDs(userIDs, mutedUserIDs), nil
+}
+
+// excludeIDs returns ids which are not in excluded
+func excludeIDs(ids, excluded []uint) []uint {
+	if len(excluded) == 0 {
+		return ids
+	}
+
+	m := make(map[uint]bool, len(excluded))
+	for _, id := range excluded {
+		m[id] = true
+	}
+
+	res := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !m[id] {
+			res = append(res, id)
+		}
+	}
+	return res
+}
@@ -806,7 +806,21 @@ func TestFavoriteArticle(t *testing.T) {
 		Tags:        []model.Tag{{Name: "hoge"}},
 	}
 
-	for _, a := range []*model.Article{&af} {
+	draft := model.Article{
+		Title:  "draft title",
+		Body:   "draft body",
+		Author: fooUser,
+		Status: model.ArticleDraft,
+	}
+
+	hidden := model.Article{
+		Title:  "hidden title",
+		Body:   "hidden body",
+		Author: fooUser,
+		Hidden: true,
+	}
+
+	for _, a := range []*model.Article{&af, &draft, &hidden} {
 		if err := h.as.Create(a); err != nil {
 			t.Fatalf("failed to create initial article record: %v", err)
 		}
@@ -840,6 +854,22 @@ func TestFavoriteArticle(t *testing.T) {
 			nil,
 			true,
 		},
+		{
+			"favorite other user's draft: failed",
+			&barUser,
+			&pb.FavoriteArticleRequest{
+				Slug: fmt.Sprintf("%d", draft.ID),
+			},
+			true,
+		},
+		{
+			"favorite hidden article: failed",
+			&barUser,
+			&pb.FavoriteArticleRequest{
+				Slug: fmt.Sprintf("%d", hidden.ID),
+			},
+			true,
+		},
 	}
 
 	var favoritesCount int32
@@ -869,6 +899,22 @@ func TestFavoriteArticle(t *testing.T) {
 		assert.True(t, got.GetFavorited())
 		assert.Equal(t, favoritesCount, got.GetFavoritesCount())
 	}
+
+	for _, a := range []*model.Article{&draft, &hidden} {
+		favorited, err := h.as.IsFavorited(a, &barUser)
+		if assert.NoError(t, err) {
+			assert.False(t, favorited, a.Title)
+		}
+	}
+
+	// favoriting again changes nothing
+	if err := h.as.AddFavorite(&af, &barUser); err != nil {
+		t.Fatalf("favorite again expected to succeed, but failed. %v", err)
+	}
+	got, err := h.as.GetByID(af.ID)
+	if assert.NoError(t, err) {
+		assert.EqualValues(t, favoritesCount, got.FavoritesCount)
+	}
 }
 
 func TestUnfavoriteArticle(t *testing.T) {
@@ -910,6 +956,30 @@ func TestUnfavoriteArticle(t *testing.T) {
 		}
 	}
 
+	// favorited before they were unpublished or hidden
+	draft := model.Article{
+		Title:  "draft title",
+		Body:   "draft body",
+		Author: fooUser,
+		Status: model.ArticleDraft,
+	}
+
+	hidden := model.Article{
+		Title:  "hidden title",
+		Body:   "hidden body",
+		Author: fooUser,
+		Hidden: true,
+	}
+
+	for _, a := range []*model.Article{&draft, &hidden} {
+		if err := h.as.Create(a); err != nil {
+			t.Fatalf("failed to create initial article record: %v", err)
+		}
+		if err := h.as.AddFavorite(a, &barUser); err != nil {
+			t.Fatalf("failed to create initial favorite articles: %v", err)
+		}
+	}
+
 	tests := []struct {
 		title          string
 		reqUser        *model.User
@@ -926,6 +996,15 @@ func TestUnfavoriteArticle(t *testing.T) {
 			0,
 			false,
 		},
+		{
+			"unfavorite article again: success",
+			&fooUser,
+			&pb.UnfavoriteArticleRequest{
+				Slug: fmt.Sprintf("%d", af.ID),
+			},
+			0,
+			false,
+		},
 		{
 			"unfavorite not favorited article: failed",
 			&barUser,
@@ -933,6 +1012,24 @@ func TestUnfavoriteArticle(t *testing.T) {
 			0,
 			true,
 		},
+		{
+			"unfavorite other user's draft: failed",
+			&barUser,
+			&pb.UnfavoriteArticleRequest{
+				Slug: fmt.Sprintf("%d", draft.ID),
+			},
+			0,
+			true,
+		},
+		{
+			"unfavorite hidden article: failed",
+			&barUser,
+			&pb.UnfavoriteArticleRequest{
+				Slug: fmt.Sprintf("%d", hidden.ID),
+			},
+			0,
+			true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -961,3 +1058,191 @@ func TestUnfavoriteArticle(t *testing.T) {
 		assert.Equal(t, tt.favoritesCount, got.GetFavoritesCount())
 	}
 }
+
+func TestArticleDrafts(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+
+	fooUser := model.User{
+		Username: "foo",
+		Email:    "foo@example.com",
+		Password: "secret",
+	}
+
+	barUser := model.User{
+		Username: "bar",
+		Email:    "bar@example.com",
+		Password: "secret",
+	}
+
+	for _, u := range []*model.User{&fooUser, &barUser} {
+		if err := h.us.Create(u); err != nil {
+			t.Fatalf("failed to create initial user record: %v", err)
+		}
+	}
+
+	fooToken, err := auth.GenerateToken(fooUser.ID)
+	if err != nil {
+		t.Error(err)
+	}
+	fooCtx := ctxWithToken(context.Background(), fooToken)
+
+	barToken, err := auth.GenerateToken(barUser.ID)
+	if err != nil {
+		t.Error(err)
+	}
+	barCtx := ctxWithToken(context.Background(), barToken)
+
+	resp, err := h.CreateArticle(fooCtx, &pb.CreateAritcleRequest{
+		Article: &pb.CreateAritcleRequest_Article{
+			Title:   "work in progress",
+			Body:    "not yet",
+			TagList: []string{"draft-tag"},
+			Status:  model.ArticleDraft,
+		},
+	})
+	if err != nil {
+		t.Fatalf("create draft expected to succeed, but failed. %v", err)
+	}
+	assert.Equal(t, model.ArticleDraft, resp.GetArticle().GetStatus())
+	slug := resp.GetArticle().GetSlug()
+
+	listed := func() int {
+		resp, err := h.GetArticles(context.Background(), &pb.GetArticlesRequest{})
+		if err != nil {
+			t.Fatalf("get articles expected to succeed, but failed. %v", err)
+		}
+		return len(resp.GetArticles())
+	}
+
+	// drafts are visible only to the author
+	assert.Equal(t, 0, listed())
+
+	tags, err := h.GetTags(context.Background(), &pb.Empty{})
+	if assert.NoError(t, err) {
+		assert.NotContains(t, tags.GetTags(), "draft-tag")
+	}
+
+	_, err = h.GetArticle(barCtx, &pb.GetArticleRequest{Slug: slug})
+	assert.Error(t, err)
+
+	_, err = h.GetArticle(fooCtx, &pb.GetArticleRequest{Slug: slug})
+	assert.NoError(t, err)
+
+	drafts, err := h.ListMyDrafts(fooCtx, &pb.ListMyDraftsRequest{})
+	if assert.NoError(t, err) && assert.Len(t, drafts.GetArticles(), 1) {
+		assert.Equal(t, slug, drafts.GetArticles()[0].GetSlug())
+	}
+
+	drafts, err = h.ListMyDrafts(barCtx, &pb.ListMyDraftsRequest{})
+	if assert.NoError(t, err) {
+		assert.Len(t, drafts.GetArticles(), 0)
+	}
+
+	_, err = h.ListMyDrafts(fooCtx, &pb.ListMyDraftsRequest{Offset: -1})
+	assert.Error(t, err)
+
+	// publish
+	_, err = h.PublishArticle(barCtx, &pb.PublishArticleRequest{Slug: slug})
+	assert.Error(t, err, "other user cannot publish the draft")
+
+	resp, err = h.PublishArticle(fooCtx, &pb.PublishArticleRequest{Slug: slug})
+	if assert.NoError(t, err) {
+		assert.Equal(t, model.ArticlePublished, resp.GetArticle().GetStatus())
+		assert.NotEmpty(t, resp.GetArticle().GetPublishedAt())
+	}
+	publishedAt := resp.GetArticle().GetPublishedAt()
+	assert.Equal(t, 1, listed())
+
+	tags, err = h.GetTags(context.Background(), &pb.Empty{})
+	if assert.NoError(t, err) {
+		assert.Contains(t, tags.GetTags(), "draft-tag")
+	}
+
+	// unpublish
+	_, err = h.UnpublishArticle(fooCtx, &pb.UnpublishArticleRequest{Slug: slug, Status: model.ArticlePublished})
+	assert.Error(t, err)
+
+	resp, err = h.UnpublishArticle(fooCtx, &pb.UnpublishArticleRequest{Slug: slug, Status: model.ArticleUnlisted})
+	if assert.NoError(t, err) {
+		assert.Equal(t, model.ArticleUnlisted, resp.GetArticle().GetStatus())
+	}
+	assert.Equal(t, 0, listed())
+
+	_, err = h.GetArticle(context.Background(), &pb.GetArticleRequest{Slug: slug})
+	assert.NoError(t, err, "unlisted article can be read by the link")
+
+	resp, err = h.UnpublishArticle(fooCtx, &pb.UnpublishArticleRequest{Slug: slug})
+	if assert.NoError(t, err) {
+		assert.Equal(t, model.ArticleDraft, resp.GetArticle().GetStatus())
+	}
+
+	// republishing keeps the first publish time
+	resp, err = h.PublishArticle(fooCtx, &pb.PublishArticleRequest{Slug: slug})
+	if assert.NoError(t, err) {
+		assert.Equal(t, publishedAt, resp.GetArticle().GetPublishedAt())
+	}
+}
+
+func TestArticleBodyHTML(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+
+	fooUser := model.User{
+		Username: "foo",
+		Email:    "foo@example.com",
+		Password: "secret",
+	}
+	if err := h.us.Create(&fooUser); err != nil {
+		t.Fatalf("failed to create initial user record: %v", err)
+	}
+
+	token, err := auth.GenerateToken(fooUser.ID)
+	if err != nil {
+		t.Error(err)
+	}
+	ctx := ctxWithToken(context.Background(), token)
+
+	resp, err := h.CreateArticle(ctx, &pb.CreateAritcleRequest{
+		Article: &pb.CreateAritcleRequest_Article{
+			Title:   "markdown",
+			Body:    "Some **bold** text <script>alert(1)</script>",
+			TagList: []string{"hoge"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("create article expected to succeed, but failed. %v", err)
+	}
+
+	pa := resp.GetArticle()
+	assert.Contains(t, pa.GetBodyHtml(), "<strong>bold</strong>")
+	assert.NotContains(t, pa.GetBodyHtml(), "<script")
+	assert.Equal(t, int32(1), pa.GetReadingTimeMinutes())
+	assert.Equal(t, "Some bold text alert(1)", pa.GetExcerpt(), "excerpt is made from the body without description")
+
+	resp, err = h.UpdateArticle(ctx, &pb.UpdateArticleRequest{
+		Article: &pb.UpdateArticleRequest_Article{
+			Slug:        pa.GetSlug(),
+			Description: "about markdown",
+			Body:        "| a |\n|---|\n| 1 |",
+		},
+	})
+	if err != nil {
+		t.Fatalf("update article expected to succeed, but failed. %v", err)
+	}
+	assert.Contains(t, resp.GetArticle().GetBodyHtml(), "<table>")
+	assert.Equal(t, "about markdown", resp.GetArticle().GetExcerpt())
+
+	resp, err = h.GetArticle(ctx, &pb.GetArticleRequest{Slug: pa.GetSlug()})
+	if assert.NoError(t, err) {
+		assert.Contains(t, resp.GetArticle().GetBodyHtml(), "<table>", "rendered HTML is stored")
+	}
+
+	cresp, err := h.CreateComment(ctx, &pb.CreateCommentRequest{
+		Slug:    pa.GetSlug(),
+		Comment: &pb.CreateCommentRequest_Comment{Body: "`code`"},
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "<p><code>code</code></p>\n", cresp.GetComment().GetBodyHtml())
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/raahii/golang-grpc-realworld-example/auth"
 	"github.com/raahii/golang-grpc-realworld-example/model"
+	"github.com/raahii/golang-grpc-realworld-example/policy"
 	pb "github.com/raahii/golang-grpc-realworld-example/proto"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -29,6 +30,10 @@ func (h *Handler) CreateComment(ctx context.Context, req *pb.CreateCommentReques
 		return nil, status.Error(codes.NotFound, "user not found")
 	}
 
+	if err := h.checkWrite(currentUser, "create comment"); err != nil {
+		return nil, err
+	}
+
 	// get article
 	articleID, err := strconv.Atoi(req.GetSlug())
 	if err != nil {
@@ -44,6 +49,24 @@ func (h *Handler) CreateComment(ctx context.Context, req *pb.CreateCommentReques
 		return nil, status.Error(codes.InvalidArgument, "invalid article id")
 	}
 
+	if !policy.CanViewArticle(currentUser, article) {
+		msg := fmt.Sprintf("user(id=%d) attempted to comment on invisible article(id=%d)", currentUser.ID, article.ID)
+		h.logger.Error().Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, "invalid article id")
+	}
+
+	blocked, err := h.us.IsBlocking(&article.Author, currentUser)
+	if err != nil {
+		msg := "failed to get blocking status"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	if blocked {
+		h.logger.Error().Msgf("blocked user(id=%d) attempted to comment on article(id=%d)", currentUser.ID, article.ID)
+		return nil, status.Error(codes.PermissionDenied, "you cannot comment on this article")
+	}
+
 	// new comment
 	comment := model.Comment{
 		Body:      req.GetComment().GetBody(),
@@ -51,6 +74,30 @@ func (h *Handler) CreateComment(ctx context.Context, req *pb.CreateCommentReques
 		ArticleID: article.ID,
 	}
 
+	if pid := req.GetComment().GetParentId(); pid != "" {
+		parentID, err := strconv.Atoi(pid)
+		if err != nil {
+			msg := fmt.Sprintf("cannot convert parent id (%s) into integer", pid)
+			h.logger.Error().Err(err).Msg(msg)
+			return nil, status.Error(codes.InvalidArgument, "invalid parent comment id")
+		}
+
+		parent, err := h.as.GetCommentByID(uint(parentID))
+		if err != nil || parent.ArticleID != article.ID {
+			msg := fmt.Sprintf("parent comment (id=%d) not found in the article", parentID)
+			h.logger.Error().Err(err).Msg(msg)
+			return nil, status.Error(codes.InvalidArgument, "invalid parent comment id")
+		}
+
+		if parent.Deleted {
+			msg := "cannot reply to a deleted comment"
+			h.logger.Error().Msg(msg)
+			return nil, status.Error(codes.FailedPrecondition, msg)
+		}
+
+		comment.ReplyTo(parent)
+	}
+
 	err = comment.Validate()
 	if err != nil {
 		err = fmt.Errorf("validation error: %w", err)
@@ -65,6 +112,7 @@ func (h *Handler) CreateComment(ctx context.Context, req *pb.CreateCommentReques
 		h.logger.Error().Err(err).Msg(msg)
 		return nil, status.Error(codes.Aborted, msg)
 	}
+	h.relay.Wake()
 
 	// map model.Comment to pb.Comment
 	pc := comment.ProtoComment()
@@ -92,13 +140,6 @@ func (h *Handler) GetComments(ctx context.Context, req *pb.GetCommentsRequest) (
 		return nil, status.Error(codes.InvalidArgument, "invalid article id")
 	}
 
-	comments, err := h.as.GetComments(article)
-	if err != nil {
-		msg := "failed to get comments"
-		h.logger.Error().Err(err).Msg(msg)
-		return nil, status.Error(codes.Aborted, msg)
-	}
-
 	var currentUser *model.User
 	userID, err := auth.GetUserID(ctx)
 	if err == nil {
@@ -109,9 +150,69 @@ func (h *Handler) GetComments(ctx context.Context, req *pb.GetCommentsRequest) (
 		}
 	}
 
+	if !policy.CanViewArticle(currentUser, article) {
+		msg := fmt.Sprintf("requested article (slug=%d) is not visible", articleID)
+		h.logger.Error().Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, "invalid article id")
+	}
+
+	mutedUserIDs, err := h.us.GetMutedUserIDs(currentUser)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to get muted user ids")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	afterID, err := decodeCursor(req.GetCursor())
+	if err != nil {
+		h.logger.Error().Err(err).Msg("invalid cursor")
+		return nil, status.Error(codes.InvalidArgument, "invalid cursor")
+	}
+
+	limitQuery := pageLimit(req.GetLimit())
+
+	// one more comment tells whether there is a next page
+	comments, err := h.as.GetComments(article, mutedUserIDs, afterID, limitQuery+1)
+	if err != nil {
+		msg := "failed to get comments"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, msg)
+	}
+
+	var nextCursor string
+	if int64(len(comments)) > limitQuery {
+		comments = comments[:limitQuery]
+		nextCursor = encodeCursor(comments[len(comments)-1].ID)
+	}
+
+	count, err := h.as.CountComments(article, mutedUserIDs)
+	if err != nil {
+		msg := "failed to count comments"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, msg)
+	}
+
+	ids := make([]uint, 0, len(comments))
+	for _, c := range comments {
+		ids = append(ids, c.ID)
+	}
+
+	replies, err := h.as.GetReplyCounts(article, mutedUserIDs, ids)
+	if err != nil {
+		msg := "failed to count replies"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, msg)
+	}
+
 	pcs := make([]*pb.Comment, 0, len(comments))
 	for _, c := range comments {
 		pc := c.ProtoComment()
+		pc.RepliesCount = replies[c.ID]
+
+		// tombstones don't reveal the author
+		if c.Deleted {
+			pcs = append(pcs, pc)
+			continue
+		}
 
 		// get whether current user follows article author
 		following, err := h.us.IsFollowing(currentUser, &c.Author)
@@ -125,7 +226,81 @@ func (h *Handler) GetComments(ctx context.Context, req *pb.GetCommentsRequest) (
 		pcs = append(pcs, pc)
 	}
 
-	return &pb.CommentsResponse{Comments: pcs}, nil
+	return &pb.CommentsResponse{
+		Comments:      pcs,
+		CommentsCount: int32(count),
+		NextCursor:    nextCursor,
+	}, nil
+}
+
+// UpdateComment edits a comment of the article
+func (h *Handler) UpdateComment(ctx context.Context, req *pb.UpdateCommentRequest) (*pb.CommentResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("update comment")
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("unauthenticated")
+		return nil, status.Errorf(codes.Unauthenticated, "unauthenticated")
+	}
+
+	currentUser, err := h.us.GetByID(userID)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("current user not found")
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	commentID, err := strconv.Atoi(req.GetId())
+	if err != nil {
+		msg := fmt.Sprintf("cannot convert id (%s) into integer", req.GetId())
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, "invalid comment id")
+	}
+
+	comment, err := h.as.GetCommentByID(uint(commentID))
+	if err != nil {
+		msg := "failed to get comment"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, msg)
+	}
+
+	if req.GetSlug() != fmt.Sprintf("%d", comment.ArticleID) {
+		msg := "the comment is not in the article"
+		h.logger.Error().Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, msg)
+	}
+
+	if !policy.CanUpdateComment(currentUser, comment) {
+		h.logger.Error().Msgf("user(id=%d) attempted to update other user's comment(id=%d)",
+			currentUser.ID, comment.ID)
+		return nil, status.Error(codes.PermissionDenied, "forbidden")
+	}
+
+	if comment.Deleted {
+		msg := "cannot edit a deleted comment"
+		h.logger.Error().Msg(msg)
+		return nil, status.Error(codes.FailedPrecondition, msg)
+	}
+
+	edited := *comment
+	edited.Body = req.GetComment().GetBody()
+	if err := edited.Validate(); err != nil {
+		err = fmt.Errorf("validation error: %w", err)
+		h.logger.Error().Err(err).Msg("validation error")
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if edited.Body != comment.Body {
+		if err := h.as.UpdateComment(comment, edited.Body); err != nil {
+			msg := "failed to update comment"
+			h.logger.Error().Err(err).Msg(msg)
+			return nil, status.Error(codes.Aborted, msg)
+		}
+	}
+
+	pc := comment.ProtoComment()
+	pc.Author = currentUser.ProtoProfile(false)
+
+	return &pb.CommentResponse{Comment: pc}, nil
 }
 
 // DeleteComment delete a commnet of the article
@@ -165,7 +340,7 @@ func (h *Handler) DeleteComment(ctx context.Context, req *pb.DeleteCommentReques
 		return nil, status.Error(codes.InvalidArgument, msg)
 	}
 
-	if comment.UserID != currentUser.ID {
+	if !policy.CanDeleteComment(currentUser, comment) {
 		msg := "forbidden"
 		h.logger.Error().Err(err).Msg(msg)
 		return nil, status.Error(codes.InvalidArgument, msg)
@@ -367,3 +367,376 @@ func TestDeleteComment(t *testing.T) {
 		}
 	}
 }
+
+func TestCommentReplies(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+
+	fooUser := model.User{
+		Username: "foo",
+		Email:    "foo@example.com",
+		Password: "secret",
+	}
+
+	barUser := model.User{
+		Username: "bar",
+		Email:    "bar@example.com",
+		Password: "secret",
+	}
+
+	for _, u := range []*model.User{&fooUser, &barUser} {
+		if err := h.us.Create(u); err != nil {
+			t.Fatalf("failed to create initial user record: %v", err)
+		}
+	}
+
+	awesomeArticle := model.Article{
+		Title:       "awesome post!",
+		Description: "awesome description!",
+		Body:        "awesome content!",
+		Tags:        []model.Tag{{Name: "hoge"}},
+		Author:      fooUser,
+	}
+
+	otherArticle := model.Article{
+		Title:       "other post!",
+		Description: "other description!",
+		Body:        "other content!",
+		Tags:        []model.Tag{{Name: "fuga"}},
+		Author:      fooUser,
+	}
+
+	for _, a := range []*model.Article{&awesomeArticle, &otherArticle} {
+		if err := h.as.Create(a); err != nil {
+			t.Fatalf("failed to create initial article record: %v", err)
+		}
+	}
+
+	otherComment := model.Comment{
+		Body:      "hello",
+		Author:    fooUser,
+		ArticleID: otherArticle.ID,
+	}
+	if err := h.as.CreateComment(&otherComment); err != nil {
+		t.Fatalf("failed to create initial article comment: %v", err)
+	}
+
+	token, err := auth.GenerateToken(barUser.ID)
+	if err != nil {
+		t.Error(err)
+	}
+	ctx := ctxWithToken(context.Background(), token)
+	slug := fmt.Sprintf("%d", awesomeArticle.ID)
+
+	reply := func(parentID, body string) (*pb.CommentResponse, error) {
+		return h.CreateComment(ctx, &pb.CreateCommentRequest{
+			Slug: slug,
+			Comment: &pb.CreateCommentRequest_Comment{
+				Body:     body,
+				ParentId: parentID,
+			},
+		})
+	}
+
+	// thread of the deepest replies
+	ids := []string{""}
+	for depth := 0; depth <= model.MaxCommentDepth; depth++ {
+		resp, err := reply(ids[len(ids)-1], fmt.Sprintf("depth %d", depth))
+		if err != nil {
+			t.Fatalf("reply at depth %d expected to succeed, but failed. %v", depth, err)
+		}
+		assert.Equal(t, ids[len(ids)-1], resp.GetComment().GetParentId())
+		ids = append(ids, resp.GetComment().GetId())
+	}
+	ids = ids[1:]
+
+	_, err = reply(ids[len(ids)-1], "too deep")
+	assert.Error(t, err, "replies deeper than the limit")
+
+	_, err = reply(fmt.Sprintf("%d", otherComment.ID), "wrong thread")
+	assert.Error(t, err, "reply to a comment of other article")
+
+	_, err = reply("123456", "no parent")
+	assert.Error(t, err, "reply to a missing comment")
+
+	resp, err := h.GetComments(ctx, &pb.GetCommentsRequest{Slug: slug})
+	if assert.NoError(t, err) && assert.Len(t, resp.GetComments(), len(ids)) {
+		for i, c := range resp.GetComments() {
+			assert.Equal(t, ids[i], c.GetId())
+			if i == 0 {
+				assert.Empty(t, c.GetParentId())
+			} else {
+				assert.Equal(t, ids[i-1], c.GetParentId())
+			}
+
+			if i < len(ids)-1 {
+				assert.Equal(t, int32(1), c.GetRepliesCount())
+			} else {
+				assert.Equal(t, int32(0), c.GetRepliesCount())
+			}
+		}
+	}
+
+	// deleting a parent leaves a tombstone
+	_, err = h.DeleteComment(ctx, &pb.DeleteCommentRequest{Slug: slug, Id: ids[0]})
+	assert.NoError(t, err)
+
+	// deleting a leaf removes it
+	_, err = h.DeleteComment(ctx, &pb.DeleteCommentRequest{Slug: slug, Id: ids[len(ids)-1]})
+	assert.NoError(t, err)
+
+	resp, err = h.GetComments(ctx, &pb.GetCommentsRequest{Slug: slug})
+	if assert.NoError(t, err) && assert.Len(t, resp.GetComments(), len(ids)-1) {
+		root := resp.GetComments()[0]
+		assert.True(t, root.GetDeleted())
+		assert.Equal(t, model.CommentTombstone, root.GetBody())
+		assert.Nil(t, root.GetAuthor())
+		assert.Equal(t, int32(1), root.GetRepliesCount())
+
+		assert.Equal(t, ids[0], resp.GetComments()[1].GetParentId())
+		assert.False(t, resp.GetComments()[1].GetDeleted())
+	}
+
+	_, err = reply(ids[0], "reply to tombstone")
+	assert.Error(t, err)
+}
+
+func TestUpdateComment(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+
+	fooUser := model.User{
+		Username: "foo",
+		Email:    "foo@example.com",
+		Password: "secret",
+	}
+
+	barUser := model.User{
+		Username: "bar",
+		Email:    "bar@example.com",
+		Password: "secret",
+	}
+
+	moderator := model.User{
+		Username: "moderator",
+		Email:    "moderator@example.com",
+		Password: "secret",
+		Role:     model.RoleModerator,
+	}
+
+	for _, u := range []*model.User{&fooUser, &barUser, &moderator} {
+		if err := h.us.Create(u); err != nil {
+			t.Fatalf("failed to create initial user record: %v", err)
+		}
+	}
+
+	awesomeArticle := model.Article{
+		Title:       "awesome post!",
+		Description: "awesome description!",
+		Body:        "awesome content!",
+		Tags:        []model.Tag{{Name: "hoge"}},
+		Author:      fooUser,
+	}
+
+	if err := h.as.Create(&awesomeArticle); err != nil {
+		t.Fatalf("failed to create initial article record: %v", err)
+	}
+
+	comment := model.Comment{
+		Body:      "nice aritcle",
+		Author:    barUser,
+		ArticleID: awesomeArticle.ID,
+	}
+	if err := h.as.CreateComment(&comment); err != nil {
+		t.Fatalf("failed to create initial article comment: %v", err)
+	}
+
+	slug := fmt.Sprintf("%d", awesomeArticle.ID)
+	id := fmt.Sprintf("%d", comment.ID)
+
+	tests := []struct {
+		title    string
+		reqUser  *model.User
+		req      *pb.UpdateCommentRequest
+		hasError bool
+	}{
+		{
+			"update comment of other user: failed",
+			&fooUser,
+			&pb.UpdateCommentRequest{
+				Slug:    slug,
+				Id:      id,
+				Comment: &pb.UpdateCommentRequest_Comment{Body: "hacked"},
+			},
+			true,
+		},
+		{
+			"update comment with empty body: failed",
+			&barUser,
+			&pb.UpdateCommentRequest{
+				Slug:    slug,
+				Id:      id,
+				Comment: &pb.UpdateCommentRequest_Comment{Body: ""},
+			},
+			true,
+		},
+		{
+			"update comment with invalid article id: failed",
+			&barUser,
+			&pb.UpdateCommentRequest{
+				Slug:    "123124",
+				Id:      id,
+				Comment: &pb.UpdateCommentRequest_Comment{Body: "nice article"},
+			},
+			true,
+		},
+		{
+			"update own comment: success",
+			&barUser,
+			&pb.UpdateCommentRequest{
+				Slug:    slug,
+				Id:      id,
+				Comment: &pb.UpdateCommentRequest_Comment{Body: "nice article"},
+			},
+			false,
+		},
+		{
+			"update own comment again: success",
+			&barUser,
+			&pb.UpdateCommentRequest{
+				Slug:    slug,
+				Id:      id,
+				Comment: &pb.UpdateCommentRequest_Comment{Body: "nice article!"},
+			},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		token, err := auth.GenerateToken(tt.reqUser.ID)
+		if err != nil {
+			t.Error(err)
+		}
+
+		resp, err := h.UpdateComment(ctxWithToken(context.Background(), token), tt.req)
+		if tt.hasError {
+			if err == nil {
+				t.Errorf("%q expected to fail, but succeeded.", tt.title)
+				t.FailNow()
+			}
+			continue
+		}
+
+		if !tt.hasError && err != nil {
+			t.Errorf("%q expected to succeed, but failed. %v", tt.title, err)
+			t.FailNow()
+		}
+
+		assert.Equal(t, id, resp.GetComment().GetId())
+		assert.Equal(t, tt.req.GetComment().GetBody(), resp.GetComment().GetBody())
+		assert.True(t, resp.GetComment().GetEdited())
+	}
+
+	// previous versions are kept for moderators
+	token, err := auth.GenerateToken(barUser.ID)
+	if err != nil {
+		t.Error(err)
+	}
+
+	req := &pb.ListCommentRevisionsRequest{Slug: slug, Id: id}
+	_, err = h.ListCommentRevisions(ctxWithToken(context.Background(), token), req)
+	assert.Error(t, err)
+
+	token, err = auth.GenerateToken(moderator.ID)
+	if err != nil {
+		t.Error(err)
+	}
+
+	resp, err := h.ListCommentRevisions(ctxWithToken(context.Background(), token), req)
+	if assert.NoError(t, err) && assert.Len(t, resp.GetRevisions(), 2) {
+		assert.Equal(t, "nice aritcle", resp.GetRevisions()[0].GetBody())
+		assert.Equal(t, "nice article", resp.GetRevisions()[1].GetBody())
+	}
+}
+
+func TestGetCommentsPagination(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+
+	fooUser := model.User{
+		Username: "foo",
+		Email:    "foo@example.com",
+		Password: "secret",
+	}
+
+	if err := h.us.Create(&fooUser); err != nil {
+		t.Fatalf("failed to create initial user record: %v", err)
+	}
+
+	awesomeArticle := model.Article{
+		Title:       "awesome post!",
+		Description: "awesome description!",
+		Body:        "awesome content!",
+		Tags:        []model.Tag{{Name: "hoge"}},
+		Author:      fooUser,
+	}
+
+	if err := h.as.Create(&awesomeArticle); err != nil {
+		t.Fatalf("failed to create initial article record: %v", err)
+	}
+
+	bodies := make([]string, 0, 5)
+	for i := 0; i < 5; i++ {
+		c := model.Comment{
+			Body:      fmt.Sprintf("comment %d", i),
+			Author:    fooUser,
+			ArticleID: awesomeArticle.ID,
+		}
+		if err := h.as.CreateComment(&c); err != nil {
+			t.Fatalf("failed to create initial article comments: %v", err)
+		}
+		bodies = append(bodies, c.Body)
+	}
+
+	slug := fmt.Sprintf("%d", awesomeArticle.ID)
+
+	var got []string
+	cursor := ""
+	for page := 0; ; page++ {
+		if page > 3 {
+			t.Fatal("too many pages")
+		}
+
+		resp, err := h.GetComments(context.Background(), &pb.GetCommentsRequest{
+			Slug:   slug,
+			Limit:  2,
+			Cursor: cursor,
+		})
+		if err != nil {
+			t.Fatalf("get comments expected to succeed, but failed. %v", err)
+		}
+
+		assert.Equal(t, int32(len(bodies)), resp.GetCommentsCount())
+		assert.LessOrEqual(t, len(resp.GetComments()), 2)
+		for _, c := range resp.GetComments() {
+			got = append(got, c.GetBody())
+		}
+
+		cursor = resp.GetNextCursor()
+		if cursor == "" {
+			break
+		}
+	}
+
+	assert.Equal(t, bodies, got)
+
+	// a negative limit gets the default page
+	resp, err := h.GetComments(context.Background(), &pb.GetCommentsRequest{Slug: slug, Limit: -1})
+	if assert.NoError(t, err) {
+		assert.Len(t, resp.GetComments(), len(bodies))
+		assert.Empty(t, resp.GetNextCursor())
+	}
+
+	_, err = h.GetComments(context.Background(), &pb.GetCommentsRequest{Slug: slug, Cursor: "!!"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+)
+
+// encodeCursor makes an opaque paging cursor pointing after the record id
+func encodeCursor(id uint) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(uint64(id), 10)))
+}
+
+// decodeCursor returns the record id of a cursor. Empty cursor means the first page.
+func decodeCursor(cursor string) (uint, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, errors.New("malformed cursor")
+	}
+
+	id, err := strconv.ParseUint(string(b), 10, 64)
+	if err != nil || id == 0 {
+		return 0, errors.New("malformed cursor")
+	}
+
+	return uint(id), nil
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/raahii/golang-grpc-realworld-example/auth"
+	"github.com/raahii/golang-grpc-realworld-example/digest"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// digestBatchSize is the number of recipients claimed at once
+	digestBatchSize = 50
+	// digestSize is the maximum number of articles in a digest
+	digestSize = 10
+
+	defaultPublicURL = "http://localhost:3000"
+	defaultMailFrom  = "Conduit <noreply@localhost>"
+)
+
+// publicURL reads $PUBLIC_URL, the URL of the gateway linked from emails
+func publicURL() string {
+	if u := os.Getenv("PUBLIC_URL"); u != "" {
+		return strings.TrimSuffix(u, "/")
+	}
+	return defaultPublicURL
+}
+
+// mailFrom reads $MAIL_FROM, the sender of emails
+func mailFrom() string {
+	if f := os.Getenv("MAIL_FROM"); f != "" {
+		return f
+	}
+	return defaultMailFrom
+}
+
+// Unsubscribe turns off the digest of the user the token was issued to
+func (h *Handler) Unsubscribe(ctx context.Context, req *pb.UnsubscribeRequest) (*pb.Empty, error) {
+	h.logger.Info().Msg("unsubscribe digest")
+
+	userID, err := auth.ParseUnsubscribeToken(req.GetToken())
+	if err != nil {
+		h.logger.Error().Err(err).Msg("invalid unsubscribe token")
+		return nil, status.Error(codes.InvalidArgument, "invalid token")
+	}
+
+	u, err := h.us.GetByID(userID)
+	if err != nil {
+		h.logger.Error().Err(err).Msgf("user(id=%d) to unsubscribe not found", userID)
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	u.DigestFrequency = model.DigestOff
+	if err := h.us.UpdateDigest(u); err != nil {
+		h.logger.Error().Err(err).Msg("failed to unsubscribe digest")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	return &pb.Empty{}, nil
+}
+
+// SendDigests emails the due digests. It is run periodically by the job runner.
+func (h *Handler) SendDigests(ctx context.Context) error {
+	for {
+		now := time.Now()
+		us, err := h.us.ClaimDigestRecipients(now, digestBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to claim digest recipients: %w", err)
+		}
+
+		for i := range us {
+			// a claimed digest is never retried, so that nobody gets it twice
+			if err := h.sendDigest(ctx, &us[i], now); err != nil {
+				h.logger.Error().Err(err).Msgf("failed to send digest to user(id=%d)", us[i].ID)
+			}
+		}
+
+		if len(us) < digestBatchSize || ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// sendDigest emails u the most favorited articles of their feed created since
+// the previous digest. Nothing is sent when there are none.
+func (h *Handler) sendDigest(ctx context.Context, u *model.User, now time.Time) error {
+	since := now.Add(-u.DigestPeriod())
+	if u.DigestSentAt != nil {
+		since = *u.DigestSentAt
+	}
+
+	userIDs, err := h.feedUserIDs(u)
+	if err != nil {
+		return err
+	}
+
+	as, err := h.as.GetTopFeedArticlesSince(userIDs, since, digestSize)
+	if err != nil {
+		return fmt.Errorf("failed to get feed articles: %w", err)
+	}
+	if len(as) == 0 {
+		return nil
+	}
+
+	d := digest.Digest{
+		Username:  u.Username,
+		Frequency: u.DigestFrequency,
+		UnsubscribeURL: fmt.Sprintf("%s/digest/unsubscribe?token=%s",
+			h.publicURL, url.QueryEscape(auth.GenerateUnsubscribeToken(u.ID))),
+	}
+	for _, a := range as {
+		d.Articles = append(d.Articles, digest.Article{
+			Title:          a.Title,
+			Description:    a.Description,
+			Author:         a.Author.Username,
+			URL:            fmt.Sprintf("%s/articles/%d", h.publicURL, a.ID),
+			FavoritesCount: a.FavoritesCount,
+		})
+	}
+
+	m, err := digest.Compose(&d)
+	if err != nil {
+		return err
+	}
+	m.From = h.mailFrom
+	m.To = u.Email
+
+	return h.mailer.Send(ctx, m)
+}
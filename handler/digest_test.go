@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/raahii/golang-grpc-realworld-example/auth"
+	"github.com/raahii/golang-grpc-realworld-example/mail"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingMailer keeps sent messages
+type recordingMailer struct {
+	messages []*mail.Message
+}
+
+func (m *recordingMailer) Send(ctx context.Context, msg *mail.Message) error {
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+func TestDigest(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+
+	mailer := &recordingMailer{}
+	h.mailer = mailer
+
+	users := []*model.User{
+		{Username: "foo", Email: "foo@example.com", Password: "secret"},
+		{Username: "bar", Email: "bar@example.com", Password: "secret"},
+		{Username: "baz", Email: "baz@example.com", Password: "secret"},
+		{Username: "qux", Email: "qux@example.com", Password: "secret"},
+	}
+	for _, u := range users {
+		if err := h.us.Create(u); err != nil {
+			t.Fatalf("failed to create initial user record: %v", err)
+		}
+	}
+	fooUser, barUser, bazUser, quxUser := users[0], users[1], users[2], users[3]
+
+	token, err := auth.GenerateToken(fooUser.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := ctxWithToken(context.Background(), token)
+
+	for _, u := range []*model.User{barUser, bazUser} {
+		if err := h.us.Follow(fooUser, u); err != nil {
+			t.Fatalf("failed to create follow relationship: %v", err)
+		}
+	}
+	if err := h.us.Mute(fooUser, bazUser); err != nil {
+		t.Fatalf("failed to create mute relationship: %v", err)
+	}
+
+	resp, err := h.UpdateUser(ctx, &pb.UpdateUserRequest{
+		User: &pb.UpdateUserRequest_User{Digest: model.DigestWeekly},
+	})
+	if err != nil {
+		t.Fatalf("update user expected to succeed, but failed. %v", err)
+	}
+	assert.Equal(t, model.DigestWeekly, resp.GetUser().GetDigest())
+
+	// nothing is due until a week passes
+	if err := h.SendDigests(context.Background()); err != nil {
+		t.Fatalf("send digests expected to succeed, but failed. %v", err)
+	}
+	assert.Empty(t, mailer.messages)
+
+	// the week started long ago, so that the articles below are in it
+	fooUser, err = h.us.GetByID(fooUser.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	weekAgo := time.Now().Add(-8 * 24 * time.Hour)
+	fooUser.DigestSentAt = &weekAgo
+	if err := h.us.UpdateDigest(fooUser); err != nil {
+		t.Fatal(err)
+	}
+
+	articles := []model.Article{
+		{Title: "less favorited", Body: "body", Author: *barUser, Status: model.ArticlePublished, FavoritesCount: 1},
+		{Title: "most favorited", Body: "body", Author: *barUser, Status: model.ArticlePublished, FavoritesCount: 5},
+		{Title: "draft", Body: "body", Author: *barUser, Status: model.ArticleDraft},
+		{Title: "muted", Body: "body", Author: *bazUser, Status: model.ArticlePublished},
+		{Title: "not followed", Body: "body", Author: *quxUser, Status: model.ArticlePublished},
+	}
+	for i := range articles {
+		if err := h.as.Create(&articles[i]); err != nil {
+			t.Fatalf("failed to create initial article record: %v", err)
+		}
+	}
+
+	if err := h.SendDigests(context.Background()); err != nil {
+		t.Fatalf("send digests expected to succeed, but failed. %v", err)
+	}
+	if !assert.Len(t, mailer.messages, 1) {
+		t.FailNow()
+	}
+
+	m := mailer.messages[0]
+	assert.Equal(t, "foo@example.com", m.To)
+	assert.Equal(t, "Your weekly digest: most favorited and 1 more", m.Subject)
+	assert.True(t, strings.Index(m.Text, "most favorited") < strings.Index(m.Text, "less favorited"))
+	for _, title := range []string{"draft", "muted", "not followed"} {
+		assert.NotContains(t, m.Text, title)
+	}
+
+	// sent once a week only
+	if err := h.SendDigests(context.Background()); err != nil {
+		t.Fatalf("send digests expected to succeed, but failed. %v", err)
+	}
+	assert.Len(t, mailer.messages, 1)
+
+	link := strings.Trim(m.Headers["List-Unsubscribe"], "<>")
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("invalid unsubscribe link %q: %v", link, err)
+	}
+	assert.Equal(t, "/digest/unsubscribe", u.Path)
+
+	if _, err := h.Unsubscribe(context.Background(), &pb.UnsubscribeRequest{Token: u.Query().Get("token")}); err != nil {
+		t.Fatalf("unsubscribe expected to succeed, but failed. %v", err)
+	}
+
+	current, err := h.CurrentUser(ctx, &pb.Empty{})
+	if assert.NoError(t, err) {
+		assert.Equal(t, model.DigestOff, current.GetUser().GetDigest())
+	}
+
+	tests := []struct {
+		title string
+		f     func() error
+	}{
+		{
+			"unknown digest frequency: failed",
+			func() error {
+				_, err := h.UpdateUser(ctx, &pb.UpdateUserRequest{
+					User: &pb.UpdateUserRequest_User{Digest: "hourly"},
+				})
+				return err
+			},
+		},
+		{
+			"tampered unsubscribe token: failed",
+			func() error {
+				// the signature of another user's token
+				token := auth.GenerateUnsubscribeToken(barUser.ID)
+				sig := token[strings.Index(token, "."):]
+				_, err := h.Unsubscribe(context.Background(), &pb.UnsubscribeRequest{
+					Token: fmt.Sprintf("%s%d%s", auth.UnsubscribeTokenPrefix, fooUser.ID, sig),
+				})
+				return err
+			},
+		},
+		{
+			"login token as unsubscribe token: failed",
+			func() error {
+				_, err := h.Unsubscribe(context.Background(), &pb.UnsubscribeRequest{Token: token})
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		if err := tt.f(); err == nil {
+			t.Errorf("%q expected to fail, but succeeded.", tt.title)
+		}
+	}
+}
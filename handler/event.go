@@ -0,0 +1,243 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/raahii/golang-grpc-realworld-example/auth"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	"github.com/raahii/golang-grpc-realworld-example/policy"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+	"github.com/raahii/golang-grpc-realworld-example/pubsub"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Types of streamed events besides the domain events defined in model
+const (
+	// eventReady is sent first when the subscription is accepted
+	eventReady = "ready"
+	// eventReset replaces eventReady when events after lastEventId were lost,
+	// so the client has to refetch what it shows
+	eventReset        = "reset"
+	eventHeartbeat    = "heartbeat"
+	eventNotification = "notification"
+)
+
+const (
+	// eventHistorySize is the number of events kept for resuming streams
+	eventHistorySize = 1000
+
+	// streamBuffer is the number of events waiting for a stream before it's closed as too slow
+	streamBuffer = 64
+
+	// heartbeatInterval keeps idle streams alive through proxies
+	heartbeatInterval = 15 * time.Second
+
+	articlesTopic = "articles"
+)
+
+func commentsTopic(articleID uint) string {
+	return fmt.Sprintf("articles/%d/comments", articleID)
+}
+
+func notificationsTopic(userID uint) string {
+	return fmt.Sprintf("users/%d/notifications", userID)
+}
+
+// Subscribe streams new articles of followed users, new comments on the watched
+// articles and notifications of current user. Events are resumed after lastEventId.
+func (h *Handler) Subscribe(req *pb.SubscribeRequest, stream pb.Events_SubscribeServer) error {
+	h.logger.Info().Interface("req", req).Msg("subscribe")
+
+	ctx := stream.Context()
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("unauthenticated")
+		return status.Errorf(codes.Unauthenticated, "unauthenticated")
+	}
+
+	currentUser, err := h.us.GetByID(userID)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("current user not found")
+		return status.Error(codes.NotFound, "user not found")
+	}
+
+	topics := []string{articlesTopic, notificationsTopic(currentUser.ID)}
+	for _, slug := range req.GetWatch() {
+		articleID, err := strconv.Atoi(slug)
+		if err != nil {
+			msg := fmt.Sprintf("cannot convert slug (%s) into integer", slug)
+			h.logger.Error().Err(err).Msg(msg)
+			return status.Error(codes.InvalidArgument, "invalid article id")
+		}
+
+		article, err := h.as.GetByID(uint(articleID))
+		if err != nil || !policy.CanViewArticle(currentUser, article) {
+			msg := fmt.Sprintf("requested article (slug=%d) not found", articleID)
+			h.logger.Error().Err(err).Msg(msg)
+			return status.Error(codes.InvalidArgument, "invalid article id")
+		}
+
+		topics = append(topics, commentsTopic(article.ID))
+	}
+
+	var lastID uint64
+	if s := req.GetLastEventId(); s != "" {
+		lastID, err = strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			msg := fmt.Sprintf("cannot convert last event id (%s) into integer", s)
+			h.logger.Error().Err(err).Msg(msg)
+			return status.Error(codes.InvalidArgument, "invalid last event id")
+		}
+	}
+
+	first := eventReady
+	sub, err := h.hub.Subscribe(topics, lastID, streamBuffer)
+	if errors.Is(err, pubsub.ErrEventsLost) {
+		first = eventReset
+		sub, err = h.hub.Subscribe(topics, 0, streamBuffer)
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to subscribe")
+		return status.Error(codes.Aborted, "internal server error")
+	}
+	defer sub.Close()
+
+	if err := stream.Send(&pb.Event{Type: first}); err != nil {
+		return err
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-heartbeat.C:
+			if err := stream.Send(&pb.Event{Type: eventHeartbeat}); err != nil {
+				return err
+			}
+
+		case e, ok := <-sub.Events():
+			if !ok {
+				h.logger.Error().Err(sub.Err()).Msgf("closed event stream of user(id=%d)", currentUser.ID)
+				return status.Error(codes.ResourceExhausted, "stream fell behind, resume from the last event id")
+			}
+
+			pe, err := h.protoEvent(currentUser, e)
+			if err != nil {
+				h.logger.Error().Err(err).Msgf("failed to convert event(id=%d)", e.ID)
+				return status.Error(codes.Aborted, "internal server error")
+			}
+
+			if pe == nil {
+				continue
+			}
+
+			if err := stream.Send(pe); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// protoEvent converts the event for current user. It returns nil if the
+// event is not for current user.
+func (h *Handler) protoEvent(currentUser *model.User, e pubsub.Event) (*pb.Event, error) {
+	pe := pb.Event{
+		Id:        strconv.FormatUint(e.ID, 10),
+		CreatedAt: e.Time.Format(model.ISO8601),
+	}
+
+	switch d := e.Data.(type) {
+	case model.Article:
+		following, err := h.us.IsFollowing(currentUser, &d.Author)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get following status: %w", err)
+		}
+
+		muted, err := h.us.IsMuting(currentUser, &d.Author)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get muting status: %w", err)
+		}
+
+		if !following || muted {
+			return nil, nil
+		}
+
+		pe.Type = model.EventArticlePublished
+		pe.Article = d.ProtoArticle(false)
+		pe.Article.Author = d.Author.ProtoProfile(following)
+
+	case model.Comment:
+		blocked, err := h.us.IsBlockedBetween(currentUser, &d.Author)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get blocking status: %w", err)
+		}
+
+		muted, err := h.us.IsMuting(currentUser, &d.Author)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get muting status: %w", err)
+		}
+
+		if blocked || muted {
+			return nil, nil
+		}
+
+		following, err := h.us.IsFollowing(currentUser, &d.Author)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get following status: %w", err)
+		}
+
+		pe.Type = model.EventCommentCreated
+		pe.Slug = fmt.Sprintf("%d", d.ArticleID)
+		pe.Comment = d.ProtoComment()
+		pe.Comment.Author = d.Author.ProtoProfile(following)
+
+	case model.Notification:
+		pe.Type = eventNotification
+		pe.Notification = d.ProtoNotification()
+
+	default:
+		return nil, fmt.Errorf("unknown event data %T", e.Data)
+	}
+
+	return &pe, nil
+}
+
+// streamEvent tells subscribers about newly published articles, new
+// comments on the articles they watch and their notifications
+func (h *Handler) streamEvent(ctx context.Context, e *pb.DomainEvent) error {
+	switch e.GetType() {
+	case model.EventArticlePublished:
+		a, err := h.as.GetByID(uint(e.GetArticleId()))
+		if err != nil {
+			return skipMissing(err)
+		}
+		if a.IsListed() {
+			h.hub.Publish(articlesTopic, *a)
+		}
+
+	case model.EventCommentCreated:
+		c, err := h.eventComment(e)
+		if err != nil {
+			return skipMissing(err)
+		}
+		h.hub.Publish(commentsTopic(c.ArticleID), *c)
+
+	case model.EventNotificationCreated:
+		n, err := h.us.GetNotificationByID(uint(e.GetNotificationId()))
+		if err != nil {
+			return skipMissing(err)
+		}
+		h.hub.Publish(notificationsTopic(n.RecipientID), *n)
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/raahii/golang-grpc-realworld-example/auth"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+// eventStream is a pb.Events_SubscribeServer passing sent events to a channel
+type eventStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	events chan *pb.Event
+}
+
+func (s *eventStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *eventStream) Send(e *pb.Event) error {
+	s.events <- e
+	return nil
+}
+
+// subscribe runs Subscribe in background until cancel is called
+func subscribe(h *Handler, ctx context.Context, req *pb.SubscribeRequest) (*eventStream, context.CancelFunc, chan error) {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &eventStream{ctx: ctx, events: make(chan *pb.Event, 10)}
+	done := make(chan error, 1)
+	go func() {
+		done <- h.Subscribe(req, s)
+	}()
+	return s, cancel, done
+}
+
+func nextEvent(t *testing.T, s *eventStream) *pb.Event {
+	t.Helper()
+	select {
+	case e := <-s.events:
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event received")
+		return nil
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+
+	users := []*model.User{
+		{Username: "foo", Email: "foo@example.com", Password: "secret"},
+		{Username: "bar", Email: "bar@example.com", Password: "secret"},
+		{Username: "baz", Email: "baz@example.com", Password: "secret"},
+	}
+	ctxs := map[string]context.Context{}
+	for _, u := range users {
+		if err := h.us.Create(u); err != nil {
+			t.Fatalf("failed to create initial user record: %v", err)
+		}
+
+		token, err := auth.GenerateToken(u.ID)
+		if err != nil {
+			t.Error(err)
+		}
+		ctxs[u.Username] = ctxWithToken(context.Background(), token)
+	}
+	fooUser, barUser := users[0], users[1]
+
+	if err := h.us.Follow(barUser, fooUser); err != nil {
+		t.Fatalf("failed to create follow relationship: %v", err)
+	}
+
+	article := model.Article{
+		Title:  "title",
+		Body:   "body",
+		Author: *fooUser,
+		Status: model.ArticlePublished,
+	}
+	if err := h.as.Create(&article); err != nil {
+		t.Fatalf("failed to create initial article record: %v", err)
+	}
+	slug := fmt.Sprintf("%d", article.ID)
+	dispatchEvents(t, h)
+
+	stream, cancel, done := subscribe(h, ctxs["bar"], &pb.SubscribeRequest{Watch: []string{slug}})
+	assert.Equal(t, eventReady, nextEvent(t, stream).GetType())
+
+	// articles of users bar doesn't follow are not streamed
+	for _, ctx := range []context.Context{ctxs["baz"], ctxs["foo"]} {
+		_, err := h.CreateArticle(ctx, &pb.CreateAritcleRequest{
+			Article: &pb.CreateAritcleRequest_Article{Title: "new article", Body: "body"},
+		})
+		if err != nil {
+			t.Fatalf("create article expected to succeed, but failed. %v", err)
+		}
+	}
+
+	_, err := h.CreateComment(ctxs["baz"], &pb.CreateCommentRequest{
+		Slug: slug, Comment: &pb.CreateCommentRequest_Comment{Body: "comment"},
+	})
+	if err != nil {
+		t.Fatalf("create comment expected to succeed, but failed. %v", err)
+	}
+
+	if _, err := h.FollowUser(ctxs["baz"], &pb.FollowRequest{Username: "bar"}); err != nil {
+		t.Fatalf("follow user expected to succeed, but failed. %v", err)
+	}
+	dispatchEvents(t, h)
+
+	e := nextEvent(t, stream)
+	assert.Equal(t, model.EventArticlePublished, e.GetType())
+	assert.Equal(t, "foo", e.GetArticle().GetAuthor().GetUsername())
+	assert.True(t, e.GetArticle().GetAuthor().GetFollowing())
+	lastID := e.GetId()
+
+	e = nextEvent(t, stream)
+	assert.Equal(t, model.EventCommentCreated, e.GetType())
+	assert.Equal(t, slug, e.GetSlug())
+	assert.Equal(t, "comment", e.GetComment().GetBody())
+
+	e = nextEvent(t, stream)
+	assert.Equal(t, eventNotification, e.GetType())
+	assert.Equal(t, "baz followed you", e.GetNotification().GetMessage())
+
+	cancel()
+	assert.NoError(t, <-done)
+
+	// resume after the article
+	stream, cancel, done = subscribe(h, ctxs["bar"], &pb.SubscribeRequest{Watch: []string{slug}, LastEventId: lastID})
+	assert.Equal(t, eventReady, nextEvent(t, stream).GetType())
+	assert.Equal(t, model.EventCommentCreated, nextEvent(t, stream).GetType())
+	assert.Equal(t, eventNotification, nextEvent(t, stream).GetType())
+	cancel()
+	assert.NoError(t, <-done)
+
+	// events before the last seen one are lost
+	stream, cancel, done = subscribe(h, ctxs["bar"], &pb.SubscribeRequest{LastEventId: "1"})
+	assert.Equal(t, eventReset, nextEvent(t, stream).GetType())
+	cancel()
+	assert.NoError(t, <-done)
+
+	tests := []struct {
+		title string
+		ctx   context.Context
+		req   *pb.SubscribeRequest
+	}{
+		{"unauthenticated: failed", context.Background(), &pb.SubscribeRequest{}},
+		{"invalid watched slug: failed", ctxs["bar"], &pb.SubscribeRequest{Watch: []string{"foo"}}},
+		{"unknown watched article: failed", ctxs["bar"], &pb.SubscribeRequest{Watch: []string{"0"}}},
+		{"invalid last event id: failed", ctxs["bar"], &pb.SubscribeRequest{LastEventId: "foo"}},
+	}
+
+	for _, tt := range tests {
+		_, cancel, done := subscribe(h, tt.ctx, tt.req)
+		if err := <-done; err == nil {
+			t.Errorf("%q expected to fail, but succeeded.", tt.title)
+		}
+		cancel()
+	}
+}
@@ -1,18 +1,117 @@
 package handler
 
 import (
+	"os"
+	"strconv"
+
+	"github.com/raahii/golang-grpc-realworld-example/mail"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	"github.com/raahii/golang-grpc-realworld-example/oidc"
+	"github.com/raahii/golang-grpc-realworld-example/outbox"
+	"github.com/raahii/golang-grpc-realworld-example/policy"
+	"github.com/raahii/golang-grpc-realworld-example/pubsub"
+	"github.com/raahii/golang-grpc-realworld-example/related"
+	"github.com/raahii/golang-grpc-realworld-example/search"
 	"github.com/raahii/golang-grpc-realworld-example/store"
+	"github.com/raahii/golang-grpc-realworld-example/views"
+	"github.com/raahii/golang-grpc-realworld-example/webhook"
 	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// defaultReportThreshold is the number of open reports that hides content
+	defaultReportThreshold = 3
+
+	// defaultLimit and maxLimit are the default and maximum page sizes of lists
+	defaultLimit = 20
+	maxLimit     = 100
 )
 
 // Handler definition
 type Handler struct {
-	logger *zerolog.Logger
-	us     *store.UserStore
-	as     *store.ArticleStore
+	logger          *zerolog.Logger
+	us              *store.UserStore
+	as              *store.ArticleStore
+	ob              *store.OutboxStore
+	idp             *oidc.Provider
+	index           *search.Index
+	hub             *pubsub.Hub
+	relay           *outbox.Relay
+	webhooks        *webhook.Sender
+	views           *views.Counter
+	related         *related.Cache
+	mailer          mail.Mailer
+	publicURL       string
+	mailFrom        string
+	reportThreshold int
+	proxyHops       int
+}
+
+// New returns a new handler with logger and database.
+// idp may be nil when external login is not configured.
+func New(l *zerolog.Logger, us *store.UserStore, as *store.ArticleStore, ob *store.OutboxStore, idp *oidc.Provider, idx *search.Index, m mail.Mailer) *Handler {
+	h := &Handler{
+		logger:          l,
+		us:              us,
+		as:              as,
+		ob:              ob,
+		idp:             idp,
+		index:           idx,
+		hub:             pubsub.NewHub(eventHistorySize),
+		relay:           outbox.NewRelay(l, ob),
+		webhooks:        webhook.NewSender(webhookTimeout),
+		views:           views.NewCounter(viewWindow),
+		related:         related.NewCache(relatedCacheTTL, relatedCacheSize),
+		mailer:          m,
+		publicURL:       publicURL(),
+		mailFrom:        mailFrom(),
+		reportThreshold: reportThreshold(),
+		proxyHops:       proxyHops(),
+	}
+	h.subscribeEvents(h.relay)
+
+	return h
+}
+
+// checkWrite fails unless the user can create or change content, logging the
+// attempted action
+func (h *Handler) checkWrite(u *model.User, action string) error {
+	if !policy.CanWrite(u) {
+		h.logger.Error().Msgf("restricted user(id=%d) attempted to %s", u.ID, action)
+		return status.Error(codes.PermissionDenied, "your account is restricted")
+	}
+	return nil
+}
+
+// checkPage fails on a negative limit or offset of a list
+func (h *Handler) checkPage(limit, offset int64) error {
+	if limit < 0 || offset < 0 {
+		msg := "limit and offset must not be negative"
+		h.logger.Error().Msg(msg)
+		return status.Error(codes.InvalidArgument, msg)
+	}
+	return nil
+}
+
+// pageLimit returns the page size for a requested limit: the default for
+// zero or less and at most maxLimit
+func pageLimit(limit int64) int64 {
+	if limit <= 0 {
+		return defaultLimit
+	}
+	if limit > maxLimit {
+		return maxLimit
+	}
+	return limit
 }
 
-// New returns a new handler with logger and database
-func New(l *zerolog.Logger, us *store.UserStore, as *store.ArticleStore) *Handler {
-	return &Handler{logger: l, us: us, as: as}
+// reportThreshold reads $REPORT_THRESHOLD. Zero disables hiding reported content.
+func reportThreshold() int {
+	n, err := strconv.Atoi(os.Getenv("REPORT_THRESHOLD"))
+	if err != nil || n < 0 {
+		return defaultReportThreshold
+	}
+	return n
 }
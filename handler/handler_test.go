@@ -4,13 +4,19 @@ import (
 	"context"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"testing"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/grpc-ecosystem/go-grpc-middleware/util/metautils"
+	"github.com/raahii/golang-grpc-realworld-example/auth"
 	"github.com/raahii/golang-grpc-realworld-example/db"
+	"github.com/raahii/golang-grpc-realworld-example/mail"
+	"github.com/raahii/golang-grpc-realworld-example/search"
 	"github.com/raahii/golang-grpc-realworld-example/store"
 	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 )
 
@@ -24,10 +30,28 @@ func setUp(t *testing.T) (*Handler, func(t *testing.T)) {
 		t.Fatal(fmt.Errorf("failed to initialize database: %w", err))
 	}
 
+	idx, err := search.Open("")
+	if err != nil {
+		t.Fatal(fmt.Errorf("failed to open search index: %w", err))
+	}
+
 	us := store.NewUserStore(d)
 	as := store.NewArticleStore(d)
+	ob := store.NewOutboxStore(d)
+	auth.SetAPITokenVerifier(us)
 
-	return New(&l, us, as), func(t *testing.T) {
+	mailDir, err := ioutil.TempDir("", "mail")
+	if err != nil {
+		t.Fatal(fmt.Errorf("failed to create mail directory: %w", err))
+	}
+	mailer, err := mail.NewFileMailer(mailDir)
+	if err != nil {
+		t.Fatal(fmt.Errorf("failed to create mailer: %w", err))
+	}
+
+	return New(&l, us, as, ob, nil, idx, mailer), func(t *testing.T) {
+		idx.Close()
+		os.RemoveAll(mailDir)
 		err := db.DropTestDB(d)
 		if err != nil {
 			t.Fatal(fmt.Errorf("failed to clean database: %w", err))
@@ -35,9 +59,34 @@ func setUp(t *testing.T) (*Handler, func(t *testing.T)) {
 	}
 }
 
+// dispatchEvents runs the follow-on work of the domain events recorded so far
+func dispatchEvents(t *testing.T, h *Handler) {
+	t.Helper()
+	if err := h.relay.DispatchAll(context.Background()); err != nil {
+		t.Fatalf("failed to dispatch domain events: %v", err)
+	}
+	if err := h.relay.FollowAll(context.Background(), time.Now()); err != nil {
+		t.Fatalf("failed to follow domain events: %v", err)
+	}
+}
+
 func ctxWithToken(ctx context.Context, token string) context.Context {
 	scheme := "Token"
 	md := metadata.Pairs("authorization", fmt.Sprintf("%s %s", scheme, token))
 	nCtx := metautils.NiceMD(md).ToIncoming(ctx)
 	return nCtx
 }
+
+// methodStream is a grpc.ServerTransportStream carrying only the method name
+type methodStream struct {
+	method string
+}
+
+func (s *methodStream) Method() string                  { return s.method }
+func (s *methodStream) SetHeader(md metadata.MD) error  { return nil }
+func (s *methodStream) SendHeader(md metadata.MD) error { return nil }
+func (s *methodStream) SetTrailer(md metadata.MD) error { return nil }
+
+func ctxWithMethod(ctx context.Context, method string) context.Context {
+	return grpc.NewContextWithServerTransportStream(ctx, &methodStream{method: method})
+}
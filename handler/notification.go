@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/raahii/golang-grpc-realworld-example/auth"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListNotifications lists notifications of current user, the most recently updated first
+func (h *Handler) ListNotifications(ctx context.Context, req *pb.ListNotificationsRequest) (*pb.NotificationsResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("list notifications")
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("unauthenticated")
+		return nil, status.Errorf(codes.Unauthenticated, "unauthenticated")
+	}
+
+	currentUser, err := h.us.GetByID(userID)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("current user not found")
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	if err := h.checkPage(req.GetLimit(), req.GetOffset()); err != nil {
+		return nil, err
+	}
+	limitQuery := pageLimit(req.GetLimit())
+
+	ns, count, err := h.us.GetNotifications(currentUser, req.GetUnread(), limitQuery, req.GetOffset())
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to get notifications")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	unread, err := h.us.CountUnreadNotifications(currentUser)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to count unread notifications")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	pns := make([]*pb.Notification, 0, len(ns))
+	for i := range ns {
+		pns = append(pns, ns[i].ProtoNotification())
+	}
+
+	return &pb.NotificationsResponse{
+		Notifications:      pns,
+		NotificationsCount: int32(count),
+		UnreadCount:        int32(unread),
+	}, nil
+}
+
+// GetUnreadCount returns the number of unread notifications of current user
+func (h *Handler) GetUnreadCount(ctx context.Context, req *pb.Empty) (*pb.UnreadCountResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("get unread count")
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("unauthenticated")
+		return nil, status.Errorf(codes.Unauthenticated, "unauthenticated")
+	}
+
+	currentUser, err := h.us.GetByID(userID)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("current user not found")
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	unread, err := h.us.CountUnreadNotifications(currentUser)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to count unread notifications")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	return &pb.UnreadCountResponse{UnreadCount: int32(unread)}, nil
+}
+
+// MarkRead marks notifications of current user as read, either the ones of ids or all of them
+func (h *Handler) MarkRead(ctx context.Context, req *pb.MarkReadRequest) (*pb.UnreadCountResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("mark notifications read")
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("unauthenticated")
+		return nil, status.Errorf(codes.Unauthenticated, "unauthenticated")
+	}
+
+	currentUser, err := h.us.GetByID(userID)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("current user not found")
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	if req.GetAll() == (len(req.GetIds()) > 0) {
+		msg := "specify either ids or all"
+		h.logger.Error().Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, msg)
+	}
+
+	var ids []uint
+	for _, s := range req.GetIds() {
+		id, err := strconv.Atoi(s)
+		if err != nil {
+			msg := fmt.Sprintf("cannot convert id (%s) into integer", s)
+			h.logger.Error().Err(err).Msg(msg)
+			return nil, status.Error(codes.InvalidArgument, "invalid notification id")
+		}
+		ids = append(ids, uint(id))
+	}
+
+	if err := h.us.MarkNotificationsRead(currentUser, ids); err != nil {
+		h.logger.Error().Err(err).Msg("failed to mark notifications read")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	unread, err := h.us.CountUnreadNotifications(currentUser)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to count unread notifications")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	return &pb.UnreadCountResponse{UnreadCount: int32(unread)}, nil
+}
+
+// notifyEvent notifies authors of favorites of and comments on their
+// articles, and users of new followers
+func (h *Handler) notifyEvent(ctx context.Context, e *pb.DomainEvent) error {
+	actor, err := h.us.GetByID(uint(e.GetActorId()))
+	if err != nil {
+		return skipMissing(err)
+	}
+
+	switch e.GetType() {
+	case model.EventArticleFavorited, model.EventCommentCreated:
+		a, err := h.as.GetByID(uint(e.GetArticleId()))
+		if err != nil {
+			return skipMissing(err)
+		}
+
+		typ := model.NotificationFavorite
+		if e.GetType() == model.EventCommentCreated {
+			typ = model.NotificationComment
+		}
+		return h.notify(actor, &a.Author, typ, a, uint(e.GetCommentId()))
+
+	case model.EventUserFollowed:
+		u, err := h.us.GetByID(uint(e.GetUserId()))
+		if err != nil {
+			return skipMissing(err)
+		}
+		return h.notify(actor, u, model.NotificationFollow, nil, 0)
+	}
+
+	return nil
+}
+
+// notify tells the recipient about the action of actor on the article, which is
+// nil for follows. Nothing is sent for one's own actions or when either of them
+// blocks the other or the recipient mutes the actor.
+func (h *Handler) notify(actor, recipient *model.User, typ string, article *model.Article, commentID uint) error {
+	if actor.ID == recipient.ID {
+		return nil
+	}
+
+	blocked, err := h.us.IsBlockedBetween(actor, recipient)
+	if err != nil {
+		return fmt.Errorf("failed to get blocking status: %w", err)
+	}
+
+	muted, err := h.us.IsMuting(recipient, actor)
+	if err != nil {
+		return fmt.Errorf("failed to get muting status: %w", err)
+	}
+
+	if blocked || muted {
+		return nil
+	}
+
+	n := model.Notification{
+		RecipientID: recipient.ID,
+		Type:        typ,
+		CommentID:   commentID,
+		ActorID:     actor.ID,
+	}
+	if article != nil {
+		n.ArticleID = article.ID
+	}
+
+	// the notification is pushed by following the event recorded with it
+	if err := h.us.CreateNotification(&n); err != nil {
+		return fmt.Errorf("failed to notify user(id=%d) of %s: %w", recipient.ID, typ, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/raahii/golang-grpc-realworld-example/auth"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotifications(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+
+	users := []*model.User{
+		{Username: "foo", Email: "foo@example.com", Password: "secret"},
+		{Username: "bar", Email: "bar@example.com", Password: "secret"},
+		{Username: "baz", Email: "baz@example.com", Password: "secret"},
+		{Username: "qux", Email: "qux@example.com", Password: "secret"},
+	}
+	ctxs := map[string]context.Context{}
+	for _, u := range users {
+		if err := h.us.Create(u); err != nil {
+			t.Fatalf("failed to create initial user record: %v", err)
+		}
+
+		token, err := auth.GenerateToken(u.ID)
+		if err != nil {
+			t.Error(err)
+		}
+		ctxs[u.Username] = ctxWithToken(context.Background(), token)
+	}
+	fooUser, quxUser := users[0], users[3]
+
+	article := model.Article{
+		Title:  "title",
+		Body:   "body",
+		Author: *fooUser,
+		Status: model.ArticlePublished,
+	}
+	if err := h.as.Create(&article); err != nil {
+		t.Fatalf("failed to create initial article record: %v", err)
+	}
+	slug := fmt.Sprintf("%d", article.ID)
+
+	if err := h.us.Mute(fooUser, quxUser); err != nil {
+		t.Fatalf("failed to create mute relationship: %v", err)
+	}
+
+	for _, f := range []func() error{
+		func() error {
+			_, err := h.FollowUser(ctxs["bar"], &pb.FollowRequest{Username: "foo"})
+			return err
+		},
+		func() error {
+			_, err := h.FavoriteArticle(ctxs["bar"], &pb.FavoriteArticleRequest{Slug: slug})
+			return err
+		},
+		func() error {
+			_, err := h.FavoriteArticle(ctxs["baz"], &pb.FavoriteArticleRequest{Slug: slug})
+			return err
+		},
+		func() error {
+			_, err := h.CreateComment(ctxs["bar"], &pb.CreateCommentRequest{
+				Slug: slug, Comment: &pb.CreateCommentRequest_Comment{Body: "first"}})
+			return err
+		},
+		func() error {
+			_, err := h.CreateComment(ctxs["bar"], &pb.CreateCommentRequest{
+				Slug: slug, Comment: &pb.CreateCommentRequest_Comment{Body: "second"}})
+			return err
+		},
+		// own actions and muted users are not notified
+		func() error {
+			_, err := h.FavoriteArticle(ctxs["foo"], &pb.FavoriteArticleRequest{Slug: slug})
+			return err
+		},
+		func() error {
+			_, err := h.FollowUser(ctxs["qux"], &pb.FollowRequest{Username: "foo"})
+			return err
+		},
+	} {
+		if err := f(); err != nil {
+			t.Fatalf("action expected to succeed, but failed. %v", err)
+		}
+	}
+	dispatchEvents(t, h)
+
+	resp, err := h.ListNotifications(ctxs["foo"], &pb.ListNotificationsRequest{})
+	if err != nil {
+		t.Fatalf("list notifications expected to succeed, but failed. %v", err)
+	}
+
+	assert.Equal(t, int32(3), resp.GetNotificationsCount())
+	assert.Equal(t, int32(3), resp.GetUnreadCount())
+
+	byType := map[string]*pb.Notification{}
+	for _, n := range resp.GetNotifications() {
+		byType[n.GetType()] = n
+	}
+
+	assert.Equal(t, "bar followed you", byType[model.NotificationFollow].GetMessage())
+	assert.Equal(t, int32(2), byType[model.NotificationFavorite].GetActorsCount())
+	assert.Equal(t, `2 people favorited your article "title"`, byType[model.NotificationFavorite].GetMessage())
+	assert.Equal(t, "baz", byType[model.NotificationFavorite].GetActor().GetUsername())
+	assert.Equal(t, int32(1), byType[model.NotificationComment].GetActorsCount())
+	assert.Equal(t, slug, byType[model.NotificationComment].GetSlug())
+	assert.Equal(t, "comment", resp.GetNotifications()[0].GetType(), "the most recent comes first")
+
+	_, err = h.ListNotifications(ctxs["foo"], &pb.ListNotificationsRequest{Offset: -1})
+	assert.Error(t, err)
+
+	// nothing for the others
+	other, err := h.GetUnreadCount(ctxs["bar"], &pb.Empty{})
+	if assert.NoError(t, err) {
+		assert.Equal(t, int32(0), other.GetUnreadCount())
+	}
+
+	tests := []struct {
+		title    string
+		req      *pb.MarkReadRequest
+		expected int32
+		hasError bool
+	}{
+		{
+			"mark by ids: success",
+			&pb.MarkReadRequest{Ids: []string{byType[model.NotificationFollow].GetId()}},
+			2,
+			false,
+		},
+		{
+			"neither ids nor all: failed",
+			&pb.MarkReadRequest{},
+			0,
+			true,
+		},
+		{
+			"both ids and all: failed",
+			&pb.MarkReadRequest{Ids: []string{"1"}, All: true},
+			0,
+			true,
+		},
+		{
+			"invalid id: failed",
+			&pb.MarkReadRequest{Ids: []string{"foo"}},
+			0,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		resp, err := h.MarkRead(ctxs["foo"], tt.req)
+		if tt.hasError {
+			if err == nil {
+				t.Errorf("%q expected to fail, but succeeded.", tt.title)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%q expected to succeed, but failed. %v", tt.title, err)
+			continue
+		}
+
+		assert.Equal(t, tt.expected, resp.GetUnreadCount(), tt.title)
+	}
+
+	// a read notification is not coalesced into
+	if _, err := h.FollowUser(ctxs["baz"], &pb.FollowRequest{Username: "foo"}); err != nil {
+		t.Fatalf("follow user expected to succeed, but failed. %v", err)
+	}
+
+	resp, err = h.ListNotifications(ctxs["foo"], &pb.ListNotificationsRequest{Unread: true})
+	if assert.NoError(t, err) {
+		assert.Equal(t, int32(3), resp.GetNotificationsCount())
+		assert.Equal(t, "baz followed you", resp.GetNotifications()[0].GetMessage())
+	}
+
+	count, err := h.MarkRead(ctxs["foo"], &pb.MarkReadRequest{All: true})
+	if assert.NoError(t, err) {
+		assert.Equal(t, int32(0), count.GetUnreadCount())
+	}
+}
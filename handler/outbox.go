@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	"github.com/raahii/golang-grpc-realworld-example/outbox"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+)
+
+const (
+	// relayInterval is how often the relay looks for events it wasn't woken for,
+	// e.g. the ones recorded by other servers or to be retried
+	relayInterval = 5 * time.Second
+	// outboxRetention is how long dispatched events are kept
+	outboxRetention = 7 * 24 * time.Hour
+)
+
+// subscribeEvents registers the follow-on work of domain events. The names
+// are recorded in receipts, so they must not change. Streams are followed on
+// every server, which pushes the events to its own clients.
+func (h *Handler) subscribeEvents(r *outbox.Relay) {
+	r.Follow(h.streamEvent,
+		model.EventArticlePublished,
+		model.EventCommentCreated,
+		model.EventNotificationCreated,
+	)
+	r.Subscribe("notifications", h.notifyEvent,
+		model.EventArticleFavorited,
+		model.EventCommentCreated,
+		model.EventUserFollowed,
+	)
+	r.Subscribe("webhooks", h.webhookEvent,
+		model.EventArticlePublished,
+		model.EventArticleUpdated,
+		model.EventCommentCreated,
+		model.EventUserFollowed,
+	)
+}
+
+// RelayEvents dispatches domain events until ctx is canceled
+func (h *Handler) RelayEvents(ctx context.Context) {
+	h.relay.Run(ctx, relayInterval)
+}
+
+// PruneOutbox deletes old dispatched events. It is run periodically by the job runner.
+func (h *Handler) PruneOutbox(ctx context.Context) error {
+	return h.ob.Prune(time.Now().Add(-outboxRetention))
+}
+
+// eventComment returns the comment of the event with its author
+func (h *Handler) eventComment(e *pb.DomainEvent) (*model.Comment, error) {
+	c, err := h.as.GetCommentByID(uint(e.GetCommentId()))
+	if err != nil {
+		return nil, err
+	}
+
+	author, err := h.us.GetByID(c.UserID)
+	if err != nil {
+		return nil, err
+	}
+	c.Author = *author
+
+	return c, nil
+}
+
+// skipMissing ignores that the records of an event were deleted
+// before it was dispatched, so that it isn't retried
+func skipMissing(err error) error {
+	if gorm.IsRecordNotFoundError(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/raahii/golang-grpc-realworld-example/auth"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutbox(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+
+	users := []*model.User{
+		{Username: "foo", Email: "foo@example.com", Password: "secret"},
+		{Username: "bar", Email: "bar@example.com", Password: "secret"},
+	}
+	ctxs := map[string]context.Context{}
+	for _, u := range users {
+		if err := h.us.Create(u); err != nil {
+			t.Fatalf("failed to create initial user record: %v", err)
+		}
+
+		token, err := auth.GenerateToken(u.ID)
+		if err != nil {
+			t.Error(err)
+		}
+		ctxs[u.Username] = ctxWithToken(context.Background(), token)
+	}
+	fooUser := users[0]
+
+	article := model.Article{
+		Title:  "title",
+		Body:   "body",
+		Author: *fooUser,
+		Status: model.ArticlePublished,
+	}
+	if err := h.as.Create(&article); err != nil {
+		t.Fatalf("failed to create initial article record: %v", err)
+	}
+	slug := fmt.Sprintf("%d", article.ID)
+
+	dispatch := func(now time.Time) int {
+		t.Helper()
+		n, err := h.relay.Dispatch(context.Background(), now)
+		if err != nil {
+			t.Fatalf("dispatch expected to succeed, but failed. %v", err)
+		}
+		return n
+	}
+	unread := func() int {
+		t.Helper()
+		n, err := h.us.CountUnreadNotifications(fooUser)
+		if err != nil {
+			t.Fatalf("failed to count notifications: %v", err)
+		}
+		return n
+	}
+
+	// the article itself was recorded as published
+	assert.Equal(t, 1, dispatch(time.Now()))
+
+	if _, err := h.FavoriteArticle(ctxs["bar"], &pb.FavoriteArticleRequest{Slug: slug}); err != nil {
+		t.Fatalf("favorite article expected to succeed, but failed. %v", err)
+	}
+	assert.Equal(t, 0, unread(), "follow-on work waits for the relay")
+
+	assert.Equal(t, 1, dispatch(time.Now()))
+	assert.Equal(t, 1, unread())
+
+	// the notification was recorded to be pushed
+	assert.Equal(t, 1, dispatch(time.Now()))
+
+	// dispatched events are not redelivered
+	assert.Equal(t, 0, dispatch(time.Now().Add(time.Hour)))
+	assert.Equal(t, 1, unread())
+
+	// following again changes nothing, so no event is recorded
+	for i := 0; i < 2; i++ {
+		if _, err := h.FollowUser(ctxs["bar"], &pb.FollowRequest{Username: "foo"}); err != nil {
+			t.Fatalf("follow user expected to succeed, but failed. %v", err)
+		}
+	}
+	assert.Equal(t, 1, dispatch(time.Now()))
+	assert.Equal(t, 2, unread())
+	assert.Equal(t, 1, dispatch(time.Now()))
+
+	// events about records deleted before dispatching are dropped, not retried
+	_, err := h.CreateComment(ctxs["bar"], &pb.CreateCommentRequest{
+		Slug: slug, Comment: &pb.CreateCommentRequest_Comment{Body: "comment"},
+	})
+	if err != nil {
+		t.Fatalf("create comment expected to succeed, but failed. %v", err)
+	}
+	if _, err := h.DeleteArticle(ctxs["foo"], &pb.DeleteArticleRequest{Slug: slug}); err != nil {
+		t.Fatalf("delete article expected to succeed, but failed. %v", err)
+	}
+	assert.Equal(t, 1, dispatch(time.Now()))
+	assert.Equal(t, 0, dispatch(time.Now().Add(time.Hour)))
+	assert.Equal(t, 2, unread())
+
+	// drafts are published only when listed
+	draft, err := h.CreateArticle(ctxs["foo"], &pb.CreateAritcleRequest{
+		Article: &pb.CreateAritcleRequest_Article{Title: "draft", Body: "body", Status: model.ArticleDraft},
+	})
+	if err != nil {
+		t.Fatalf("create article expected to succeed, but failed. %v", err)
+	}
+	assert.Equal(t, 0, dispatch(time.Now()))
+
+	if _, err := h.PublishArticle(ctxs["foo"], &pb.PublishArticleRequest{Slug: draft.GetArticle().GetSlug()}); err != nil {
+		t.Fatalf("publish article expected to succeed, but failed. %v", err)
+	}
+	assert.Equal(t, 1, dispatch(time.Now()))
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/raahii/golang-grpc-realworld-example/auth"
+	"github.com/raahii/golang-grpc-realworld-example/model"
 	pb "github.com/raahii/golang-grpc-realworld-example/proto"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -33,6 +34,19 @@ func (h *Handler) ShowProfile(ctx context.Context, req *pb.ShowProfileRequest) (
 		return nil, status.Error(codes.NotFound, msg)
 	}
 
+	blocked, err := h.us.IsBlocking(requestUser, currentUser)
+	if err != nil {
+		msg := "failed to get blocking status"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	if blocked {
+		h.logger.Error().Msgf("blocked user(id=%d) attempted to show profile of user(id=%d)",
+			currentUser.ID, requestUser.ID)
+		return nil, status.Error(codes.NotFound, "user was not found")
+	}
+
 	following, err := h.us.IsFollowing(currentUser, requestUser)
 	if err != nil {
 		msg := "failed to get following status"
@@ -40,7 +54,23 @@ func (h *Handler) ShowProfile(ctx context.Context, req *pb.ShowProfileRequest) (
 		return nil, status.Error(codes.NotFound, "internal server error")
 	}
 
-	return &pb.ProfileResponse{Profile: requestUser.ProtoProfile(following)}, nil
+	pp := requestUser.ProtoProfile(following)
+
+	pp.Blocking, err = h.us.IsBlocking(currentUser, requestUser)
+	if err != nil {
+		msg := "failed to get blocking status"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	pp.Muting, err = h.us.IsMuting(currentUser, requestUser)
+	if err != nil {
+		msg := "failed to get muting status"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	return &pb.ProfileResponse{Profile: pp}, nil
 }
 
 // FollowUser follow a user
@@ -60,6 +90,10 @@ func (h *Handler) FollowUser(ctx context.Context, req *pb.FollowRequest) (*pb.Pr
 		return nil, status.Error(codes.NotFound, "user not found")
 	}
 
+	if err := h.checkWrite(currentUser, "follow user"); err != nil {
+		return nil, err
+	}
+
 	if currentUser.Username == req.GetUsername() {
 		h.logger.Error().Msg("cannot follow yourself")
 		return nil, status.Error(codes.InvalidArgument, "cannot follow yourself")
@@ -71,6 +105,19 @@ func (h *Handler) FollowUser(ctx context.Context, req *pb.FollowRequest) (*pb.Pr
 		return nil, status.Error(codes.NotFound, "user was not found")
 	}
 
+	blocked, err := h.us.IsBlockedBetween(currentUser, requestUser)
+	if err != nil {
+		msg := "failed to get blocking status"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	if blocked {
+		h.logger.Error().Msgf("user(id=%d) attempted to follow user(id=%d) across a block",
+			currentUser.ID, requestUser.ID)
+		return nil, status.Error(codes.PermissionDenied, "you cannot follow the user")
+	}
+
 	err = h.us.Follow(currentUser, requestUser)
 	if err != nil {
 		msg := fmt.Sprintf("failed to follow user: (ID: %d) -> (ID: %d)",
@@ -78,6 +125,7 @@ func (h *Handler) FollowUser(ctx context.Context, req *pb.FollowRequest) (*pb.Pr
 		h.logger.Error().Err(err).Msg(msg)
 		return nil, status.Error(codes.Aborted, "failed to follow user")
 	}
+	h.relay.Wake()
 
 	return &pb.ProfileResponse{Profile: requestUser.ProtoProfile(true)}, nil
 }
@@ -99,6 +147,10 @@ func (h *Handler) UnfollowUser(ctx context.Context, req *pb.UnfollowRequest) (*p
 		return nil, status.Error(codes.NotFound, "user not found")
 	}
 
+	if err := h.checkWrite(currentUser, "unfollow user"); err != nil {
+		return nil, err
+	}
+
 	if currentUser.Username == req.GetUsername() {
 		h.logger.Error().Msg("cannot follow yourself")
 		return nil, status.Error(codes.InvalidArgument, "cannot follow yourself")
@@ -133,3 +185,337 @@ func (h *Handler) UnfollowUser(ctx context.Context, req *pb.UnfollowRequest) (*p
 
 	return &pb.ProfileResponse{Profile: requestUser.ProtoProfile(false)}, nil
 }
+
+// BlockUser blocks a user
+func (h *Handler) BlockUser(ctx context.Context, req *pb.BlockRequest) (*pb.ProfileResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("block user")
+
+	currentUser, requestUser, err := h.relationshipUsers(ctx, req.GetUsername())
+	if err != nil {
+		return nil, err
+	}
+
+	blocking, err := h.us.IsBlocking(currentUser, requestUser)
+	if err != nil {
+		msg := "failed to get blocking status"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	if blocking {
+		h.logger.Error().Msg("current user is already blocking request user")
+		return nil, status.Error(codes.AlreadyExists, "you are already blocking the user")
+	}
+
+	err = h.us.Block(currentUser, requestUser)
+	if err != nil {
+		msg := fmt.Sprintf("failed to block user: (ID: %d) -> (ID: %d)",
+			currentUser.ID, requestUser.ID)
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, "failed to block user")
+	}
+
+	pp := requestUser.ProtoProfile(false)
+	pp.Blocking = true
+
+	return &pb.ProfileResponse{Profile: pp}, nil
+}
+
+// UnblockUser unblocks a user
+func (h *Handler) UnblockUser(ctx context.Context, req *pb.UnblockRequest) (*pb.ProfileResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("unblock user")
+
+	currentUser, requestUser, err := h.relationshipUsers(ctx, req.GetUsername())
+	if err != nil {
+		return nil, err
+	}
+
+	blocking, err := h.us.IsBlocking(currentUser, requestUser)
+	if err != nil {
+		msg := "failed to get blocking status"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	if !blocking {
+		h.logger.Error().Msg("current user is not blocking request user")
+		return nil, status.Error(codes.FailedPrecondition, "you are not blocking the user")
+	}
+
+	err = h.us.Unblock(currentUser, requestUser)
+	if err != nil {
+		msg := fmt.Sprintf("failed to unblock user: (ID: %d) -> (ID: %d)",
+			currentUser.ID, requestUser.ID)
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, "failed to unblock user")
+	}
+
+	return &pb.ProfileResponse{Profile: requestUser.ProtoProfile(false)}, nil
+}
+
+// MuteUser mutes a user
+func (h *Handler) MuteUser(ctx context.Context, req *pb.MuteRequest) (*pb.ProfileResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("mute user")
+
+	currentUser, requestUser, err := h.relationshipUsers(ctx, req.GetUsername())
+	if err != nil {
+		return nil, err
+	}
+
+	muting, err := h.us.IsMuting(currentUser, requestUser)
+	if err != nil {
+		msg := "failed to get muting status"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	if muting {
+		h.logger.Error().Msg("current user is already muting request user")
+		return nil, status.Error(codes.AlreadyExists, "you are already muting the user")
+	}
+
+	err = h.us.Mute(currentUser, requestUser)
+	if err != nil {
+		msg := fmt.Sprintf("failed to mute user: (ID: %d) -> (ID: %d)",
+			currentUser.ID, requestUser.ID)
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, "failed to mute user")
+	}
+
+	following, err := h.us.IsFollowing(currentUser, requestUser)
+	if err != nil {
+		msg := "failed to get following status"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.NotFound, "internal server error")
+	}
+
+	pp := requestUser.ProtoProfile(following)
+	pp.Muting = true
+
+	return &pb.ProfileResponse{Profile: pp}, nil
+}
+
+// UnmuteUser unmutes a user
+func (h *Handler) UnmuteUser(ctx context.Context, req *pb.UnmuteRequest) (*pb.ProfileResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("unmute user")
+
+	currentUser, requestUser, err := h.relationshipUsers(ctx, req.GetUsername())
+	if err != nil {
+		return nil, err
+	}
+
+	muting, err := h.us.IsMuting(currentUser, requestUser)
+	if err != nil {
+		msg := "failed to get muting status"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	if !muting {
+		h.logger.Error().Msg("current user is not muting request user")
+		return nil, status.Error(codes.FailedPrecondition, "you are not muting the user")
+	}
+
+	err = h.us.Unmute(currentUser, requestUser)
+	if err != nil {
+		msg := fmt.Sprintf("failed to unmute user: (ID: %d) -> (ID: %d)",
+			currentUser.ID, requestUser.ID)
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, "failed to unmute user")
+	}
+
+	following, err := h.us.IsFollowing(currentUser, requestUser)
+	if err != nil {
+		msg := "failed to get following status"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.NotFound, "internal server error")
+	}
+
+	return &pb.ProfileResponse{Profile: requestUser.ProtoProfile(following)}, nil
+}
+
+// ListBlockedUsers lists users current user blocks
+func (h *Handler) ListBlockedUsers(ctx context.Context, req *pb.Empty) (*pb.ProfilesResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("list blocked users")
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("unauthenticated")
+		return nil, status.Errorf(codes.Unauthenticated, "unauthenticated")
+	}
+
+	currentUser, err := h.us.GetByID(userID)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("current user not found")
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	us, err := h.us.GetBlockedUsers(currentUser)
+	if err != nil {
+		msg := "failed to get blocked users"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	pps := make([]*pb.Profile, 0, len(us))
+	for _, u := range us {
+		pp := u.ProtoProfile(false)
+		pp.Blocking = true
+		pps = append(pps, pp)
+	}
+
+	return &pb.ProfilesResponse{Profiles: pps}, nil
+}
+
+// ListMutedUsers lists users current user mutes
+func (h *Handler) ListMutedUsers(ctx context.Context, req *pb.Empty) (*pb.ProfilesResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("list muted users")
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("unauthenticated")
+		return nil, status.Errorf(codes.Unauthenticated, "unauthenticated")
+	}
+
+	currentUser, err := h.us.GetByID(userID)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("current user not found")
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	us, err := h.us.GetMutedUsers(currentUser)
+	if err != nil {
+		msg := "failed to get muted users"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	pps := make([]*pb.Profile, 0, len(us))
+	for _, u := range us {
+		following, err := h.us.IsFollowing(currentUser, &u)
+		if err != nil {
+			msg := "failed to get following status"
+			h.logger.Error().Err(err).Msg(msg)
+			return nil, status.Error(codes.NotFound, "internal server error")
+		}
+
+		pp := u.ProtoProfile(following)
+		pp.Muting = true
+		pps = append(pps, pp)
+	}
+
+	return &pb.ProfilesResponse{Profiles: pps}, nil
+}
+
+// relationshipUsers returns current user and the other user of a block or mute request
+func (h *Handler) relationshipUsers(ctx context.Context, username string) (*model.User, *model.User, error) {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("unauthenticated")
+		return nil, nil, status.Errorf(codes.Unauthenticated, "unauthenticated")
+	}
+
+	currentUser, err := h.us.GetByID(userID)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("current user not found")
+		return nil, nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	if err := h.checkWrite(currentUser, "block or mute user"); err != nil {
+		return nil, nil, err
+	}
+
+	if currentUser.Username == username {
+		msg := "cannot block or mute yourself"
+		h.logger.Error().Msg(msg)
+		return nil, nil, status.Error(codes.InvalidArgument, msg)
+	}
+
+	requestUser, err := h.us.GetByUsername(username)
+	if err != nil {
+		msg := "user was not found"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, nil, status.Error(codes.NotFound, msg)
+	}
+
+	return currentUser, requestUser, nil
+}
+
+// ListFollowers lists users following the user
+func (h *Handler) ListFollowers(ctx context.Context, req *pb.ListFollowersRequest) (*pb.ProfilesResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("list followers")
+
+	return h.listFollows(ctx, req.GetUsername(), req.GetLimit(), req.GetOffset(), h.us.GetFollowers)
+}
+
+// ListFollowing lists users the user follows
+func (h *Handler) ListFollowing(ctx context.Context, req *pb.ListFollowingRequest) (*pb.ProfilesResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("list following")
+
+	return h.listFollows(ctx, req.GetUsername(), req.GetLimit(), req.GetOffset(), h.us.GetFollowing)
+}
+
+// listFollows lists profiles related to the user by get, with following flags for current user
+func (h *Handler) listFollows(ctx context.Context, username string, limit, offset int64,
+	get func(m *model.User, limit, offset int64) ([]model.User, error)) (*pb.ProfilesResponse, error) {
+	if err := h.checkPage(limit, offset); err != nil {
+		return nil, err
+	}
+
+	var currentUser *model.User
+	userID, err := auth.GetUserID(ctx)
+	if err == nil {
+		currentUser, err = h.us.GetByID(userID)
+		if err != nil {
+			h.logger.Error().Err(err).Msg("current user not found")
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+	}
+
+	requestUser, err := h.us.GetByUsername(username)
+	if err != nil {
+		msg := "user was not found"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.NotFound, msg)
+	}
+
+	blocked, err := h.us.IsBlocking(requestUser, currentUser)
+	if err != nil {
+		msg := "failed to get blocking status"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	if blocked {
+		h.logger.Error().Msgf("blocked user(id=%d) attempted to list follows of user(id=%d)",
+			currentUser.ID, requestUser.ID)
+		return nil, status.Error(codes.NotFound, "user was not found")
+	}
+
+	us, err := get(requestUser, pageLimit(limit), offset)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to search users in the database")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	following := map[uint]bool{}
+	if currentUser != nil {
+		ids, err := h.us.GetFollowingUserIDs(currentUser)
+		if err != nil {
+			msg := fmt.Sprintf("failed to get following user ids of user %d", currentUser.ID)
+			h.logger.Error().Err(err).Msg(msg)
+			return nil, status.Error(codes.Aborted, "internal server error")
+		}
+
+		for _, id := range ids {
+			following[id] = true
+		}
+	}
+
+	pps := make([]*pb.Profile, 0, len(us))
+	for _, u := range us {
+		pps = append(pps, u.ProtoProfile(following[u.ID]))
+	}
+
+	return &pb.ProfilesResponse{Profiles: pps}, nil
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/raahii/golang-grpc-realworld-example/auth"
@@ -270,3 +271,371 @@ func TestUnfollowUser(t *testing.T) {
 		assert.Equal(t, resp.GetProfile().GetFollowing(), tt.expected.GetFollowing())
 	}
 }
+
+func TestBlockUser(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+
+	fooUser := model.User{
+		Username: "foo",
+		Email:    "foo@example.com",
+		Password: "secret",
+	}
+
+	barUser := model.User{
+		Username: "bar",
+		Email:    "bar@example.com",
+		Password: "secret",
+	}
+
+	for _, u := range []*model.User{&fooUser, &barUser} {
+		if err := h.us.Create(u); err != nil {
+			t.Fatalf("failed to create initial user record: %v", err)
+		}
+	}
+
+	if err := h.us.Follow(&barUser, &fooUser); err != nil {
+		t.Fatalf("failed to create initial user follow relationship: %v", err)
+	}
+
+	article := model.Article{
+		Title:       "awesome post!",
+		Description: "awesome description!",
+		Body:        "awesome content!",
+		Tags:        []model.Tag{{Name: "hoge"}},
+		Author:      fooUser,
+	}
+
+	if err := h.as.Create(&article); err != nil {
+		t.Fatalf("failed to create initial article record: %v", err)
+	}
+
+	fooToken, err := auth.GenerateToken(fooUser.ID)
+	if err != nil {
+		t.Error(err)
+	}
+	fooCtx := ctxWithToken(context.Background(), fooToken)
+
+	barToken, err := auth.GenerateToken(barUser.ID)
+	if err != nil {
+		t.Error(err)
+	}
+	barCtx := ctxWithToken(context.Background(), barToken)
+
+	tests := []struct {
+		title    string
+		req      *pb.BlockRequest
+		hasError bool
+	}{
+		{
+			"block yourself: failed",
+			&pb.BlockRequest{Username: fooUser.Username},
+			true,
+		},
+		{
+			"block invalid user: failed",
+			&pb.BlockRequest{Username: "invalid username"},
+			true,
+		},
+		{
+			"block bar: success",
+			&pb.BlockRequest{Username: barUser.Username},
+			false,
+		},
+		{
+			"block bar twice: failed",
+			&pb.BlockRequest{Username: barUser.Username},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		resp, err := h.BlockUser(fooCtx, tt.req)
+		if tt.hasError {
+			if err == nil {
+				t.Errorf("%q expected to fail, but succeeded.", tt.title)
+				t.FailNow()
+			}
+			continue
+		}
+
+		if !tt.hasError && err != nil {
+			t.Errorf("%q expected to succeed, but failed. %v", tt.title, err)
+			t.FailNow()
+		}
+
+		assert.True(t, resp.GetProfile().GetBlocking())
+		assert.False(t, resp.GetProfile().GetFollowing())
+	}
+
+	// the follow relationship is removed and cannot be created again
+	following, err := h.us.IsFollowing(&barUser, &fooUser)
+	if assert.NoError(t, err) {
+		assert.False(t, following)
+	}
+
+	_, err = h.FollowUser(barCtx, &pb.FollowRequest{Username: fooUser.Username})
+	assert.Error(t, err)
+
+	_, err = h.ShowProfile(barCtx, &pb.ShowProfileRequest{Username: fooUser.Username})
+	assert.Error(t, err)
+
+	_, err = h.CreateComment(barCtx, &pb.CreateCommentRequest{
+		Slug:    fmt.Sprintf("%d", article.ID),
+		Comment: &pb.CreateCommentRequest_Comment{Body: "hello"},
+	})
+	assert.Error(t, err)
+
+	blocks, err := h.ListBlockedUsers(fooCtx, &pb.Empty{})
+	if assert.NoError(t, err) && assert.Len(t, blocks.GetProfiles(), 1) {
+		assert.Equal(t, barUser.Username, blocks.GetProfiles()[0].GetUsername())
+	}
+
+	// unblock
+	_, err = h.UnblockUser(fooCtx, &pb.UnblockRequest{Username: barUser.Username})
+	assert.NoError(t, err)
+
+	_, err = h.UnblockUser(fooCtx, &pb.UnblockRequest{Username: barUser.Username})
+	assert.Error(t, err)
+
+	_, err = h.FollowUser(barCtx, &pb.FollowRequest{Username: fooUser.Username})
+	assert.NoError(t, err)
+}
+
+func TestMuteUser(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+
+	fooUser := model.User{
+		Username: "foo",
+		Email:    "foo@example.com",
+		Password: "secret",
+	}
+
+	barUser := model.User{
+		Username: "bar",
+		Email:    "bar@example.com",
+		Password: "secret",
+	}
+
+	for _, u := range []*model.User{&fooUser, &barUser} {
+		if err := h.us.Create(u); err != nil {
+			t.Fatalf("failed to create initial user record: %v", err)
+		}
+	}
+
+	if err := h.us.Follow(&fooUser, &barUser); err != nil {
+		t.Fatalf("failed to create initial user follow relationship: %v", err)
+	}
+
+	article := model.Article{
+		Title:       "awesome post!",
+		Description: "awesome description!",
+		Body:        "awesome content!",
+		Tags:        []model.Tag{{Name: "hoge"}},
+		Author:      barUser,
+	}
+
+	if err := h.as.Create(&article); err != nil {
+		t.Fatalf("failed to create initial article record: %v", err)
+	}
+
+	comment := model.Comment{
+		Body:      "self promotion",
+		Author:    barUser,
+		ArticleID: article.ID,
+	}
+
+	if err := h.as.CreateComment(&comment); err != nil {
+		t.Fatalf("failed to create initial article comment: %v", err)
+	}
+
+	token, err := auth.GenerateToken(fooUser.ID)
+	if err != nil {
+		t.Error(err)
+	}
+	ctx := ctxWithToken(context.Background(), token)
+
+	resp, err := h.MuteUser(ctx, &pb.MuteRequest{Username: barUser.Username})
+	if err != nil {
+		t.Fatalf("mute user expected to succeed, but failed. %v", err)
+	}
+	assert.True(t, resp.GetProfile().GetMuting())
+	assert.True(t, resp.GetProfile().GetFollowing())
+
+	_, err = h.MuteUser(ctx, &pb.MuteRequest{Username: barUser.Username})
+	assert.Error(t, err)
+
+	// muted user's content is filtered out for the muter
+	articles, err := h.GetArticles(ctx, &pb.GetArticlesRequest{})
+	if assert.NoError(t, err) {
+		assert.Len(t, articles.GetArticles(), 0)
+	}
+
+	feed, err := h.GetFeedArticles(ctx, &pb.GetFeedArticlesRequest{})
+	if assert.NoError(t, err) {
+		assert.Len(t, feed.GetArticles(), 0)
+	}
+
+	comments, err := h.GetComments(ctx, &pb.GetCommentsRequest{Slug: fmt.Sprintf("%d", article.ID)})
+	if assert.NoError(t, err) {
+		assert.Len(t, comments.GetComments(), 0)
+	}
+
+	// but not for others
+	articles, err = h.GetArticles(context.Background(), &pb.GetArticlesRequest{})
+	if assert.NoError(t, err) {
+		assert.Len(t, articles.GetArticles(), 1)
+	}
+
+	mutes, err := h.ListMutedUsers(ctx, &pb.Empty{})
+	if assert.NoError(t, err) && assert.Len(t, mutes.GetProfiles(), 1) {
+		assert.Equal(t, barUser.Username, mutes.GetProfiles()[0].GetUsername())
+	}
+
+	_, err = h.UnmuteUser(ctx, &pb.UnmuteRequest{Username: barUser.Username})
+	assert.NoError(t, err)
+
+	articles, err = h.GetArticles(ctx, &pb.GetArticlesRequest{})
+	if assert.NoError(t, err) {
+		assert.Len(t, articles.GetArticles(), 1)
+	}
+}
+
+func TestListFollowers(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+
+	fooUser := model.User{
+		Username: "foo",
+		Email:    "foo@example.com",
+		Password: "secret",
+	}
+
+	barUser := model.User{
+		Username: "bar",
+		Email:    "bar@example.com",
+		Password: "secret",
+	}
+
+	bazUser := model.User{
+		Username: "baz",
+		Email:    "baz@example.com",
+		Password: "secret",
+	}
+
+	for _, u := range []*model.User{&fooUser, &barUser, &bazUser} {
+		if err := h.us.Create(u); err != nil {
+			t.Fatalf("failed to create initial user record: %v", err)
+		}
+	}
+
+	for _, f := range [][2]*model.User{{&barUser, &fooUser}, {&bazUser, &fooUser}, {&fooUser, &barUser}} {
+		if err := h.us.Follow(f[0], f[1]); err != nil {
+			t.Fatalf("failed to create initial user follow relationship: %v", err)
+		}
+	}
+
+	token, err := auth.GenerateToken(fooUser.ID)
+	if err != nil {
+		t.Error(err)
+	}
+	ctx := ctxWithToken(context.Background(), token)
+
+	tests := []struct {
+		title     string
+		ctx       context.Context
+		req       *pb.ListFollowersRequest
+		expected  []string
+		following []bool
+		hasError  bool
+	}{
+		{
+			"list followers of foo: success",
+			ctx,
+			&pb.ListFollowersRequest{Username: fooUser.Username},
+			[]string{barUser.Username, bazUser.Username},
+			[]bool{true, false},
+			false,
+		},
+		{
+			"list followers of foo with limit: success",
+			ctx,
+			&pb.ListFollowersRequest{Username: fooUser.Username, Limit: 1, Offset: 1},
+			[]string{bazUser.Username},
+			[]bool{false},
+			false,
+		},
+		{
+			"list followers of bar without token: success",
+			context.Background(),
+			&pb.ListFollowersRequest{Username: barUser.Username},
+			[]string{fooUser.Username},
+			[]bool{false},
+			false,
+		},
+		{
+			"list followers of invalid user: failed",
+			ctx,
+			&pb.ListFollowersRequest{Username: "invalid username"},
+			nil,
+			nil,
+			true,
+		},
+		{
+			"list followers with negative offset: failed",
+			ctx,
+			&pb.ListFollowersRequest{Username: fooUser.Username, Offset: -1},
+			nil,
+			nil,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		resp, err := h.ListFollowers(tt.ctx, tt.req)
+		if tt.hasError {
+			if err == nil {
+				t.Errorf("%q expected to fail, but succeeded.", tt.title)
+				t.FailNow()
+			}
+			continue
+		}
+
+		if !tt.hasError && err != nil {
+			t.Errorf("%q expected to succeed, but failed. %v", tt.title, err)
+			t.FailNow()
+		}
+
+		if assert.Len(t, resp.GetProfiles(), len(tt.expected), tt.title) {
+			for i, p := range resp.GetProfiles() {
+				assert.Equal(t, tt.expected[i], p.GetUsername(), tt.title)
+				assert.Equal(t, tt.following[i], p.GetFollowing(), tt.title)
+			}
+		}
+	}
+
+	following, err := h.ListFollowing(ctx, &pb.ListFollowingRequest{Username: fooUser.Username})
+	if assert.NoError(t, err) && assert.Len(t, following.GetProfiles(), 1) {
+		assert.Equal(t, barUser.Username, following.GetProfiles()[0].GetUsername())
+		assert.True(t, following.GetProfiles()[0].GetFollowing())
+	}
+
+	// counters
+	resp, err := h.ShowProfile(ctx, &pb.ShowProfileRequest{Username: fooUser.Username})
+	if assert.NoError(t, err) {
+		assert.Equal(t, int32(2), resp.GetProfile().GetFollowersCount())
+		assert.Equal(t, int32(1), resp.GetProfile().GetFollowingCount())
+	}
+
+	unfollowed, err := h.UnfollowUser(ctx, &pb.UnfollowRequest{Username: barUser.Username})
+	if assert.NoError(t, err) {
+		assert.Equal(t, int32(0), unfollowed.GetProfile().GetFollowersCount())
+	}
+
+	resp, err = h.ShowProfile(ctx, &pb.ShowProfileRequest{Username: fooUser.Username})
+	if assert.NoError(t, err) {
+		assert.Equal(t, int32(2), resp.GetProfile().GetFollowersCount())
+		assert.Equal(t, int32(0), resp.GetProfile().GetFollowingCount())
+	}
+}
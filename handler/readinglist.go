@@ -0,0 +1,472 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/jinzhu/gorm"
+	"github.com/raahii/golang-grpc-realworld-example/auth"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defaultReadingListID identifies the default reading list in requests
+const defaultReadingListID = "default"
+
+// CreateReadingList creates a reading list of current user
+func (h *Handler) CreateReadingList(ctx context.Context, req *pb.CreateReadingListRequest) (*pb.ReadingListResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("create reading list")
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("unauthenticated")
+		return nil, status.Errorf(codes.Unauthenticated, "unauthenticated")
+	}
+
+	currentUser, err := h.us.GetByID(userID)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("current user not found")
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	if err := h.checkWrite(currentUser, "create reading list"); err != nil {
+		return nil, err
+	}
+
+	l := model.ReadingList{
+		UserID: currentUser.ID,
+		Name:   req.GetReadingList().GetName(),
+	}
+
+	err = l.Validate()
+	if err != nil {
+		err = fmt.Errorf("validation error: %w", err)
+		h.logger.Error().Err(err).Msg("validation error")
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := h.as.CreateReadingList(&l); err != nil {
+		h.logger.Error().Err(err).Msg("failed to create reading list")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	return &pb.ReadingListResponse{ReadingList: l.ProtoReadingList(h.publicURL)}, nil
+}
+
+// ListReadingLists returns reading lists of current user, the default one first
+func (h *Handler) ListReadingLists(ctx context.Context, req *pb.Empty) (*pb.ReadingListsResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("list reading lists")
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("unauthenticated")
+		return nil, status.Errorf(codes.Unauthenticated, "unauthenticated")
+	}
+
+	currentUser, err := h.us.GetByID(userID)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("current user not found")
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	ls, err := h.as.GetReadingLists(currentUser)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to get reading lists")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	pls := make([]*pb.ReadingList, 0, len(ls))
+	for i := range ls {
+		pls = append(pls, ls[i].ProtoReadingList(h.publicURL))
+	}
+
+	return &pb.ReadingListsResponse{ReadingLists: pls}, nil
+}
+
+// GetReadingList returns a reading list of current user with its articles in order
+func (h *Handler) GetReadingList(ctx context.Context, req *pb.GetReadingListRequest) (*pb.ReadingListResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("get reading list")
+
+	currentUser, l, err := h.ownReadingList(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	return h.readingListResponse(currentUser, l, req.GetLimit(), req.GetOffset())
+}
+
+// UpdateReadingList renames a reading list of current user
+func (h *Handler) UpdateReadingList(ctx context.Context, req *pb.UpdateReadingListRequest) (*pb.ReadingListResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("update reading list")
+
+	currentUser, l, err := h.ownReadingList(ctx, req.GetReadingList().GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.checkWrite(currentUser, "update reading list"); err != nil {
+		return nil, err
+	}
+
+	l.Name = req.GetReadingList().GetName()
+
+	err = l.Validate()
+	if err != nil {
+		err = fmt.Errorf("validation error: %w", err)
+		h.logger.Error().Err(err).Msg("validation error")
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := h.as.UpdateReadingList(l); err != nil {
+		h.logger.Error().Err(err).Msg("failed to update reading list")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	return &pb.ReadingListResponse{ReadingList: l.ProtoReadingList(h.publicURL)}, nil
+}
+
+// DeleteReadingList deletes a reading list of current user. The default one can't be deleted.
+func (h *Handler) DeleteReadingList(ctx context.Context, req *pb.DeleteReadingListRequest) (*pb.Empty, error) {
+	h.logger.Info().Interface("req", req).Msg("delete reading list")
+
+	currentUser, l, err := h.ownReadingList(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.checkWrite(currentUser, "delete reading list"); err != nil {
+		return nil, err
+	}
+
+	if l.IsDefault {
+		h.logger.Error().Msgf("attempted to delete default reading list(id=%d)", l.ID)
+		return nil, status.Error(codes.FailedPrecondition, "the default reading list can't be deleted")
+	}
+
+	if err := h.as.DeleteReadingList(l); err != nil {
+		h.logger.Error().Err(err).Msg("failed to delete reading list")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	return &pb.Empty{}, nil
+}
+
+// ShareReadingList makes a reading list of current user viewable by anyone
+// with its share link. The default list is always private.
+func (h *Handler) ShareReadingList(ctx context.Context, req *pb.ShareReadingListRequest) (*pb.ReadingListResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("share reading list")
+
+	currentUser, l, err := h.ownReadingList(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.checkWrite(currentUser, "share reading list"); err != nil {
+		return nil, err
+	}
+
+	if l.IsDefault {
+		h.logger.Error().Msgf("attempted to share default reading list(id=%d)", l.ID)
+		return nil, status.Error(codes.FailedPrecondition, "the default reading list can't be shared")
+	}
+
+	if l.ShareToken == nil {
+		token, err := model.GenerateShareToken()
+		if err != nil {
+			err = fmt.Errorf("failed to generate share token: %w", err)
+			h.logger.Error().Err(err).Msg("internal server error")
+			return nil, status.Error(codes.Aborted, "internal server error")
+		}
+		l.ShareToken = &token
+
+		if err := h.as.UpdateReadingList(l); err != nil {
+			h.logger.Error().Err(err).Msg("failed to share reading list")
+			return nil, status.Error(codes.Aborted, "internal server error")
+		}
+	}
+
+	return &pb.ReadingListResponse{ReadingList: l.ProtoReadingList(h.publicURL)}, nil
+}
+
+// UnshareReadingList makes a reading list of current user private again.
+// Sharing it later gives it a new link.
+func (h *Handler) UnshareReadingList(ctx context.Context, req *pb.ShareReadingListRequest) (*pb.ReadingListResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("unshare reading list")
+
+	currentUser, l, err := h.ownReadingList(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.checkWrite(currentUser, "unshare reading list"); err != nil {
+		return nil, err
+	}
+
+	l.ShareToken = nil
+	if err := h.as.UpdateReadingList(l); err != nil {
+		h.logger.Error().Err(err).Msg("failed to unshare reading list")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	return &pb.ReadingListResponse{ReadingList: l.ProtoReadingList(h.publicURL)}, nil
+}
+
+// AddToReadingList appends an article to a reading list of current user
+func (h *Handler) AddToReadingList(ctx context.Context, req *pb.AddToReadingListRequest) (*pb.ReadingListResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("add to reading list")
+
+	currentUser, l, err := h.ownReadingList(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.checkWrite(currentUser, "add to reading list"); err != nil {
+		return nil, err
+	}
+
+	article, err := h.readingListArticle(req.GetSlug())
+	if err != nil {
+		return nil, err
+	}
+
+	if !article.IsListed() {
+		h.logger.Error().Msgf("attempted to add unlisted article(id=%d) to reading list", article.ID)
+		return nil, status.Error(codes.NotFound, "article not found")
+	}
+
+	if err := h.as.AddToReadingList(l, article); err != nil {
+		h.logger.Error().Err(err).Msg("failed to add article to reading list")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	return h.readingListCountResponse(l)
+}
+
+// RemoveFromReadingList removes an article from a reading list of current user
+func (h *Handler) RemoveFromReadingList(ctx context.Context, req *pb.RemoveFromReadingListRequest) (*pb.ReadingListResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("remove from reading list")
+
+	currentUser, l, err := h.ownReadingList(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.checkWrite(currentUser, "remove from reading list"); err != nil {
+		return nil, err
+	}
+
+	article, err := h.readingListArticle(req.GetSlug())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.as.RemoveFromReadingList(l, article); err != nil {
+		h.logger.Error().Err(err).Msg("failed to remove article from reading list")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	return h.readingListCountResponse(l)
+}
+
+// MoveInReadingList moves an article of a reading list of current user to
+// the position, the zero based index in the list
+func (h *Handler) MoveInReadingList(ctx context.Context, req *pb.MoveInReadingListRequest) (*pb.ReadingListResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("move in reading list")
+
+	currentUser, l, err := h.ownReadingList(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.checkWrite(currentUser, "move in reading list"); err != nil {
+		return nil, err
+	}
+
+	if req.GetPosition() < 0 {
+		h.logger.Error().Msgf("invalid position %d", req.GetPosition())
+		return nil, status.Error(codes.InvalidArgument, "position must not be negative")
+	}
+
+	article, err := h.readingListArticle(req.GetSlug())
+	if err != nil {
+		return nil, err
+	}
+
+	err = h.as.MoveInReadingList(l, article, int(req.GetPosition()))
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			h.logger.Error().Err(err).Msgf("article(id=%d) not in reading list(id=%d)", article.ID, l.ID)
+			return nil, status.Error(codes.NotFound, "article is not in the reading list")
+		}
+		h.logger.Error().Err(err).Msg("failed to move article in reading list")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	return h.readingListResponse(currentUser, l, 0, 0)
+}
+
+// GetSharedReadingList returns a shared reading list with its articles. Anyone
+// with the share token can view it.
+func (h *Handler) GetSharedReadingList(ctx context.Context, req *pb.GetSharedReadingListRequest) (*pb.ReadingListResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("get shared reading list")
+
+	if req.GetToken() == "" {
+		h.logger.Error().Msg("empty share token")
+		return nil, status.Error(codes.NotFound, "reading list not found")
+	}
+
+	l, err := h.as.GetReadingListByShareToken(req.GetToken())
+	if err != nil {
+		h.logger.Error().Err(err).Msg("shared reading list not found")
+		return nil, status.Error(codes.NotFound, "reading list not found")
+	}
+
+	var currentUser *model.User
+	userID, err := auth.GetUserID(ctx)
+	if err == nil {
+		currentUser, err = h.us.GetByID(userID)
+		if err != nil {
+			h.logger.Error().Err(err).Msg("current user not found")
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+	}
+
+	resp, err := h.readingListResponse(currentUser, l, req.GetLimit(), req.GetOffset())
+	if err != nil {
+		return nil, err
+	}
+
+	following, err := h.us.IsFollowing(currentUser, &l.User)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to get following status")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+	resp.ReadingList.Owner = l.User.ProtoProfile(following)
+
+	return resp, nil
+}
+
+// ownReadingList returns current user and their reading list identified by
+// id, which is "default" for the default list
+func (h *Handler) ownReadingList(ctx context.Context, id string) (*model.User, *model.ReadingList, error) {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("unauthenticated")
+		return nil, nil, status.Errorf(codes.Unauthenticated, "unauthenticated")
+	}
+
+	currentUser, err := h.us.GetByID(userID)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("current user not found")
+		return nil, nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	if id == defaultReadingListID {
+		l, err := h.as.GetDefaultReadingList(currentUser)
+		if err != nil {
+			h.logger.Error().Err(err).Msg("failed to get default reading list")
+			return nil, nil, status.Error(codes.Aborted, "internal server error")
+		}
+		return currentUser, l, nil
+	}
+
+	listID, err := strconv.Atoi(id)
+	if err != nil {
+		msg := fmt.Sprintf("cannot convert reading list id (%s) into integer", id)
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, nil, status.Error(codes.InvalidArgument, "invalid reading list id")
+	}
+
+	l, err := h.as.GetReadingListByID(uint(listID))
+	if err != nil || l.UserID != currentUser.ID {
+		// others' lists are indistinguishable from missing ones
+		h.logger.Error().Err(err).Msgf("reading list(id=%d) not found", listID)
+		return nil, nil, status.Error(codes.NotFound, "reading list not found")
+	}
+
+	return currentUser, l, nil
+}
+
+// readingListArticle returns the article identified by slug
+func (h *Handler) readingListArticle(slug string) (*model.Article, error) {
+	articleID, err := strconv.Atoi(slug)
+	if err != nil {
+		msg := fmt.Sprintf("cannot convert slug (%s) into integer", slug)
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, "invalid article id")
+	}
+
+	article, err := h.as.GetByID(uint(articleID))
+	if err != nil {
+		msg := fmt.Sprintf("requested article (slug=%d) not found", articleID)
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.NotFound, "article not found")
+	}
+
+	return article, nil
+}
+
+// readingListCountResponse builds the response for a changed reading list
+// with its new number of articles
+func (h *Handler) readingListCountResponse(l *model.ReadingList) (*pb.ReadingListResponse, error) {
+	l, err := h.as.GetReadingListByID(l.ID)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to get reading list")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	return &pb.ReadingListResponse{ReadingList: l.ProtoReadingList(h.publicURL)}, nil
+}
+
+// readingListResponse builds the response for a reading list with its
+// articles as seen by currentUser, who may be nil
+func (h *Handler) readingListResponse(currentUser *model.User, l *model.ReadingList, limit, offset int64) (*pb.ReadingListResponse, error) {
+	if err := h.checkPage(limit, offset); err != nil {
+		return nil, err
+	}
+
+	as, err := h.as.GetReadingListArticles(l, pageLimit(limit), offset)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to get reading list articles")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	pas := make([]*pb.Article, 0, len(as))
+	for _, a := range as {
+		// get whether the article is current user's favorite
+		favorited, err := h.as.IsFavorited(&a, currentUser)
+		if err != nil {
+			msg := "failed to get favorited status"
+			h.logger.Error().Err(err).Msg(msg)
+			return nil, status.Error(codes.Aborted, "internal server error")
+		}
+		pa := a.ProtoArticle(favorited)
+
+		bookmarked, err := h.as.IsBookmarked(&a, currentUser)
+		if err != nil {
+			msg := "failed to get bookmarked status"
+			h.logger.Error().Err(err).Msg(msg)
+			return nil, status.Error(codes.Aborted, "internal server error")
+		}
+		pa.Bookmarked = bookmarked
+
+		// get whether current user follows article author
+		following, err := h.us.IsFollowing(currentUser, &a.Author)
+		if err != nil {
+			msg := "failed to get following status"
+			h.logger.Error().Err(err).Msg(msg)
+			return nil, status.Error(codes.Aborted, "internal server error")
+		}
+		pa.Author = a.Author.ProtoProfile(following)
+
+		pas = append(pas, pa)
+	}
+
+	return &pb.ReadingListResponse{
+		ReadingList: l.ProtoReadingList(h.publicURL),
+		Articles:    pas,
+	}, nil
+}
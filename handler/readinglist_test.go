@@ -0,0 +1,260 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"testing"
+
+	"github.com/raahii/golang-grpc-realworld-example/auth"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadingLists(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+
+	fooUser := model.User{Username: "foo", Email: "foo@example.com", Password: "secret"}
+	barUser := model.User{Username: "bar", Email: "bar@example.com", Password: "secret"}
+	for _, u := range []*model.User{&fooUser, &barUser} {
+		if err := h.us.Create(u); err != nil {
+			t.Fatalf("failed to create initial user record: %v", err)
+		}
+	}
+
+	ctxs := map[string]context.Context{}
+	for _, u := range []*model.User{&fooUser, &barUser} {
+		token, err := auth.GenerateToken(u.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctxs[u.Username] = ctxWithToken(context.Background(), token)
+	}
+	ctx := ctxs["foo"]
+
+	var slugs []string
+	for i := 0; i < 3; i++ {
+		a := model.Article{
+			Title:  fmt.Sprintf("article %d", i),
+			Body:   "body",
+			Author: barUser,
+		}
+		if err := h.as.Create(&a); err != nil {
+			t.Fatalf("failed to create initial article record: %v", err)
+		}
+		slugs = append(slugs, fmt.Sprintf("%d", a.ID))
+	}
+	draft := model.Article{Title: "draft", Body: "body", Author: barUser, Status: model.ArticleDraft}
+	if err := h.as.Create(&draft); err != nil {
+		t.Fatalf("failed to create initial article record: %v", err)
+	}
+
+	// every user has the default list
+	lresp, err := h.ListReadingLists(ctx, &pb.Empty{})
+	if err != nil {
+		t.Fatalf("list reading lists expected to succeed, but failed. %v", err)
+	}
+	if assert.Len(t, lresp.GetReadingLists(), 1) {
+		l := lresp.GetReadingLists()[0]
+		assert.Equal(t, model.DefaultReadingListName, l.GetName())
+		assert.True(t, l.GetIsDefault())
+	}
+
+	for _, slug := range slugs {
+		resp, err := h.AddToReadingList(ctx, &pb.AddToReadingListRequest{Id: "default", Slug: slug})
+		if err != nil {
+			t.Fatalf("add to reading list expected to succeed, but failed. %v", err)
+		}
+		assert.Equal(t, lresp.GetReadingLists()[0].GetId(), resp.GetReadingList().GetId())
+	}
+
+	// adding twice does nothing
+	resp, err := h.AddToReadingList(ctx, &pb.AddToReadingListRequest{Id: "default", Slug: slugs[0]})
+	if err != nil {
+		t.Fatalf("add to reading list expected to succeed, but failed. %v", err)
+	}
+	assert.EqualValues(t, 3, resp.GetReadingList().GetArticlesCount())
+
+	titles := func(resp *pb.ReadingListResponse) []string {
+		var ts []string
+		for _, a := range resp.GetArticles() {
+			ts = append(ts, a.GetTitle())
+			assert.True(t, a.GetBookmarked())
+		}
+		return ts
+	}
+
+	resp, err = h.GetReadingList(ctx, &pb.GetReadingListRequest{Id: "default"})
+	if err != nil {
+		t.Fatalf("get reading list expected to succeed, but failed. %v", err)
+	}
+	assert.Equal(t, []string{"article 0", "article 1", "article 2"}, titles(resp))
+
+	resp, err = h.MoveInReadingList(ctx, &pb.MoveInReadingListRequest{Id: "default", Slug: slugs[2], Position: 0})
+	if err != nil {
+		t.Fatalf("move in reading list expected to succeed, but failed. %v", err)
+	}
+	assert.Equal(t, []string{"article 2", "article 0", "article 1"}, titles(resp))
+
+	resp, err = h.MoveInReadingList(ctx, &pb.MoveInReadingListRequest{Id: "default", Slug: slugs[2], Position: 10})
+	if err != nil {
+		t.Fatalf("move in reading list expected to succeed, but failed. %v", err)
+	}
+	assert.Equal(t, []string{"article 0", "article 1", "article 2"}, titles(resp))
+
+	// bookmarked is per viewer
+	aresp, err := h.GetArticle(ctx, &pb.GetArticleRequest{Slug: slugs[0]})
+	if assert.NoError(t, err) {
+		assert.True(t, aresp.GetArticle().GetBookmarked())
+		assert.False(t, aresp.GetArticle().GetFavorited())
+	}
+	aresp, err = h.GetArticle(ctxs["bar"], &pb.GetArticleRequest{Slug: slugs[0]})
+	if assert.NoError(t, err) {
+		assert.False(t, aresp.GetArticle().GetBookmarked())
+	}
+
+	resp, err = h.RemoveFromReadingList(ctx, &pb.RemoveFromReadingListRequest{Id: "default", Slug: slugs[0]})
+	if err != nil {
+		t.Fatalf("remove from reading list expected to succeed, but failed. %v", err)
+	}
+	assert.EqualValues(t, 2, resp.GetReadingList().GetArticlesCount())
+
+	aresp, err = h.GetArticle(ctx, &pb.GetArticleRequest{Slug: slugs[0]})
+	if assert.NoError(t, err) {
+		assert.False(t, aresp.GetArticle().GetBookmarked())
+	}
+
+	// a named list shared by a link
+	resp, err = h.CreateReadingList(ctx, &pb.CreateReadingListRequest{
+		ReadingList: &pb.CreateReadingListRequest_ReadingList{Name: "go"},
+	})
+	if err != nil {
+		t.Fatalf("create reading list expected to succeed, but failed. %v", err)
+	}
+	listID := resp.GetReadingList().GetId()
+	assert.False(t, resp.GetReadingList().GetShared())
+
+	if _, err := h.AddToReadingList(ctx, &pb.AddToReadingListRequest{Id: listID, Slug: slugs[1]}); err != nil {
+		t.Fatalf("add to reading list expected to succeed, but failed. %v", err)
+	}
+
+	resp, err = h.ShareReadingList(ctx, &pb.ShareReadingListRequest{Id: listID})
+	if err != nil {
+		t.Fatalf("share reading list expected to succeed, but failed. %v", err)
+	}
+	assert.True(t, resp.GetReadingList().GetShared())
+	token := path.Base(resp.GetReadingList().GetShareUrl())
+
+	resp, err = h.GetSharedReadingList(context.Background(), &pb.GetSharedReadingListRequest{Token: token})
+	if err != nil {
+		t.Fatalf("get shared reading list expected to succeed, but failed. %v", err)
+	}
+	assert.Equal(t, "go", resp.GetReadingList().GetName())
+	assert.Equal(t, "foo", resp.GetReadingList().GetOwner().GetUsername())
+	if assert.Len(t, resp.GetArticles(), 1) {
+		// anonymous viewers have no bookmarks
+		assert.False(t, resp.GetArticles()[0].GetBookmarked())
+	}
+
+	_, err = h.GetSharedReadingList(context.Background(), &pb.GetSharedReadingListRequest{Token: token, Limit: -1})
+	assert.Error(t, err)
+
+	if _, err := h.UnshareReadingList(ctx, &pb.ShareReadingListRequest{Id: listID}); err != nil {
+		t.Fatalf("unshare reading list expected to succeed, but failed. %v", err)
+	}
+
+	lresp, err = h.ListReadingLists(ctx, &pb.Empty{})
+	if err != nil {
+		t.Fatalf("list reading lists expected to succeed, but failed. %v", err)
+	}
+	if assert.Len(t, lresp.GetReadingLists(), 2) {
+		assert.True(t, lresp.GetReadingLists()[0].GetIsDefault())
+		assert.EqualValues(t, 2, lresp.GetReadingLists()[0].GetArticlesCount())
+		assert.Equal(t, "go", lresp.GetReadingLists()[1].GetName())
+		assert.EqualValues(t, 1, lresp.GetReadingLists()[1].GetArticlesCount())
+	}
+
+	// deleting a list keeps the bookmarks in the others
+	if _, err := h.DeleteReadingList(ctx, &pb.DeleteReadingListRequest{Id: listID}); err != nil {
+		t.Fatalf("delete reading list expected to succeed, but failed. %v", err)
+	}
+	aresp, err = h.GetArticle(ctx, &pb.GetArticleRequest{Slug: slugs[1]})
+	if assert.NoError(t, err) {
+		assert.True(t, aresp.GetArticle().GetBookmarked())
+	}
+
+	tests := []struct {
+		title string
+		f     func() error
+	}{
+		{
+			"empty name: failed",
+			func() error {
+				_, err := h.CreateReadingList(ctx, &pb.CreateReadingListRequest{
+					ReadingList: &pb.CreateReadingListRequest_ReadingList{Name: ""},
+				})
+				return err
+			},
+		},
+		{
+			"delete the default list: failed",
+			func() error {
+				_, err := h.DeleteReadingList(ctx, &pb.DeleteReadingListRequest{Id: "default"})
+				return err
+			},
+		},
+		{
+			"share the default list: failed",
+			func() error {
+				_, err := h.ShareReadingList(ctx, &pb.ShareReadingListRequest{Id: "default"})
+				return err
+			},
+		},
+		{
+			"others' list: failed",
+			func() error {
+				_, err := h.AddToReadingList(ctxs["bar"], &pb.AddToReadingListRequest{
+					Id:   lresp.GetReadingLists()[0].GetId(),
+					Slug: slugs[0],
+				})
+				return err
+			},
+		},
+		{
+			"add a draft: failed",
+			func() error {
+				_, err := h.AddToReadingList(ctx, &pb.AddToReadingListRequest{Id: "default", Slug: fmt.Sprintf("%d", draft.ID)})
+				return err
+			},
+		},
+		{
+			"move an article not in the list: failed",
+			func() error {
+				_, err := h.MoveInReadingList(ctx, &pb.MoveInReadingListRequest{Id: "default", Slug: slugs[0], Position: 0})
+				return err
+			},
+		},
+		{
+			"unshared list: failed",
+			func() error {
+				_, err := h.GetSharedReadingList(context.Background(), &pb.GetSharedReadingListRequest{Token: token})
+				return err
+			},
+		},
+		{
+			"unauthenticated: failed",
+			func() error {
+				_, err := h.ListReadingLists(context.Background(), &pb.Empty{})
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		if err := tt.f(); err == nil {
+			t.Errorf("%q expected to fail, but succeeded.", tt.title)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/raahii/golang-grpc-realworld-example/auth"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	"github.com/raahii/golang-grpc-realworld-example/policy"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+	"github.com/raahii/golang-grpc-realworld-example/related"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// defaultRelatedLimit is the number of related articles returned by default
+	defaultRelatedLimit = 5
+
+	// relatedCandidates is how many articles each of tags, favorites and text
+	// similarity proposes, and how many of the most related are cached
+	relatedCandidates = 100
+
+	// relatedCacheTTL and relatedCacheSize bound the cache of related articles.
+	// A changed article drops its own ranking and the rankings including it,
+	// also when the search index sync picks up a change through another server.
+	// New articles join the rankings of other articles as they expire.
+	relatedCacheTTL  = time.Hour
+	relatedCacheSize = 10000
+)
+
+// GetRelatedArticles returns listed articles related to an article by shared
+// tags, users who favorited both and similar text, the most related first.
+// Current user's own and favorite articles are left out.
+func (h *Handler) GetRelatedArticles(ctx context.Context, req *pb.GetRelatedArticlesRequest) (*pb.ArticlesResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("get related articles")
+
+	articleID, err := strconv.Atoi(req.GetSlug())
+	if err != nil {
+		msg := fmt.Sprintf("cannot convert slug (%s) into integer", req.GetSlug())
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, "invalid article id")
+	}
+
+	article, err := h.as.GetByID(uint(articleID))
+	if err != nil {
+		msg := fmt.Sprintf("requested article (slug=%d) not found", articleID)
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.NotFound, "article not found")
+	}
+
+	var currentUser *model.User
+	userID, err := auth.GetUserID(ctx)
+	if err == nil {
+		currentUser, err = h.us.GetByID(userID)
+		if err != nil {
+			h.logger.Error().Err(err).Msg("current user not found")
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+	}
+
+	if !policy.CanViewArticle(currentUser, article) {
+		h.logger.Error().Msgf("attempted to get articles related to hidden article(id=%d)", article.ID)
+		return nil, status.Error(codes.NotFound, "article not found")
+	}
+
+	limitQuery := req.GetLimit()
+	if limitQuery < 0 {
+		msg := "limit must not be negative"
+		h.logger.Error().Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, msg)
+	}
+	if limitQuery == 0 {
+		limitQuery = defaultRelatedLimit
+	}
+	// only the most related candidates are ranked
+	if limitQuery > relatedCandidates {
+		limitQuery = relatedCandidates
+	}
+
+	scores, err := h.relatedScores(article)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to rank related articles")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	ids := make([]uint, 0, len(scores))
+	for _, s := range scores {
+		ids = append(ids, s.ArticleID)
+	}
+	// the cached ranking may include articles unlisted since
+	as, err := h.as.GetListedArticlesByIDs(ids)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to get related articles")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+	articles := make(map[uint]model.Article, len(as))
+	for _, a := range as {
+		articles[a.ID] = a
+	}
+
+	mutedUserIDs, err := h.us.GetMutedUserIDs(currentUser)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to get muted user ids")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+	excludedUsers := map[uint]bool{}
+	for _, id := range mutedUserIDs {
+		excludedUsers[id] = true
+	}
+
+	favorites := map[uint]bool{}
+	if currentUser != nil {
+		excludedUsers[currentUser.ID] = true
+
+		favoriteIDs, err := h.as.GetFavoriteArticleIDs(currentUser)
+		if err != nil {
+			h.logger.Error().Err(err).Msg("failed to get favorite article ids")
+			return nil, status.Error(codes.Aborted, "internal server error")
+		}
+		for _, id := range favoriteIDs {
+			favorites[id] = true
+		}
+	}
+
+	pas := make([]*pb.Article, 0, len(scores))
+	for _, s := range scores {
+		if int64(len(pas)) >= limitQuery {
+			break
+		}
+
+		a, ok := articles[s.ArticleID]
+		if !ok || excludedUsers[a.UserID] || favorites[a.ID] {
+			continue
+		}
+
+		// favorite articles are left out
+		pa := a.ProtoArticle(false)
+
+		// get whether the article is in current user's reading lists
+		bookmarked, err := h.as.IsBookmarked(&a, currentUser)
+		if err != nil {
+			msg := "failed to get bookmarked status"
+			h.logger.Error().Err(err).Msg(msg)
+			return nil, status.Error(codes.Aborted, "internal server error")
+		}
+		pa.Bookmarked = bookmarked
+
+		// get whether current user follows article author
+		following, err := h.us.IsFollowing(currentUser, &a.Author)
+		if err != nil {
+			msg := "failed to get following status"
+			h.logger.Error().Err(err).Msg(msg)
+			return nil, status.Error(codes.Aborted, "internal server error")
+		}
+		pa.Author = a.Author.ProtoProfile(following)
+
+		pas = append(pas, pa)
+	}
+
+	return &pb.ArticlesResponse{Articles: pas, ArticlesCount: int32(len(pas))}, nil
+}
+
+// relatedScores returns the articles related to the article, the most related
+// first, from the cache or ranked from the database and the search index
+func (h *Handler) relatedScores(a *model.Article) ([]related.Score, error) {
+	now := time.Now()
+	scores, generation, ok := h.related.Get(a.ID, now)
+	if ok {
+		return scores, nil
+	}
+
+	candidates := map[uint]related.Signals{}
+
+	tags, err := h.as.GetSharedTagCounts(a, relatedCandidates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count shared tags: %w", err)
+	}
+	for id, n := range tags {
+		s := candidates[id]
+		s.SharedTags = n
+		candidates[id] = s
+	}
+
+	favorites, err := h.as.GetCoFavoriteCounts(a, relatedCandidates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count co-favorites: %w", err)
+	}
+	for id, n := range favorites {
+		s := candidates[id]
+		s.CoFavorites = n
+		candidates[id] = s
+	}
+
+	hits, err := h.index.SimilarArticles(a, relatedCandidates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search similar articles: %w", err)
+	}
+	for _, hit := range hits {
+		s := candidates[hit.ArticleID]
+		s.Text = hit.Score
+		candidates[hit.ArticleID] = s
+	}
+
+	scores = related.Rank(candidates, len(a.Tags), int(a.FavoritesCount))
+	if len(scores) > relatedCandidates {
+		scores = scores[:relatedCandidates]
+	}
+	h.related.Set(a.ID, scores, generation, now)
+
+	return scores, nil
+}
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/raahii/golang-grpc-realworld-example/auth"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRelatedArticles(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+
+	fooUser := model.User{Username: "foo", Email: "foo@example.com", Password: "secret"}
+	barUser := model.User{Username: "bar", Email: "bar@example.com", Password: "secret"}
+	bazUser := model.User{Username: "baz", Email: "baz@example.com", Password: "secret"}
+	quxUser := model.User{Username: "qux", Email: "qux@example.com", Password: "secret"}
+	for _, u := range []*model.User{&fooUser, &barUser, &bazUser, &quxUser} {
+		if err := h.us.Create(u); err != nil {
+			t.Fatalf("failed to create initial user record: %v", err)
+		}
+	}
+
+	newArticle := func(author model.User, title, body string, tags ...string) *model.Article {
+		a := model.Article{Title: title, Body: body, Author: author}
+		for _, name := range tags {
+			a.Tags = append(a.Tags, model.Tag{Name: name})
+		}
+		return &a
+	}
+
+	base := newArticle(fooUser, "Goroutines in Go", "Goroutines talk over channels.", "go", "concurrency")
+	tagged := newArticle(bazUser, "Context package", "Cancellation and deadlines.", "go", "concurrency")
+	cofavorited := newArticle(bazUser, "Cooking pasta", "Boil water and add the pasta.", "food")
+	similar := newArticle(bazUser, "Select statements", "Select waits on channels of goroutines.", "tips")
+	own := newArticle(barUser, "Worker pools", "Start a few workers.", "go", "concurrency")
+	favorited := newArticle(bazUser, "Mutexes", "Guard shared state.", "go", "concurrency")
+	unrelated := newArticle(bazUser, "Gardening", "Plants need sunlight.", "garden")
+	draft := newArticle(bazUser, "Draft", "Not yet.", "go", "concurrency")
+	draft.Status = model.ArticleDraft
+
+	all := []*model.Article{base, tagged, cofavorited, similar, own, favorited, unrelated, draft}
+	for _, a := range all {
+		if err := h.as.Create(a); err != nil {
+			t.Fatalf("failed to create initial article record: %v", err)
+		}
+	}
+
+	for _, f := range []struct {
+		a *model.Article
+		u *model.User
+	}{
+		{base, &quxUser},
+		{cofavorited, &quxUser},
+		{favorited, &barUser},
+	} {
+		if err := h.as.AddFavorite(f.a, f.u); err != nil {
+			t.Fatalf("failed to add favorite: %v", err)
+		}
+	}
+
+	if err := h.SyncSearchIndex(context.Background()); err != nil {
+		t.Fatalf("sync search index expected to succeed, but failed. %v", err)
+	}
+
+	ctxs := map[string]context.Context{"guest": context.Background()}
+	for _, u := range []*model.User{&barUser, &bazUser} {
+		token, err := auth.GenerateToken(u.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctxs[u.Username] = ctxWithToken(context.Background(), token)
+	}
+
+	titles := func(resp *pb.ArticlesResponse) []string {
+		ts := []string{}
+		for _, a := range resp.GetArticles() {
+			ts = append(ts, a.GetTitle())
+		}
+		return ts
+	}
+	slug := fmt.Sprintf("%d", base.ID)
+
+	tests := []struct {
+		title    string
+		ctx      context.Context
+		req      *pb.GetRelatedArticlesRequest
+		expected []string
+	}{
+		{
+			"guest: tags, favorites and text",
+			ctxs["guest"],
+			&pb.GetRelatedArticlesRequest{Slug: slug, Limit: 10},
+			[]string{tagged.Title, cofavorited.Title, similar.Title, own.Title, favorited.Title},
+		},
+		{
+			"reader: own and favorite articles are left out",
+			ctxs["bar"],
+			&pb.GetRelatedArticlesRequest{Slug: slug, Limit: 10},
+			[]string{tagged.Title, cofavorited.Title, similar.Title},
+		},
+	}
+
+	for _, tt := range tests {
+		resp, err := h.GetRelatedArticles(tt.ctx, tt.req)
+		if err != nil {
+			t.Errorf("%q expected to succeed, but failed. %v", tt.title, err)
+			continue
+		}
+		assert.ElementsMatch(t, tt.expected, titles(resp), tt.title)
+	}
+
+	// sharing all tags ranks first
+	resp, err := h.GetRelatedArticles(ctxs["bar"], &pb.GetRelatedArticlesRequest{Slug: slug, Limit: 1})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{tagged.Title}, titles(resp))
+	}
+
+	// changes of a related article drop the cached ranking
+	_, err = h.UpdateArticle(ctxs["baz"], &pb.UpdateArticleRequest{
+		Article: &pb.UpdateArticleRequest_Article{
+			Slug: fmt.Sprintf("%d", similar.ID),
+			Body: "Plants need water.",
+		},
+	})
+	if err != nil {
+		t.Fatalf("update article expected to succeed, but failed. %v", err)
+	}
+
+	resp, err = h.GetRelatedArticles(ctxs["bar"], &pb.GetRelatedArticlesRequest{Slug: slug, Limit: 10})
+	if assert.NoError(t, err) {
+		assert.NotContains(t, titles(resp), similar.Title)
+	}
+
+	// changes of other articles keep it
+	_, err = h.UpdateArticle(ctxs["baz"], &pb.UpdateArticleRequest{
+		Article: &pb.UpdateArticleRequest_Article{
+			Slug: fmt.Sprintf("%d", unrelated.ID),
+			Body: "Goroutines grow plants through channels.",
+		},
+	})
+	if err != nil {
+		t.Fatalf("update article expected to succeed, but failed. %v", err)
+	}
+
+	resp, err = h.GetRelatedArticles(ctxs["bar"], &pb.GetRelatedArticlesRequest{Slug: slug, Limit: 10})
+	if assert.NoError(t, err) {
+		assert.NotContains(t, titles(resp), unrelated.Title)
+	}
+
+	failures := []struct {
+		title string
+		ctx   context.Context
+		req   *pb.GetRelatedArticlesRequest
+	}{
+		{
+			"draft of another user: failed",
+			ctxs["bar"],
+			&pb.GetRelatedArticlesRequest{Slug: fmt.Sprintf("%d", draft.ID)},
+		},
+		{
+			"unknown article: failed",
+			ctxs["guest"],
+			&pb.GetRelatedArticlesRequest{Slug: "0"},
+		},
+		{
+			"invalid slug: failed",
+			ctxs["guest"],
+			&pb.GetRelatedArticlesRequest{Slug: "invalid"},
+		},
+		{
+			"negative limit: failed",
+			ctxs["guest"],
+			&pb.GetRelatedArticlesRequest{Slug: slug, Limit: -1},
+		},
+	}
+
+	for _, tt := range failures {
+		if _, err := h.GetRelatedArticles(tt.ctx, tt.req); err == nil {
+			t.Errorf("%q expected to fail, but succeeded.", tt.title)
+		}
+	}
+}
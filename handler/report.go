@@ -0,0 +1,277 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/raahii/golang-grpc-realworld-example/auth"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	"github.com/raahii/golang-grpc-realworld-example/policy"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ReportArticle reports an article to moderators
+func (h *Handler) ReportArticle(ctx context.Context, req *pb.ReportArticleRequest) (*pb.ReportResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("report article")
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("unauthenticated")
+		return nil, status.Errorf(codes.Unauthenticated, "unauthenticated")
+	}
+
+	currentUser, err := h.us.GetByID(userID)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("current user not found")
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	articleID, err := strconv.Atoi(req.GetSlug())
+	if err != nil {
+		msg := fmt.Sprintf("cannot convert slug (%s) into integer", req.GetSlug())
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, "invalid article id")
+	}
+
+	article, err := h.as.GetByID(uint(articleID))
+	if err != nil {
+		msg := fmt.Sprintf("requested article (slug=%d) not found", articleID)
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, "invalid article id")
+	}
+
+	if !policy.CanReportArticle(currentUser, article) {
+		h.logger.Error().Msgf("user(id=%d) attempted to report article(id=%d)", currentUser.ID, article.ID)
+		return nil, status.Error(codes.PermissionDenied, "forbidden")
+	}
+
+	report := model.Report{
+		ContentType: model.ContentArticle,
+		ArticleID:   article.ID,
+		ReporterID:  currentUser.ID,
+		Reporter:    *currentUser,
+		Reason:      req.GetReason(),
+	}
+
+	return h.createReport(&report)
+}
+
+// ReportComment reports a comment to moderators
+func (h *Handler) ReportComment(ctx context.Context, req *pb.ReportCommentRequest) (*pb.ReportResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("report comment")
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("unauthenticated")
+		return nil, status.Errorf(codes.Unauthenticated, "unauthenticated")
+	}
+
+	currentUser, err := h.us.GetByID(userID)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("current user not found")
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	commentID, err := strconv.Atoi(req.GetId())
+	if err != nil {
+		msg := fmt.Sprintf("cannot convert id (%s) into integer", req.GetId())
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, "invalid comment id")
+	}
+
+	comment, err := h.as.GetCommentByID(uint(commentID))
+	if err != nil {
+		msg := "failed to get comment"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, msg)
+	}
+
+	if req.GetSlug() != fmt.Sprintf("%d", comment.ArticleID) {
+		msg := "the comment is not in the article"
+		h.logger.Error().Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, msg)
+	}
+
+	if !policy.CanReportComment(currentUser, comment) {
+		h.logger.Error().Msgf("user(id=%d) attempted to report comment(id=%d)", currentUser.ID, comment.ID)
+		return nil, status.Error(codes.PermissionDenied, "forbidden")
+	}
+
+	report := model.Report{
+		ContentType: model.ContentComment,
+		ArticleID:   comment.ArticleID,
+		CommentID:   comment.ID,
+		ReporterID:  currentUser.ID,
+		Reporter:    *currentUser,
+		Reason:      req.GetReason(),
+	}
+
+	return h.createReport(&report)
+}
+
+// createReport validates and saves a report, hiding the content when it reaches the threshold
+func (h *Handler) createReport(report *model.Report) (*pb.ReportResponse, error) {
+	if err := report.Validate(); err != nil {
+		err = fmt.Errorf("validation error: %w", err)
+		h.logger.Error().Err(err).Msg("validation error")
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	reported, err := h.as.HasOpenReport(report)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to search reports in the database")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	if reported {
+		msg := "you have already reported it"
+		h.logger.Error().Msg(msg)
+		return nil, status.Error(codes.AlreadyExists, msg)
+	}
+
+	hidden, err := h.as.CreateReport(report, h.reportThreshold)
+	if err != nil {
+		msg := "failed to create report"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, msg)
+	}
+
+	if hidden {
+		h.logger.Info().Msgf("%s (article=%d, comment=%d) was hidden by reports",
+			report.ContentType, report.ArticleID, report.CommentID)
+		if report.ContentType == model.ContentArticle {
+			h.unindexArticle(report.ArticleID)
+		}
+	}
+
+	return &pb.ReportResponse{Report: report.ProtoReport()}, nil
+}
+
+// ListReports lists reports for moderators
+func (h *Handler) ListReports(ctx context.Context, req *pb.ListReportsRequest) (*pb.ReportsResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("list reports")
+
+	if _, err := h.currentModerator(ctx); err != nil {
+		return nil, err
+	}
+
+	limitQuery := pageLimit(req.GetLimit())
+
+	rs, count, err := h.as.GetReports(req.GetStatus(), limitQuery, req.GetOffset())
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to search reports in the database")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	prs := make([]*pb.Report, 0, len(rs))
+	for _, r := range rs {
+		prs = append(prs, r.ProtoReport())
+	}
+
+	return &pb.ReportsResponse{Reports: prs, ReportsCount: int32(count)}, nil
+}
+
+// DismissReport dismisses the open reports on the reported content and shows it again
+func (h *Handler) DismissReport(ctx context.Context, req *pb.DismissReportRequest) (*pb.ReportResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("dismiss report")
+
+	return h.resolveReport(ctx, req.GetId(), model.ActionDismiss, req.GetNote())
+}
+
+// ResolveReport hides or removes the reported content and closes its open reports
+func (h *Handler) ResolveReport(ctx context.Context, req *pb.ResolveReportRequest) (*pb.ReportResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("resolve report")
+
+	if req.GetAction() == model.ActionDismiss || !model.IsValidAction(req.GetAction()) {
+		msg := fmt.Sprintf("unknown action: %q", req.GetAction())
+		h.logger.Error().Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, msg)
+	}
+
+	return h.resolveReport(ctx, req.GetId(), req.GetAction(), req.GetNote())
+}
+
+// resolveReport applies a moderator's decision to a report and records it
+func (h *Handler) resolveReport(ctx context.Context, id, action, note string) (*pb.ReportResponse, error) {
+	currentUser, err := h.currentModerator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reportID, err := strconv.Atoi(id)
+	if err != nil {
+		msg := fmt.Sprintf("cannot convert id (%s) into integer", id)
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, "invalid report id")
+	}
+
+	report, err := h.as.GetReportByID(uint(reportID))
+	if err != nil {
+		msg := "report was not found"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.NotFound, msg)
+	}
+
+	if report.Status != model.ReportOpen {
+		msg := "the report is already resolved"
+		h.logger.Error().Msg(msg)
+		return nil, status.Error(codes.FailedPrecondition, msg)
+	}
+
+	decision := model.ModerationAction{
+		ModeratorID: currentUser.ID,
+		Action:      action,
+		ContentType: report.ContentType,
+		ArticleID:   report.ArticleID,
+		CommentID:   report.CommentID,
+		ReportID:    report.ID,
+		Note:        note,
+	}
+
+	if err := h.as.ResolveReports(report, &decision); err != nil {
+		msg := "failed to resolve report"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, msg)
+	}
+
+	h.logger.Info().Msgf("user(id=%d) resolved report(id=%d) with %q", currentUser.ID, report.ID, action)
+
+	if report.ContentType == model.ContentArticle {
+		if action == model.ActionDismiss {
+			if article, err := h.as.GetByID(report.ArticleID); err == nil {
+				h.indexArticle(article)
+			}
+		} else {
+			h.unindexArticle(report.ArticleID)
+		}
+	}
+
+	return &pb.ReportResponse{Report: report.ProtoReport()}, nil
+}
+
+// ListModerationActions returns the audit log of moderators' decisions
+func (h *Handler) ListModerationActions(ctx context.Context, req *pb.ListModerationActionsRequest) (*pb.ModerationActionsResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("list moderation actions")
+
+	if _, err := h.currentModerator(ctx); err != nil {
+		return nil, err
+	}
+
+	limitQuery := pageLimit(req.GetLimit())
+
+	as, err := h.as.GetModerationActions(limitQuery, req.GetOffset())
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to search moderation actions in the database")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	pas := make([]*pb.ModerationAction, 0, len(as))
+	for _, a := range as {
+		pas = append(pas, a.ProtoModerationAction())
+	}
+
+	return &pb.ModerationActionsResponse{Actions: pas}, nil
+}
@@ -0,0 +1,268 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/raahii/golang-grpc-realworld-example/auth"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func createReportTestData(t *testing.T, h *Handler) ([]model.User, model.Article, model.Comment) {
+	users := []model.User{
+		{Username: "foo", Email: "foo@example.com", Password: "secret"},
+		{Username: "bar", Email: "bar@example.com", Password: "secret"},
+		{Username: "baz", Email: "baz@example.com", Password: "secret"},
+		{Username: "moderator", Email: "moderator@example.com", Password: "secret", Role: model.RoleModerator},
+	}
+
+	for i := range users {
+		if err := h.us.Create(&users[i]); err != nil {
+			t.Fatalf("failed to create initial user record: %v", err)
+		}
+	}
+
+	article := model.Article{
+		Title:       "awesome post!",
+		Description: "awesome description!",
+		Body:        "awesome content!",
+		Tags:        []model.Tag{{Name: "hoge"}},
+		Author:      users[0],
+	}
+
+	if err := h.as.Create(&article); err != nil {
+		t.Fatalf("failed to create initial article record: %v", err)
+	}
+
+	comment := model.Comment{
+		Body:      "spam spam spam",
+		Author:    users[0],
+		ArticleID: article.ID,
+	}
+
+	if err := h.as.CreateComment(&comment); err != nil {
+		t.Fatalf("failed to create initial article comment: %v", err)
+	}
+
+	return users, article, comment
+}
+
+func TestReportArticle(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+
+	users, article, _ := createReportTestData(t, h)
+	slug := fmt.Sprintf("%d", article.ID)
+
+	tests := []struct {
+		title    string
+		reqUser  *model.User
+		req      *pb.ReportArticleRequest
+		hasError bool
+	}{
+		{
+			"report own article: failed",
+			&users[0],
+			&pb.ReportArticleRequest{Slug: slug, Reason: "spam"},
+			true,
+		},
+		{
+			"report without reason: failed",
+			&users[1],
+			&pb.ReportArticleRequest{Slug: slug},
+			true,
+		},
+		{
+			"report article: success",
+			&users[1],
+			&pb.ReportArticleRequest{Slug: slug, Reason: "spam"},
+			false,
+		},
+		{
+			"report the article twice: failed",
+			&users[1],
+			&pb.ReportArticleRequest{Slug: slug, Reason: "spam again"},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		token, err := auth.GenerateToken(tt.reqUser.ID)
+		if err != nil {
+			t.Error(err)
+		}
+
+		resp, err := h.ReportArticle(ctxWithToken(context.Background(), token), tt.req)
+		if tt.hasError {
+			if err == nil {
+				t.Errorf("%q expected to fail, but succeeded.", tt.title)
+				t.FailNow()
+			}
+			continue
+		}
+
+		if !tt.hasError && err != nil {
+			t.Errorf("%q expected to succeed, but failed. %v", tt.title, err)
+			t.FailNow()
+		}
+
+		assert.Equal(t, model.ContentArticle, resp.GetReport().GetType())
+		assert.Equal(t, slug, resp.GetReport().GetSlug())
+		assert.Equal(t, tt.req.GetReason(), resp.GetReport().GetReason())
+		assert.Equal(t, model.ReportOpen, resp.GetReport().GetStatus())
+		assert.Equal(t, tt.reqUser.Username, resp.GetReport().GetReporter().GetUsername())
+	}
+}
+
+func TestReportHidesContent(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+	h.reportThreshold = 2
+
+	users, article, comment := createReportTestData(t, h)
+	slug := fmt.Sprintf("%d", article.ID)
+
+	for _, u := range users[1:3] {
+		token, err := auth.GenerateToken(u.ID)
+		if err != nil {
+			t.Error(err)
+		}
+		ctx := ctxWithToken(context.Background(), token)
+
+		_, err = h.ReportArticle(ctx, &pb.ReportArticleRequest{Slug: slug, Reason: "spam"})
+		if err != nil {
+			t.Fatalf("report article expected to succeed, but failed. %v", err)
+		}
+
+		_, err = h.ReportComment(ctx, &pb.ReportCommentRequest{
+			Slug:   slug,
+			Id:     fmt.Sprintf("%d", comment.ID),
+			Reason: "spam",
+		})
+		if err != nil {
+			t.Fatalf("report comment expected to succeed, but failed. %v", err)
+		}
+	}
+
+	// hidden from readers
+	_, err := h.GetArticle(context.Background(), &pb.GetArticleRequest{Slug: slug})
+	assert.Error(t, err)
+
+	articles, err := h.GetArticles(context.Background(), &pb.GetArticlesRequest{})
+	if assert.NoError(t, err) {
+		assert.Len(t, articles.GetArticles(), 0)
+	}
+
+	_, err = h.GetComments(context.Background(), &pb.GetCommentsRequest{Slug: slug})
+	assert.Error(t, err)
+
+	// still visible to the author and moderators, except for the hidden comment
+	for _, u := range []model.User{users[0], users[3]} {
+		token, err := auth.GenerateToken(u.ID)
+		if err != nil {
+			t.Error(err)
+		}
+		ctx := ctxWithToken(context.Background(), token)
+
+		_, err = h.GetArticle(ctx, &pb.GetArticleRequest{Slug: slug})
+		assert.NoError(t, err, u.Username)
+
+		comments, err := h.GetComments(ctx, &pb.GetCommentsRequest{Slug: slug})
+		if assert.NoError(t, err, u.Username) {
+			assert.Len(t, comments.GetComments(), 0, u.Username)
+		}
+	}
+}
+
+func TestResolveReport(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+	h.reportThreshold = 1
+
+	users, article, comment := createReportTestData(t, h)
+	slug := fmt.Sprintf("%d", article.ID)
+
+	userToken, err := auth.GenerateToken(users[1].ID)
+	if err != nil {
+		t.Error(err)
+	}
+	userCtx := ctxWithToken(context.Background(), userToken)
+
+	moderatorToken, err := auth.GenerateToken(users[3].ID)
+	if err != nil {
+		t.Error(err)
+	}
+	moderatorCtx := ctxWithToken(context.Background(), moderatorToken)
+
+	articleReport, err := h.ReportArticle(userCtx, &pb.ReportArticleRequest{Slug: slug, Reason: "spam"})
+	if err != nil {
+		t.Fatalf("report article expected to succeed, but failed. %v", err)
+	}
+
+	commentReport, err := h.ReportComment(userCtx, &pb.ReportCommentRequest{
+		Slug:   slug,
+		Id:     fmt.Sprintf("%d", comment.ID),
+		Reason: "spam",
+	})
+	if err != nil {
+		t.Fatalf("report comment expected to succeed, but failed. %v", err)
+	}
+
+	// only moderators can review reports
+	_, err = h.ListReports(userCtx, &pb.ListReportsRequest{})
+	assert.Error(t, err)
+
+	reports, err := h.ListReports(moderatorCtx, &pb.ListReportsRequest{Status: model.ReportOpen})
+	if assert.NoError(t, err) {
+		assert.Equal(t, int32(2), reports.GetReportsCount())
+	}
+
+	// dismissing shows the article again
+	resp, err := h.DismissReport(moderatorCtx, &pb.DismissReportRequest{
+		Id:   articleReport.GetReport().GetId(),
+		Note: "not spam",
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, model.ReportDismissed, resp.GetReport().GetStatus())
+		assert.NotEmpty(t, resp.GetReport().GetResolvedAt())
+	}
+
+	_, err = h.GetArticle(context.Background(), &pb.GetArticleRequest{Slug: slug})
+	assert.NoError(t, err)
+
+	_, err = h.DismissReport(moderatorCtx, &pb.DismissReportRequest{Id: articleReport.GetReport().GetId()})
+	assert.Error(t, err, "resolved report cannot be resolved again")
+
+	_, err = h.ResolveReport(moderatorCtx, &pb.ResolveReportRequest{
+		Id:     commentReport.GetReport().GetId(),
+		Action: "ignore",
+	})
+	assert.Error(t, err, "unknown action")
+
+	// removing deletes the comment
+	resp, err = h.ResolveReport(moderatorCtx, &pb.ResolveReportRequest{
+		Id:     commentReport.GetReport().GetId(),
+		Action: model.ActionRemove,
+		Note:   "advertisement",
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, model.ReportActioned, resp.GetReport().GetStatus())
+	}
+
+	_, err = h.as.GetCommentByID(comment.ID)
+	assert.Error(t, err)
+
+	// decisions are recorded in the moderation log
+	log, err := h.ListModerationActions(moderatorCtx, &pb.ListModerationActionsRequest{})
+	if assert.NoError(t, err) && assert.Len(t, log.GetActions(), 2) {
+		actions := []string{log.GetActions()[0].GetAction(), log.GetActions()[1].GetAction()}
+		assert.ElementsMatch(t, []string{model.ActionDismiss, model.ActionRemove}, actions)
+		for _, a := range log.GetActions() {
+			assert.Equal(t, users[3].Username, a.GetModerator().GetUsername())
+			assert.NotEmpty(t, a.GetReportId())
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/raahii/golang-grpc-realworld-example/auth"
+	"github.com/raahii/golang-grpc-realworld-example/diff"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	"github.com/raahii/golang-grpc-realworld-example/policy"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// diffContext is the number of unchanged lines around changes in diffs
+	diffContext = 3
+	// maxDiffSize is the largest combined size in bytes of two revisions to diff
+	maxDiffSize = 1 << 20
+)
+
+// ListArticleRevisions lists revisions of an article, latest first
+func (h *Handler) ListArticleRevisions(ctx context.Context, req *pb.ListArticleRevisionsRequest) (*pb.ArticleRevisionsResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("list article revisions")
+
+	article, err := h.revisedArticle(ctx, req.GetSlug())
+	if err != nil {
+		return nil, err
+	}
+
+	rs, err := h.as.GetArticleRevisions(article)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to get article revisions")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	prs := make([]*pb.ArticleRevision, 0, len(rs))
+	for _, r := range rs {
+		prs = append(prs, r.ProtoArticleRevision())
+	}
+
+	return &pb.ArticleRevisionsResponse{Revisions: prs, RevisionsCount: int32(len(prs))}, nil
+}
+
+// GetArticleRevision gets a revision of an article
+func (h *Handler) GetArticleRevision(ctx context.Context, req *pb.GetArticleRevisionRequest) (*pb.ArticleRevisionResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("get article revision")
+
+	article, err := h.revisedArticle(ctx, req.GetSlug())
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := h.as.GetArticleRevision(article, req.GetNumber())
+	if err != nil {
+		h.logger.Error().Err(err).Msgf("revision %d of article(id=%d) not found", req.GetNumber(), article.ID)
+		return nil, status.Error(codes.NotFound, "revision not found")
+	}
+
+	return &pb.ArticleRevisionResponse{Revision: r.ProtoArticleRevision()}, nil
+}
+
+// DiffArticleRevisions returns the unified diff between two revisions of an article.
+// to defaults to the latest revision and from to the one before to.
+func (h *Handler) DiffArticleRevisions(ctx context.Context, req *pb.DiffArticleRevisionsRequest) (*pb.ArticleDiffResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("diff article revisions")
+
+	article, err := h.revisedArticle(ctx, req.GetSlug())
+	if err != nil {
+		return nil, err
+	}
+
+	to := req.GetTo()
+	if to == 0 {
+		rs, err := h.as.GetArticleRevisions(article)
+		if err != nil {
+			h.logger.Error().Err(err).Msg("failed to get article revisions")
+			return nil, status.Error(codes.Aborted, "internal server error")
+		}
+		if len(rs) == 0 {
+			return nil, status.Error(codes.NotFound, "revision not found")
+		}
+		to = rs[0].Number
+	}
+
+	from := req.GetFrom()
+	if from == 0 {
+		from = to - 1
+	}
+
+	// revision 0 is the empty article before the first revision
+	texts := make([]string, 2)
+	for i, n := range []int32{from, to} {
+		if n == 0 {
+			continue
+		}
+
+		r, err := h.as.GetArticleRevision(article, n)
+		if err != nil {
+			h.logger.Error().Err(err).Msgf("revision %d of article(id=%d) not found", n, article.ID)
+			return nil, status.Error(codes.NotFound, "revision not found")
+		}
+		texts[i] = r.Text()
+	}
+
+	if len(texts[0])+len(texts[1]) > maxDiffSize {
+		return nil, status.Error(codes.FailedPrecondition, "revisions are too large to diff")
+	}
+
+	d := diff.Unified(
+		fmt.Sprintf("revision %d", from),
+		fmt.Sprintf("revision %d", to),
+		texts[0], texts[1], diffContext,
+	)
+
+	return &pb.ArticleDiffResponse{From: from, To: to, Diff: d}, nil
+}
+
+// RestoreArticleRevision brings back the content of a revision as a new revision
+func (h *Handler) RestoreArticleRevision(ctx context.Context, req *pb.RestoreArticleRevisionRequest) (*pb.ArticleResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("restore article revision")
+
+	currentUser, article, err := h.ownArticle(ctx, req.GetSlug())
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := h.as.GetArticleRevision(article, req.GetNumber())
+	if err != nil {
+		h.logger.Error().Err(err).Msgf("revision %d of article(id=%d) not found", req.GetNumber(), article.ID)
+		return nil, status.Error(codes.NotFound, "revision not found")
+	}
+
+	article.Title = r.Title
+	article.Description = r.Description
+	article.Body = r.Body
+
+	if err := h.as.Update(article, currentUser.ID); err != nil {
+		h.logger.Error().Err(err).Msg("failed to restore article revision")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+	h.indexArticle(article)
+	h.relay.Wake()
+
+	return h.ownArticleResponse(currentUser, article)
+}
+
+// revisedArticle returns the article identified by slug
+// if current user can read its revisions
+func (h *Handler) revisedArticle(ctx context.Context, slug string) (*model.Article, error) {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("unauthenticated")
+		return nil, status.Errorf(codes.Unauthenticated, "unauthenticated")
+	}
+
+	currentUser, err := h.us.GetByID(userID)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("current user not found")
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	articleID, err := strconv.Atoi(slug)
+	if err != nil {
+		msg := fmt.Sprintf("cannot convert slug (%s) into integer", slug)
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, "invalid article id")
+	}
+
+	article, err := h.as.GetByID(uint(articleID))
+	if err != nil {
+		msg := fmt.Sprintf("requested article (slug=%d) not found", articleID)
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, "invalid article id")
+	}
+
+	if !policy.CanViewArticleRevisions(currentUser, article) {
+		h.logger.Error().Msgf("user(id=%d) attempted to read revisions of article(id=%d)",
+			currentUser.ID, article.ID)
+		return nil, status.Error(codes.PermissionDenied, "forbidden")
+	}
+
+	return article, nil
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/raahii/golang-grpc-realworld-example/auth"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArticleRevisions(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+
+	fooUser := model.User{
+		Username: "foo",
+		Email:    "foo@example.com",
+		Password: "secret",
+	}
+
+	barUser := model.User{
+		Username: "bar",
+		Email:    "bar@example.com",
+		Password: "secret",
+	}
+
+	for _, u := range []*model.User{&fooUser, &barUser} {
+		if err := h.us.Create(u); err != nil {
+			t.Fatalf("failed to create initial user record: %v", err)
+		}
+	}
+
+	article := model.Article{
+		Title:       "original title",
+		Description: "original desc",
+		Body:        "first line\nsecond line",
+		Author:      fooUser,
+		Tags:        []model.Tag{{Name: "hoge"}},
+	}
+	if err := h.as.Create(&article); err != nil {
+		t.Fatalf("failed to create initial article record: %v", err)
+	}
+	slug := fmt.Sprintf("%d", article.ID)
+
+	fooToken, err := auth.GenerateToken(fooUser.ID)
+	if err != nil {
+		t.Error(err)
+	}
+	fooCtx := ctxWithToken(context.Background(), fooToken)
+
+	barToken, err := auth.GenerateToken(barUser.ID)
+	if err != nil {
+		t.Error(err)
+	}
+	barCtx := ctxWithToken(context.Background(), barToken)
+
+	for _, ra := range []*pb.UpdateArticleRequest_Article{
+		{Slug: slug, Title: "new title"},
+		{Slug: slug, Body: "first line\nchanged line"},
+	} {
+		if _, err := h.UpdateArticle(fooCtx, &pb.UpdateArticleRequest{Article: ra}); err != nil {
+			t.Fatalf("update article expected to succeed, but failed. %v", err)
+		}
+	}
+
+	// list
+	list, err := h.ListArticleRevisions(fooCtx, &pb.ListArticleRevisionsRequest{Slug: slug})
+	if assert.NoError(t, err) && assert.Len(t, list.GetRevisions(), 3) {
+		assert.Equal(t, int32(3), list.GetRevisions()[0].GetNumber())
+		assert.Equal(t, "foo", list.GetRevisions()[0].GetEditor().GetUsername())
+		assert.Equal(t, int32(1), list.GetRevisions()[2].GetNumber())
+	}
+
+	_, err = h.ListArticleRevisions(barCtx, &pb.ListArticleRevisionsRequest{Slug: slug})
+	assert.Error(t, err, "other users cannot read revisions")
+
+	// get
+	rev, err := h.GetArticleRevision(fooCtx, &pb.GetArticleRevisionRequest{Slug: slug, Number: 1})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "original title", rev.GetRevision().GetTitle())
+		assert.Equal(t, "first line\nsecond line", rev.GetRevision().GetBody())
+	}
+
+	_, err = h.GetArticleRevision(fooCtx, &pb.GetArticleRevisionRequest{Slug: slug, Number: 4})
+	assert.Error(t, err)
+
+	// diff
+	d, err := h.DiffArticleRevisions(fooCtx, &pb.DiffArticleRevisionsRequest{Slug: slug})
+	if assert.NoError(t, err) {
+		assert.Equal(t, int32(2), d.GetFrom())
+		assert.Equal(t, int32(3), d.GetTo())
+		assert.Contains(t, d.GetDiff(), "-second line\n+changed line\n")
+		assert.NotContains(t, d.GetDiff(), "title")
+	}
+
+	d, err = h.DiffArticleRevisions(fooCtx, &pb.DiffArticleRevisionsRequest{Slug: slug, From: 1, To: 3})
+	if assert.NoError(t, err) {
+		assert.Contains(t, d.GetDiff(), "-# original title\n+# new title\n")
+	}
+
+	// restore
+	_, err = h.RestoreArticleRevision(barCtx, &pb.RestoreArticleRevisionRequest{Slug: slug, Number: 1})
+	assert.Error(t, err, "other users cannot restore revisions")
+
+	resp, err := h.RestoreArticleRevision(fooCtx, &pb.RestoreArticleRevisionRequest{Slug: slug, Number: 1})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "original title", resp.GetArticle().GetTitle())
+		assert.Equal(t, "first line\nsecond line", resp.GetArticle().GetBody())
+	}
+
+	d, err = h.DiffArticleRevisions(fooCtx, &pb.DiffArticleRevisionsRequest{Slug: slug, From: 1})
+	if assert.NoError(t, err) {
+		assert.Equal(t, int32(4), d.GetTo())
+		assert.Empty(t, d.GetDiff(), "restored revision is the same as the first one")
+	}
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// publishBatchSize is the max number of articles published by one scheduler run
+const publishBatchSize = 100
+
+// ScheduleArticle sets or changes the time to publish a draft
+func (h *Handler) ScheduleArticle(ctx context.Context, req *pb.ScheduleArticleRequest) (*pb.ArticleResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("schedule article")
+
+	publishAt, err := parseTime(req.GetPublishAt())
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to parse publishAt")
+		return nil, status.Error(codes.InvalidArgument, "invalid publishAt")
+	}
+
+	if !publishAt.After(time.Now()) {
+		msg := "publishAt must be in the future"
+		h.logger.Error().Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, msg)
+	}
+
+	currentUser, article, err := h.ownArticle(ctx, req.GetSlug())
+	if err != nil {
+		return nil, err
+	}
+
+	if article.Status != model.ArticleDraft {
+		msg := "only drafts can be scheduled"
+		h.logger.Error().Msgf("article(id=%d) is %s", article.ID, article.Status)
+		return nil, status.Error(codes.FailedPrecondition, msg)
+	}
+
+	if err := h.as.SchedulePublish(article, &publishAt); err != nil {
+		msg := "failed to schedule article"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, msg)
+	}
+
+	return h.ownArticleResponse(currentUser, article)
+}
+
+// UnscheduleArticle cancels the scheduled publication of a draft
+func (h *Handler) UnscheduleArticle(ctx context.Context, req *pb.UnscheduleArticleRequest) (*pb.ArticleResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("unschedule article")
+
+	currentUser, article, err := h.ownArticle(ctx, req.GetSlug())
+	if err != nil {
+		return nil, err
+	}
+
+	if article.PublishAt == nil {
+		msg := "article is not scheduled"
+		h.logger.Error().Msgf("article(id=%d) is not scheduled", article.ID)
+		return nil, status.Error(codes.FailedPrecondition, msg)
+	}
+
+	if err := h.as.SchedulePublish(article, nil); err != nil {
+		msg := "failed to unschedule article"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, msg)
+	}
+
+	return h.ownArticleResponse(currentUser, article)
+}
+
+// PublishScheduledArticles publishes drafts whose scheduled time has come.
+// It is run periodically by the job runner.
+func (h *Handler) PublishScheduledArticles(ctx context.Context) error {
+	for {
+		as, err := h.as.PublishDueArticles(time.Now(), publishBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to publish scheduled articles: %w", err)
+		}
+
+		for _, a := range as {
+			h.logger.Info().Uint("article", a.ID).Msg("published scheduled article")
+			if article, err := h.as.GetByID(a.ID); err == nil {
+				h.indexArticle(article)
+			}
+		}
+		h.relay.Wake()
+
+		if len(as) < publishBatchSize || ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// parseTime parses a RFC 3339 time, or one in the format the API returns
+func parseTime(s string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, s)
+	if err == nil {
+		return t, nil
+	}
+	return time.Parse(model.ISO8601, s)
+}
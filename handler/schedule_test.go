@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/raahii/golang-grpc-realworld-example/auth"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleArticle(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+
+	fooUser := model.User{
+		Username: "foo",
+		Email:    "foo@example.com",
+		Password: "secret",
+	}
+
+	barUser := model.User{
+		Username: "bar",
+		Email:    "bar@example.com",
+		Password: "secret",
+	}
+
+	for _, u := range []*model.User{&fooUser, &barUser} {
+		if err := h.us.Create(u); err != nil {
+			t.Fatalf("failed to create initial user record: %v", err)
+		}
+	}
+
+	draft := model.Article{
+		Title:  "draft",
+		Body:   "draft body",
+		Author: fooUser,
+		Tags:   []model.Tag{{Name: "tag"}},
+		Status: model.ArticleDraft,
+	}
+
+	published := model.Article{
+		Title:  "published",
+		Body:   "published body",
+		Author: fooUser,
+		Tags:   []model.Tag{{Name: "tag"}},
+		Status: model.ArticlePublished,
+	}
+
+	for _, a := range []*model.Article{&draft, &published} {
+		if err := h.as.Create(a); err != nil {
+			t.Fatalf("failed to create initial article record: %v", err)
+		}
+	}
+
+	draftSlug := fmt.Sprintf("%d", draft.ID)
+	publishedSlug := fmt.Sprintf("%d", published.ID)
+	future := time.Now().Add(time.Hour).Format(time.RFC3339)
+	past := time.Now().Add(-time.Hour).Format(time.RFC3339)
+
+	tests := []struct {
+		title    string
+		reqUser  *model.User
+		req      *pb.ScheduleArticleRequest
+		hasError bool
+	}{
+		{
+			"schedule draft: success",
+			&fooUser,
+			&pb.ScheduleArticleRequest{Slug: draftSlug, PublishAt: future},
+			false,
+		},
+		{
+			"change schedule: success",
+			&fooUser,
+			&pb.ScheduleArticleRequest{Slug: draftSlug, PublishAt: time.Now().Add(2 * time.Hour).Format(time.RFC3339)},
+			false,
+		},
+		{
+			"schedule in the past: failed",
+			&fooUser,
+			&pb.ScheduleArticleRequest{Slug: draftSlug, PublishAt: past},
+			true,
+		},
+		{
+			"invalid time: failed",
+			&fooUser,
+			&pb.ScheduleArticleRequest{Slug: draftSlug, PublishAt: "tomorrow"},
+			true,
+		},
+		{
+			"schedule published article: failed",
+			&fooUser,
+			&pb.ScheduleArticleRequest{Slug: publishedSlug, PublishAt: future},
+			true,
+		},
+		{
+			"schedule other's article: failed",
+			&barUser,
+			&pb.ScheduleArticleRequest{Slug: draftSlug, PublishAt: future},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		token, err := auth.GenerateToken(tt.reqUser.ID)
+		if err != nil {
+			t.Error(err)
+		}
+		ctx := ctxWithToken(context.Background(), token)
+
+		resp, err := h.ScheduleArticle(ctx, tt.req)
+		if tt.hasError {
+			if err == nil {
+				t.Errorf("%q expected to fail, but succeeded.", tt.title)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%q expected to succeed, but failed. %v", tt.title, err)
+			continue
+		}
+
+		assert.Equal(t, model.ArticleDraft, resp.GetArticle().GetStatus())
+		assert.NotEmpty(t, resp.GetArticle().GetPublishAt())
+	}
+
+	token, err := auth.GenerateToken(fooUser.ID)
+	if err != nil {
+		t.Error(err)
+	}
+	ctx := ctxWithToken(context.Background(), token)
+
+	// cancel
+	resp, err := h.UnscheduleArticle(ctx, &pb.UnscheduleArticleRequest{Slug: draftSlug})
+	if assert.NoError(t, err) {
+		assert.Empty(t, resp.GetArticle().GetPublishAt())
+	}
+
+	_, err = h.UnscheduleArticle(ctx, &pb.UnscheduleArticleRequest{Slug: draftSlug})
+	assert.Error(t, err, "article is no longer scheduled")
+
+	// the scheduler publishes due articles only
+	other := model.Article{
+		Title:  "later",
+		Body:   "later body",
+		Author: fooUser,
+		Tags:   []model.Tag{{Name: "tag"}},
+		Status: model.ArticleDraft,
+	}
+	if err := h.as.Create(&other); err != nil {
+		t.Fatalf("failed to create article record: %v", err)
+	}
+
+	due := time.Now().Add(-time.Minute)
+	later := time.Now().Add(time.Hour)
+	assert.NoError(t, h.as.SchedulePublish(&draft, &due))
+	assert.NoError(t, h.as.SchedulePublish(&other, &later))
+
+	if err := h.PublishScheduledArticles(context.Background()); err != nil {
+		t.Fatalf("failed to publish scheduled articles: %v", err)
+	}
+
+	a, err := h.as.GetByID(draft.ID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, model.ArticlePublished, a.Status)
+		assert.Nil(t, a.PublishAt)
+		assert.NotNil(t, a.PublishedAt)
+	}
+
+	a, err = h.as.GetByID(other.ID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, model.ArticleDraft, a.Status)
+		assert.NotNil(t, a.PublishAt)
+		assert.Nil(t, a.PublishedAt)
+	}
+}
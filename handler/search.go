@@ -0,0 +1,333 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/raahii/golang-grpc-realworld-example/auth"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	"github.com/raahii/golang-grpc-realworld-example/policy"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+	"github.com/raahii/golang-grpc-realworld-example/search"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// syncBatchSize is the number of rows loaded at once by the index sync
+	syncBatchSize = 500
+
+	// syncOverlap is subtracted from the time of the last sync,
+	// allowing for clock differences between servers
+	syncOverlap = time.Minute
+)
+
+// SearchArticles searches articles by relevance
+func (h *Handler) SearchArticles(ctx context.Context, req *pb.SearchArticlesRequest) (*pb.SearchArticlesResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("search articles")
+
+	if req.GetQ() == "" {
+		msg := "search query is empty"
+		h.logger.Error().Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, msg)
+	}
+
+	if err := h.checkPage(req.GetLimit(), req.GetOffset()); err != nil {
+		return nil, err
+	}
+	limitQuery := pageLimit(req.GetLimit())
+
+	var currentUser *model.User
+	userID, err := auth.GetUserID(ctx)
+	if err == nil {
+		currentUser, err = h.us.GetByID(userID)
+		if err != nil {
+			h.logger.Error().Err(err).Msg("current user not found")
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+	}
+
+	mutedUserIDs, err := h.us.GetMutedUserIDs(currentUser)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to get muted user ids")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	q := search.ArticleQuery{
+		Text:            req.GetQ(),
+		Tag:             req.GetTag(),
+		Author:          req.GetAuthor(),
+		ExcludedAuthors: mutedUserIDs,
+		Limit:           int(limitQuery),
+		Offset:          int(req.GetOffset()),
+	}
+
+	// unknown users are ignored like GetArticles does
+	if req.GetFavorited() != "" {
+		if u, err := h.us.GetByUsername(req.GetFavorited()); err == nil {
+			q.ArticleIDs, err = h.as.GetFavoriteArticleIDs(u)
+			if err != nil {
+				h.logger.Error().Err(err).Msg("failed to get favorite article ids")
+				return nil, status.Error(codes.Aborted, "internal server error")
+			}
+		}
+	}
+
+	res, err := h.index.SearchArticles(q)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to search articles")
+		return nil, status.Error(codes.InvalidArgument, "invalid search query")
+	}
+
+	ids := make([]uint, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		ids = append(ids, hit.ArticleID)
+	}
+
+	as, err := h.as.GetListedArticlesByIDs(ids)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to get articles of search hits")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	articles := make(map[uint]*model.Article, len(as))
+	for i := range as {
+		articles[as[i].ID] = &as[i]
+	}
+
+	phs := make([]*pb.ArticleSearchHit, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		// the index may lag behind the database
+		a, ok := articles[hit.ArticleID]
+		if !ok {
+			continue
+		}
+
+		favorited, err := h.as.IsFavorited(a, currentUser)
+		if err != nil {
+			msg := "failed to get favorited status"
+			h.logger.Error().Err(err).Msg(msg)
+			return nil, status.Error(codes.Aborted, "internal server error")
+		}
+		pa := a.ProtoArticle(favorited)
+
+		// get whether the article is in current user's reading lists
+		bookmarked, err := h.as.IsBookmarked(a, currentUser)
+		if err != nil {
+			msg := "failed to get bookmarked status"
+			h.logger.Error().Err(err).Msg(msg)
+			return nil, status.Error(codes.Aborted, "internal server error")
+		}
+		pa.Bookmarked = bookmarked
+
+		following, err := h.us.IsFollowing(currentUser, &a.Author)
+		if err != nil {
+			msg := "failed to get following status"
+			h.logger.Error().Err(err).Msg(msg)
+			return nil, status.Error(codes.NotFound, "internal server error")
+		}
+		pa.Author = a.Author.ProtoProfile(following)
+
+		phs = append(phs, &pb.ArticleSearchHit{
+			Article:  pa,
+			Snippets: hit.Snippets,
+			Score:    hit.Score,
+		})
+	}
+
+	return &pb.SearchArticlesResponse{Hits: phs, HitsCount: int32(res.Total)}, nil
+}
+
+// SearchProfiles searches users by username and bio. In autocomplete mode
+// only usernames matching the prefix are returned, for @mention pickers.
+func (h *Handler) SearchProfiles(ctx context.Context, req *pb.SearchProfilesRequest) (*pb.SearchProfilesResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("search profiles")
+
+	if req.GetQ() == "" {
+		msg := "search query is empty"
+		h.logger.Error().Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, msg)
+	}
+
+	if err := h.checkPage(req.GetLimit(), req.GetOffset()); err != nil {
+		return nil, err
+	}
+	limitQuery := pageLimit(req.GetLimit())
+	if req.GetLimit() == 0 && req.GetAutocomplete() {
+		limitQuery = 5
+	}
+
+	var currentUser *model.User
+	userID, err := auth.GetUserID(ctx)
+	if err == nil {
+		currentUser, err = h.us.GetByID(userID)
+		if err != nil {
+			h.logger.Error().Err(err).Msg("current user not found")
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+	}
+
+	blockedUserIDs, err := h.us.GetBlockRelatedUserIDs(currentUser)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to get blocked user ids")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	now := time.Now()
+	res, err := h.index.SearchUsers(search.UserQuery{
+		Text:          req.GetQ(),
+		Autocomplete:  req.GetAutocomplete(),
+		ExcludedUsers: blockedUserIDs,
+		Now:           now,
+		Limit:         int(limitQuery),
+		Offset:        int(req.GetOffset()),
+	})
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to search users")
+		return nil, status.Error(codes.InvalidArgument, "invalid search query")
+	}
+
+	if req.GetAutocomplete() {
+		usernames := make([]string, 0, len(res.Hits))
+		for _, hit := range res.Hits {
+			usernames = append(usernames, hit.Username)
+		}
+		return &pb.SearchProfilesResponse{Usernames: usernames, ProfilesCount: int32(res.Total)}, nil
+	}
+
+	ids := make([]uint, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		ids = append(ids, hit.UserID)
+	}
+
+	us, err := h.us.GetUsersByIDs(ids)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to get users of search hits")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	users := make(map[uint]*model.User, len(us))
+	for i := range us {
+		users[us[i].ID] = &us[i]
+	}
+
+	following := map[uint]bool{}
+	if currentUser != nil {
+		ids, err := h.us.GetFollowingUserIDs(currentUser)
+		if err != nil {
+			msg := fmt.Sprintf("failed to get following user ids of user %d", currentUser.ID)
+			h.logger.Error().Err(err).Msg(msg)
+			return nil, status.Error(codes.Aborted, "internal server error")
+		}
+
+		for _, id := range ids {
+			following[id] = true
+		}
+	}
+
+	pps := make([]*pb.Profile, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		// the index may lag behind the database
+		u, ok := users[hit.UserID]
+		if !ok || policy.IsBanned(u) || policy.IsSuspended(u, now) {
+			continue
+		}
+		pps = append(pps, u.ProtoProfile(following[u.ID]))
+	}
+
+	return &pb.SearchProfilesResponse{Profiles: pps, ProfilesCount: int32(res.Total)}, nil
+}
+
+// indexArticle updates the search index and drops the related articles
+// cached with the article after it has changed. Errors are only logged since
+// the periodic sync retries the article.
+func (h *Handler) indexArticle(a *model.Article) {
+	h.related.Invalidate(a.ID)
+	if err := h.index.UpdateArticle(a); err != nil {
+		h.logger.Error().Err(err).Msgf("failed to index article(id=%d)", a.ID)
+	}
+}
+
+// unindexArticle removes a deleted or hidden article from the search index
+// and drops the related articles cached with it
+func (h *Handler) unindexArticle(id uint) {
+	h.related.Invalidate(id)
+	if err := h.index.DeleteArticle(id); err != nil {
+		h.logger.Error().Err(err).Msgf("failed to remove article(id=%d) from the index", id)
+	}
+}
+
+// indexUser updates the search index after the user has changed.
+// Errors are only logged since the periodic sync retries the user.
+func (h *Handler) indexUser(u *model.User) {
+	if err := h.index.UpdateUser(u); err != nil {
+		h.logger.Error().Err(err).Msgf("failed to index user(id=%d)", u.ID)
+	}
+}
+
+// SyncSearchIndex indexes articles and users changed since the last sync. It is run
+// periodically by the job runner, builds the index on the first start and
+// picks up changes made through other servers.
+func (h *Handler) SyncSearchIndex(ctx context.Context) error {
+	start := time.Now()
+
+	since, err := h.index.SyncedUntil()
+	if err != nil {
+		return fmt.Errorf("failed to get last sync of search index: %w", err)
+	}
+
+	var afterID uint
+	for {
+		as, err := h.as.GetArticlesChangedSince(since, afterID, syncBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to get changed articles: %w", err)
+		}
+
+		// the articles may have changed through other servers
+		ids := make([]uint, 0, len(as))
+		for i := range as {
+			if err := h.index.UpdateArticle(&as[i]); err != nil {
+				return fmt.Errorf("failed to index article(id=%d): %w", as[i].ID, err)
+			}
+			ids = append(ids, as[i].ID)
+		}
+		if len(ids) > 0 {
+			h.related.Invalidate(ids...)
+		}
+
+		if len(as) < syncBatchSize {
+			break
+		}
+		afterID = as[len(as)-1].ID
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+
+	afterID = 0
+	for {
+		us, err := h.us.GetUsersChangedSince(since, afterID, syncBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to get changed users: %w", err)
+		}
+
+		for i := range us {
+			if err := h.index.UpdateUser(&us[i]); err != nil {
+				return fmt.Errorf("failed to index user(id=%d): %w", us[i].ID, err)
+			}
+		}
+
+		if len(us) < syncBatchSize {
+			break
+		}
+		afterID = us[len(us)-1].ID
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+
+	return h.index.SetSyncedUntil(start.Add(-syncOverlap))
+}
@@ -0,0 +1,288 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/raahii/golang-grpc-realworld-example/auth"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchArticles(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+
+	fooUser := model.User{
+		Username: "foo",
+		Email:    "foo@example.com",
+		Password: "secret",
+	}
+	if err := h.us.Create(&fooUser); err != nil {
+		t.Fatalf("failed to create initial user record: %v", err)
+	}
+
+	token, err := auth.GenerateToken(fooUser.ID)
+	if err != nil {
+		t.Error(err)
+	}
+	ctx := ctxWithToken(context.Background(), token)
+
+	slugs := map[string]string{}
+	for _, ra := range []*pb.CreateAritcleRequest_Article{
+		{Title: "Learning Go", Body: "Go is a programming language.", TagList: []string{"go"}},
+		{Title: "Cooking pasta", Body: "Boil water and add pasta.", TagList: []string{"food"}},
+		{Title: "Rust notes", Body: "Rust is a programming language.", TagList: []string{"rust"}},
+		{Title: "Secret draft", Body: "A programming language draft.", TagList: []string{"go"}, Status: model.ArticleDraft},
+	} {
+		resp, err := h.CreateArticle(ctx, &pb.CreateAritcleRequest{Article: ra})
+		if err != nil {
+			t.Fatalf("create article expected to succeed, but failed. %v", err)
+		}
+		slugs[ra.Title] = resp.GetArticle().GetSlug()
+	}
+
+	tests := []struct {
+		title    string
+		req      *pb.SearchArticlesRequest
+		expected []string
+		hasError bool
+	}{
+		{
+			"search by word: success",
+			&pb.SearchArticlesRequest{Q: "pasta"},
+			[]string{"Cooking pasta"},
+			false,
+		},
+		{
+			"search by phrase: success",
+			&pb.SearchArticlesRequest{Q: `"programming language"`},
+			[]string{"Learning Go", "Rust notes"},
+			false,
+		},
+		{
+			"search by prefix: success",
+			&pb.SearchArticlesRequest{Q: "progr*"},
+			[]string{"Learning Go", "Rust notes"},
+			false,
+		},
+		{
+			"search with tag: success",
+			&pb.SearchArticlesRequest{Q: "language", Tag: "rust"},
+			[]string{"Rust notes"},
+			false,
+		},
+		{
+			"search with author: success",
+			&pb.SearchArticlesRequest{Q: "language", Author: "nobody"},
+			[]string{},
+			false,
+		},
+		{
+			"empty query: failed",
+			&pb.SearchArticlesRequest{Q: ""},
+			nil,
+			true,
+		},
+		{
+			"negative limit: failed",
+			&pb.SearchArticlesRequest{Q: "language", Limit: -1},
+			nil,
+			true,
+		},
+		{
+			"negative offset: failed",
+			&pb.SearchArticlesRequest{Q: "language", Offset: -1},
+			nil,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		resp, err := h.SearchArticles(context.Background(), tt.req)
+		if tt.hasError {
+			if err == nil {
+				t.Errorf("%q expected to fail, but succeeded.", tt.title)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%q expected to succeed, but failed. %v", tt.title, err)
+			continue
+		}
+
+		titles := []string{}
+		for _, hit := range resp.GetHits() {
+			titles = append(titles, hit.GetArticle().GetTitle())
+			assert.NotEmpty(t, hit.GetSnippets(), tt.title)
+		}
+		assert.ElementsMatch(t, tt.expected, titles, tt.title)
+	}
+
+	// deleted articles are removed from the index
+	_, err = h.DeleteArticle(ctx, &pb.DeleteArticleRequest{Slug: slugs["Cooking pasta"]})
+	assert.NoError(t, err)
+
+	resp, err := h.SearchArticles(context.Background(), &pb.SearchArticlesRequest{Q: "pasta"})
+	if assert.NoError(t, err) {
+		assert.Len(t, resp.GetHits(), 0)
+	}
+
+	// articles written without the handler are picked up by the sync
+	a := model.Article{
+		Title:  "Written elsewhere",
+		Body:   "Synchronized later.",
+		Author: fooUser,
+		Tags:   []model.Tag{{Name: "sync"}},
+		Status: model.ArticlePublished,
+	}
+	if err := h.as.Create(&a); err != nil {
+		t.Fatalf("failed to create article record: %v", err)
+	}
+
+	resp, err = h.SearchArticles(context.Background(), &pb.SearchArticlesRequest{Q: "synchronized"})
+	if assert.NoError(t, err) {
+		assert.Len(t, resp.GetHits(), 0)
+	}
+
+	assert.NoError(t, h.SyncSearchIndex(context.Background()))
+
+	resp, err = h.SearchArticles(context.Background(), &pb.SearchArticlesRequest{Q: "synchronized"})
+	if assert.NoError(t, err) {
+		assert.Len(t, resp.GetHits(), 1)
+	}
+}
+
+func TestSearchProfiles(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+
+	users := []*model.User{
+		{Username: "foo", Email: "foo@example.com", Password: "secret"},
+		{Username: "alice", Email: "alice@example.com", Password: "secret", Bio: "Gopher"},
+		{Username: "alicia", Email: "alicia@example.com", Password: "secret", Bio: "Writes about Rust"},
+		{Username: "alien", Email: "alien@example.com", Password: "secret"},
+		{Username: "alina", Email: "alina@example.com", Password: "secret"},
+	}
+	for _, u := range users {
+		if err := h.us.Create(u); err != nil {
+			t.Fatalf("failed to create initial user record: %v", err)
+		}
+	}
+	fooUser, aliceUser, alienUser, alinaUser := users[0], users[1], users[3], users[4]
+
+	// users created without the handler are picked up by the sync
+	if err := h.SyncSearchIndex(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := h.us.Follow(fooUser, aliceUser); err != nil {
+		t.Fatalf("failed to create follow relationship: %v", err)
+	}
+
+	if err := h.us.Block(alienUser, fooUser); err != nil {
+		t.Fatalf("failed to create block relationship: %v", err)
+	}
+
+	alinaUser.Status = model.StatusSuspended
+	if err := h.us.UpdateStatus(alinaUser); err != nil {
+		t.Fatalf("failed to suspend user: %v", err)
+	}
+	h.indexUser(alinaUser)
+
+	token, err := auth.GenerateToken(fooUser.ID)
+	if err != nil {
+		t.Error(err)
+	}
+	ctx := ctxWithToken(context.Background(), token)
+
+	tests := []struct {
+		title    string
+		ctx      context.Context
+		req      *pb.SearchProfilesRequest
+		expected []string
+		hasError bool
+	}{
+		{
+			"search by username: success",
+			ctx,
+			&pb.SearchProfilesRequest{Q: "alice"},
+			[]string{"alice", "alicia"},
+			false,
+		},
+		{
+			"search by bio: success",
+			ctx,
+			&pb.SearchProfilesRequest{Q: "rust"},
+			[]string{"alicia"},
+			false,
+		},
+		{
+			"autocomplete excludes blocking users: success",
+			ctx,
+			&pb.SearchProfilesRequest{Q: "ali", Autocomplete: true},
+			[]string{"alice", "alicia"},
+			false,
+		},
+		{
+			"autocomplete as guest: success",
+			context.Background(),
+			&pb.SearchProfilesRequest{Q: "ali", Autocomplete: true},
+			[]string{"alice", "alicia", "alien"},
+			false,
+		},
+		{
+			"empty query: failed",
+			ctx,
+			&pb.SearchProfilesRequest{Q: ""},
+			nil,
+			true,
+		},
+		{
+			"negative limit in autocomplete: failed",
+			ctx,
+			&pb.SearchProfilesRequest{Q: "ali", Autocomplete: true, Limit: -1},
+			nil,
+			true,
+		},
+		{
+			"negative offset: failed",
+			ctx,
+			&pb.SearchProfilesRequest{Q: "ali", Offset: -1},
+			nil,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		resp, err := h.SearchProfiles(tt.ctx, tt.req)
+		if tt.hasError {
+			if err == nil {
+				t.Errorf("%q expected to fail, but succeeded.", tt.title)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%q expected to succeed, but failed. %v", tt.title, err)
+			continue
+		}
+
+		usernames := resp.GetUsernames()
+		if !tt.req.GetAutocomplete() {
+			assert.Empty(t, usernames, tt.title)
+			for _, p := range resp.GetProfiles() {
+				usernames = append(usernames, p.GetUsername())
+			}
+		}
+		assert.ElementsMatch(t, tt.expected, usernames, tt.title)
+	}
+
+	resp, err := h.SearchProfiles(ctx, &pb.SearchProfilesRequest{Q: "alice"})
+	if assert.NoError(t, err) && assert.NotEmpty(t, resp.GetProfiles()) {
+		assert.Equal(t, "alice", resp.GetProfiles()[0].GetUsername())
+		assert.True(t, resp.GetProfiles()[0].GetFollowing())
+	}
+}
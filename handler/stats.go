@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/raahii/golang-grpc-realworld-example/auth"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	"github.com/raahii/golang-grpc-realworld-example/policy"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+	"github.com/raahii/golang-grpc-realworld-example/views"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// viewWindow is how long repeated views by the same viewer count as one
+	viewWindow = 30 * time.Minute
+
+	// defaultStatsDays and maxStatsDays are the default and maximum number of
+	// days of daily stats
+	defaultStatsDays = 30
+	maxStatsDays     = 365
+)
+
+// recordView counts a view of the article by the client of the request.
+// Viewers are told apart by user, or by IP address for guests. Views by the
+// author and by bots are not counted.
+func (h *Handler) recordView(ctx context.Context, a *model.Article, currentUser *model.User) {
+	if !a.IsListed() || (currentUser != nil && currentUser.ID == a.UserID) {
+		return
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	// the gateway passes the user agent of browsers in grpcgateway-user-agent
+	ua := firstValue(md, "grpcgateway-user-agent")
+	if ua == "" {
+		ua = firstValue(md, "user-agent")
+	}
+	if views.IsBot(ua) {
+		return
+	}
+
+	var viewer string
+	if currentUser != nil {
+		viewer = fmt.Sprintf("user:%d", currentUser.ID)
+	} else if ip := h.clientIP(ctx, md); ip != "" {
+		viewer = "ip:" + ip
+	} else {
+		return
+	}
+
+	h.views.Record(a.ID, viewer, time.Now())
+}
+
+// clientIP returns the IP address of the client. Requests from the gateway,
+// a trusted proxy on a loopback or private address, carry X-Forwarded-For
+// with the address the gateway was connected from appended last, so the
+// client is the last address, or the one proxyHops before it when the
+// gateway is behind that many reverse proxies. Earlier addresses are set by
+// the client. Other requests are told apart by their peer address.
+func (h *Handler) clientIP(ctx context.Context, md metadata.MD) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !(ip.IsLoopback() || ip.IsPrivate()) {
+		return host
+	}
+
+	// clients can add values through the gateway too, which come first
+	vs := md.Get("x-forwarded-for")
+	if len(vs) == 0 {
+		return host
+	}
+	hops := strings.Split(vs[len(vs)-1], ",")
+	i := len(hops) - 1 - h.proxyHops
+	if i < 0 {
+		i = 0
+	}
+	return strings.TrimSpace(hops[i])
+}
+
+// proxyHops reads $TRUSTED_PROXY_HOPS, the number of reverse proxies in
+// front of the gateway
+func proxyHops() int {
+	n, err := strconv.Atoi(os.Getenv("TRUSTED_PROXY_HOPS"))
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if vs := md.Get(key); len(vs) > 0 {
+		return vs[0]
+	}
+	return ""
+}
+
+// FlushArticleViews writes the buffered views to the database. It is run
+// periodically by the job runner. Views failed to be written are kept for
+// the next run.
+func (h *Handler) FlushArticleViews(ctx context.Context) error {
+	cs := h.views.Take(time.Now())
+	if len(cs) == 0 {
+		return nil
+	}
+
+	stats := make([]model.ArticleDailyStat, 0, len(cs))
+	for _, c := range cs {
+		stats = append(stats, model.ArticleDailyStat{
+			ArticleID: c.ArticleID,
+			Date:      c.Day.Format(model.DateLayout),
+			Views:     c.Views,
+		})
+	}
+
+	if err := h.as.AddArticleViews(stats); err != nil {
+		h.views.Restore(cs)
+		return fmt.Errorf("failed to add article views: %w", err)
+	}
+
+	return nil
+}
+
+// GetArticleStats returns the views, favorites and comments of an article of
+// current user, in total and for each of the last days
+func (h *Handler) GetArticleStats(ctx context.Context, req *pb.GetArticleStatsRequest) (*pb.ArticleStatsResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("get article stats")
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("unauthenticated")
+		return nil, status.Errorf(codes.Unauthenticated, "unauthenticated")
+	}
+
+	currentUser, err := h.us.GetByID(userID)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("current user not found")
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	articleID, err := strconv.Atoi(req.GetSlug())
+	if err != nil {
+		msg := fmt.Sprintf("cannot convert slug (%s) into integer", req.GetSlug())
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, "invalid article id")
+	}
+
+	article, err := h.as.GetByID(uint(articleID))
+	if err != nil {
+		msg := fmt.Sprintf("requested article (slug=%d) not found", articleID)
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.NotFound, "article not found")
+	}
+
+	if !policy.CanViewArticleStats(currentUser, article) {
+		h.logger.Error().Msgf("user(id=%d) attempted to get stats of article(id=%d)", currentUser.ID, article.ID)
+		return nil, status.Error(codes.PermissionDenied, "forbidden")
+	}
+
+	days := int(req.GetDays())
+	if days == 0 {
+		days = defaultStatsDays
+	}
+	if days < 0 || days > maxStatsDays {
+		msg := fmt.Sprintf("days must be between 1 and %d", maxStatsDays)
+		h.logger.Error().Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, msg)
+	}
+
+	// one entry a day, the oldest first, today included
+	first := views.Day(time.Now()).AddDate(0, 0, 1-days)
+	daily := make([]*pb.DailyArticleStats, days)
+	byDate := make(map[string]*pb.DailyArticleStats, days)
+	for i := range daily {
+		date := first.AddDate(0, 0, i).Format(model.DateLayout)
+		daily[i] = &pb.DailyArticleStats{Date: date}
+		byDate[date] = daily[i]
+	}
+
+	stats, err := h.as.GetArticleDailyStats(article, first.Format(model.DateLayout))
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to get daily article stats")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+	for _, st := range stats {
+		if d, ok := byDate[st.Date]; ok {
+			d.Views = st.Views
+			d.Favorites = st.Favorites
+		}
+	}
+
+	ts, err := h.as.GetCommentTimes(article, first)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to get comment times")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+	for _, t := range ts {
+		if d, ok := byDate[views.Day(t).Format(model.DateLayout)]; ok {
+			d.Comments++
+		}
+	}
+
+	total, err := h.as.CountArticleViews(article)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to count article views")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	comments, err := h.as.CountComments(article, nil)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to count comments")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	return &pb.ArticleStatsResponse{
+		Stats: &pb.ArticleStats{
+			Slug:           req.GetSlug(),
+			ViewsCount:     total,
+			FavoritesCount: article.FavoritesCount,
+			CommentsCount:  int32(comments),
+			Daily:          daily,
+		},
+	}, nil
+}
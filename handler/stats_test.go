@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/raahii/golang-grpc-realworld-example/auth"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const browserUserAgent = "Mozilla/5.0 (X11; Linux x86_64; rv:78.0) Gecko/20100101 Firefox/78.0"
+
+func TestArticleStats(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+
+	fooUser := model.User{Username: "foo", Email: "foo@example.com", Password: "secret"}
+	barUser := model.User{Username: "bar", Email: "bar@example.com", Password: "secret"}
+	for _, u := range []*model.User{&fooUser, &barUser} {
+		if err := h.us.Create(u); err != nil {
+			t.Fatalf("failed to create initial user record: %v", err)
+		}
+	}
+
+	ctxs := map[string]context.Context{}
+	for _, u := range []*model.User{&fooUser, &barUser} {
+		token, err := auth.GenerateToken(u.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctxs[u.Username] = metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+			"authorization", "Token "+token,
+			"user-agent", browserUserAgent,
+		))
+	}
+	// guests reach the server through the gateway on loopback, which appends
+	// their address to X-Forwarded-For
+	withPeer := func(addr string) context.Context {
+		tcpAddr, err := net.ResolveTCPAddr("tcp", addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return peer.NewContext(context.Background(), &peer.Peer{Addr: tcpAddr})
+	}
+	guest := func(xff, userAgent string) context.Context {
+		return metadata.NewIncomingContext(withPeer("127.0.0.1:40000"), metadata.Pairs(
+			"grpcgateway-user-agent", userAgent,
+			"x-forwarded-for", xff,
+		))
+	}
+	// direct grpc clients can set any metadata
+	direct := func(addr, xff string) context.Context {
+		return metadata.NewIncomingContext(withPeer(addr), metadata.Pairs(
+			"user-agent", browserUserAgent,
+			"x-forwarded-for", xff,
+		))
+	}
+
+	article := model.Article{Title: "title", Body: "body", Author: fooUser}
+	if err := h.as.Create(&article); err != nil {
+		t.Fatalf("failed to create initial article record: %v", err)
+	}
+	slug := fmt.Sprintf("%d", article.ID)
+
+	for _, ctx := range []context.Context{
+		ctxs["bar"],
+		ctxs["bar"], // within the window
+		ctxs["foo"], // the author
+		guest("203.0.113.1", browserUserAgent),
+		guest("203.0.113.1", browserUserAgent), // within the window
+		guest("198.51.100.1, 203.0.113.1", browserUserAgent), // spoofed by the client
+		direct("192.0.2.1:50000", "198.51.100.2"),
+		direct("192.0.2.1:50001", "198.51.100.3"), // the same peer
+		guest("203.0.113.2", "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)"),
+		guest("203.0.113.3", ""),
+	} {
+		if _, err := h.GetArticle(ctx, &pb.GetArticleRequest{Slug: slug}); err != nil {
+			t.Fatalf("get article expected to succeed, but failed. %v", err)
+		}
+	}
+
+	if err := h.as.AddFavorite(&article, &barUser); err != nil {
+		t.Fatalf("failed to add favorite: %v", err)
+	}
+	// favoriting again or unfavoriting without a favorite is not counted
+	if err := h.as.AddFavorite(&article, &barUser); err != nil {
+		t.Fatalf("failed to add favorite: %v", err)
+	}
+	if err := h.as.DeleteFavorite(&article, &fooUser); err != nil {
+		t.Fatalf("failed to delete favorite: %v", err)
+	}
+	comment := model.Comment{Body: "comment", UserID: barUser.ID, ArticleID: article.ID}
+	if err := h.as.CreateComment(&comment); err != nil {
+		t.Fatalf("failed to create comment: %v", err)
+	}
+
+	// views are counted when they are flushed
+	resp, err := h.GetArticleStats(ctxs["foo"], &pb.GetArticleStatsRequest{Slug: slug})
+	if err != nil {
+		t.Fatalf("get article stats expected to succeed, but failed. %v", err)
+	}
+	assert.EqualValues(t, 0, resp.GetStats().GetViewsCount())
+
+	if err := h.FlushArticleViews(context.Background()); err != nil {
+		t.Fatalf("flush article views expected to succeed, but failed. %v", err)
+	}
+
+	resp, err = h.GetArticleStats(ctxs["foo"], &pb.GetArticleStatsRequest{Slug: slug})
+	if err != nil {
+		t.Fatalf("get article stats expected to succeed, but failed. %v", err)
+	}
+	stats := resp.GetStats()
+	assert.EqualValues(t, 3, stats.GetViewsCount())
+	assert.EqualValues(t, 1, stats.GetFavoritesCount())
+	assert.EqualValues(t, 1, stats.GetCommentsCount())
+	if assert.Len(t, stats.GetDaily(), 30) {
+		today := stats.GetDaily()[29]
+		assert.Equal(t, time.Now().UTC().Format(model.DateLayout), today.GetDate())
+		assert.EqualValues(t, 3, today.GetViews())
+		assert.EqualValues(t, 1, today.GetFavorites())
+		assert.EqualValues(t, 1, today.GetComments())
+		assert.EqualValues(t, 0, stats.GetDaily()[0].GetViews())
+	}
+
+	// later flushes add up
+	if _, err := h.GetArticle(guest("203.0.113.4", browserUserAgent), &pb.GetArticleRequest{Slug: slug}); err != nil {
+		t.Fatalf("get article expected to succeed, but failed. %v", err)
+	}
+	if err := h.FlushArticleViews(context.Background()); err != nil {
+		t.Fatalf("flush article views expected to succeed, but failed. %v", err)
+	}
+
+	resp, err = h.GetArticleStats(ctxs["foo"], &pb.GetArticleStatsRequest{Slug: slug, Days: 7})
+	if err != nil {
+		t.Fatalf("get article stats expected to succeed, but failed. %v", err)
+	}
+	assert.EqualValues(t, 4, resp.GetStats().GetViewsCount())
+	if assert.Len(t, resp.GetStats().GetDaily(), 7) {
+		assert.EqualValues(t, 4, resp.GetStats().GetDaily()[6].GetViews())
+	}
+
+	tests := []struct {
+		title string
+		ctx   context.Context
+		req   *pb.GetArticleStatsRequest
+	}{
+		{
+			"not the author: failed",
+			ctxs["bar"],
+			&pb.GetArticleStatsRequest{Slug: slug},
+		},
+		{
+			"unauthenticated: failed",
+			context.Background(),
+			&pb.GetArticleStatsRequest{Slug: slug},
+		},
+		{
+			"too many days: failed",
+			ctxs["foo"],
+			&pb.GetArticleStatsRequest{Slug: slug, Days: 1000},
+		},
+		{
+			"unknown article: failed",
+			ctxs["foo"],
+			&pb.GetArticleStatsRequest{Slug: "0"},
+		},
+	}
+
+	for _, tt := range tests {
+		if _, err := h.GetArticleStats(tt.ctx, tt.req); err == nil {
+			t.Errorf("%q expected to fail, but succeeded.", tt.title)
+		}
+	}
+}
@@ -25,3 +25,26 @@ func (h *Handler) GetTags(ctx context.Context, req *pb.Empty) (*pb.TagsResponse,
 
 	return &pb.TagsResponse{Tags: tagNames}, nil
 }
+
+// GetTrendingTags returns the tags of trending articles, the most trending first
+func (h *Handler) GetTrendingTags(ctx context.Context, req *pb.GetTrendingTagsRequest) (*pb.TagsResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("get trending tags")
+
+	if err := h.checkPage(req.GetLimit(), 0); err != nil {
+		return nil, err
+	}
+	limitQuery := pageLimit(req.GetLimit())
+
+	tags, err := h.as.GetTrendingTags(limitQuery)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to get trending tags")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	tagNames := make([]string, 0, len(tags))
+	for _, t := range tags {
+		tagNames = append(tagNames, t.Name)
+	}
+
+	return &pb.TagsResponse{Tags: tagNames}, nil
+}
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/raahii/golang-grpc-realworld-example/auth"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CreateAPIToken creates a personal access token of current user
+func (h *Handler) CreateAPIToken(ctx context.Context, req *pb.CreateAPITokenRequest) (*pb.CreateAPITokenResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("create api token")
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		msg := "unauthenticated"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Errorf(codes.Unauthenticated, msg)
+	}
+
+	currentUser, err := h.us.GetByID(userID)
+	if err != nil {
+		msg := "not user found"
+		err = fmt.Errorf("token is valid but the user not found: %w", err)
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.NotFound, msg)
+	}
+
+	if err := h.checkWrite(currentUser, "create api token"); err != nil {
+		return nil, err
+	}
+
+	rt := req.GetApiToken()
+	if rt.GetExpiresInDays() < 0 {
+		msg := "expiresInDays must not be negative"
+		h.logger.Error().Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, msg)
+	}
+
+	var expiresAt *time.Time
+	if days := rt.GetExpiresInDays(); days > 0 {
+		t := time.Now().AddDate(0, 0, int(days))
+		expiresAt = &t
+	}
+
+	token, plain, err := model.NewAPIToken(currentUser.ID, rt.GetName(), rt.GetScopes(), expiresAt)
+	if err != nil {
+		msg := "internal server error"
+		err = fmt.Errorf("failed to generate api token: %w", err)
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, msg)
+	}
+
+	err = token.Validate()
+	if err != nil {
+		err = fmt.Errorf("validation error: %w", err)
+		h.logger.Error().Err(err).Msg("validation error")
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	err = h.us.CreateAPIToken(token)
+	if err != nil {
+		msg := "failed to create api token"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, msg)
+	}
+
+	return &pb.CreateAPITokenResponse{ApiToken: token.ProtoAPIToken(), Token: plain}, nil
+}
+
+// ListAPITokens returns personal access tokens of current user
+func (h *Handler) ListAPITokens(ctx context.Context, req *pb.Empty) (*pb.APITokensResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("list api tokens")
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		msg := "unauthenticated"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Errorf(codes.Unauthenticated, msg)
+	}
+
+	currentUser, err := h.us.GetByID(userID)
+	if err != nil {
+		msg := "not user found"
+		err = fmt.Errorf("token is valid but the user not found: %w", err)
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.NotFound, msg)
+	}
+
+	tokens, err := h.us.GetAPITokens(currentUser)
+	if err != nil {
+		msg := "failed to get api tokens"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, msg)
+	}
+
+	pts := make([]*pb.APIToken, 0, len(tokens))
+	for _, t := range tokens {
+		pts = append(pts, t.ProtoAPIToken())
+	}
+
+	return &pb.APITokensResponse{ApiTokens: pts}, nil
+}
+
+// RevokeAPIToken revokes a personal access token of current user
+func (h *Handler) RevokeAPIToken(ctx context.Context, req *pb.RevokeAPITokenRequest) (*pb.Empty, error) {
+	h.logger.Info().Interface("req", req).Msg("revoke api token")
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		msg := "unauthenticated"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Errorf(codes.Unauthenticated, msg)
+	}
+
+	currentUser, err := h.us.GetByID(userID)
+	if err != nil {
+		msg := "not user found"
+		err = fmt.Errorf("token is valid but the user not found: %w", err)
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.NotFound, msg)
+	}
+
+	tokenID, err := strconv.Atoi(req.GetId())
+	if err != nil {
+		msg := fmt.Sprintf("cannot convert id (%s) into integer", req.GetId())
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, "invalid token id")
+	}
+
+	token, err := h.us.GetAPITokenByID(uint(tokenID))
+	if err != nil {
+		msg := fmt.Sprintf("requested api token (id=%d) not found", tokenID)
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.NotFound, "token not found")
+	}
+
+	if token.UserID != currentUser.ID {
+		msg := fmt.Sprintf("user(id=%d) attempted to revoke other user's api token(id=%d)",
+			currentUser.ID, token.ID)
+		h.logger.Error().Msg(msg)
+		return nil, status.Error(codes.NotFound, "token not found")
+	}
+
+	err = h.us.RevokeAPIToken(token)
+	if err != nil {
+		msg := "failed to revoke api token"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, msg)
+	}
+
+	return &pb.Empty{}, nil
+}
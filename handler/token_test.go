@@ -0,0 +1,373 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/raahii/golang-grpc-realworld-example/auth"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateAPIToken(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+
+	fooUser := model.User{
+		Username: "foo",
+		Email:    "foo@example.com",
+		Password: "secret",
+	}
+
+	if err := h.us.Create(&fooUser); err != nil {
+		t.Fatalf("failed to create initial user record: %v", err)
+	}
+
+	tests := []struct {
+		title    string
+		reqUser  *model.User
+		req      *pb.CreateAPITokenRequest
+		hasError bool
+	}{
+		{
+			"create read-only token: success",
+			&fooUser,
+			&pb.CreateAPITokenRequest{
+				ApiToken: &pb.CreateAPITokenRequest_APIToken{
+					Name:   "ci",
+					Scopes: []string{auth.ScopeRead},
+				},
+			},
+			false,
+		},
+		{
+			"create expiring token: success",
+			&fooUser,
+			&pb.CreateAPITokenRequest{
+				ApiToken: &pb.CreateAPITokenRequest_APIToken{
+					Name:          "script",
+					Scopes:        []string{auth.ScopeRead, auth.ScopeArticlesWrite},
+					ExpiresInDays: 30,
+				},
+			},
+			false,
+		},
+		{
+			"create token without name: failed",
+			&fooUser,
+			&pb.CreateAPITokenRequest{
+				ApiToken: &pb.CreateAPITokenRequest_APIToken{
+					Scopes: []string{auth.ScopeRead},
+				},
+			},
+			true,
+		},
+		{
+			"create token with unknown scope: failed",
+			&fooUser,
+			&pb.CreateAPITokenRequest{
+				ApiToken: &pb.CreateAPITokenRequest_APIToken{
+					Name:   "admin",
+					Scopes: []string{"admin"},
+				},
+			},
+			true,
+		},
+		{
+			"create token from unauthenticated user: failed",
+			nil,
+			&pb.CreateAPITokenRequest{
+				ApiToken: &pb.CreateAPITokenRequest_APIToken{
+					Name:   "ci",
+					Scopes: []string{auth.ScopeRead},
+				},
+			},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		ctx := context.Background()
+		if tt.reqUser != nil {
+			token, err := auth.GenerateToken(tt.reqUser.ID)
+			if err != nil {
+				t.Error(err)
+			}
+
+			ctx = ctxWithToken(ctx, token)
+		}
+
+		resp, err := h.CreateAPIToken(ctx, tt.req)
+		if tt.hasError {
+			if err == nil {
+				t.Errorf("%q expected to fail, but succeeded.", tt.title)
+				t.FailNow()
+			}
+			continue
+		}
+
+		if !tt.hasError && err != nil {
+			t.Errorf("%q expected to succeed, but failed. %v", tt.title, err)
+			t.FailNow()
+		}
+
+		got := resp.GetApiToken()
+		assert.NotEmpty(t, got.GetId())
+		assert.Equal(t, tt.req.GetApiToken().GetName(), got.GetName())
+		assert.Equal(t, tt.req.GetApiToken().GetScopes(), got.GetScopes())
+		assert.True(t, strings.HasPrefix(resp.GetToken(), auth.APITokenPrefix))
+		assert.True(t, strings.HasPrefix(resp.GetToken(), got.GetPrefix()))
+		assert.Empty(t, got.GetLastUsedAt())
+		assert.Equal(t, tt.req.GetApiToken().GetExpiresInDays() > 0, got.GetExpiresAt() != "")
+	}
+}
+
+func TestListAPITokens(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+
+	fooUser := model.User{
+		Username: "foo",
+		Email:    "foo@example.com",
+		Password: "secret",
+	}
+
+	barUser := model.User{
+		Username: "bar",
+		Email:    "bar@example.com",
+		Password: "secret",
+	}
+
+	for _, u := range []*model.User{&fooUser, &barUser} {
+		if err := h.us.Create(u); err != nil {
+			t.Fatalf("failed to create initial user record: %v", err)
+		}
+	}
+
+	for i, u := range []*model.User{&fooUser, &fooUser, &barUser} {
+		m, _, err := model.NewAPIToken(u.ID, fmt.Sprintf("token%d", i), []string{auth.ScopeRead}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := h.us.CreateAPIToken(m); err != nil {
+			t.Fatalf("failed to create initial api token: %v", err)
+		}
+	}
+
+	token, err := auth.GenerateToken(fooUser.ID)
+	if err != nil {
+		t.Error(err)
+	}
+
+	resp, err := h.ListAPITokens(ctxWithToken(context.Background(), token), &pb.Empty{})
+	if err != nil {
+		t.Fatalf("list api tokens expected to succeed, but failed. %v", err)
+	}
+
+	assert.Len(t, resp.GetApiTokens(), 2)
+	for _, got := range resp.GetApiTokens() {
+		assert.NotEqual(t, "token2", got.GetName())
+	}
+}
+
+func TestRevokeAPIToken(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+
+	fooUser := model.User{
+		Username: "foo",
+		Email:    "foo@example.com",
+		Password: "secret",
+	}
+
+	barUser := model.User{
+		Username: "bar",
+		Email:    "bar@example.com",
+		Password: "secret",
+	}
+
+	for _, u := range []*model.User{&fooUser, &barUser} {
+		if err := h.us.Create(u); err != nil {
+			t.Fatalf("failed to create initial user record: %v", err)
+		}
+	}
+
+	apiToken, plain, err := model.NewAPIToken(fooUser.ID, "ci", []string{auth.ScopeRead}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.us.CreateAPIToken(apiToken); err != nil {
+		t.Fatalf("failed to create initial api token: %v", err)
+	}
+
+	tests := []struct {
+		title    string
+		reqUser  *model.User
+		req      *pb.RevokeAPITokenRequest
+		hasError bool
+	}{
+		{
+			"revoke token of other user: failed",
+			&barUser,
+			&pb.RevokeAPITokenRequest{Id: fmt.Sprintf("%d", apiToken.ID)},
+			true,
+		},
+		{
+			"revoke token with invalid id: failed",
+			&fooUser,
+			&pb.RevokeAPITokenRequest{Id: "123456"},
+			true,
+		},
+		{
+			"revoke token: success",
+			&fooUser,
+			&pb.RevokeAPITokenRequest{Id: fmt.Sprintf("%d", apiToken.ID)},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		token, err := auth.GenerateToken(tt.reqUser.ID)
+		if err != nil {
+			t.Error(err)
+		}
+
+		_, err = h.RevokeAPIToken(ctxWithToken(context.Background(), token), tt.req)
+		if tt.hasError {
+			if err == nil {
+				t.Errorf("%q expected to fail, but succeeded.", tt.title)
+				t.FailNow()
+			}
+			continue
+		}
+
+		if !tt.hasError && err != nil {
+			t.Errorf("%q expected to succeed, but failed. %v", tt.title, err)
+			t.FailNow()
+		}
+	}
+
+	// revoked token is no longer accepted
+	ctx := ctxWithMethod(ctxWithToken(context.Background(), plain), "/user.Users/ShowProfile")
+	_, err = auth.GetUserID(ctx)
+	assert.Error(t, err)
+}
+
+func TestAPITokenAuthentication(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+
+	fooUser := model.User{
+		Username: "foo",
+		Email:    "foo@example.com",
+		Password: "secret",
+	}
+
+	if err := h.us.Create(&fooUser); err != nil {
+		t.Fatalf("failed to create initial user record: %v", err)
+	}
+
+	newToken := func(scopes []string, expiresAt *time.Time) string {
+		m, plain, err := model.NewAPIToken(fooUser.ID, "token", scopes, expiresAt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := h.us.CreateAPIToken(m); err != nil {
+			t.Fatalf("failed to create initial api token: %v", err)
+		}
+		return plain
+	}
+
+	readOnly := newToken([]string{auth.ScopeRead}, nil)
+	writer := newToken([]string{auth.ScopeArticlesWrite}, nil)
+	expired := newToken([]string{auth.ScopeRead}, &time.Time{})
+
+	tests := []struct {
+		title    string
+		token    string
+		method   string
+		hasError bool
+	}{
+		{
+			"read with read-only token: success",
+			readOnly,
+			"/article.Articles/GetArticles",
+			false,
+		},
+		{
+			"write with read-only token: failed",
+			readOnly,
+			"/article.Articles/CreateArticle",
+			true,
+		},
+		{
+			"write with articles:write token: success",
+			writer,
+			"/article.Articles/CreateArticle",
+			false,
+		},
+		{
+			"comment with articles:write token: failed",
+			writer,
+			"/article.Articles/CreateComment",
+			true,
+		},
+		{
+			"manage tokens with api token: failed",
+			readOnly,
+			"/user.Users/CreateAPIToken",
+			true,
+		},
+		{
+			"read with expired token: failed",
+			expired,
+			"/article.Articles/GetArticles",
+			true,
+		},
+		{
+			"read with unknown token: failed",
+			auth.APITokenPrefix + "unknown",
+			"/article.Articles/GetArticles",
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		ctx := ctxWithMethod(ctxWithToken(context.Background(), tt.token), tt.method)
+		userID, err := auth.GetUserID(ctx)
+		if tt.hasError {
+			if err == nil {
+				t.Errorf("%q expected to fail, but succeeded.", tt.title)
+				t.FailNow()
+			}
+			continue
+		}
+
+		if !tt.hasError && err != nil {
+			t.Errorf("%q expected to succeed, but failed. %v", tt.title, err)
+			t.FailNow()
+		}
+
+		assert.Equal(t, fooUser.ID, userID)
+	}
+
+	// last used timestamp is recorded
+	token, err := auth.GenerateToken(fooUser.ID)
+	if err != nil {
+		t.Error(err)
+	}
+
+	resp, err := h.ListAPITokens(ctxWithToken(context.Background(), token), &pb.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pt := range resp.GetApiTokens() {
+		if strings.HasPrefix(readOnly, pt.GetPrefix()) {
+			assert.NotEmpty(t, pt.GetLastUsedAt())
+		}
+	}
+}
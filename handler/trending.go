@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	"github.com/raahii/golang-grpc-realworld-example/trending"
+	"github.com/raahii/golang-grpc-realworld-example/views"
+)
+
+// RankTrendingArticles recomputes the trending scores of articles from their
+// views, favorites and comments in the trending window. It is run
+// periodically by the job runner.
+func (h *Handler) RankTrendingArticles(ctx context.Context) error {
+	now := time.Now()
+	since := now.Add(-trending.Window)
+	scores := trending.NewScores(now)
+
+	stats, err := h.as.GetDailyStatsSince(views.Day(since).Format(model.DateLayout))
+	if err != nil {
+		return fmt.Errorf("failed to get daily article stats: %w", err)
+	}
+	for _, st := range stats {
+		day, err := time.Parse(model.DateLayout, st.Date)
+		if err != nil {
+			return fmt.Errorf("invalid date of daily article stats (%s): %w", st.Date, err)
+		}
+		scores.AddDaily(st.ArticleID, day, st.Views, st.Favorites)
+	}
+
+	cs, err := h.as.GetCommentsSince(since)
+	if err != nil {
+		return fmt.Errorf("failed to get recent comments: %w", err)
+	}
+	for _, c := range cs {
+		scores.AddComment(c.ArticleID, c.CreatedAt)
+	}
+
+	ranked := scores.Ranked()
+	trends := make([]model.ArticleTrend, 0, len(ranked))
+	for _, s := range ranked {
+		trends = append(trends, model.ArticleTrend{ArticleID: s.ArticleID, Score: s.Value})
+	}
+
+	if err := h.as.ReplaceArticleTrends(trends); err != nil {
+		return fmt.Errorf("failed to replace trending scores: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrending(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+
+	fooUser := model.User{Username: "foo", Email: "foo@example.com", Password: "secret"}
+	barUser := model.User{Username: "bar", Email: "bar@example.com", Password: "secret"}
+	bazUser := model.User{Username: "baz", Email: "baz@example.com", Password: "secret"}
+	for _, u := range []*model.User{&fooUser, &barUser, &bazUser} {
+		if err := h.us.Create(u); err != nil {
+			t.Fatalf("failed to create initial user record: %v", err)
+		}
+	}
+
+	old := model.Article{Title: "old", Body: "body", Author: fooUser,
+		Tags: []model.Tag{{Name: "old"}}}
+	discussed := model.Article{Title: "discussed", Body: "body", Author: fooUser,
+		Tags: []model.Tag{{Name: "dragons"}, {Name: "shared"}}}
+	for _, a := range []*model.Article{&old, &discussed} {
+		if err := h.as.Create(a); err != nil {
+			t.Fatalf("failed to create initial article record: %v", err)
+		}
+	}
+	viewed := model.Article{Title: "viewed", Body: "body", Author: fooUser,
+		Tags: []model.Tag{{Name: "training"}, discussed.Tags[1]}}
+	if err := h.as.Create(&viewed); err != nil {
+		t.Fatalf("failed to create initial article record: %v", err)
+	}
+
+	for _, f := range []struct {
+		a *model.Article
+		u *model.User
+	}{
+		{&old, &barUser},
+		{&old, &bazUser},
+		{&discussed, &barUser},
+		{&discussed, &barUser}, // favoriting again does not count
+	} {
+		if err := h.as.AddFavorite(f.a, f.u); err != nil {
+			t.Fatalf("failed to add favorite: %v", err)
+		}
+	}
+
+	for _, c := range []model.Comment{
+		{Body: "comment", UserID: barUser.ID, ArticleID: discussed.ID},
+		{Body: "comment", UserID: bazUser.ID, ArticleID: discussed.ID},
+		{Body: "comment", UserID: barUser.ID, ArticleID: viewed.ID},
+	} {
+		if err := h.as.CreateComment(&c); err != nil {
+			t.Fatalf("failed to create comment: %v", err)
+		}
+	}
+
+	err := h.as.AddArticleViews([]model.ArticleDailyStat{
+		{ArticleID: viewed.ID, Date: time.Now().UTC().Format(model.DateLayout), Views: 100},
+	})
+	if err != nil {
+		t.Fatalf("failed to add article views: %v", err)
+	}
+
+	if err := h.RankTrendingArticles(context.Background()); err != nil {
+		t.Fatalf("rank trending articles expected to succeed, but failed. %v", err)
+	}
+
+	tests := []struct {
+		title    string
+		req      *pb.GetArticlesRequest
+		expected []string
+	}{
+		{
+			"newest first",
+			&pb.GetArticlesRequest{Sort: model.SortNewest},
+			[]string{"viewed", "discussed", "old"},
+		},
+		{
+			"most favorited first",
+			&pb.GetArticlesRequest{Sort: model.SortFavorited},
+			[]string{"old", "discussed", "viewed"},
+		},
+		{
+			"most commented first",
+			&pb.GetArticlesRequest{Sort: model.SortCommented},
+			[]string{"discussed", "viewed", "old"},
+		},
+		{
+			"trending first",
+			&pb.GetArticlesRequest{Sort: model.SortTrending},
+			[]string{"viewed", "discussed", "old"},
+		},
+		{
+			"trending with tag and limit",
+			&pb.GetArticlesRequest{Sort: model.SortTrending, Tag: "shared", Limit: 1},
+			[]string{"viewed"},
+		},
+	}
+
+	for _, tt := range tests {
+		resp, err := h.GetArticles(context.Background(), tt.req)
+		if err != nil {
+			t.Errorf("%q expected to succeed, but failed. %v", tt.title, err)
+			continue
+		}
+
+		titles := []string{}
+		for _, a := range resp.GetArticles() {
+			titles = append(titles, a.GetTitle())
+		}
+		assert.Equal(t, tt.expected, titles, tt.title)
+	}
+
+	if _, err := h.GetArticles(context.Background(), &pb.GetArticlesRequest{Sort: "random"}); err == nil {
+		t.Errorf("%q expected to fail, but succeeded.", "unknown sort")
+	}
+
+	// shared is the tag of both viewed and discussed
+	resp, err := h.GetTrendingTags(context.Background(), &pb.GetTrendingTagsRequest{})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"shared", "training", "dragons", "old"}, resp.GetTags())
+	}
+
+	resp, err = h.GetTrendingTags(context.Background(), &pb.GetTrendingTagsRequest{Limit: 2})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"shared", "training"}, resp.GetTags())
+	}
+
+	_, err = h.GetTrendingTags(context.Background(), &pb.GetTrendingTagsRequest{Limit: -1})
+	assert.Error(t, err)
+
+	// articles without recent activity drop out of the ranking
+	if err := h.as.DeleteFavorite(&old, &barUser); err != nil {
+		t.Fatalf("failed to delete favorite: %v", err)
+	}
+	if err := h.as.DeleteFavorite(&old, &bazUser); err != nil {
+		t.Fatalf("failed to delete favorite: %v", err)
+	}
+	if err := h.RankTrendingArticles(context.Background()); err != nil {
+		t.Fatalf("rank trending articles expected to succeed, but failed. %v", err)
+	}
+
+	resp, err = h.GetTrendingTags(context.Background(), &pb.GetTrendingTagsRequest{})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"shared", "training", "dragons"}, resp.GetTags())
+	}
+}
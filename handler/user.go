@@ -3,9 +3,13 @@ package handler
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/jinzhu/gorm"
 	"github.com/raahii/golang-grpc-realworld-example/auth"
 	"github.com/raahii/golang-grpc-realworld-example/model"
+	"github.com/raahii/golang-grpc-realworld-example/oidc"
+	"github.com/raahii/golang-grpc-realworld-example/policy"
 	pb "github.com/raahii/golang-grpc-realworld-example/proto"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -28,6 +32,11 @@ func (h *Handler) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (*pb.
 		return nil, status.Error(codes.InvalidArgument, "invalid email or password")
 	}
 
+	if !policy.CanSignIn(u) {
+		h.logger.Error().Msgf("banned user(id=%d) attempted to login", u.ID)
+		return nil, status.Error(codes.PermissionDenied, "your account is banned")
+	}
+
 	token, err := auth.GenerateToken(u.ID)
 	if err != nil {
 		msg := "internal server error"
@@ -39,6 +48,139 @@ func (h *Handler) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (*pb.
 	return &pb.UserResponse{User: u.ProtoUser(token)}, nil
 }
 
+// LoginOIDC signs in a user with an ID token of the external identity provider.
+// The identity is linked to the user having the same verified email, or to a
+// newly created user on first login.
+func (h *Handler) LoginOIDC(ctx context.Context, req *pb.LoginOIDCRequest) (*pb.UserResponse, error) {
+	h.logger.Info().Msg("login user with oidc")
+
+	if h.idp == nil {
+		msg := "external login is not configured"
+		h.logger.Error().Msg(msg)
+		return nil, status.Error(codes.Unimplemented, msg)
+	}
+
+	claims, err := h.idp.Verify(ctx, req.GetIdToken(), req.GetNonce())
+	if err != nil {
+		msg := "invalid id token"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Unauthenticated, msg)
+	}
+
+	u, err := h.us.GetByIdentity(claims.Issuer, claims.Subject)
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		msg := "internal server error"
+		err = fmt.Errorf("failed to get user by identity: %w", err)
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, msg)
+	}
+
+	if u == nil {
+		u, err = h.linkIdentity(claims)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !policy.CanSignIn(u) {
+		h.logger.Error().Msgf("banned user(id=%d) attempted to login", u.ID)
+		return nil, status.Error(codes.PermissionDenied, "your account is banned")
+	}
+
+	token, err := auth.GenerateToken(u.ID)
+	if err != nil {
+		msg := "internal server error"
+		err := fmt.Errorf("Failed to create token. %w", err)
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, msg)
+	}
+
+	return &pb.UserResponse{User: u.ProtoUser(token)}, nil
+}
+
+// linkIdentity links a new external identity to the user with the same email,
+// creating the user if no one has the email
+func (h *Handler) linkIdentity(claims *oidc.Claims) (*model.User, error) {
+	if claims.Email == "" || !claims.EmailVerified {
+		msg := "email is not verified"
+		h.logger.Error().Str("subject", claims.Subject).Msg(msg)
+		return nil, status.Error(codes.PermissionDenied, msg)
+	}
+
+	identity := model.Identity{
+		Provider: claims.Issuer,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	}
+
+	u, err := h.us.GetByEmail(claims.Email)
+	if err == nil {
+		identity.UserID = u.ID
+		if err := h.us.CreateIdentity(&identity); err != nil {
+			msg := "internal server error"
+			err = fmt.Errorf("failed to link identity: %w", err)
+			h.logger.Error().Err(err).Msg(msg)
+			return nil, status.Error(codes.Aborted, msg)
+		}
+		return u, nil
+	}
+
+	if !gorm.IsRecordNotFoundError(err) {
+		msg := "internal server error"
+		err = fmt.Errorf("failed to get user by email: %w", err)
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, msg)
+	}
+
+	username, err := h.us.AllocateUsername(
+		model.UsernameCandidate(claims.PreferredUsername, claims.Email))
+	if err != nil {
+		msg := "internal server error"
+		err = fmt.Errorf("failed to allocate username: %w", err)
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, msg)
+	}
+
+	// the user signs in only through the provider, the password is never revealed
+	password, err := oidc.RandomString(32)
+	if err != nil {
+		msg := "internal server error"
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, msg)
+	}
+
+	u = &model.User{
+		Username: username,
+		Email:    claims.Email,
+		Password: password,
+		Image:    claims.Picture,
+	}
+
+	if err := u.Validate(); err != nil {
+		msg := "validation error"
+		err = fmt.Errorf("validation error: %w", err)
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, msg)
+	}
+
+	if err := u.HashPassword(); err != nil {
+		msg := "internal server error"
+		err := fmt.Errorf("Failed to hash password, %w", err)
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Aborted, msg)
+	}
+
+	if err := h.us.CreateWithIdentity(u, &identity); err != nil {
+		msg := "internal server error"
+		err := fmt.Errorf("Failed to create user. %w", err)
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.Canceled, msg)
+	}
+	h.indexUser(u)
+
+	return u, nil
+}
+
 // CreateUser registers a new user
 func (h *Handler) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.UserResponse, error) {
 	h.logger.Info().Interface("req", req).Msg("create user")
@@ -72,6 +214,7 @@ func (h *Handler) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*p
 		h.logger.Error().Err(err).Msg(msg)
 		return nil, status.Error(codes.Canceled, msg)
 	}
+	h.indexUser(&u)
 
 	token, err := auth.GenerateToken(u.ID)
 	if err != nil {
@@ -103,6 +246,11 @@ func (h *Handler) CurrentUser(ctx context.Context, req *pb.Empty) (*pb.UserRespo
 		return nil, status.Error(codes.NotFound, msg)
 	}
 
+	if !policy.CanSignIn(u) {
+		h.logger.Error().Msgf("banned user(id=%d) attempted to refresh token", u.ID)
+		return nil, status.Error(codes.PermissionDenied, "your account is banned")
+	}
+
 	token, err := auth.GenerateToken(u.ID)
 	if err != nil {
 		msg := "internal server error"
@@ -133,6 +281,10 @@ func (h *Handler) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*p
 		return nil, status.Error(codes.NotFound, msg)
 	}
 
+	if err := h.checkWrite(u, "update user"); err != nil {
+		return nil, err
+	}
+
 	// update non zero-valu fields eonly
 	username := req.GetUser().GetUsername()
 	if username != "" {
@@ -159,6 +311,14 @@ func (h *Handler) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*p
 		u.Bio = bio
 	}
 
+	// the first digest covers the period starting now
+	digest := req.GetUser().GetDigest()
+	if digest != "" && digest != u.DigestFrequency {
+		now := time.Now()
+		u.DigestFrequency = digest
+		u.DigestSentAt = &now
+	}
+
 	// validation
 	err = u.Validate()
 	if err != nil {
@@ -184,6 +344,7 @@ func (h *Handler) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*p
 		h.logger.Error().Err(err).Msg(msg)
 		return nil, status.Error(codes.InvalidArgument, msg)
 	}
+	h.indexUser(u)
 
 	token, err := auth.GenerateToken(u.ID)
 	if err != nil {
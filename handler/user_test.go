@@ -7,6 +7,8 @@ import (
 
 	"github.com/raahii/golang-grpc-realworld-example/auth"
 	"github.com/raahii/golang-grpc-realworld-example/model"
+	"github.com/raahii/golang-grpc-realworld-example/oidc"
+	"github.com/raahii/golang-grpc-realworld-example/oidc/oidctest"
 	pb "github.com/raahii/golang-grpc-realworld-example/proto"
 	"github.com/stretchr/testify/assert"
 )
@@ -217,6 +219,124 @@ func TestLoginUser(t *testing.T) {
 	}
 }
 
+func TestLoginOIDC(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+
+	idp := oidctest.NewProvider("realworld")
+	defer idp.Close()
+	h.idp = oidc.NewProvider(idp.URL, idp.ClientID, "", "")
+
+	fooUser := model.User{
+		Username: "foo",
+		Email:    "foo@example.com",
+		Password: "secret",
+	}
+
+	if err := h.us.Create(&fooUser); err != nil {
+		t.Fatalf("failed to create initial user record: %v", err)
+	}
+
+	tests := []struct {
+		title    string
+		req      *pb.LoginOIDCRequest
+		username string
+		email    string
+		hasError bool
+	}{
+		{
+			"login with verified email of fooUser: linked to fooUser",
+			&pb.LoginOIDCRequest{
+				IdToken: idp.IDToken(oidctest.User{
+					Subject:       "1",
+					Email:         "foo@example.com",
+					EmailVerified: true,
+				}, "n0nce", nil),
+				Nonce: "n0nce",
+			},
+			"foo",
+			"foo@example.com",
+			false,
+		},
+		{
+			"login with linked identity: success",
+			&pb.LoginOIDCRequest{
+				IdToken: idp.IDToken(oidctest.User{Subject: "1"}, "n0nce", nil),
+				Nonce:   "n0nce",
+			},
+			"foo",
+			"foo@example.com",
+			false,
+		},
+		{
+			"first login with taken username: username allocated",
+			&pb.LoginOIDCRequest{
+				IdToken: idp.IDToken(oidctest.User{
+					Subject:           "2",
+					Email:             "another.foo@example.com",
+					EmailVerified:     true,
+					PreferredUsername: "foo",
+				}, "n0nce", nil),
+				Nonce: "n0nce",
+			},
+			"foo1",
+			"another.foo@example.com",
+			false,
+		},
+		{
+			"first login without preferred username: username from email",
+			&pb.LoginOIDCRequest{
+				IdToken: idp.IDToken(oidctest.User{
+					Subject:       "3",
+					Email:         "bar.baz@example.com",
+					EmailVerified: true,
+				}, "n0nce", nil),
+				Nonce: "n0nce",
+			},
+			"barbaz",
+			"bar.baz@example.com",
+			false,
+		},
+		{
+			"login with unverified email: failed",
+			&pb.LoginOIDCRequest{
+				IdToken: idp.IDToken(oidctest.User{
+					Subject: "4",
+					Email:   "foo@example.com",
+				}, "n0nce", nil),
+				Nonce: "n0nce",
+			},
+			"",
+			"",
+			true,
+		},
+		{
+			"login with wrong nonce: failed",
+			&pb.LoginOIDCRequest{
+				IdToken: idp.IDToken(oidctest.User{Subject: "1"}, "n0nce", nil),
+				Nonce:   "other",
+			},
+			"",
+			"",
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		resp, err := h.LoginOIDC(context.Background(), tt.req)
+		if (err != nil) != tt.hasError {
+			t.Errorf("%q hasError %t, but got error: %v.", tt.title, tt.hasError, err)
+			t.FailNow()
+		}
+
+		if !tt.hasError {
+			assert.Equal(t, tt.username, resp.GetUser().GetUsername(), tt.title)
+			assert.Equal(t, tt.email, resp.GetUser().GetEmail(), tt.title)
+			assert.NotEmpty(t, resp.GetUser().GetToken(), tt.title)
+		}
+	}
+}
+
 func TestCurrentUser(t *testing.T) {
 	h, cleaner := setUp(t)
 	defer cleaner(t)
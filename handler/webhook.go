@@ -0,0 +1,386 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/raahii/golang-grpc-realworld-example/auth"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	"github.com/raahii/golang-grpc-realworld-example/policy"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+	"github.com/raahii/golang-grpc-realworld-example/webhook"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// webhookTimeout is how long a receiver may take to respond
+	webhookTimeout = 10 * time.Second
+	// deliveryLease is how long claimed deliveries are hidden from other servers
+	deliveryLease = time.Minute
+	// deliveryBatchSize is the number of deliveries claimed at once
+	deliveryBatchSize = 100
+)
+
+// payloadMarshaler encodes webhook payloads the same way the REST API does
+var payloadMarshaler = jsonpb.Marshaler{OrigName: true, EmitDefaults: true}
+
+// CreateWebhook registers a webhook of current user. The signing secret is
+// returned only here.
+func (h *Handler) CreateWebhook(ctx context.Context, req *pb.CreateWebhookRequest) (*pb.CreateWebhookResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("create webhook")
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("unauthenticated")
+		return nil, status.Errorf(codes.Unauthenticated, "unauthenticated")
+	}
+
+	currentUser, err := h.us.GetByID(userID)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("current user not found")
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	if err := h.checkWrite(currentUser, "create webhook"); err != nil {
+		return nil, err
+	}
+
+	rw := req.GetWebhook()
+	if rw.GetGlobal() && !policy.IsAdmin(currentUser) {
+		h.logger.Error().Msgf("user(id=%d) attempted to create a global webhook", currentUser.ID)
+		return nil, status.Error(codes.PermissionDenied, "only admins can create global webhooks")
+	}
+
+	secret, err := webhook.GenerateSecret()
+	if err != nil {
+		err = fmt.Errorf("failed to generate webhook secret: %w", err)
+		h.logger.Error().Err(err).Msg("internal server error")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	w := model.Webhook{
+		UserID: currentUser.ID,
+		URL:    rw.GetUrl(),
+		Secret: secret,
+		Events: strings.Join(rw.GetEvents(), ","),
+		Global: rw.GetGlobal(),
+	}
+
+	err = w.Validate()
+	if err != nil {
+		err = fmt.Errorf("validation error: %w", err)
+		h.logger.Error().Err(err).Msg("validation error")
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := h.us.CreateWebhook(&w); err != nil {
+		h.logger.Error().Err(err).Msg("failed to create webhook")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	return &pb.CreateWebhookResponse{Webhook: w.ProtoWebhook(), Secret: secret}, nil
+}
+
+// ListWebhooks returns webhooks of current user
+func (h *Handler) ListWebhooks(ctx context.Context, req *pb.Empty) (*pb.WebhooksResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("list webhooks")
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("unauthenticated")
+		return nil, status.Errorf(codes.Unauthenticated, "unauthenticated")
+	}
+
+	currentUser, err := h.us.GetByID(userID)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("current user not found")
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	ws, err := h.us.GetWebhooks(currentUser)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to get webhooks")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	pws := make([]*pb.Webhook, 0, len(ws))
+	for i := range ws {
+		pws = append(pws, ws[i].ProtoWebhook())
+	}
+
+	return &pb.WebhooksResponse{Webhooks: pws}, nil
+}
+
+// DeleteWebhook deletes a webhook of current user. Its pending deliveries are given up.
+func (h *Handler) DeleteWebhook(ctx context.Context, req *pb.DeleteWebhookRequest) (*pb.Empty, error) {
+	h.logger.Info().Interface("req", req).Msg("delete webhook")
+
+	currentUser, w, err := h.ownWebhook(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.checkWrite(currentUser, "delete webhook"); err != nil {
+		return nil, err
+	}
+
+	if err := h.us.DeleteWebhook(w); err != nil {
+		h.logger.Error().Err(err).Msg("failed to delete webhook")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	return &pb.Empty{}, nil
+}
+
+// ListWebhookDeliveries returns deliveries of a webhook of current user, the newest first
+func (h *Handler) ListWebhookDeliveries(ctx context.Context, req *pb.ListWebhookDeliveriesRequest) (*pb.WebhookDeliveriesResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("list webhook deliveries")
+
+	_, w, err := h.ownWebhook(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.GetStatus() {
+	case "", model.DeliveryPending, model.DeliverySucceeded, model.DeliveryFailed:
+	default:
+		msg := fmt.Sprintf("unknown delivery status %q", req.GetStatus())
+		h.logger.Error().Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, msg)
+	}
+
+	if err := h.checkPage(req.GetLimit(), req.GetOffset()); err != nil {
+		return nil, err
+	}
+	limit := pageLimit(req.GetLimit())
+
+	ds, count, err := h.us.GetWebhookDeliveries(w, req.GetStatus(), limit, req.GetOffset())
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to get webhook deliveries")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	pds := make([]*pb.WebhookDelivery, 0, len(ds))
+	for i := range ds {
+		pds = append(pds, ds[i].ProtoWebhookDelivery())
+	}
+
+	return &pb.WebhookDeliveriesResponse{Deliveries: pds, DeliveriesCount: int32(count)}, nil
+}
+
+// ReplayWebhookDelivery queues a given up delivery again
+func (h *Handler) ReplayWebhookDelivery(ctx context.Context, req *pb.ReplayWebhookDeliveryRequest) (*pb.WebhookDeliveryResponse, error) {
+	h.logger.Info().Interface("req", req).Msg("replay webhook delivery")
+
+	currentUser, w, err := h.ownWebhook(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.checkWrite(currentUser, "replay webhook delivery"); err != nil {
+		return nil, err
+	}
+
+	deliveryID, err := strconv.Atoi(req.GetDeliveryId())
+	if err != nil {
+		msg := fmt.Sprintf("cannot convert delivery id (%s) into integer", req.GetDeliveryId())
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, status.Error(codes.InvalidArgument, "invalid delivery id")
+	}
+
+	d, err := h.us.GetWebhookDeliveryByID(uint(deliveryID))
+	if err != nil || d.WebhookID != w.ID {
+		h.logger.Error().Err(err).Msgf("delivery(id=%d) not found", deliveryID)
+		return nil, status.Error(codes.NotFound, "delivery not found")
+	}
+
+	if d.Status != model.DeliveryFailed {
+		msg := "only failed deliveries can be replayed"
+		h.logger.Error().Msg(msg)
+		return nil, status.Error(codes.FailedPrecondition, msg)
+	}
+
+	if err := h.us.ReplayDelivery(d); err != nil {
+		h.logger.Error().Err(err).Msg("failed to replay webhook delivery")
+		return nil, status.Error(codes.Aborted, "internal server error")
+	}
+
+	return &pb.WebhookDeliveryResponse{Delivery: d.ProtoWebhookDelivery()}, nil
+}
+
+// ownWebhook returns current user and the webhook of id if current user owns it
+func (h *Handler) ownWebhook(ctx context.Context, id string) (*model.User, *model.Webhook, error) {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("unauthenticated")
+		return nil, nil, status.Errorf(codes.Unauthenticated, "unauthenticated")
+	}
+
+	currentUser, err := h.us.GetByID(userID)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("current user not found")
+		return nil, nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	webhookID, err := strconv.Atoi(id)
+	if err != nil {
+		msg := fmt.Sprintf("cannot convert webhook id (%s) into integer", id)
+		h.logger.Error().Err(err).Msg(msg)
+		return nil, nil, status.Error(codes.InvalidArgument, "invalid webhook id")
+	}
+
+	w, err := h.us.GetWebhookByID(uint(webhookID))
+	if err != nil || w.UserID != currentUser.ID {
+		// others' webhooks are indistinguishable from missing ones
+		h.logger.Error().Err(err).Msgf("webhook(id=%d) not found", webhookID)
+		return nil, nil, status.Error(codes.NotFound, "webhook not found")
+	}
+
+	return currentUser, w, nil
+}
+
+// emitWebhook queues the domain event about owner for the webhooks receiving
+// it, with pe as the payload. The deliveries share the id of the domain event.
+func (h *Handler) emitWebhook(owner *model.User, e *pb.DomainEvent, pe *pb.Event) error {
+	ws, err := h.us.GetWebhooksFor(owner, e.GetType())
+	if err != nil {
+		return fmt.Errorf("failed to get webhooks: %w", err)
+	}
+	if len(ws) == 0 {
+		return nil
+	}
+
+	pe.Id = e.GetId()
+	pe.Type = e.GetType()
+	pe.CreatedAt = e.GetOccurredAt()
+
+	payload, err := payloadMarshaler.MarshalToString(pe)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	return h.us.EnqueueDeliveries(ws, e.GetId(), e.GetType(), payload)
+}
+
+// webhookEvent sends domain events to webhooks
+func (h *Handler) webhookEvent(ctx context.Context, e *pb.DomainEvent) error {
+	switch e.GetType() {
+	case model.EventArticlePublished, model.EventArticleUpdated:
+		a, err := h.as.GetByID(uint(e.GetArticleId()))
+		if err != nil {
+			return skipMissing(err)
+		}
+		if !a.IsListed() {
+			return nil
+		}
+
+		pa := a.ProtoArticle(false)
+		pa.Author = a.Author.ProtoProfile(false)
+		return h.emitWebhook(&a.Author, e, &pb.Event{Article: pa, Slug: pa.Slug})
+
+	case model.EventCommentCreated:
+		a, err := h.as.GetByID(uint(e.GetArticleId()))
+		if err != nil {
+			return skipMissing(err)
+		}
+		c, err := h.eventComment(e)
+		if err != nil {
+			return skipMissing(err)
+		}
+		if !a.IsListed() || c.Hidden {
+			return nil
+		}
+
+		pc := c.ProtoComment()
+		pc.Author = c.Author.ProtoProfile(false)
+		return h.emitWebhook(&a.Author, e, &pb.Event{
+			Slug:    fmt.Sprintf("%d", a.ID),
+			Comment: pc,
+			Actor:   pc.Author,
+		})
+
+	case model.EventUserFollowed:
+		actor, err := h.us.GetByID(uint(e.GetActorId()))
+		if err != nil {
+			return skipMissing(err)
+		}
+		u, err := h.us.GetByID(uint(e.GetUserId()))
+		if err != nil {
+			return skipMissing(err)
+		}
+
+		return h.emitWebhook(u, e, &pb.Event{
+			Actor:   actor.ProtoProfile(false),
+			Profile: u.ProtoProfile(false),
+		})
+	}
+
+	return nil
+}
+
+// DeliverWebhooks sends the due webhook deliveries. Failed attempts are
+// retried with backoff until webhook.MaxAttempts.
+func (h *Handler) DeliverWebhooks(ctx context.Context) error {
+	for {
+		n, err := h.deliverWebhooks(ctx, time.Now())
+		if err != nil || n < deliveryBatchSize {
+			return err
+		}
+	}
+}
+
+// deliverWebhooks sends a batch of deliveries due at now and returns its size
+func (h *Handler) deliverWebhooks(ctx context.Context, now time.Time) (int, error) {
+	ds, err := h.us.ClaimDueDeliveries(now, deliveryLease, deliveryBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	for i := range ds {
+		d := &ds[i]
+
+		if d.Webhook.ID == 0 {
+			d.Status = model.DeliveryFailed
+			d.NextAttemptAt = nil
+			d.LastError = "webhook was deleted"
+		} else {
+			code, err := h.webhooks.Send(ctx, &webhook.Delivery{
+				ID:     fmt.Sprintf("%d", d.ID),
+				Event:  d.Event,
+				URL:    d.Webhook.URL,
+				Secret: d.Webhook.Secret,
+				Body:   []byte(d.Payload),
+			})
+			d.Attempts++
+			d.ResponseStatus = int32(code)
+
+			switch {
+			case err == nil:
+				t := time.Now()
+				d.Status = model.DeliverySucceeded
+				d.NextAttemptAt = nil
+				d.DeliveredAt = &t
+				d.LastError = ""
+			case d.Attempts >= webhook.MaxAttempts:
+				d.Status = model.DeliveryFailed
+				d.NextAttemptAt = nil
+				d.LastError = err.Error()
+			default:
+				t := now.Add(webhook.Backoff(int(d.Attempts)))
+				d.NextAttemptAt = &t
+				d.LastError = err.Error()
+			}
+		}
+
+		if err := h.us.UpdateDelivery(d); err != nil {
+			h.logger.Error().Err(err).Msgf("failed to save webhook delivery(id=%d)", d.ID)
+		}
+	}
+
+	return len(ds), nil
+}
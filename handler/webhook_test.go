@@ -0,0 +1,312 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/raahii/golang-grpc-realworld-example/auth"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+	"github.com/raahii/golang-grpc-realworld-example/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+// webhookReceiver records verified payloads and responds with code
+type webhookReceiver struct {
+	mu       sync.Mutex
+	secret   string
+	code     int
+	received []map[string]interface{}
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	body, _ := ioutil.ReadAll(req.Body)
+	if err := webhook.Verify(r.secret, req.Header.Get(webhook.SignatureHeader), body, time.Now(), time.Minute); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.received = append(r.received, payload)
+
+	w.WriteHeader(r.code)
+}
+
+func TestWebhooks(t *testing.T) {
+	h, cleaner := setUp(t)
+	defer cleaner(t)
+
+	// the receiver listens on loopback
+	h.webhooks = webhook.NewInsecureSender(time.Second)
+
+	admin, _, fooUser := createAdminTestUsers(t, h)
+	barUser := model.User{Username: "bar", Email: "bar@example.com", Password: "secret"}
+	if err := h.us.Create(&barUser); err != nil {
+		t.Fatalf("failed to create initial user record: %v", err)
+	}
+
+	ctxs := map[string]context.Context{}
+	for _, u := range []*model.User{&admin, &fooUser, &barUser} {
+		token, err := auth.GenerateToken(u.ID)
+		if err != nil {
+			t.Error(err)
+		}
+		ctxs[u.Username] = ctxWithToken(context.Background(), token)
+	}
+
+	receiver := &webhookReceiver{code: http.StatusOK}
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	resp, err := h.CreateWebhook(ctxs["foo"], &pb.CreateWebhookRequest{
+		Webhook: &pb.CreateWebhookRequest_Webhook{
+			Url:    srv.URL,
+			Events: []string{model.EventUserFollowed, model.EventCommentCreated},
+		},
+	})
+	if err != nil {
+		t.Fatalf("create webhook expected to succeed, but failed. %v", err)
+	}
+	receiver.secret = resp.GetSecret()
+	webhookID := resp.GetWebhook().GetId()
+
+	// followed by bar
+	if _, err := h.FollowUser(ctxs["bar"], &pb.FollowRequest{Username: "foo"}); err != nil {
+		t.Fatalf("follow user expected to succeed, but failed. %v", err)
+	}
+	// bar's articles are none of foo's webhook
+	if _, err := h.CreateArticle(ctxs["bar"], &pb.CreateAritcleRequest{
+		Article: &pb.CreateAritcleRequest_Article{Title: "title", Body: "body"},
+	}); err != nil {
+		t.Fatalf("create article expected to succeed, but failed. %v", err)
+	}
+	// comments readers cannot see are not sent
+	hidden := model.Article{Title: "hidden", Body: "body", Author: fooUser, Hidden: true}
+	if err := h.as.Create(&hidden); err != nil {
+		t.Fatalf("failed to create initial article record: %v", err)
+	}
+	visible := model.Article{Title: "visible", Body: "body", Author: fooUser}
+	if err := h.as.Create(&visible); err != nil {
+		t.Fatalf("failed to create initial article record: %v", err)
+	}
+	for _, c := range []model.Comment{
+		{Body: "comment", UserID: barUser.ID, ArticleID: hidden.ID},
+		{Body: "comment", UserID: barUser.ID, ArticleID: visible.ID, Hidden: true},
+	} {
+		if err := h.as.CreateComment(&c); err != nil {
+			t.Fatalf("failed to create comment: %v", err)
+		}
+	}
+	dispatchEvents(t, h)
+
+	if err := h.DeliverWebhooks(context.Background()); err != nil {
+		t.Fatalf("deliver webhooks expected to succeed, but failed. %v", err)
+	}
+
+	if assert.Len(t, receiver.received, 1) {
+		payload := receiver.received[0]
+		assert.Equal(t, model.EventUserFollowed, payload["type"])
+		assert.Equal(t, "bar", payload["actor"].(map[string]interface{})["username"])
+		assert.Equal(t, "foo", payload["profile"].(map[string]interface{})["username"])
+	}
+
+	deliveries, err := h.ListWebhookDeliveries(ctxs["foo"], &pb.ListWebhookDeliveriesRequest{Id: webhookID})
+	if err != nil {
+		t.Fatalf("list webhook deliveries expected to succeed, but failed. %v", err)
+	}
+	if assert.Len(t, deliveries.GetDeliveries(), 1) {
+		d := deliveries.GetDeliveries()[0]
+		assert.Equal(t, model.DeliverySucceeded, d.GetStatus())
+		assert.Equal(t, int32(1), d.GetAttempts())
+		assert.Equal(t, int32(http.StatusOK), d.GetResponseStatus())
+	}
+
+	// the receiver is down until the delivery is given up
+	receiver.code = http.StatusServiceUnavailable
+	if _, err := h.UnfollowUser(ctxs["bar"], &pb.UnfollowRequest{Username: "foo"}); err != nil {
+		t.Fatalf("unfollow user expected to succeed, but failed. %v", err)
+	}
+	if _, err := h.FollowUser(ctxs["bar"], &pb.FollowRequest{Username: "foo"}); err != nil {
+		t.Fatalf("follow user expected to succeed, but failed. %v", err)
+	}
+	dispatchEvents(t, h)
+
+	now := time.Now()
+	for i := 0; i < webhook.MaxAttempts; i++ {
+		if _, err := h.deliverWebhooks(context.Background(), now); err != nil {
+			t.Fatalf("deliver webhooks expected to succeed, but failed. %v", err)
+		}
+		now = now.Add(webhook.Backoff(i + 1))
+	}
+
+	deliveries, err = h.ListWebhookDeliveries(ctxs["foo"], &pb.ListWebhookDeliveriesRequest{
+		Id: webhookID, Status: model.DeliveryFailed,
+	})
+	if err != nil {
+		t.Fatalf("list webhook deliveries expected to succeed, but failed. %v", err)
+	}
+	if !assert.Len(t, deliveries.GetDeliveries(), 1) {
+		t.FailNow()
+	}
+	failed := deliveries.GetDeliveries()[0]
+	assert.Equal(t, int32(webhook.MaxAttempts), failed.GetAttempts())
+	assert.Equal(t, int32(http.StatusServiceUnavailable), failed.GetResponseStatus())
+	assert.Empty(t, failed.GetNextAttemptAt())
+
+	_, err = h.ListWebhookDeliveries(ctxs["foo"], &pb.ListWebhookDeliveriesRequest{Id: webhookID, Offset: -1})
+	assert.Error(t, err)
+
+	// replayed once the receiver is back
+	receiver.code = http.StatusOK
+	replayed, err := h.ReplayWebhookDelivery(ctxs["foo"], &pb.ReplayWebhookDeliveryRequest{
+		Id: webhookID, DeliveryId: failed.GetId(),
+	})
+	if err != nil {
+		t.Fatalf("replay webhook delivery expected to succeed, but failed. %v", err)
+	}
+	assert.Equal(t, model.DeliveryPending, replayed.GetDelivery().GetStatus())
+
+	if err := h.DeliverWebhooks(context.Background()); err != nil {
+		t.Fatalf("deliver webhooks expected to succeed, but failed. %v", err)
+	}
+	assert.Len(t, receiver.received, 2)
+
+	// global webhooks receive events about everyone
+	global, err := h.CreateWebhook(ctxs["admin"], &pb.CreateWebhookRequest{
+		Webhook: &pb.CreateWebhookRequest_Webhook{
+			Url:    srv.URL,
+			Events: []string{model.EventArticlePublished},
+			Global: true,
+		},
+	})
+	if err != nil {
+		t.Fatalf("create global webhook expected to succeed, but failed. %v", err)
+	}
+	receiver.secret = global.GetSecret()
+
+	if _, err := h.CreateArticle(ctxs["bar"], &pb.CreateAritcleRequest{
+		Article: &pb.CreateAritcleRequest_Article{Title: "another", Body: "body"},
+	}); err != nil {
+		t.Fatalf("create article expected to succeed, but failed. %v", err)
+	}
+	dispatchEvents(t, h)
+	if err := h.DeliverWebhooks(context.Background()); err != nil {
+		t.Fatalf("deliver webhooks expected to succeed, but failed. %v", err)
+	}
+	if assert.Len(t, receiver.received, 3) {
+		payload := receiver.received[2]
+		assert.Equal(t, model.EventArticlePublished, payload["type"])
+		assert.Equal(t, "another", payload["article"].(map[string]interface{})["title"])
+	}
+
+	if _, err := h.DeleteWebhook(ctxs["foo"], &pb.DeleteWebhookRequest{Id: webhookID}); err != nil {
+		t.Fatalf("delete webhook expected to succeed, but failed. %v", err)
+	}
+	list, err := h.ListWebhooks(ctxs["foo"], &pb.Empty{})
+	if assert.NoError(t, err) {
+		assert.Empty(t, list.GetWebhooks())
+	}
+
+	tests := []struct {
+		title string
+		f     func() error
+	}{
+		{
+			"create with unknown event: failed",
+			func() error {
+				_, err := h.CreateWebhook(ctxs["bar"], &pb.CreateWebhookRequest{
+					Webhook: &pb.CreateWebhookRequest_Webhook{Url: srv.URL, Events: []string{"article.deleted"}},
+				})
+				return err
+			},
+		},
+		{
+			"create with non-http url: failed",
+			func() error {
+				_, err := h.CreateWebhook(ctxs["bar"], &pb.CreateWebhookRequest{
+					Webhook: &pb.CreateWebhookRequest_Webhook{Url: "ftp://example.com", Events: []string{model.EventUserFollowed}},
+				})
+				return err
+			},
+		},
+		{
+			"create global by non-admin: failed",
+			func() error {
+				_, err := h.CreateWebhook(ctxs["bar"], &pb.CreateWebhookRequest{
+					Webhook: &pb.CreateWebhookRequest_Webhook{Url: srv.URL, Events: []string{model.EventUserFollowed}, Global: true},
+				})
+				return err
+			},
+		},
+		{
+			"delete other's webhook: failed",
+			func() error {
+				_, err := h.DeleteWebhook(ctxs["bar"], &pb.DeleteWebhookRequest{Id: global.GetWebhook().GetId()})
+				return err
+			},
+		},
+		{
+			"list deliveries of deleted webhook: failed",
+			func() error {
+				_, err := h.ListWebhookDeliveries(ctxs["foo"], &pb.ListWebhookDeliveriesRequest{Id: webhookID})
+				return err
+			},
+		},
+		{
+			"replay succeeded delivery: failed",
+			func() error {
+				ds, err := h.ListWebhookDeliveries(ctxs["admin"], &pb.ListWebhookDeliveriesRequest{Id: global.GetWebhook().GetId()})
+				if err != nil || len(ds.GetDeliveries()) == 0 {
+					return nil
+				}
+				_, err = h.ReplayWebhookDelivery(ctxs["admin"], &pb.ReplayWebhookDeliveryRequest{
+					Id: global.GetWebhook().GetId(), DeliveryId: ds.GetDeliveries()[0].GetId(),
+				})
+				return err
+			},
+		},
+		{
+			"replay delivery of another webhook: failed",
+			func() error {
+				_, err := h.ReplayWebhookDelivery(ctxs["admin"], &pb.ReplayWebhookDeliveryRequest{
+					Id: global.GetWebhook().GetId(), DeliveryId: failed.GetId(),
+				})
+				return err
+			},
+		},
+		{
+			"unauthenticated: failed",
+			func() error {
+				_, err := h.ListWebhooks(context.Background(), &pb.Empty{})
+				return err
+			},
+		},
+		{
+			"invalid webhook id: failed",
+			func() error {
+				_, err := h.DeleteWebhook(ctxs["foo"], &pb.DeleteWebhookRequest{Id: fmt.Sprintf("x%s", webhookID)})
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		if err := tt.f(); err == nil {
+			t.Errorf("%q expected to fail, but succeeded.", tt.title)
+		}
+	}
+}
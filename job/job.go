@@ -0,0 +1,83 @@
+// Package job runs background jobs periodically
+package job
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Func is a unit of background work. It should return when ctx is done.
+type Func func(ctx context.Context) error
+
+type entry struct {
+	name     string
+	interval time.Duration
+	run      Func
+}
+
+// Runner runs registered jobs, each in its own goroutine
+type Runner struct {
+	logger *zerolog.Logger
+	jobs   []entry
+	wg     sync.WaitGroup
+}
+
+// NewRunner returns a new Runner
+func NewRunner(l *zerolog.Logger) *Runner {
+	return &Runner{logger: l}
+}
+
+// Add registers a job run every interval. Jobs must be added before Start.
+func (r *Runner) Add(name string, interval time.Duration, f Func) {
+	r.jobs = append(r.jobs, entry{name: name, interval: interval, run: f})
+}
+
+// Start starts the jobs. They run once immediately and then every interval
+// until ctx is canceled.
+func (r *Runner) Start(ctx context.Context) {
+	for _, e := range r.jobs {
+		r.wg.Add(1)
+		go func(e entry) {
+			defer r.wg.Done()
+			r.loop(ctx, e)
+		}(e)
+	}
+}
+
+// Wait blocks until all jobs have stopped
+func (r *Runner) Wait() {
+	r.wg.Wait()
+}
+
+func (r *Runner) loop(ctx context.Context, e entry) {
+	t := time.NewTicker(e.interval)
+	defer t.Stop()
+
+	for {
+		if err := r.runOnce(ctx, e); err != nil {
+			r.logger.Error().Err(err).Str("job", e.name).Msg("job failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// runOnce runs the job and turns a panic into an error,
+// so that a broken job doesn't bring the server down
+func (r *Runner) runOnce(ctx context.Context, e entry) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+
+	return e.run(ctx)
+}
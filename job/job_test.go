@@ -0,0 +1,48 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunner(t *testing.T) {
+	l := zerolog.New(ioutil.Discard)
+	r := NewRunner(&l)
+
+	var ok, failed, panicked int32
+	r.Add("ok", 10*time.Millisecond, func(ctx context.Context) error {
+		atomic.AddInt32(&ok, 1)
+		return nil
+	})
+	r.Add("failed", 10*time.Millisecond, func(ctx context.Context) error {
+		atomic.AddInt32(&failed, 1)
+		return errors.New("failed")
+	})
+	r.Add("panicked", 10*time.Millisecond, func(ctx context.Context) error {
+		atomic.AddInt32(&panicked, 1)
+		panic("boom")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.Start(ctx)
+	time.Sleep(55 * time.Millisecond)
+	cancel()
+	r.Wait()
+
+	// failing jobs keep being scheduled
+	for _, n := range []*int32{&ok, &failed, &panicked} {
+		assert.True(t, atomic.LoadInt32(n) >= 2, "job ran %d times", atomic.LoadInt32(n))
+	}
+
+	// no more runs after Wait returns
+	n := atomic.LoadInt32(&ok)
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, n, atomic.LoadInt32(&ok))
+}
@@ -0,0 +1,126 @@
+// Package mail composes and sends emails
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Message is an email with plain text and HTML alternatives
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+	// Headers are extra headers such as List-Unsubscribe
+	Headers map[string]string
+}
+
+// Mailer sends messages
+type Mailer interface {
+	Send(ctx context.Context, m *Message) error
+}
+
+// Bytes encodes the message in MIME format as of date
+func (m *Message) Bytes(date time.Time) ([]byte, error) {
+	var b bytes.Buffer
+	w := multipart.NewWriter(&b)
+
+	header := map[string]string{
+		"From":         m.From,
+		"To":           m.To,
+		"Subject":      mime.QEncoding.Encode("utf-8", m.Subject),
+		"Date":         date.Format(time.RFC1123Z),
+		"MIME-Version": "1.0",
+		"Content-Type": fmt.Sprintf("multipart/alternative; boundary=%q", w.Boundary()),
+	}
+	for k, v := range m.Headers {
+		header[k] = v
+	}
+
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var out bytes.Buffer
+	for _, k := range keys {
+		fmt.Fprintf(&out, "%s: %s\r\n", k, header[k])
+	}
+	out.WriteString("\r\n")
+
+	// the preferred alternative comes last
+	parts := []struct{ typ, body string }{
+		{"text/plain; charset=utf-8", m.Text},
+		{"text/html; charset=utf-8", m.HTML},
+	}
+	for _, p := range parts {
+		pw, err := w.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {p.typ},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		qw := quotedprintable.NewWriter(pw)
+		if _, err := qw.Write([]byte(p.body)); err != nil {
+			return nil, err
+		}
+		if err := qw.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	out.Write(b.Bytes())
+	return out.Bytes(), nil
+}
+
+// FileMailer writes messages into a directory as .eml files instead of
+// sending them, for local development
+type FileMailer struct {
+	dir string
+}
+
+// NewFileMailer returns a FileMailer writing into dir, which is created if missing
+func NewFileMailer(dir string) (*FileMailer, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileMailer{dir: dir}, nil
+}
+
+// Send writes the message into a new file named after the time and recipient
+func (f *FileMailer) Send(ctx context.Context, m *Message) error {
+	now := time.Now()
+	b, err := m.Bytes(now)
+	if err != nil {
+		return err
+	}
+
+	r := make([]byte, 4)
+	if _, err := rand.Read(r); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", now.Format("20060102T150405"), hex.EncodeToString(r))
+	return ioutil.WriteFile(filepath.Join(f.dir, name), b, 0644)
+}
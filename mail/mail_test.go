@@ -0,0 +1,83 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	netmail "net/mail"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileMailer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mail")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	m, err := NewFileMailer(filepath.Join(dir, "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = m.Send(context.Background(), &Message{
+		From:    "Conduit <digest@example.com>",
+		To:      "foo@example.com",
+		Subject: "Your weekly digest – 3 new articles",
+		Text:    "Hello foo,\nsee the articles below.",
+		HTML:    `<p style="margin: 0">Hello foo</p>`,
+		Headers: map[string]string{"List-Unsubscribe-Post": "List-Unsubscribe=One-Click"},
+	})
+	if err != nil {
+		t.Fatalf("send expected to succeed, but failed. %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "out", "*.eml"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected one .eml file, got %v (%v)", files, err)
+	}
+
+	raw, err := ioutil.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := netmail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("failed to parse message: %v", err)
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Your weekly digest – 3 new articles", subject)
+	assert.Equal(t, "foo@example.com", msg.Header.Get("To"))
+	assert.Equal(t, "List-Unsubscribe=One-Click", msg.Header.Get("List-Unsubscribe-Post"))
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	assert.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	// the multipart reader decodes quoted-printable parts
+	r := multipart.NewReader(msg.Body, params["boundary"])
+	var bodies []string
+	for {
+		p, err := r.NextPart()
+		if err != nil {
+			break
+		}
+		b, _ := ioutil.ReadAll(p)
+		bodies = append(bodies, p.Header.Get("Content-Type")+"\n"+string(b))
+	}
+
+	assert.Equal(t, []string{
+		// line breaks are CRLF on the wire
+		"text/plain; charset=utf-8\nHello foo,\r\nsee the articles below.",
+		"text/html; charset=utf-8\n<p style=\"margin: 0\">Hello foo</p>",
+	}, bodies)
+}
@@ -0,0 +1,77 @@
+// Package markdown renders user written markdown to sanitized HTML
+package markdown
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// wordsPerMinute is the reading speed assumed by ReadingTime
+const wordsPerMinute = 200
+
+var (
+	md = goldmark.New(
+		goldmark.WithExtensions(extension.Table),
+	)
+
+	// policy allows the HTML of user generated content, and the
+	// classes telling syntax highlighters the language of code blocks.
+	// Scripts, styles and event handler attributes are removed.
+	policy = func() *bluemonday.Policy {
+		p := bluemonday.UGCPolicy()
+		p.AllowAttrs("class").
+			Matching(regexp.MustCompile(`^language-[\w+#-]+$`)).
+			OnElements("code")
+		return p
+	}()
+
+	strict = bluemonday.StrictPolicy()
+)
+
+// Render renders CommonMark with GFM tables to sanitized HTML
+func Render(src string) string {
+	var buf bytes.Buffer
+	if err := md.Convert([]byte(src), &buf); err != nil {
+		// goldmark only fails on write errors, which bytes.Buffer never returns
+		return policy.Sanitize(src)
+	}
+	return policy.Sanitize(buf.String())
+}
+
+// PlainText returns the text content of h with whitespace collapsed
+func PlainText(h string) string {
+	// keep words in separate elements apart
+	h = strings.NewReplacer("<", " <", ">", "> ").Replace(h)
+	return strings.Join(strings.Fields(html.UnescapeString(strict.Sanitize(h))), " ")
+}
+
+// Excerpt returns the text content of h cut to about n characters at a word boundary
+func Excerpt(h string, n int) string {
+	s := PlainText(h)
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	r := []rune(s)[:n]
+	if i := strings.LastIndex(string(r), " "); i > 0 {
+		return string(r)[:i] + "…"
+	}
+	return string(r) + "…"
+}
+
+// ReadingTime returns the minutes to read text, at least 1
+func ReadingTime(text string) int32 {
+	words := len(strings.Fields(text))
+	m := (words + wordsPerMinute - 1) / wordsPerMinute
+	if m < 1 {
+		return 1
+	}
+	return int32(m)
+}
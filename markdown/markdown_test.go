@@ -0,0 +1,83 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		title    string
+		src      string
+		contains []string
+		excludes []string
+	}{
+		{
+			"commonmark",
+			"# Title\n\nsome *emphasis* and [a link](https://example.com)",
+			[]string{"<h1>Title</h1>", "<em>emphasis</em>", `<a href="https://example.com" rel="nofollow">a link</a>`},
+			nil,
+		},
+		{
+			"gfm table",
+			"| a | b |\n|---|---|\n| 1 | 2 |",
+			[]string{"<table>", "<th>a</th>", "<td>2</td>"},
+			nil,
+		},
+		{
+			"code block keeps language class",
+			"```go\nfmt.Println(1)\n```",
+			[]string{`<code class="language-go">`},
+			nil,
+		},
+		{
+			"raw script is removed",
+			"hello <script>alert(1)</script>",
+			[]string{"hello"},
+			[]string{"<script", "alert(1)</script>"},
+		},
+		{
+			"style and event attributes are removed",
+			`<p style="color:red" onclick="alert(1)">x</p><style>p{}</style>`,
+			nil,
+			[]string{"style", "onclick"},
+		},
+		{
+			"javascript link is removed",
+			"[click](javascript:alert(1))",
+			[]string{"click"},
+			[]string{"javascript:"},
+		},
+		{
+			"arbitrary class is removed",
+			"```\" onmouseover=\"alert(1)\nx\n```",
+			nil,
+			[]string{"onmouseover"},
+		},
+	}
+
+	for _, tt := range tests {
+		h := Render(tt.src)
+		for _, s := range tt.contains {
+			assert.Contains(t, h, s, tt.title)
+		}
+		for _, s := range tt.excludes {
+			assert.NotContains(t, h, s, tt.title)
+		}
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	h := Render("# Title\n\nFirst paragraph &amp; more.\n\n- item one\n- item two")
+	assert.Equal(t, "Title First paragraph & more. item one item two", PlainText(h))
+	assert.Equal(t, "Title First…", Excerpt(h, 14))
+	assert.Equal(t, PlainText(h), Excerpt(h, 100))
+}
+
+func TestReadingTime(t *testing.T) {
+	assert.Equal(t, int32(1), ReadingTime(""))
+	assert.Equal(t, int32(1), ReadingTime(strings.Repeat("word ", 200)))
+	assert.Equal(t, int32(2), ReadingTime(strings.Repeat("word ", 201)))
+}
@@ -2,25 +2,52 @@ package model
 
 import (
 	"fmt"
+	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/jinzhu/gorm"
+	"github.com/raahii/golang-grpc-realworld-example/markdown"
 	pb "github.com/raahii/golang-grpc-realworld-example/proto"
 )
 
 const ISO8601 = "2006-01-02T15:04:05-0700Z"
 
+// excerptLength is the length of excerpts of articles without description
+const excerptLength = 200
+
+// Statuses of articles. Only published articles are listed,
+// unlisted and archived ones can still be read by their links.
+const (
+	ArticleDraft     = "draft"
+	ArticlePublished = "published"
+	ArticleUnlisted  = "unlisted"
+	ArticleArchived  = "archived"
+)
+
+// Orders of article lists
+const (
+	SortNewest    = "newest"
+	SortFavorited = "favorited"
+	SortCommented = "commented"
+	SortTrending  = "trending"
+)
+
 // Article model
 type Article struct {
 	gorm.Model
-	Title          string `gorm:"not null"`
-	Description    string `gorm:"not null"`
-	Body           string `gorm:"not null"`
-	Tags           []Tag  `gorm:"many2many:article_tags"`
-	Author         User   `gorm:"foreignkey:UserID"`
-	UserID         uint   `gorm:"not null"`
-	FavoritesCount int32  `gorm:"not null;default=0"`
-	FavoritedUsers []User `gorm:"many2many:favorite_articles"`
+	Title          string     `gorm:"not null"`
+	Description    string     `gorm:"not null"`
+	Body           string     `gorm:"not null"`
+	BodyHTML       string     `gorm:"type:text"`
+	Tags           []Tag      `gorm:"many2many:article_tags"`
+	Author         User       `gorm:"foreignkey:UserID"`
+	UserID         uint       `gorm:"not null"`
+	FavoritesCount int32      `gorm:"not null;default=0"`
+	FavoritedUsers []User     `gorm:"many2many:favorite_articles"`
+	Hidden         bool       `gorm:"not null;default:false"`
+	Status         string     `gorm:"not null;default:'published'"`
+	PublishAt      *time.Time `gorm:"index"`
+	PublishedAt    *time.Time `gorm:"index"`
 	Comments       []Comment
 }
 
@@ -39,6 +66,10 @@ func (a Article) Validate() error {
 			&a.Tags,
 			validation.Required,
 		),
+		validation.Field(
+			&a.Status,
+			validation.In(ArticleDraft, ArticlePublished, ArticleUnlisted, ArticleArchived),
+		),
 	)
 }
 
@@ -57,6 +88,29 @@ func (a *Article) Overwrite(title, description, body string) {
 	}
 }
 
+// RenderBody renders the markdown body to the cached HTML
+func (a *Article) RenderBody() {
+	a.BodyHTML = markdown.Render(a.Body)
+}
+
+// html returns the HTML of the body. Articles saved before HTML was cached are rendered here.
+func (a *Article) html() string {
+	if a.BodyHTML == "" && a.Body != "" {
+		return markdown.Render(a.Body)
+	}
+	return a.BodyHTML
+}
+
+// PlainBody returns the body as plain text
+func (a *Article) PlainBody() string {
+	return markdown.PlainText(a.html())
+}
+
+// IsListed returns whether the article appears in article lists and search
+func (a *Article) IsListed() bool {
+	return a.Status == ArticlePublished && !a.Hidden && a.DeletedAt == nil
+}
+
 // ProtoArticle generates proto aritcle model from article
 func (a *Article) ProtoArticle(favorited bool) *pb.Article {
 	pa := pb.Article{
@@ -68,6 +122,16 @@ func (a *Article) ProtoArticle(favorited bool) *pb.Article {
 		Favorited:      favorited,
 		CreatedAt:      a.CreatedAt.Format(ISO8601),
 		UpdatedAt:      a.UpdatedAt.Format(ISO8601),
+		Status:         a.Status,
+	}
+
+	// rendered body
+	h := a.html()
+	pa.BodyHtml = h
+	pa.ReadingTimeMinutes = markdown.ReadingTime(a.Body)
+	pa.Excerpt = a.Description
+	if pa.Excerpt == "" {
+		pa.Excerpt = markdown.Excerpt(h, excerptLength)
 	}
 
 	// article tags
@@ -78,10 +142,60 @@ func (a *Article) ProtoArticle(favorited bool) *pb.Article {
 	pa.TagList = tags
 
 	// article dates
+	if a.PublishAt != nil {
+		pa.PublishAt = a.PublishAt.Format(ISO8601)
+	}
+	if a.PublishedAt != nil {
+		pa.PublishedAt = a.PublishedAt.Format(ISO8601)
+	}
 
 	return &pa
 }
 
+// ArticleRevision is a version of an article. Every update of an article
+// creates a new revision numbered from 1.
+type ArticleRevision struct {
+	gorm.Model
+	ArticleID   uint   `gorm:"not null;unique_index:idx_article_revision_number"`
+	Number      int32  `gorm:"not null;unique_index:idx_article_revision_number"`
+	Title       string `gorm:"not null"`
+	Description string `gorm:"not null"`
+	Body        string `gorm:"not null"`
+	BodyHTML    string `gorm:"type:text"`
+	Editor      User   `gorm:"foreignkey:EditorID"`
+	EditorID    uint   `gorm:"not null"`
+}
+
+// NewArticleRevision returns a revision holding the current content of a
+func NewArticleRevision(a *Article, editorID uint) ArticleRevision {
+	return ArticleRevision{
+		ArticleID:   a.ID,
+		Title:       a.Title,
+		Description: a.Description,
+		Body:        a.Body,
+		BodyHTML:    a.html(),
+		EditorID:    editorID,
+	}
+}
+
+// Text returns the content of the revision as a text file, used for diffs
+func (r *ArticleRevision) Text() string {
+	return fmt.Sprintf("# %s\n\n%s\n\n%s\n", r.Title, r.Description, r.Body)
+}
+
+// ProtoArticleRevision generates proto article revision model from article revision
+func (r *ArticleRevision) ProtoArticleRevision() *pb.ArticleRevision {
+	return &pb.ArticleRevision{
+		Number:      r.Number,
+		Title:       r.Title,
+		Description: r.Description,
+		Body:        r.Body,
+		BodyHtml:    r.BodyHTML,
+		Editor:      r.Editor.ProtoProfile(false),
+		CreatedAt:   r.CreatedAt.Format(ISO8601),
+	}
+}
+
 // Tag model
 type Tag struct {
 	gorm.Model
@@ -2,20 +2,34 @@ package model
 
 import (
 	"fmt"
+	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/jinzhu/gorm"
+	"github.com/raahii/golang-grpc-realworld-example/markdown"
 	pb "github.com/raahii/golang-grpc-realworld-example/proto"
 )
 
+// MaxCommentDepth is the deepest level of replies. Top level comments are at depth 0.
+const MaxCommentDepth = 4
+
+// CommentTombstone replaces the body of a deleted comment which still has replies
+const CommentTombstone = "[deleted]"
+
 // Comment model
 type Comment struct {
 	gorm.Model
 	Body      string `gorm:"not null"`
+	BodyHTML  string `gorm:"type:text"`
 	UserID    uint   `gorm:"not null"`
 	Author    User   `gorm:"foreignkey:UserID"`
 	ArticleID uint   `gorm:"not null"`
 	Article   Article
+	Hidden    bool  `gorm:"not null;default:false"`
+	ParentID  *uint `gorm:"index"`
+	Depth     int   `gorm:"not null;default:0"`
+	Deleted   bool  `gorm:"not null;default:false"`
+	EditedAt  *time.Time
 }
 
 // Validate validates fields of comment model
@@ -25,15 +39,60 @@ func (c Comment) Validate() error {
 			&c.Body,
 			validation.Required,
 		),
+		validation.Field(
+			&c.Depth,
+			validation.Max(MaxCommentDepth),
+		),
 	)
 }
 
+// ReplyTo makes the comment a reply to parent
+func (c *Comment) ReplyTo(parent *Comment) {
+	c.ParentID = &parent.ID
+	c.Depth = parent.Depth + 1
+}
+
+// RenderBody renders the markdown body to the cached HTML
+func (c *Comment) RenderBody() {
+	c.BodyHTML = markdown.Render(c.Body)
+}
+
 // ProtoComment generates proto comment model from article
 func (c *Comment) ProtoComment() *pb.Comment {
-	return &pb.Comment{
+	pc := pb.Comment{
 		Id:        fmt.Sprintf("%d", c.ID),
 		Body:      c.Body,
+		BodyHtml:  c.BodyHTML,
 		CreatedAt: c.CreatedAt.Format(ISO8601),
 		UpdatedAt: c.UpdatedAt.Format(ISO8601),
+		Deleted:   c.Deleted,
+		Edited:    c.EditedAt != nil,
+	}
+
+	// comments saved before HTML was cached
+	if pc.BodyHtml == "" && c.Body != "" {
+		pc.BodyHtml = markdown.Render(c.Body)
+	}
+
+	if c.ParentID != nil {
+		pc.ParentId = fmt.Sprintf("%d", *c.ParentID)
+	}
+
+	return &pc
+}
+
+// CommentRevision is a previous version of an edited comment.
+// CreatedAt is when the version was replaced by an edit.
+type CommentRevision struct {
+	gorm.Model
+	CommentID uint   `gorm:"not null;index"`
+	Body      string `gorm:"not null"`
+}
+
+// ProtoCommentRevision generates proto comment revision model from comment revision
+func (r *CommentRevision) ProtoCommentRevision() *pb.CommentRevision {
+	return &pb.CommentRevision{
+		Body:       r.Body,
+		ReplacedAt: r.CreatedAt.Format(ISO8601),
 	}
 }
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Identity is an external identity provider account linked to a user
+type Identity struct {
+	gorm.Model
+	Provider string `gorm:"unique_index:idx_identity_provider_subject;not null"`
+	Subject  string `gorm:"unique_index:idx_identity_provider_subject;not null"`
+	Email    string `gorm:"not null"`
+	UserID   uint   `gorm:"not null"`
+	User     User   `gorm:"foreignkey:UserID"`
+}
+
+var nonUsernameChars = regexp.MustCompile("[^a-zA-Z0-9]+")
+
+// UsernameCandidate derives a base username from identity claims
+func UsernameCandidate(preferredUsername, email string) string {
+	for _, s := range []string{preferredUsername, strings.SplitN(email, "@", 2)[0]} {
+		name := nonUsernameChars.ReplaceAllString(s, "")
+		if len(name) > 32 {
+			name = name[:32]
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return "user"
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"fmt"
+
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+)
+
+// Types of moderated content
+const (
+	ContentArticle = "article"
+	ContentComment = "comment"
+)
+
+// ModerationItem is an article or a comment to be reviewed by moderators
+type ModerationItem struct {
+	Article *Article
+	Comment *Comment
+}
+
+// ProtoModerationItem generates proto moderation item model from moderation item
+func (i *ModerationItem) ProtoModerationItem() *pb.ModerationItem {
+	if i.Comment != nil {
+		return &pb.ModerationItem{
+			Type:      ContentComment,
+			Slug:      fmt.Sprintf("%d", i.Comment.ArticleID),
+			CommentId: fmt.Sprintf("%d", i.Comment.ID),
+			Body:      i.Comment.Body,
+			Author:    i.Comment.Author.ProtoProfile(false),
+			CreatedAt: i.Comment.CreatedAt.Format(ISO8601),
+		}
+	}
+
+	return &pb.ModerationItem{
+		Type:      ContentArticle,
+		Slug:      fmt.Sprintf("%d", i.Article.ID),
+		Title:     i.Article.Title,
+		Body:      i.Article.Body,
+		Author:    i.Article.Author.ProtoProfile(false),
+		CreatedAt: i.Article.CreatedAt.Format(ISO8601),
+	}
+}
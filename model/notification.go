@@ -0,0 +1,82 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+)
+
+// Types of notifications
+const (
+	NotificationFollow   = "follow"
+	NotificationFavorite = "favorite"
+	NotificationComment  = "comment"
+)
+
+// Notification tells a user that others followed them, favorited or commented
+// on their article. Bursts of the same kind on the same target are coalesced
+// into one unread notification counting the distinct actors.
+type Notification struct {
+	gorm.Model
+	RecipientID uint   `gorm:"not null;index:idx_notification_recipient"`
+	Type        string `gorm:"not null"`
+	ArticleID   uint   `gorm:"not null;default:0"` // zero for follows
+	Article     Article
+	CommentID   uint  `gorm:"not null;default:0"` // the latest comment
+	ActorID     uint  `gorm:"not null"`           // the latest actor
+	Actor       User  `gorm:"foreignkey:ActorID"`
+	ActorsCount int32 `gorm:"not null;default:1"`
+	ReadAt      *time.Time
+}
+
+// NotificationActor records who took part in a coalesced notification,
+// so that an actor repeating the action is counted once
+type NotificationActor struct {
+	NotificationID uint `gorm:"primary_key;auto_increment:false"`
+	UserID         uint `gorm:"primary_key;auto_increment:false"`
+}
+
+// Message describes the notification to the recipient
+func (n *Notification) Message() string {
+	who := n.Actor.Username
+	if n.ActorsCount > 1 {
+		who = fmt.Sprintf("%d people", n.ActorsCount)
+	}
+
+	switch n.Type {
+	case NotificationFollow:
+		return fmt.Sprintf("%s followed you", who)
+	case NotificationFavorite:
+		return fmt.Sprintf("%s favorited your article %q", who, n.Article.Title)
+	case NotificationComment:
+		return fmt.Sprintf("%s commented on your article %q", who, n.Article.Title)
+	}
+	return ""
+}
+
+// ProtoNotification generates proto notification model from notification
+func (n *Notification) ProtoNotification() *pb.Notification {
+	pn := pb.Notification{
+		Id:          fmt.Sprintf("%d", n.ID),
+		Type:        n.Type,
+		Actor:       n.Actor.ProtoProfile(false),
+		ActorsCount: n.ActorsCount,
+		Message:     n.Message(),
+		Read:        n.ReadAt != nil,
+		CreatedAt:   n.CreatedAt.Format(ISO8601),
+		UpdatedAt:   n.UpdatedAt.Format(ISO8601),
+	}
+
+	if n.ArticleID != 0 {
+		pn.Slug = fmt.Sprintf("%d", n.ArticleID)
+		pn.Title = n.Article.Title
+	}
+
+	if n.CommentID != 0 {
+		pn.CommentId = fmt.Sprintf("%d", n.CommentID)
+	}
+
+	return &pn
+}
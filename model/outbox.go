@@ -0,0 +1,80 @@
+package model
+
+import (
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/uuid"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+)
+
+// Types of domain events
+const (
+	EventArticlePublished = "article.published"
+	EventArticleUpdated   = "article.updated"
+	EventArticleFavorited = "article.favorited"
+	EventCommentCreated   = "comment.created"
+	EventUserFollowed     = "user.followed"
+
+	// EventNotificationCreated is recorded when a notification is created or
+	// another actor is coalesced into it, so that every server can push it
+	EventNotificationCreated = "notification.created"
+)
+
+// OutboxEvent is a domain event saved in the transaction of the change it
+// records, waiting to be dispatched to subscribers
+type OutboxEvent struct {
+	ID            uint       `gorm:"primary_key"`
+	EventID       string     `gorm:"not null;unique_index"`
+	Type          string     `gorm:"not null"`
+	Payload       []byte     `gorm:"type:blob;not null"` // encoded pb.DomainEvent
+	Attempts      int32      `gorm:"not null;default:0"`
+	NextAttemptAt *time.Time `gorm:"index"` // nil once dispatched or given up
+	LastError     string     `gorm:"type:text"`
+	DispatchedAt  *time.Time
+	CreatedAt     time.Time
+}
+
+// NewOutboxEvent returns an outbox event of typ with a new id.
+// fill sets the records the event refers to.
+func NewOutboxEvent(typ string, actorID uint, fill func(e *pb.DomainEvent)) (*OutboxEvent, error) {
+	now := time.Now()
+	e := pb.DomainEvent{
+		Id:         uuid.New().String(),
+		Type:       typ,
+		OccurredAt: now.Format(ISO8601),
+		ActorId:    uint64(actorID),
+	}
+	if fill != nil {
+		fill(&e)
+	}
+
+	payload, err := proto.Marshal(&e)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OutboxEvent{
+		EventID:       e.Id,
+		Type:          typ,
+		Payload:       payload,
+		NextAttemptAt: &now,
+	}, nil
+}
+
+// DomainEvent decodes the payload
+func (o *OutboxEvent) DomainEvent() (*pb.DomainEvent, error) {
+	var e pb.DomainEvent
+	if err := proto.Unmarshal(o.Payload, &e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// OutboxReceipt records that a subscriber has handled an event, so that
+// redelivering the event skips it
+type OutboxReceipt struct {
+	EventID    string `gorm:"primary_key"`
+	Subscriber string `gorm:"primary_key"`
+	CreatedAt  time.Time
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/jinzhu/gorm"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+)
+
+// DefaultReadingListName is the name of the reading list every user has
+const DefaultReadingListName = "Read later"
+
+// ReadingList is a private list of articles to read later, separate from
+// favorites. Lists other than the default one can be shared by a link.
+type ReadingList struct {
+	gorm.Model
+	UserID     uint    `gorm:"not null;index"`
+	User       User    `gorm:"foreignkey:UserID"`
+	Name       string  `gorm:"not null"`
+	IsDefault  bool    `gorm:"not null;default:false"`
+	ShareToken *string `gorm:"unique_index"` // set while shared
+
+	// ArticlesCount is the number of listed articles in the list, filled by the store
+	ArticlesCount int32 `gorm:"-"`
+}
+
+// ReadingListItem is an article in a reading list. Items are ordered by position.
+type ReadingListItem struct {
+	ReadingListID uint    `gorm:"primary_key;auto_increment:false"`
+	ArticleID     uint    `gorm:"primary_key;auto_increment:false"`
+	Article       Article `gorm:"foreignkey:ArticleID"`
+	Position      int     `gorm:"not null"`
+	CreatedAt     time.Time
+}
+
+// Validate validates fields of reading list model
+func (l ReadingList) Validate() error {
+	return validation.ValidateStruct(&l,
+		validation.Field(
+			&l.Name,
+			validation.Required,
+			validation.Length(1, 100),
+		),
+	)
+}
+
+// GenerateShareToken generates the token of the share link of a reading list
+func GenerateShareToken() (string, error) {
+	b := make([]byte, 18)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// ProtoReadingList generates proto reading list model from reading list.
+// The share URL is the share token under baseURL.
+func (l *ReadingList) ProtoReadingList(baseURL string) *pb.ReadingList {
+	pl := pb.ReadingList{
+		Id:            fmt.Sprintf("%d", l.ID),
+		Name:          l.Name,
+		IsDefault:     l.IsDefault,
+		Shared:        l.ShareToken != nil,
+		ArticlesCount: l.ArticlesCount,
+		CreatedAt:     l.CreatedAt.Format(ISO8601),
+		UpdatedAt:     l.UpdatedAt.Format(ISO8601),
+	}
+	if l.ShareToken != nil {
+		pl.ShareUrl = baseURL + "/reading-lists/" + *l.ShareToken
+	}
+	return &pl
+}
@@ -0,0 +1,129 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/jinzhu/gorm"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+)
+
+// Statuses of reports
+const (
+	ReportOpen      = "open"
+	ReportDismissed = "dismissed"
+	ReportActioned  = "actioned"
+)
+
+// Decisions of moderators
+const (
+	ActionDismiss = "dismiss"
+	ActionHide    = "hide"
+	ActionRemove  = "remove"
+)
+
+// Report is a flag raised by a reader on an article or a comment
+type Report struct {
+	gorm.Model
+	ContentType string `gorm:"not null"`
+	ArticleID   uint   `gorm:"not null;index"`
+	CommentID   uint   `gorm:"not null;default:0"` // zero when the article is reported
+	ReporterID  uint   `gorm:"not null"`
+	Reporter    User   `gorm:"foreignkey:ReporterID"`
+	Reason      string `gorm:"not null"`
+	Status      string `gorm:"not null;default:'open'"`
+	ResolvedAt  *time.Time
+}
+
+// Validate validates fields of report model
+func (r Report) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(
+			&r.ContentType,
+			validation.Required,
+			validation.In(ContentArticle, ContentComment),
+		),
+		validation.Field(
+			&r.Reason,
+			validation.Required,
+			validation.Length(1, 500),
+		),
+	)
+}
+
+// ProtoReport generates proto report model from report
+func (r *Report) ProtoReport() *pb.Report {
+	pr := pb.Report{
+		Id:        fmt.Sprintf("%d", r.ID),
+		Type:      r.ContentType,
+		Slug:      fmt.Sprintf("%d", r.ArticleID),
+		Reason:    r.Reason,
+		Status:    r.Status,
+		Reporter:  r.Reporter.ProtoProfile(false),
+		CreatedAt: r.CreatedAt.Format(ISO8601),
+	}
+
+	if r.ContentType == ContentComment {
+		pr.CommentId = fmt.Sprintf("%d", r.CommentID)
+	}
+
+	if r.ResolvedAt != nil {
+		pr.ResolvedAt = r.ResolvedAt.Format(ISO8601)
+	}
+
+	return &pr
+}
+
+// ModerationAction is an audit log entry of a moderator's decision
+type ModerationAction struct {
+	gorm.Model
+	ModeratorID uint   `gorm:"not null"`
+	Moderator   User   `gorm:"foreignkey:ModeratorID"`
+	Action      string `gorm:"not null"`
+	ContentType string `gorm:"not null"`
+	ArticleID   uint   `gorm:"not null"`
+	CommentID   uint   `gorm:"not null;default:0"`
+	ReportID    uint   `gorm:"not null;default:0"` // zero when no report was involved
+	Note        string `gorm:"not null"`
+}
+
+// IsValidAction returns whether action is a known decision on reported content
+func IsValidAction(action string) bool {
+	switch action {
+	case ActionDismiss, ActionHide, ActionRemove:
+		return true
+	}
+	return false
+}
+
+// ReportStatus returns the status of reports resolved by the action
+func (a *ModerationAction) ReportStatus() string {
+	if a.Action == ActionDismiss {
+		return ReportDismissed
+	}
+	return ReportActioned
+}
+
+// ProtoModerationAction generates proto moderation action model from moderation action
+func (a *ModerationAction) ProtoModerationAction() *pb.ModerationAction {
+	pa := pb.ModerationAction{
+		Id:        fmt.Sprintf("%d", a.ID),
+		Action:    a.Action,
+		Type:      a.ContentType,
+		Slug:      fmt.Sprintf("%d", a.ArticleID),
+		Moderator: a.Moderator.ProtoProfile(false),
+		Note:      a.Note,
+		CreatedAt: a.CreatedAt.Format(ISO8601),
+	}
+
+	if a.ContentType == ContentComment {
+		pa.CommentId = fmt.Sprintf("%d", a.CommentID)
+	}
+
+	if a.ReportID != 0 {
+		pa.ReportId = fmt.Sprintf("%d", a.ReportID)
+	}
+
+	return &pa
+}
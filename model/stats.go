@@ -0,0 +1,20 @@
+package model
+
+// DateLayout is the format of days in article stats, which are days in UTC
+const DateLayout = "2006-01-02"
+
+// ArticleDailyStat is the number of views of an article and the net number
+// of favorites it got on a day
+type ArticleDailyStat struct {
+	ArticleID uint   `gorm:"primary_key;auto_increment:false"`
+	Date      string `gorm:"primary_key;type:char(10)"`
+	Views     int64  `gorm:"not null;default:0"`
+	Favorites int64  `gorm:"not null;default:0"`
+}
+
+// ArticleTrend is the trending score of an article. The scores are
+// recomputed periodically, and articles without recent activity have none.
+type ArticleTrend struct {
+	ArticleID uint    `gorm:"primary_key;auto_increment:false"`
+	Score     float64 `gorm:"not null;index"`
+}
@@ -0,0 +1,133 @@
+package model
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/jinzhu/gorm"
+	"github.com/raahii/golang-grpc-realworld-example/auth"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+)
+
+// apiTokenPrefixLength is the length of the token prefix stored in plain text to look up and identify tokens
+const apiTokenPrefixLength = len(auth.APITokenPrefix) + 8
+
+// APIToken is a personal access token of a user
+type APIToken struct {
+	gorm.Model
+	UserID     uint   `gorm:"not null;index"`
+	Name       string `gorm:"not null"`
+	Prefix     string `gorm:"unique_index;not null"`
+	Hash       string `gorm:"not null"`
+	Scopes     string `gorm:"not null"`
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+}
+
+// NewAPIToken generates a new token and returns it with the plain token,
+// which is never stored and shown to the user only once
+func NewAPIToken(userID uint, name string, scopes []string, expiresAt *time.Time) (*APIToken, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", err
+	}
+	plain := auth.APITokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	t := APIToken{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    APITokenPrefixOf(plain),
+		Hash:      HashAPIToken(plain),
+		Scopes:    strings.Join(scopes, ","),
+		ExpiresAt: expiresAt,
+	}
+
+	return &t, plain, nil
+}
+
+// APITokenPrefixOf returns the visible prefix of the plain token
+func APITokenPrefixOf(plain string) string {
+	if len(plain) < apiTokenPrefixLength {
+		return plain
+	}
+	return plain[:apiTokenPrefixLength]
+}
+
+// HashAPIToken hashes the plain token. Tokens have enough entropy for a plain sha256.
+func HashAPIToken(plain string) string {
+	h := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(h[:])
+}
+
+// Validate validates fields of api token model
+func (t APIToken) Validate() error {
+	return validation.ValidateStruct(&t,
+		validation.Field(
+			&t.Name,
+			validation.Required,
+			validation.Length(1, 100),
+		),
+		validation.Field(
+			&t.Scopes,
+			validation.Required,
+			validation.By(func(v interface{}) error {
+				for _, s := range t.ScopeList() {
+					if !auth.IsValidScope(s) {
+						return fmt.Errorf("unknown scope %q", s)
+					}
+				}
+				return nil
+			}),
+		),
+		validation.Field(
+			&t.ExpiresAt,
+			validation.By(func(v interface{}) error {
+				if t.ExpiresAt != nil && t.ExpiresAt.Before(time.Now()) {
+					return errors.New("must be in the future")
+				}
+				return nil
+			}),
+		),
+	)
+}
+
+// ScopeList returns the scopes of the token
+func (t *APIToken) ScopeList() []string {
+	if t.Scopes == "" {
+		return []string{}
+	}
+	return strings.Split(t.Scopes, ",")
+}
+
+// IsExpired returns whether the token is expired at now
+func (t *APIToken) IsExpired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+// ProtoAPIToken generates proto api token model from api token
+func (t *APIToken) ProtoAPIToken() *pb.APIToken {
+	pt := pb.APIToken{
+		Id:        fmt.Sprintf("%d", t.ID),
+		Name:      t.Name,
+		Prefix:    t.Prefix,
+		Scopes:    t.ScopeList(),
+		CreatedAt: t.CreatedAt.Format(ISO8601),
+	}
+
+	if t.ExpiresAt != nil {
+		pt.ExpiresAt = t.ExpiresAt.Format(ISO8601)
+	}
+
+	if t.LastUsedAt != nil {
+		pt.LastUsedAt = t.LastUsedAt.Format(ISO8601)
+	}
+
+	return &pt
+}
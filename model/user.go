@@ -3,6 +3,7 @@ package model
 import (
 	"errors"
 	"regexp"
+	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/go-ozzo/ozzo-validation/is"
@@ -11,6 +12,27 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// Roles of users
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// Statuses of user accounts
+const (
+	StatusActive    = "active"
+	StatusSuspended = "suspended"
+	StatusBanned    = "banned"
+)
+
+// Frequencies of email digests
+const (
+	DigestOff    = "off"
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
 // User is user model
 type User struct {
 	gorm.Model
@@ -19,8 +41,17 @@ type User struct {
 	Password         string    `gorm:"not null"`
 	Bio              string    `gorm:"not null"`
 	Image            string    `gorm:"not null"`
+	Role             string    `gorm:"not null;default:'user'"`
+	Status           string    `gorm:"not null;default:'active'"`
+	FollowersCount   int32     `gorm:"not null;default:0"`
+	FollowingCount   int32     `gorm:"not null;default:0"`
 	Follows          []User    `gorm:"many2many:follows;jointable_foreignkey:from_user_id;association_jointable_foreignkey:to_user_id"`
 	FavoriteArticles []Article `gorm:"many2many:favorite_articles;"`
+	Blocks           []User    `gorm:"many2many:blocks;jointable_foreignkey:from_user_id;association_jointable_foreignkey:to_user_id"`
+	Mutes            []User    `gorm:"many2many:mutes;jointable_foreignkey:from_user_id;association_jointable_foreignkey:to_user_id"`
+	SuspendedUntil   *time.Time
+	DigestFrequency  string `gorm:"not null;default:'off'"`
+	DigestSentAt     *time.Time
 }
 
 // Validate validates fields of user model
@@ -40,9 +71,24 @@ func (u User) Validate() error {
 			&u.Password,
 			validation.Required,
 		),
+		validation.Field(
+			&u.DigestFrequency,
+			validation.In(DigestOff, DigestDaily, DigestWeekly),
+		),
 	)
 }
 
+// DigestPeriod returns the interval between digests of the user, zero if off
+func (u *User) DigestPeriod() time.Duration {
+	switch u.digestFrequency() {
+	case DigestDaily:
+		return 24 * time.Hour
+	case DigestWeekly:
+		return 7 * 24 * time.Hour
+	}
+	return 0
+}
+
 // HashPassword makes password field crypted
 func (u *User) HashPassword() error {
 	if len(u.Password) == 0 {
@@ -72,15 +118,44 @@ func (u *User) ProtoUser(token string) *pb.User {
 		Username: u.Username,
 		Bio:      u.Bio,
 		Image:    u.Image,
+		Digest:   u.digestFrequency(),
 	}
 }
 
+// digestFrequency returns the frequency of digests, which is blank
+// for users created in this process
+func (u *User) digestFrequency() string {
+	if u.DigestFrequency == "" {
+		return DigestOff
+	}
+	return u.DigestFrequency
+}
+
+// ProtoAdminUser generates proto admin user model from user
+func (u *User) ProtoAdminUser() *pb.AdminUser {
+	pu := pb.AdminUser{
+		Username:  u.Username,
+		Email:     u.Email,
+		Role:      u.Role,
+		Status:    u.Status,
+		CreatedAt: u.CreatedAt.Format(ISO8601),
+	}
+
+	if u.SuspendedUntil != nil {
+		pu.SuspendedUntil = u.SuspendedUntil.Format(ISO8601)
+	}
+
+	return &pu
+}
+
 // ProtoProfile generates proto profile model from user
 func (u *User) ProtoProfile(following bool) *pb.Profile {
 	return &pb.Profile{
-		Username:  u.Username,
-		Bio:       u.Bio,
-		Image:     u.Image,
-		Following: following,
+		Username:       u.Username,
+		Bio:            u.Bio,
+		Image:          u.Image,
+		Following:      following,
+		FollowersCount: u.FollowersCount,
+		FollowingCount: u.FollowingCount,
 	}
 }
@@ -0,0 +1,148 @@
+package model
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/jinzhu/gorm"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+)
+
+// WebhookEvents are the events webhooks can subscribe to
+var WebhookEvents = []string{
+	EventArticlePublished,
+	EventArticleUpdated,
+	EventCommentCreated,
+	EventUserFollowed,
+}
+
+// Statuses of webhook deliveries
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed" // given up after the last attempt
+)
+
+// Webhook is an endpoint receiving events about its owner's articles and
+// profile. Global webhooks, which only admins can create, receive all events.
+type Webhook struct {
+	gorm.Model
+	UserID uint   `gorm:"not null;index"`
+	User   User   `gorm:"foreignkey:UserID"`
+	URL    string `gorm:"not null"`
+	Secret string `gorm:"not null"`
+	Events string `gorm:"not null"` // comma separated
+	Global bool   `gorm:"not null;default:false"`
+}
+
+// Validate validates fields of webhook model
+func (w Webhook) Validate() error {
+	return validation.ValidateStruct(&w,
+		validation.Field(
+			&w.URL,
+			validation.Required,
+			validation.Length(1, 2000),
+			validation.By(httpURL),
+		),
+		validation.Field(
+			&w.Events,
+			validation.Required,
+			validation.By(webhookEvents),
+		),
+	)
+}
+
+func httpURL(value interface{}) error {
+	u, err := url.Parse(value.(string))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("must be an absolute http or https URL")
+	}
+	return nil
+}
+
+func webhookEvents(value interface{}) error {
+	for _, e := range strings.Split(value.(string), ",") {
+		ok := false
+		for _, known := range WebhookEvents {
+			ok = ok || e == known
+		}
+		if !ok {
+			return fmt.Errorf("unknown event %q", e)
+		}
+	}
+	return nil
+}
+
+// EventList returns the events the webhook subscribes to
+func (w *Webhook) EventList() []string {
+	if w.Events == "" {
+		return []string{}
+	}
+	return strings.Split(w.Events, ",")
+}
+
+// Subscribes returns whether the webhook receives the event
+func (w *Webhook) Subscribes(event string) bool {
+	for _, e := range w.EventList() {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// ProtoWebhook generates proto webhook model from webhook. The secret is never included.
+func (w *Webhook) ProtoWebhook() *pb.Webhook {
+	return &pb.Webhook{
+		Id:        fmt.Sprintf("%d", w.ID),
+		Url:       w.URL,
+		Events:    w.EventList(),
+		Global:    w.Global,
+		CreatedAt: w.CreatedAt.Format(ISO8601),
+	}
+}
+
+// WebhookDelivery is an event queued for a webhook. Failed attempts are
+// retried with backoff until the delivery succeeds or is given up.
+type WebhookDelivery struct {
+	gorm.Model
+	WebhookID      uint       `gorm:"not null;index"`
+	Webhook        Webhook    `gorm:"foreignkey:WebhookID"`
+	EventID        string     `gorm:"not null"` // the same for all deliveries of an event
+	Event          string     `gorm:"not null"`
+	Payload        string     `gorm:"type:text;not null"`
+	Status         string     `gorm:"not null;default:'pending'"`
+	Attempts       int32      `gorm:"not null;default:0"`
+	NextAttemptAt  *time.Time `gorm:"index"` // nil unless pending
+	ResponseStatus int32      `gorm:"not null;default:0"`
+	LastError      string     `gorm:"type:text"`
+	DeliveredAt    *time.Time
+}
+
+// ProtoWebhookDelivery generates proto webhook delivery model from webhook delivery
+func (d *WebhookDelivery) ProtoWebhookDelivery() *pb.WebhookDelivery {
+	pd := pb.WebhookDelivery{
+		Id:             fmt.Sprintf("%d", d.ID),
+		Event:          d.Event,
+		EventId:        d.EventID,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		ResponseStatus: d.ResponseStatus,
+		LastError:      d.LastError,
+		Payload:        d.Payload,
+		CreatedAt:      d.CreatedAt.Format(ISO8601),
+	}
+
+	if d.NextAttemptAt != nil {
+		pd.NextAttemptAt = d.NextAttemptAt.Format(ISO8601)
+	}
+
+	if d.DeliveredAt != nil {
+		pd.DeliveredAt = d.DeliveredAt.Format(ISO8601)
+	}
+
+	return &pd
+}
@@ -0,0 +1,139 @@
+package oidc
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	stateCookie = "oidc_state"
+	sessionTTL  = 10 * time.Minute
+)
+
+// session holds the values bound to an in-flight authorization request
+type session struct {
+	nonce     string
+	verifier  string
+	expiresAt time.Time
+}
+
+// LoginFunc completes the sign in with the raw ID token returned by the provider.
+// The token is not verified yet; implementations must call Verify with the nonce.
+type LoginFunc func(w http.ResponseWriter, r *http.Request, rawIDToken, nonce string)
+
+// LoginHandler redirects the user agent to the provider's authorization endpoint
+func (p *Provider) LoginHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state, err := RandomString(16)
+		if err != nil {
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		nonce, err := RandomString(16)
+		if err != nil {
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		verifier, err := GenerateVerifier()
+		if err != nil {
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		u, err := p.AuthCodeURL(r.Context(), state, nonce, verifier)
+		if err != nil {
+			http.Error(w, "identity provider is unavailable", http.StatusBadGateway)
+			return
+		}
+
+		p.saveSession(state, session{
+			nonce:     nonce,
+			verifier:  verifier,
+			expiresAt: time.Now().Add(sessionTTL),
+		})
+
+		http.SetCookie(w, &http.Cookie{
+			Name:     stateCookie,
+			Value:    state,
+			Path:     "/",
+			MaxAge:   int(sessionTTL.Seconds()),
+			HttpOnly: true,
+			Secure:   r.TLS != nil,
+			SameSite: http.SameSiteLaxMode,
+		})
+		http.Redirect(w, r, u, http.StatusFound)
+	})
+}
+
+// CallbackHandler handles the redirect back from the provider, exchanges the
+// authorization code and passes the ID token to login
+func (p *Provider) CallbackHandler(login LoginFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if e := q.Get("error"); e != "" {
+			http.Error(w, "authorization failed: "+e, http.StatusUnauthorized)
+			return
+		}
+
+		state := q.Get("state")
+		c, err := r.Cookie(stateCookie)
+		if err != nil || state == "" || c.Value != state {
+			http.Error(w, "invalid state", http.StatusBadRequest)
+			return
+		}
+
+		http.SetCookie(w, &http.Cookie{Name: stateCookie, Path: "/", MaxAge: -1})
+
+		s, ok := p.takeSession(state)
+		if !ok {
+			http.Error(w, "login session expired", http.StatusBadRequest)
+			return
+		}
+
+		rawIDToken, err := p.Exchange(r.Context(), q.Get("code"), s.verifier)
+		if err != nil {
+			http.Error(w, "failed to exchange authorization code", http.StatusUnauthorized)
+			return
+		}
+
+		login(w, r, rawIDToken, s.nonce)
+	})
+}
+
+func (p *Provider) saveSession(state string, s session) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.sessions == nil {
+		p.sessions = map[string]session{}
+	}
+
+	// drop abandoned sessions
+	now := time.Now()
+	for k, v := range p.sessions {
+		if now.After(v.expiresAt) {
+			delete(p.sessions, k)
+		}
+	}
+
+	p.sessions[state] = s
+}
+
+func (p *Provider) takeSession(state string) (session, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s, ok := p.sessions[state]
+	if !ok {
+		return session{}, false
+	}
+	delete(p.sessions, state)
+
+	if time.Now().After(s.expiresAt) {
+		return session{}, false
+	}
+
+	return s, true
+}
@@ -0,0 +1,206 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Provider is an OpenID Connect identity provider used for authorization code flow with PKCE
+type Provider struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	Client       *http.Client
+
+	mu       sync.Mutex
+	metadata *metadata
+	keys     map[string]interface{}
+	sessions map[string]session
+}
+
+// metadata is a subset of the OpenID Provider Metadata
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// NewProvider returns a new Provider. The provider metadata is discovered lazily.
+func NewProvider(issuer, clientID, clientSecret, redirectURL string) *Provider {
+	return &Provider{
+		Issuer:       strings.TrimSuffix(issuer, "/"),
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       []string{"openid", "email", "profile"},
+		Client:       &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// NewProviderFromEnv returns a Provider configured by environment variables,
+// or nil if $OIDC_ISSUER is not set
+func NewProviderFromEnv() *Provider {
+	issuer := os.Getenv("OIDC_ISSUER")
+	if issuer == "" {
+		return nil
+	}
+
+	return NewProvider(
+		issuer,
+		os.Getenv("OIDC_CLIENT_ID"),
+		os.Getenv("OIDC_CLIENT_SECRET"),
+		os.Getenv("OIDC_REDIRECT_URL"),
+	)
+}
+
+// discover fetches and caches the provider metadata
+func (p *Provider) discover(ctx context.Context) (*metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	var m metadata
+	err := p.getJSON(ctx, p.Issuer+"/.well-known/openid-configuration", &m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover provider: %w", err)
+	}
+
+	if strings.TrimSuffix(m.Issuer, "/") != p.Issuer {
+		return nil, fmt.Errorf("issuer mismatch: expected %q, got %q", p.Issuer, m.Issuer)
+	}
+
+	p.metadata = &m
+	return p.metadata, nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, u string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status from %s: %s", u, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// AuthCodeURL returns the authorization endpoint URL the user should be redirected to
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	m, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", p.ClientID)
+	q.Set("redirect_uri", p.RedirectURL)
+	q.Set("scope", strings.Join(p.Scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", Challenge(verifier))
+	q.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(m.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+
+	return m.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+// Exchange exchanges an authorization code for a raw ID token
+func (p *Provider) Exchange(ctx context.Context, code, verifier string) (string, error) {
+	m, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.RedirectURL)
+	form.Set("client_id", p.ClientID)
+	form.Set("code_verifier", verifier)
+
+	req, err := http.NewRequest(http.MethodPost, m.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.ClientID), url.QueryEscape(p.ClientSecret))
+	}
+
+	resp, err := p.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode token response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token request failed: %s: %s %s",
+			resp.Status, body.Error, body.ErrorDescription)
+	}
+
+	if body.IDToken == "" {
+		return "", errors.New("token response has no id_token")
+	}
+
+	return body.IDToken, nil
+}
+
+// RandomString returns a url-safe random string with n bytes of entropy
+func RandomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// GenerateVerifier generates a PKCE code verifier
+func GenerateVerifier() (string, error) {
+	return RandomString(32)
+}
+
+// Challenge computes the S256 PKCE code challenge of the verifier
+func Challenge(verifier string) string {
+	h := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(h[:])
+}
@@ -0,0 +1,191 @@
+package oidc
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/raahii/golang-grpc-realworld-example/oidc/oidctest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChallenge(t *testing.T) {
+	// base64url(sha256(verifier)) without padding
+	verifier := "dBjftJeZ4CVP-mJ92K9rNSQYV1xfVrS3E3Qlgw0-Z4M"
+	assert.Equal(t, "wq7Wd8ikcKEnu5dxSX5nflv18dsia1b73AR9biCvy4E", Challenge(verifier))
+
+	v, err := GenerateVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, v, 43)
+}
+
+func TestLoginFlow(t *testing.T) {
+	idp := oidctest.NewProvider("realworld")
+	defer idp.Close()
+
+	idp.SetUser(oidctest.User{
+		Subject:       "12345",
+		Email:         "foo@example.com",
+		EmailVerified: true,
+	})
+
+	mux := http.NewServeMux()
+	app := httptest.NewServer(mux)
+	defer app.Close()
+
+	p := NewProvider(idp.URL, idp.ClientID, "", app.URL+"/oauth/callback")
+	mux.Handle("/oauth/login", p.LoginHandler())
+	mux.Handle("/oauth/callback", p.CallbackHandler(
+		func(w http.ResponseWriter, r *http.Request, rawIDToken, nonce string) {
+			c, err := p.Verify(r.Context(), rawIDToken, nonce)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprintf(w, "%s %s %t", c.Subject, c.Email, c.EmailVerified)
+		}))
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Jar: jar}
+
+	resp, err := client.Get(app.URL + "/oauth/login")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.Equal(t, "12345 foo@example.com true", string(body))
+
+	// the state is consumed by the first callback
+	resp, err = client.Get(resp.Request.URL.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestCallbackWithoutStateCookie(t *testing.T) {
+	idp := oidctest.NewProvider("realworld")
+	defer idp.Close()
+
+	p := NewProvider(idp.URL, idp.ClientID, "", "http://localhost/oauth/callback")
+	h := p.CallbackHandler(func(w http.ResponseWriter, r *http.Request, rawIDToken, nonce string) {
+		t.Error("login must not be called")
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/oauth/callback?code=abc&state=xyz", nil)
+	h.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestVerify(t *testing.T) {
+	idp := oidctest.NewProvider("realworld")
+	defer idp.Close()
+
+	other := oidctest.NewProvider("realworld")
+	defer other.Close()
+
+	u := oidctest.User{
+		Subject:           "12345",
+		Email:             "foo@example.com",
+		EmailVerified:     true,
+		PreferredUsername: "foo",
+	}
+
+	tests := []struct {
+		title    string
+		token    string
+		nonce    string
+		hasError bool
+	}{
+		{
+			"valid token: success",
+			idp.IDToken(u, "n0nce", nil),
+			"n0nce",
+			false,
+		},
+		{
+			"nonce mismatch: failed",
+			idp.IDToken(u, "n0nce", nil),
+			"other",
+			true,
+		},
+		{
+			"audience mismatch: failed",
+			idp.IDToken(u, "n0nce", map[string]interface{}{"aud": "someone-else"}),
+			"n0nce",
+			true,
+		},
+		{
+			"audience in list: success",
+			idp.IDToken(u, "n0nce", map[string]interface{}{"aud": []string{"x", "realworld"}}),
+			"n0nce",
+			false,
+		},
+		{
+			"expired token: failed",
+			idp.IDToken(u, "n0nce", map[string]interface{}{"exp": time.Now().Add(-time.Minute).Unix()}),
+			"n0nce",
+			true,
+		},
+		{
+			"issuer mismatch: failed",
+			idp.IDToken(u, "n0nce", map[string]interface{}{"iss": "https://evil.example.com"}),
+			"n0nce",
+			true,
+		},
+		{
+			"signed by other key: failed",
+			other.IDToken(u, "n0nce", map[string]interface{}{"iss": idp.URL}),
+			"n0nce",
+			true,
+		},
+		{
+			"malformed token: failed",
+			"not-a-token",
+			"",
+			true,
+		},
+	}
+
+	p := NewProvider(idp.URL, idp.ClientID, "", "")
+	for _, tt := range tests {
+		c, err := p.Verify(context.Background(), tt.token, tt.nonce)
+		if tt.hasError {
+			if err == nil {
+				t.Errorf("%q expected to fail, but succeeded.", tt.title)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%q expected to succeed, but failed. %v", tt.title, err)
+			continue
+		}
+
+		assert.Equal(t, idp.URL, c.Issuer)
+		assert.Equal(t, u.Subject, c.Subject)
+		assert.Equal(t, u.Email, c.Email)
+		assert.Equal(t, u.EmailVerified, c.EmailVerified)
+		assert.Equal(t, u.PreferredUsername, c.PreferredUsername)
+	}
+}
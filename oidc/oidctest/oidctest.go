@@ -0,0 +1,209 @@
+// Package oidctest provides a fake OpenID Connect provider for tests
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+const keyID = "test-key"
+
+// User is the identity the provider signs in
+type User struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	PreferredUsername string
+}
+
+// authRequest is an issued authorization code waiting to be exchanged
+type authRequest struct {
+	redirectURI string
+	challenge   string
+	nonce       string
+	user        User
+}
+
+// Provider is a fake OpenID Connect provider backed by httptest.Server
+type Provider struct {
+	*httptest.Server
+	ClientID string
+
+	key   *rsa.PrivateKey
+	mu    sync.Mutex
+	user  User
+	codes map[string]authRequest
+}
+
+// NewProvider starts a fake provider accepting the clientID
+func NewProvider(clientID string) *Provider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+
+	p := &Provider{
+		ClientID: clientID,
+		key:      key,
+		codes:    map[string]authRequest{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("/authorize", p.authorize)
+	mux.HandleFunc("/token", p.token)
+	mux.HandleFunc("/keys", p.keys)
+	p.Server = httptest.NewServer(mux)
+
+	return p
+}
+
+// SetUser sets the identity signed in by subsequent authorization requests
+func (p *Provider) SetUser(u User) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.user = u
+}
+
+// IDToken issues a signed ID token for u. Extra claims overwrite the defaults.
+func (p *Provider) IDToken(u User, nonce string, extra map[string]interface{}) string {
+	now := time.Now()
+	c := jwt.MapClaims{
+		"iss":                p.URL,
+		"sub":                u.Subject,
+		"aud":                p.ClientID,
+		"iat":                now.Unix(),
+		"exp":                now.Add(time.Hour).Unix(),
+		"email":              u.Email,
+		"email_verified":     u.EmailVerified,
+		"preferred_username": u.PreferredUsername,
+	}
+	if nonce != "" {
+		c["nonce"] = nonce
+	}
+	for k, v := range extra {
+		c[k] = v
+	}
+
+	t := jwt.NewWithClaims(jwt.SigningMethodRS256, c)
+	t.Header["kid"] = keyID
+	s, err := t.SignedString(p.key)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func (p *Provider) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 p.URL,
+		"authorization_endpoint": p.URL + "/authorize",
+		"token_endpoint":         p.URL + "/token",
+		"jwks_uri":               p.URL + "/keys",
+	})
+}
+
+func (p *Provider) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("client_id") != p.ClientID || q.Get("response_type") != "code" ||
+		q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+
+	code := randomString()
+
+	p.mu.Lock()
+	p.codes[code] = authRequest{
+		redirectURI: q.Get("redirect_uri"),
+		challenge:   q.Get("code_challenge"),
+		nonce:       q.Get("nonce"),
+		user:        p.user,
+	}
+	p.mu.Unlock()
+
+	u, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	rq := u.Query()
+	rq.Set("code", code)
+	rq.Set("state", q.Get("state"))
+	u.RawQuery = rq.Encode()
+
+	http.Redirect(w, r, u.String(), http.StatusFound)
+}
+
+func (p *Provider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	p.mu.Lock()
+	ar, ok := p.codes[r.PostForm.Get("code")]
+	delete(p.codes, r.PostForm.Get("code"))
+	p.mu.Unlock()
+
+	if !ok || r.PostForm.Get("grant_type") != "authorization_code" ||
+		r.PostForm.Get("redirect_uri") != ar.redirectURI {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	h := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(h[:]) != ar.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error":             "invalid_grant",
+			"error_description": "code verifier mismatch",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     p.IDToken(ar.user, ar.nonce, nil),
+	})
+}
+
+func (p *Provider) keys(w http.ResponseWriter, r *http.Request) {
+	pub := p.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kid": keyID,
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func randomString() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,218 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// Claims is a set of identity claims extracted from a verified ID token
+type Claims struct {
+	Issuer            string
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	PreferredUsername string
+	Name              string
+	Picture           string
+}
+
+// jwk is a JSON Web Key
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// Verify verifies the signature and the claims of a raw ID token
+func (p *Provider) Verify(ctx context.Context, rawIDToken, nonce string) (*Claims, error) {
+	if _, err := p.discover(ctx); err != nil {
+		return nil, err
+	}
+
+	parser := jwt.Parser{
+		ValidMethods: []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"},
+	}
+
+	mc := jwt.MapClaims{}
+	_, err := parser.ParseWithClaims(rawIDToken, mc, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid id token: %w", err)
+	}
+
+	iss, _ := mc["iss"].(string)
+	if strings.TrimSuffix(iss, "/") != p.Issuer {
+		return nil, fmt.Errorf("invalid id token: unexpected issuer %q", iss)
+	}
+
+	if !hasAudience(mc["aud"], p.ClientID) {
+		return nil, errors.New("invalid id token: audience mismatch")
+	}
+
+	// exp is required for ID tokens, jwt-go only validates it when present
+	exp, ok := mc["exp"].(float64)
+	if !ok || time.Unix(int64(exp), 0).Before(time.Now()) {
+		return nil, errors.New("invalid id token: token expired")
+	}
+
+	if n, _ := mc["nonce"].(string); n != nonce {
+		return nil, errors.New("invalid id token: nonce mismatch")
+	}
+
+	c := Claims{Issuer: p.Issuer}
+	c.Subject, _ = mc["sub"].(string)
+	c.Email, _ = mc["email"].(string)
+	c.PreferredUsername, _ = mc["preferred_username"].(string)
+	c.Name, _ = mc["name"].(string)
+	c.Picture, _ = mc["picture"].(string)
+
+	// some providers encode email_verified as a string
+	switch v := mc["email_verified"].(type) {
+	case bool:
+		c.EmailVerified = v
+	case string:
+		c.EmailVerified = v == "true"
+	}
+
+	if c.Subject == "" {
+		return nil, errors.New("invalid id token: subject is empty")
+	}
+
+	return &c, nil
+}
+
+func hasAudience(aud interface{}, clientID string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == clientID
+	case []interface{}:
+		for _, a := range v {
+			if s, ok := a.(string); ok && s == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// key returns the verification key for kid, refreshing the key set once when it's unknown
+func (p *Provider) key(ctx context.Context, kid string) (interface{}, error) {
+	p.mu.Lock()
+	k, ok := p.keys[kid]
+	p.mu.Unlock()
+	if ok {
+		return k, nil
+	}
+
+	if err := p.refreshKeys(ctx); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if k, ok := p.keys[kid]; ok {
+		return k, nil
+	}
+
+	// tokens without kid are accepted only when the key set has a single key
+	if kid == "" && len(p.keys) == 1 {
+		for _, k := range p.keys {
+			return k, nil
+		}
+	}
+
+	return nil, fmt.Errorf("signing key (kid=%q) not found", kid)
+}
+
+func (p *Provider) refreshKeys(ctx context.Context) error {
+	m, err := p.discover(ctx)
+	if err != nil {
+		return err
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := p.getJSON(ctx, m.JWKSURI, &set); err != nil {
+		return fmt.Errorf("failed to fetch key set: %w", err)
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, k := range set.Keys {
+		pub, err := k.publicKey()
+		if err != nil {
+			// skip keys we cannot use rather than failing the whole set
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.mu.Unlock()
+
+	return nil
+}
+
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve: %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,326 @@
+// Package outbox dispatches domain events recorded in the outbox to
+// in-process subscribers, once across servers or on every server
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// MaxAttempts is the number of attempts before an event is given up
+	MaxAttempts = 10
+
+	// lease is how long claimed events are hidden from the other relays
+	lease = time.Minute
+	// batchSize is the number of events claimed at once
+	batchSize = 100
+
+	baseBackoff = 5 * time.Second
+	maxBackoff  = time.Hour
+
+	// gapTimeout is how long followers wait for the events of ids skipped by
+	// the ones recorded later, i.e. of transactions still in flight
+	gapTimeout = time.Minute
+)
+
+// Store persists outbox events and which subscribers have handled them
+type Store interface {
+	Claim(now time.Time, lease time.Duration, limit int) ([]model.OutboxEvent, error)
+	Update(e *model.OutboxEvent) error
+	HasReceipt(eventID, subscriber string) (bool, error)
+	CreateReceipt(eventID, subscriber string) error
+	LastIDBefore(t time.Time) (uint, error)
+	After(id uint, limit int) ([]model.OutboxEvent, error)
+	Find(ids []uint) ([]model.OutboxEvent, error)
+}
+
+// Func handles a domain event. An event is delivered at least once, so
+// side effects outside the database should be keyed by the event id.
+type Func func(ctx context.Context, e *pb.DomainEvent) error
+
+type subscriber struct {
+	name   string
+	types  map[string]bool
+	handle Func
+}
+
+// Relay dispatches outbox events to subscribers in the order they were recorded
+type Relay struct {
+	logger      *zerolog.Logger
+	store       Store
+	subscribers []subscriber
+	followers   []subscriber
+	wake        chan struct{}
+
+	// started is when the relay was created; followers get the events
+	// recorded since
+	started   time.Time
+	following bool
+	cursor    uint               // the last event id followed
+	gaps      map[uint]time.Time // ids below cursor not seen yet, and when they were skipped
+}
+
+// NewRelay returns a new Relay
+func NewRelay(l *zerolog.Logger, s Store) *Relay {
+	return &Relay{
+		logger:  l,
+		store:   s,
+		wake:    make(chan struct{}, 1),
+		started: time.Now(),
+		gaps:    map[uint]time.Time{},
+	}
+}
+
+// Subscribe registers f to handle events of types. name identifies the
+// subscriber in receipts, so it must stay the same across releases.
+// Subscribers must be registered before Run.
+func (r *Relay) Subscribe(name string, f Func, types ...string) {
+	s := subscriber{name: name, types: map[string]bool{}, handle: f}
+	for _, t := range types {
+		s.types[t] = true
+	}
+	r.subscribers = append(r.subscribers, s)
+}
+
+// Follow registers f to handle events of types on this server. Every server
+// reads the outbox on its own and passes each event to f once, without
+// receipts or retries, which suits pushing events to the clients connected to
+// the server. Events of transactions committed late come out of order.
+// Followers must be registered before Run.
+func (r *Relay) Follow(f Func, types ...string) {
+	s := subscriber{types: map[string]bool{}, handle: f}
+	for _, t := range types {
+		s.types[t] = true
+	}
+	r.followers = append(r.followers, s)
+}
+
+// Wake makes Run dispatch without waiting for the next poll, e.g. right
+// after a change recorded events
+func (r *Relay) Wake() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run dispatches and follows events every interval, or when woken, until ctx
+// is canceled
+func (r *Relay) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		if err := r.DispatchAll(ctx); err != nil {
+			r.logger.Error().Err(err).Msg("failed to dispatch domain events")
+		}
+		if err := r.FollowAll(ctx, time.Now()); err != nil {
+			r.logger.Error().Err(err).Msg("failed to follow domain events")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		case <-r.wake:
+		}
+	}
+}
+
+// DispatchAll dispatches the events due now
+func (r *Relay) DispatchAll(ctx context.Context) error {
+	for {
+		n, err := r.Dispatch(ctx, time.Now())
+		if err != nil || n < batchSize || ctx.Err() != nil {
+			return err
+		}
+	}
+}
+
+// Dispatch dispatches a batch of events due at now and returns its size.
+// An event is retried with backoff until all its subscribers have handled it,
+// skipping the ones which already have.
+func (r *Relay) Dispatch(ctx context.Context, now time.Time) (int, error) {
+	es, err := r.store.Claim(now, lease, batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+
+	for i := range es {
+		o := &es[i]
+
+		err := r.dispatch(ctx, o)
+		o.Attempts++
+
+		switch {
+		case err == nil:
+			t := time.Now()
+			o.DispatchedAt = &t
+			o.NextAttemptAt = nil
+			o.LastError = ""
+		case o.Attempts >= MaxAttempts:
+			r.logger.Error().Err(err).Str("event", o.EventID).Msg("gave up domain event")
+			o.NextAttemptAt = nil
+			o.LastError = err.Error()
+		default:
+			t := now.Add(Backoff(int(o.Attempts)))
+			o.NextAttemptAt = &t
+			o.LastError = err.Error()
+		}
+
+		if err := r.store.Update(o); err != nil {
+			r.logger.Error().Err(err).Str("event", o.EventID).Msg("failed to save outbox event")
+		}
+	}
+
+	return len(es), nil
+}
+
+// dispatch passes the event to its subscribers and returns the last error
+func (r *Relay) dispatch(ctx context.Context, o *model.OutboxEvent) error {
+	e, err := o.DomainEvent()
+	if err != nil {
+		return fmt.Errorf("failed to decode event: %w", err)
+	}
+
+	var last error
+	for _, s := range r.subscribers {
+		if !s.types[e.Type] {
+			continue
+		}
+
+		done, err := r.store.HasReceipt(e.Id, s.name)
+		if err != nil {
+			last = fmt.Errorf("failed to get receipt of %s: %w", s.name, err)
+			continue
+		}
+		if done {
+			continue
+		}
+
+		if err := handle(ctx, s, e); err != nil {
+			r.logger.Error().Err(err).Str("event", e.Id).Str("subscriber", s.name).Msg("subscriber failed")
+			last = fmt.Errorf("%s: %w", s.name, err)
+			continue
+		}
+
+		if err := r.store.CreateReceipt(e.Id, s.name); err != nil {
+			last = fmt.Errorf("failed to save receipt of %s: %w", s.name, err)
+		}
+	}
+
+	return last
+}
+
+// FollowAll passes the events recorded since the last call to the followers.
+// Ids skipped by later events are looked up again until gapTimeout after now.
+func (r *Relay) FollowAll(ctx context.Context, now time.Time) error {
+	if len(r.followers) == 0 {
+		return nil
+	}
+
+	if !r.following {
+		// the database may keep whole seconds only
+		id, err := r.store.LastIDBefore(r.started.Truncate(time.Second))
+		if err != nil {
+			return fmt.Errorf("failed to get the last outbox event: %w", err)
+		}
+		r.cursor = id
+		r.following = true
+	}
+
+	if len(r.gaps) > 0 {
+		ids := make([]uint, 0, len(r.gaps))
+		for id := range r.gaps {
+			ids = append(ids, id)
+		}
+
+		es, err := r.store.Find(ids)
+		if err != nil {
+			return fmt.Errorf("failed to get outbox events: %w", err)
+		}
+		for i := range es {
+			delete(r.gaps, es[i].ID)
+			r.follow(ctx, &es[i])
+		}
+	}
+
+	for {
+		es, err := r.store.After(r.cursor, batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to get outbox events: %w", err)
+		}
+
+		for i := range es {
+			o := &es[i]
+			// a jump larger than a batch is not made by transactions in flight
+			if o.ID-r.cursor <= batchSize {
+				for id := r.cursor + 1; id < o.ID; id++ {
+					r.gaps[id] = now
+				}
+			}
+			r.cursor = o.ID
+			r.follow(ctx, o)
+		}
+
+		if len(es) < batchSize || ctx.Err() != nil {
+			break
+		}
+	}
+
+	for id, t := range r.gaps {
+		if now.Sub(t) >= gapTimeout {
+			delete(r.gaps, id)
+		}
+	}
+
+	return nil
+}
+
+// follow passes the event to its followers. Errors are only logged since
+// followers are not retried.
+func (r *Relay) follow(ctx context.Context, o *model.OutboxEvent) {
+	e, err := o.DomainEvent()
+	if err != nil {
+		r.logger.Error().Err(err).Str("event", o.EventID).Msg("failed to decode domain event")
+		return
+	}
+
+	for _, s := range r.followers {
+		if !s.types[e.Type] {
+			continue
+		}
+		if err := handle(ctx, s, e); err != nil {
+			r.logger.Error().Err(err).Str("event", e.Id).Msg("follower failed")
+		}
+	}
+}
+
+// handle runs the subscriber and turns a panic into an error
+func handle(ctx context.Context, s subscriber, e *pb.DomainEvent) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return s.handle(ctx, e)
+}
+
+// Backoff returns how long to wait before the next attempt after the
+// attempt-th one failed. It doubles from 5 seconds up to an hour.
+func Backoff(attempt int) time.Duration {
+	d := baseBackoff
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return d
+}
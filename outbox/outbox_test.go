@@ -0,0 +1,233 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryStore is a Store keeping events in memory
+type memoryStore struct {
+	events   map[uint]*model.OutboxEvent
+	receipts map[string]bool
+	lastID   uint
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{events: map[uint]*model.OutboxEvent{}, receipts: map[string]bool{}}
+}
+
+func (s *memoryStore) add(t *testing.T, typ string, articleID uint) *model.OutboxEvent {
+	e, err := model.NewOutboxEvent(typ, 1, func(e *pb.DomainEvent) {
+		e.ArticleId = uint64(articleID)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.lastID++
+	e.ID = s.lastID
+	e.CreatedAt = time.Now()
+	s.events[e.ID] = e
+	return e
+}
+
+func (s *memoryStore) Claim(now time.Time, lease time.Duration, limit int) ([]model.OutboxEvent, error) {
+	var es []model.OutboxEvent
+	for _, e := range s.events {
+		if e.NextAttemptAt != nil && !e.NextAttemptAt.After(now) {
+			es = append(es, *e)
+			t := now.Add(lease)
+			e.NextAttemptAt = &t
+		}
+	}
+	sort.Slice(es, func(i, j int) bool { return es[i].ID < es[j].ID })
+	if len(es) > limit {
+		es = es[:limit]
+	}
+	return es, nil
+}
+
+func (s *memoryStore) Update(e *model.OutboxEvent) error {
+	c := *e
+	s.events[e.ID] = &c
+	return nil
+}
+
+func (s *memoryStore) HasReceipt(eventID, subscriber string) (bool, error) {
+	return s.receipts[eventID+" "+subscriber], nil
+}
+
+func (s *memoryStore) CreateReceipt(eventID, subscriber string) error {
+	s.receipts[eventID+" "+subscriber] = true
+	return nil
+}
+
+func (s *memoryStore) LastIDBefore(t time.Time) (uint, error) {
+	var id uint
+	for _, e := range s.events {
+		if e.CreatedAt.Before(t) && e.ID > id {
+			id = e.ID
+		}
+	}
+	return id, nil
+}
+
+func (s *memoryStore) After(id uint, limit int) ([]model.OutboxEvent, error) {
+	var es []model.OutboxEvent
+	for _, e := range s.events {
+		if e.ID > id {
+			es = append(es, *e)
+		}
+	}
+	sort.Slice(es, func(i, j int) bool { return es[i].ID < es[j].ID })
+	if len(es) > limit {
+		es = es[:limit]
+	}
+	return es, nil
+}
+
+func (s *memoryStore) Find(ids []uint) ([]model.OutboxEvent, error) {
+	var es []model.OutboxEvent
+	for _, id := range ids {
+		if e, ok := s.events[id]; ok {
+			es = append(es, *e)
+		}
+	}
+	sort.Slice(es, func(i, j int) bool { return es[i].ID < es[j].ID })
+	return es, nil
+}
+
+func TestDispatch(t *testing.T) {
+	l := zerolog.New(ioutil.Discard)
+	s := newMemoryStore()
+	r := NewRelay(&l, s)
+
+	var favorited, commented []uint64
+	failing := true
+	r.Subscribe("favorites", func(ctx context.Context, e *pb.DomainEvent) error {
+		favorited = append(favorited, e.GetArticleId())
+		return nil
+	}, model.EventArticleFavorited)
+	r.Subscribe("comments", func(ctx context.Context, e *pb.DomainEvent) error {
+		if failing {
+			return errors.New("unavailable")
+		}
+		commented = append(commented, e.GetArticleId())
+		return nil
+	}, model.EventArticleFavorited, model.EventCommentCreated)
+	r.Subscribe("panicking", func(ctx context.Context, e *pb.DomainEvent) error {
+		panic("broken")
+	}, model.EventUserFollowed)
+
+	fav := s.add(t, model.EventArticleFavorited, 1)
+	com := s.add(t, model.EventCommentCreated, 2)
+	fol := s.add(t, model.EventUserFollowed, 0)
+
+	now := time.Now()
+	n, err := r.Dispatch(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, []uint64{1}, favorited)
+	assert.Empty(t, commented)
+
+	for _, e := range []*model.OutboxEvent{fav, com, fol} {
+		e = s.events[e.ID]
+		assert.Equal(t, int32(1), e.Attempts)
+		assert.Nil(t, e.DispatchedAt)
+		assert.Equal(t, now.Add(Backoff(1)), *e.NextAttemptAt)
+	}
+
+	// nothing is due before the backoff
+	n, err = r.Dispatch(context.Background(), now.Add(time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	// retried subscribers which handled an event already are skipped
+	failing = false
+	now = now.Add(Backoff(1))
+	_, err = r.Dispatch(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{1}, favorited)
+	assert.Equal(t, []uint64{1, 2}, commented)
+
+	assert.NotNil(t, s.events[fav.ID].DispatchedAt)
+	assert.Nil(t, s.events[fav.ID].NextAttemptAt)
+	assert.NotNil(t, s.events[com.ID].DispatchedAt)
+	assert.Contains(t, s.events[fol.ID].LastError, "panic: broken")
+
+	// given up after the last attempt
+	for i := 2; i < MaxAttempts; i++ {
+		now = now.Add(Backoff(i))
+		_, err = r.Dispatch(context.Background(), now)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(MaxAttempts), s.events[fol.ID].Attempts)
+	assert.Nil(t, s.events[fol.ID].NextAttemptAt)
+	assert.Nil(t, s.events[fol.ID].DispatchedAt)
+}
+
+func TestFollow(t *testing.T) {
+	l := zerolog.New(ioutil.Discard)
+	s := newMemoryStore()
+
+	old := s.add(t, model.EventArticleFavorited, 1)
+	old.CreatedAt = time.Now().Add(-time.Minute)
+
+	r := NewRelay(&l, s)
+	var favorited []uint64
+	r.Follow(func(ctx context.Context, e *pb.DomainEvent) error {
+		favorited = append(favorited, e.GetArticleId())
+		return nil
+	}, model.EventArticleFavorited)
+	r.Follow(func(ctx context.Context, e *pb.DomainEvent) error {
+		panic("broken")
+	}, model.EventArticleFavorited)
+
+	s.add(t, model.EventArticleFavorited, 2)
+	late := s.add(t, model.EventArticleFavorited, 3)
+	delete(s.events, late.ID) // not committed yet
+	s.add(t, model.EventCommentCreated, 4)
+	s.add(t, model.EventArticleFavorited, 5)
+
+	// events recorded before the relay was created are not followed
+	now := time.Now()
+	assert.NoError(t, r.FollowAll(context.Background(), now))
+	assert.Equal(t, []uint64{2, 5}, favorited)
+
+	// each event is followed once
+	assert.NoError(t, r.FollowAll(context.Background(), now))
+	assert.Equal(t, []uint64{2, 5}, favorited)
+
+	// events committed late are followed too
+	s.events[late.ID] = late
+	assert.NoError(t, r.FollowAll(context.Background(), now.Add(time.Second)))
+	assert.Equal(t, []uint64{2, 5, 3}, favorited)
+
+	// but not later than gapTimeout
+	lost := s.add(t, model.EventArticleFavorited, 6)
+	delete(s.events, lost.ID)
+	s.add(t, model.EventArticleFavorited, 7)
+	assert.NoError(t, r.FollowAll(context.Background(), now))
+	assert.NoError(t, r.FollowAll(context.Background(), now.Add(gapTimeout)))
+	s.events[lost.ID] = lost
+	assert.NoError(t, r.FollowAll(context.Background(), now.Add(gapTimeout+time.Second)))
+	assert.Equal(t, []uint64{2, 5, 3, 7}, favorited)
+
+	// followers leave the events to be dispatched
+	assert.Nil(t, s.events[late.ID].DispatchedAt)
+	assert.Empty(t, s.receipts)
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 5*time.Second, Backoff(1))
+	assert.Equal(t, 10*time.Second, Backoff(2))
+	assert.Equal(t, time.Hour, Backoff(20))
+}
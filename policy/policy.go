@@ -0,0 +1,137 @@
+// Package policy centralizes authorization decisions based on user roles and account status
+package policy
+
+import (
+	"time"
+
+	"github.com/raahii/golang-grpc-realworld-example/model"
+)
+
+// rank orders roles by privilege
+func rank(role string) int {
+	switch role {
+	case model.RoleAdmin:
+		return 2
+	case model.RoleModerator:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// IsValidRole returns whether role is a known role
+func IsValidRole(role string) bool {
+	switch role {
+	case model.RoleUser, model.RoleModerator, model.RoleAdmin:
+		return true
+	}
+	return false
+}
+
+// IsAdmin returns whether u is an administrator
+func IsAdmin(u *model.User) bool {
+	return u != nil && u.Role == model.RoleAdmin
+}
+
+// IsModerator returns whether u is a moderator or an administrator
+func IsModerator(u *model.User) bool {
+	return u != nil && rank(u.Role) >= rank(model.RoleModerator)
+}
+
+// IsBanned returns whether u is banned
+func IsBanned(u *model.User) bool {
+	return u != nil && u.Status == model.StatusBanned
+}
+
+// IsSuspended returns whether u is suspended at now
+func IsSuspended(u *model.User, now time.Time) bool {
+	if u == nil || u.Status != model.StatusSuspended {
+		return false
+	}
+	return u.SuspendedUntil == nil || now.Before(*u.SuspendedUntil)
+}
+
+// CanSignIn returns whether u can sign in
+func CanSignIn(u *model.User) bool {
+	return u != nil && !IsBanned(u)
+}
+
+// CanWrite returns whether u can create or change content
+func CanWrite(u *model.User) bool {
+	return CanSignIn(u) && !IsSuspended(u, time.Now())
+}
+
+// CanUpdateArticle returns whether u can update the article
+func CanUpdateArticle(u *model.User, a *model.Article) bool {
+	return CanWrite(u) && a.UserID == u.ID
+}
+
+// CanDeleteArticle returns whether u can delete the article
+func CanDeleteArticle(u *model.User, a *model.Article) bool {
+	return CanUpdateArticle(u, a) || CanModerate(u)
+}
+
+// CanViewArticleRevisions returns whether u can read previous versions of the article
+func CanViewArticleRevisions(u *model.User, a *model.Article) bool {
+	return a.UserID == u.ID || CanModerate(u)
+}
+
+// CanViewArticleStats returns whether u can read the views and other stats of the article
+func CanViewArticleStats(u *model.User, a *model.Article) bool {
+	return a.UserID == u.ID
+}
+
+// CanUpdateComment returns whether u can edit the comment
+func CanUpdateComment(u *model.User, c *model.Comment) bool {
+	return CanWrite(u) && c.UserID == u.ID
+}
+
+// CanDeleteComment returns whether u can delete the comment
+func CanDeleteComment(u *model.User, c *model.Comment) bool {
+	return CanUpdateComment(u, c) || CanModerate(u)
+}
+
+// CanModerate returns whether u can moderate content of any user
+func CanModerate(u *model.User) bool {
+	return IsModerator(u) && CanWrite(u)
+}
+
+// CanListUsers returns whether u can list all of users
+func CanListUsers(u *model.User) bool {
+	return CanModerate(u)
+}
+
+// CanSuspend returns whether actor can suspend or reinstate target.
+// Moderators can suspend users, administrators can also suspend moderators.
+func CanSuspend(actor, target *model.User) bool {
+	return CanModerate(actor) && actor.ID != target.ID && rank(actor.Role) > rank(target.Role)
+}
+
+// CanBan returns whether actor can ban target
+func CanBan(actor, target *model.User) bool {
+	return IsAdmin(actor) && CanWrite(actor) && actor.ID != target.ID && !IsAdmin(target)
+}
+
+// CanAssignRole returns whether actor can change the role of target
+func CanAssignRole(actor, target *model.User) bool {
+	return IsAdmin(actor) && CanWrite(actor) && actor.ID != target.ID
+}
+
+// CanViewArticle returns whether u can read the article. Hidden articles and
+// drafts are only visible to the author and moderators. u may be nil for guests.
+func CanViewArticle(u *model.User, a *model.Article) bool {
+	if !a.Hidden && a.Status != model.ArticleDraft {
+		return true
+	}
+	return u != nil && (a.UserID == u.ID || CanModerate(u))
+}
+
+// CanReportArticle returns whether u can report the article
+func CanReportArticle(u *model.User, a *model.Article) bool {
+	return CanWrite(u) && a.UserID != u.ID
+}
+
+// CanReportComment returns whether u can report the comment
+func CanReportComment(u *model.User, c *model.Comment) bool {
+	return CanWrite(u) && c.UserID != u.ID
+}
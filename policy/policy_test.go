@@ -0,0 +1,143 @@
+package policy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSuspended(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		title    string
+		user     *model.User
+		expected bool
+	}{
+		{"active user", &model.User{Status: model.StatusActive}, false},
+		{"suspended indefinitely", &model.User{Status: model.StatusSuspended}, true},
+		{"suspended until future", &model.User{Status: model.StatusSuspended, SuspendedUntil: &future}, true},
+		{"suspension expired", &model.User{Status: model.StatusSuspended, SuspendedUntil: &past}, false},
+		{"nil user", nil, false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, IsSuspended(tt.user, now), tt.title)
+	}
+}
+
+func TestContentPolicy(t *testing.T) {
+	author := &model.User{Role: model.RoleUser}
+	author.ID = 1
+	other := &model.User{Role: model.RoleUser}
+	other.ID = 2
+	moderator := &model.User{Role: model.RoleModerator}
+	moderator.ID = 3
+	suspended := &model.User{Role: model.RoleUser, Status: model.StatusSuspended}
+	suspended.ID = 1
+	suspendedModerator := &model.User{Role: model.RoleModerator, Status: model.StatusSuspended}
+	suspendedModerator.ID = 4
+
+	article := &model.Article{UserID: 1}
+	comment := &model.Comment{UserID: 1}
+
+	tests := []struct {
+		title            string
+		user             *model.User
+		canUpdate        bool
+		canDelete        bool
+		canViewRevisions bool
+		canViewStats     bool
+	}{
+		{"author", author, true, true, true, true},
+		{"other user", other, false, false, false, false},
+		{"moderator", moderator, false, true, true, false},
+		{"suspended author", suspended, false, false, true, true},
+		{"suspended moderator", suspendedModerator, false, false, false, false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.canUpdate, CanUpdateArticle(tt.user, article), tt.title)
+		assert.Equal(t, tt.canDelete, CanDeleteArticle(tt.user, article), tt.title)
+		assert.Equal(t, tt.canUpdate, CanUpdateComment(tt.user, comment), tt.title)
+		assert.Equal(t, tt.canDelete, CanDeleteComment(tt.user, comment), tt.title)
+		assert.Equal(t, tt.canViewRevisions, CanViewArticleRevisions(tt.user, article), tt.title)
+		assert.Equal(t, tt.canViewStats, CanViewArticleStats(tt.user, article), tt.title)
+	}
+}
+
+func TestUserManagementPolicy(t *testing.T) {
+	user := &model.User{Role: model.RoleUser}
+	user.ID = 1
+	moderator := &model.User{Role: model.RoleModerator}
+	moderator.ID = 2
+	admin := &model.User{Role: model.RoleAdmin}
+	admin.ID = 3
+	otherAdmin := &model.User{Role: model.RoleAdmin}
+	otherAdmin.ID = 4
+
+	tests := []struct {
+		title      string
+		actor      *model.User
+		target     *model.User
+		canSuspend bool
+		canBan     bool
+		canAssign  bool
+	}{
+		{"user -> user", user, moderator, false, false, false},
+		{"moderator -> user", moderator, user, true, false, false},
+		{"moderator -> admin", moderator, admin, false, false, false},
+		{"admin -> user", admin, user, true, true, true},
+		{"admin -> moderator", admin, moderator, true, true, true},
+		{"admin -> other admin", admin, otherAdmin, false, false, true},
+		{"admin -> self", admin, admin, false, false, false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.canSuspend, CanSuspend(tt.actor, tt.target), tt.title)
+		assert.Equal(t, tt.canBan, CanBan(tt.actor, tt.target), tt.title)
+		assert.Equal(t, tt.canAssign, CanAssignRole(tt.actor, tt.target), tt.title)
+	}
+}
+
+func TestReportPolicy(t *testing.T) {
+	author := &model.User{Role: model.RoleUser}
+	author.ID = 1
+	other := &model.User{Role: model.RoleUser}
+	other.ID = 2
+	moderator := &model.User{Role: model.RoleModerator}
+	moderator.ID = 3
+	suspended := &model.User{Role: model.RoleUser, Status: model.StatusSuspended}
+	suspended.ID = 4
+
+	hidden := &model.Article{UserID: 1, Hidden: true}
+	comment := &model.Comment{UserID: 1}
+
+	tests := []struct {
+		title     string
+		user      *model.User
+		canView   bool
+		canReport bool
+	}{
+		{"guest", nil, false, false},
+		{"author", author, true, false},
+		{"other user", other, false, true},
+		{"moderator", moderator, true, true},
+		{"suspended user", suspended, false, false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.canView, CanViewArticle(tt.user, hidden), tt.title)
+		assert.True(t, CanViewArticle(tt.user, &model.Article{UserID: 1}), tt.title)
+		assert.Equal(t, tt.canView, CanViewArticle(tt.user, &model.Article{UserID: 1, Status: model.ArticleDraft}), tt.title)
+		assert.True(t, CanViewArticle(tt.user, &model.Article{UserID: 1, Status: model.ArticleUnlisted}), tt.title)
+		if tt.user != nil {
+			assert.Equal(t, tt.canReport, CanReportArticle(tt.user, hidden), tt.title)
+			assert.Equal(t, tt.canReport, CanReportComment(tt.user, comment), tt.title)
+		}
+	}
+}
@@ -36,16 +36,23 @@ type Article struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Slug           string   `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
-	Title          string   `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
-	Description    string   `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
-	Body           string   `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`
-	TagList        []string `protobuf:"bytes,5,rep,name=tagList,proto3" json:"tagList,omitempty"`
-	CreatedAt      string   `protobuf:"bytes,6,opt,name=createdAt,proto3" json:"createdAt,omitempty"`
-	UpdatedAt      string   `protobuf:"bytes,7,opt,name=updatedAt,proto3" json:"updatedAt,omitempty"`
-	Favorited      bool     `protobuf:"varint,8,opt,name=favorited,proto3" json:"favorited,omitempty"`
-	FavoritesCount int32    `protobuf:"varint,9,opt,name=favoritesCount,proto3" json:"favoritesCount,omitempty"`
-	Author         *Profile `protobuf:"bytes,10,opt,name=author,proto3" json:"author,omitempty"`
+	Slug               string   `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	Title              string   `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Description        string   `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Body               string   `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`
+	TagList            []string `protobuf:"bytes,5,rep,name=tagList,proto3" json:"tagList,omitempty"`
+	CreatedAt          string   `protobuf:"bytes,6,opt,name=createdAt,proto3" json:"createdAt,omitempty"`
+	UpdatedAt          string   `protobuf:"bytes,7,opt,name=updatedAt,proto3" json:"updatedAt,omitempty"`
+	Favorited          bool     `protobuf:"varint,8,opt,name=favorited,proto3" json:"favorited,omitempty"`
+	FavoritesCount     int32    `protobuf:"varint,9,opt,name=favoritesCount,proto3" json:"favoritesCount,omitempty"`
+	Author             *Profile `protobuf:"bytes,10,opt,name=author,proto3" json:"author,omitempty"`
+	Status             string   `protobuf:"bytes,11,opt,name=status,proto3" json:"status,omitempty"`
+	PublishAt          string   `protobuf:"bytes,12,opt,name=publishAt,proto3" json:"publishAt,omitempty"`
+	BodyHtml           string   `protobuf:"bytes,13,opt,name=bodyHtml,proto3" json:"bodyHtml,omitempty"`
+	ReadingTimeMinutes int32    `protobuf:"varint,14,opt,name=readingTimeMinutes,proto3" json:"readingTimeMinutes,omitempty"`
+	Excerpt            string   `protobuf:"bytes,15,opt,name=excerpt,proto3" json:"excerpt,omitempty"`
+	Bookmarked         bool     `protobuf:"varint,16,opt,name=bookmarked,proto3" json:"bookmarked,omitempty"`
+	PublishedAt        string   `protobuf:"bytes,17,opt,name=publishedAt,proto3" json:"publishedAt,omitempty"`
 }
 
 func (x *Article) Reset() {
@@ -150,16 +157,70 @@ func (x *Article) GetAuthor() *Profile {
 	return nil
 }
 
+func (x *Article) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Article) GetPublishAt() string {
+	if x != nil {
+		return x.PublishAt
+	}
+	return ""
+}
+
+func (x *Article) GetBodyHtml() string {
+	if x != nil {
+		return x.BodyHtml
+	}
+	return ""
+}
+
+func (x *Article) GetReadingTimeMinutes() int32 {
+	if x != nil {
+		return x.ReadingTimeMinutes
+	}
+	return 0
+}
+
+func (x *Article) GetExcerpt() string {
+	if x != nil {
+		return x.Excerpt
+	}
+	return ""
+}
+
+func (x *Article) GetBookmarked() bool {
+	if x != nil {
+		return x.Bookmarked
+	}
+	return false
+}
+
+func (x *Article) GetPublishedAt() string {
+	if x != nil {
+		return x.PublishedAt
+	}
+	return ""
+}
+
 type Comment struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Id        string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	CreatedAt string   `protobuf:"bytes,2,opt,name=createdAt,proto3" json:"createdAt,omitempty"`
-	UpdatedAt string   `protobuf:"bytes,3,opt,name=updatedAt,proto3" json:"updatedAt,omitempty"`
-	Body      string   `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`
-	Author    *Profile `protobuf:"bytes,5,opt,name=author,proto3" json:"author,omitempty"`
+	Id           string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CreatedAt    string   `protobuf:"bytes,2,opt,name=createdAt,proto3" json:"createdAt,omitempty"`
+	UpdatedAt    string   `protobuf:"bytes,3,opt,name=updatedAt,proto3" json:"updatedAt,omitempty"`
+	Body         string   `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`
+	Author       *Profile `protobuf:"bytes,5,opt,name=author,proto3" json:"author,omitempty"`
+	ParentId     string   `protobuf:"bytes,6,opt,name=parentId,proto3" json:"parentId,omitempty"`
+	RepliesCount int32    `protobuf:"varint,7,opt,name=repliesCount,proto3" json:"repliesCount,omitempty"`
+	Deleted      bool     `protobuf:"varint,8,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	Edited       bool     `protobuf:"varint,9,opt,name=edited,proto3" json:"edited,omitempty"`
+	BodyHtml     string   `protobuf:"bytes,10,opt,name=bodyHtml,proto3" json:"bodyHtml,omitempty"`
 }
 
 func (x *Comment) Reset() {
@@ -229,17 +290,57 @@ func (x *Comment) GetAuthor() *Profile {
 	return nil
 }
 
-// request message
-type CreateAritcleRequest struct {
+func (x *Comment) GetParentId() string {
+	if x != nil {
+		return x.ParentId
+	}
+	return ""
+}
+
+func (x *Comment) GetRepliesCount() int32 {
+	if x != nil {
+		return x.RepliesCount
+	}
+	return 0
+}
+
+func (x *Comment) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
+func (x *Comment) GetEdited() bool {
+	if x != nil {
+		return x.Edited
+	}
+	return false
+}
+
+func (x *Comment) GetBodyHtml() string {
+	if x != nil {
+		return x.BodyHtml
+	}
+	return ""
+}
+
+type ArticleRevision struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Article *CreateAritcleRequest_Article `protobuf:"bytes,1,opt,name=article,proto3" json:"article,omitempty"`
+	Number      int32    `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	Title       string   `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Description string   `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Body        string   `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`
+	Editor      *Profile `protobuf:"bytes,5,opt,name=editor,proto3" json:"editor,omitempty"`
+	CreatedAt   string   `protobuf:"bytes,6,opt,name=createdAt,proto3" json:"createdAt,omitempty"`
+	BodyHtml    string   `protobuf:"bytes,7,opt,name=bodyHtml,proto3" json:"bodyHtml,omitempty"`
 }
 
-func (x *CreateAritcleRequest) Reset() {
-	*x = CreateAritcleRequest{}
+func (x *ArticleRevision) Reset() {
+	*x = ArticleRevision{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_article_proto_msgTypes[2]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -247,13 +348,13 @@ func (x *CreateAritcleRequest) Reset() {
 	}
 }
 
-func (x *CreateAritcleRequest) String() string {
+func (x *ArticleRevision) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateAritcleRequest) ProtoMessage() {}
+func (*ArticleRevision) ProtoMessage() {}
 
-func (x *CreateAritcleRequest) ProtoReflect() protoreflect.Message {
+func (x *ArticleRevision) ProtoReflect() protoreflect.Message {
 	mi := &file_article_proto_msgTypes[2]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -265,28 +366,73 @@ func (x *CreateAritcleRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateAritcleRequest.ProtoReflect.Descriptor instead.
-func (*CreateAritcleRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use ArticleRevision.ProtoReflect.Descriptor instead.
+func (*ArticleRevision) Descriptor() ([]byte, []int) {
 	return file_article_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *CreateAritcleRequest) GetArticle() *CreateAritcleRequest_Article {
+func (x *ArticleRevision) GetNumber() int32 {
 	if x != nil {
-		return x.Article
+		return x.Number
+	}
+	return 0
+}
+
+func (x *ArticleRevision) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *ArticleRevision) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *ArticleRevision) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *ArticleRevision) GetEditor() *Profile {
+	if x != nil {
+		return x.Editor
 	}
 	return nil
 }
 
-type GetArticleRequest struct {
+func (x *ArticleRevision) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *ArticleRevision) GetBodyHtml() string {
+	if x != nil {
+		return x.BodyHtml
+	}
+	return ""
+}
+
+type DailyArticleStats struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Slug string `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	Date      string `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"`
+	Views     int64  `protobuf:"varint,2,opt,name=views,proto3" json:"views,omitempty"`
+	Favorites int64  `protobuf:"varint,3,opt,name=favorites,proto3" json:"favorites,omitempty"`
+	Comments  int64  `protobuf:"varint,4,opt,name=comments,proto3" json:"comments,omitempty"`
 }
 
-func (x *GetArticleRequest) Reset() {
-	*x = GetArticleRequest{}
+func (x *DailyArticleStats) Reset() {
+	*x = DailyArticleStats{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_article_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -294,13 +440,13 @@ func (x *GetArticleRequest) Reset() {
 	}
 }
 
-func (x *GetArticleRequest) String() string {
+func (x *DailyArticleStats) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetArticleRequest) ProtoMessage() {}
+func (*DailyArticleStats) ProtoMessage() {}
 
-func (x *GetArticleRequest) ProtoReflect() protoreflect.Message {
+func (x *DailyArticleStats) ProtoReflect() protoreflect.Message {
 	mi := &file_article_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -312,32 +458,53 @@ func (x *GetArticleRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetArticleRequest.ProtoReflect.Descriptor instead.
-func (*GetArticleRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use DailyArticleStats.ProtoReflect.Descriptor instead.
+func (*DailyArticleStats) Descriptor() ([]byte, []int) {
 	return file_article_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *GetArticleRequest) GetSlug() string {
+func (x *DailyArticleStats) GetDate() string {
 	if x != nil {
-		return x.Slug
+		return x.Date
 	}
 	return ""
 }
 
-type GetArticlesRequest struct {
+func (x *DailyArticleStats) GetViews() int64 {
+	if x != nil {
+		return x.Views
+	}
+	return 0
+}
+
+func (x *DailyArticleStats) GetFavorites() int64 {
+	if x != nil {
+		return x.Favorites
+	}
+	return 0
+}
+
+func (x *DailyArticleStats) GetComments() int64 {
+	if x != nil {
+		return x.Comments
+	}
+	return 0
+}
+
+type ArticleStats struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Tag       string `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"`
-	Author    string `protobuf:"bytes,2,opt,name=author,proto3" json:"author,omitempty"`
-	Favorited string `protobuf:"bytes,3,opt,name=favorited,proto3" json:"favorited,omitempty"`
-	Limit     int64  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
-	Offset    int64  `protobuf:"varint,5,opt,name=offset,proto3" json:"offset,omitempty"`
+	Slug           string               `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	ViewsCount     int64                `protobuf:"varint,2,opt,name=viewsCount,proto3" json:"viewsCount,omitempty"`
+	FavoritesCount int32                `protobuf:"varint,3,opt,name=favoritesCount,proto3" json:"favoritesCount,omitempty"`
+	CommentsCount  int32                `protobuf:"varint,4,opt,name=commentsCount,proto3" json:"commentsCount,omitempty"`
+	Daily          []*DailyArticleStats `protobuf:"bytes,5,rep,name=daily,proto3" json:"daily,omitempty"`
 }
 
-func (x *GetArticlesRequest) Reset() {
-	*x = GetArticlesRequest{}
+func (x *ArticleStats) Reset() {
+	*x = ArticleStats{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_article_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -345,13 +512,13 @@ func (x *GetArticlesRequest) Reset() {
 	}
 }
 
-func (x *GetArticlesRequest) String() string {
+func (x *ArticleStats) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetArticlesRequest) ProtoMessage() {}
+func (*ArticleStats) ProtoMessage() {}
 
-func (x *GetArticlesRequest) ProtoReflect() protoreflect.Message {
+func (x *ArticleStats) ProtoReflect() protoreflect.Message {
 	mi := &file_article_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -363,57 +530,58 @@ func (x *GetArticlesRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetArticlesRequest.ProtoReflect.Descriptor instead.
-func (*GetArticlesRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use ArticleStats.ProtoReflect.Descriptor instead.
+func (*ArticleStats) Descriptor() ([]byte, []int) {
 	return file_article_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *GetArticlesRequest) GetTag() string {
+func (x *ArticleStats) GetSlug() string {
 	if x != nil {
-		return x.Tag
+		return x.Slug
 	}
 	return ""
 }
 
-func (x *GetArticlesRequest) GetAuthor() string {
+func (x *ArticleStats) GetViewsCount() int64 {
 	if x != nil {
-		return x.Author
+		return x.ViewsCount
 	}
-	return ""
+	return 0
 }
 
-func (x *GetArticlesRequest) GetFavorited() string {
+func (x *ArticleStats) GetFavoritesCount() int32 {
 	if x != nil {
-		return x.Favorited
+		return x.FavoritesCount
 	}
-	return ""
+	return 0
 }
 
-func (x *GetArticlesRequest) GetLimit() int64 {
+func (x *ArticleStats) GetCommentsCount() int32 {
 	if x != nil {
-		return x.Limit
+		return x.CommentsCount
 	}
 	return 0
 }
 
-func (x *GetArticlesRequest) GetOffset() int64 {
+func (x *ArticleStats) GetDaily() []*DailyArticleStats {
 	if x != nil {
-		return x.Offset
+		return x.Daily
 	}
-	return 0
+	return nil
 }
 
-type GetFeedArticlesRequest struct {
+type ArticleSearchHit struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Limit  int64 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
-	Offset int64 `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	Article  *Article `protobuf:"bytes,1,opt,name=article,proto3" json:"article,omitempty"`
+	Snippets []string `protobuf:"bytes,2,rep,name=snippets,proto3" json:"snippets,omitempty"`
+	Score    float64  `protobuf:"fixed64,3,opt,name=score,proto3" json:"score,omitempty"`
 }
 
-func (x *GetFeedArticlesRequest) Reset() {
-	*x = GetFeedArticlesRequest{}
+func (x *ArticleSearchHit) Reset() {
+	*x = ArticleSearchHit{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_article_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -421,13 +589,13 @@ func (x *GetFeedArticlesRequest) Reset() {
 	}
 }
 
-func (x *GetFeedArticlesRequest) String() string {
+func (x *ArticleSearchHit) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetFeedArticlesRequest) ProtoMessage() {}
+func (*ArticleSearchHit) ProtoMessage() {}
 
-func (x *GetFeedArticlesRequest) ProtoReflect() protoreflect.Message {
+func (x *ArticleSearchHit) ProtoReflect() protoreflect.Message {
 	mi := &file_article_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -439,35 +607,50 @@ func (x *GetFeedArticlesRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetFeedArticlesRequest.ProtoReflect.Descriptor instead.
-func (*GetFeedArticlesRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use ArticleSearchHit.ProtoReflect.Descriptor instead.
+func (*ArticleSearchHit) Descriptor() ([]byte, []int) {
 	return file_article_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *GetFeedArticlesRequest) GetLimit() int64 {
+func (x *ArticleSearchHit) GetArticle() *Article {
 	if x != nil {
-		return x.Limit
+		return x.Article
 	}
-	return 0
+	return nil
 }
 
-func (x *GetFeedArticlesRequest) GetOffset() int64 {
+func (x *ArticleSearchHit) GetSnippets() []string {
 	if x != nil {
-		return x.Offset
+		return x.Snippets
+	}
+	return nil
+}
+
+func (x *ArticleSearchHit) GetScore() float64 {
+	if x != nil {
+		return x.Score
 	}
 	return 0
 }
 
-type UpdateArticleRequest struct {
+type Report struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Article *UpdateArticleRequest_Article `protobuf:"bytes,1,opt,name=article,proto3" json:"article,omitempty"`
+	Id         string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type       string   `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Slug       string   `protobuf:"bytes,3,opt,name=slug,proto3" json:"slug,omitempty"`
+	CommentId  string   `protobuf:"bytes,4,opt,name=commentId,proto3" json:"commentId,omitempty"`
+	Reason     string   `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	Status     string   `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`
+	Reporter   *Profile `protobuf:"bytes,7,opt,name=reporter,proto3" json:"reporter,omitempty"`
+	CreatedAt  string   `protobuf:"bytes,8,opt,name=createdAt,proto3" json:"createdAt,omitempty"`
+	ResolvedAt string   `protobuf:"bytes,9,opt,name=resolvedAt,proto3" json:"resolvedAt,omitempty"`
 }
 
-func (x *UpdateArticleRequest) Reset() {
-	*x = UpdateArticleRequest{}
+func (x *Report) Reset() {
+	*x = Report{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_article_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -475,13 +658,13 @@ func (x *UpdateArticleRequest) Reset() {
 	}
 }
 
-func (x *UpdateArticleRequest) String() string {
+func (x *Report) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateArticleRequest) ProtoMessage() {}
+func (*Report) ProtoMessage() {}
 
-func (x *UpdateArticleRequest) ProtoReflect() protoreflect.Message {
+func (x *Report) ProtoReflect() protoreflect.Message {
 	mi := &file_article_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -493,28 +676,85 @@ func (x *UpdateArticleRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateArticleRequest.ProtoReflect.Descriptor instead.
-func (*UpdateArticleRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use Report.ProtoReflect.Descriptor instead.
+func (*Report) Descriptor() ([]byte, []int) {
 	return file_article_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *UpdateArticleRequest) GetArticle() *UpdateArticleRequest_Article {
+func (x *Report) GetId() string {
 	if x != nil {
-		return x.Article
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Report) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Report) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *Report) GetCommentId() string {
+	if x != nil {
+		return x.CommentId
+	}
+	return ""
+}
+
+func (x *Report) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Report) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Report) GetReporter() *Profile {
+	if x != nil {
+		return x.Reporter
 	}
 	return nil
 }
 
-type DeleteArticleRequest struct {
+func (x *Report) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *Report) GetResolvedAt() string {
+	if x != nil {
+		return x.ResolvedAt
+	}
+	return ""
+}
+
+// request message
+type CreateAritcleRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Slug string `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	Article *CreateAritcleRequest_Article `protobuf:"bytes,1,opt,name=article,proto3" json:"article,omitempty"`
 }
 
-func (x *DeleteArticleRequest) Reset() {
-	*x = DeleteArticleRequest{}
+func (x *CreateAritcleRequest) Reset() {
+	*x = CreateAritcleRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_article_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -522,13 +762,13 @@ func (x *DeleteArticleRequest) Reset() {
 	}
 }
 
-func (x *DeleteArticleRequest) String() string {
+func (x *CreateAritcleRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteArticleRequest) ProtoMessage() {}
+func (*CreateAritcleRequest) ProtoMessage() {}
 
-func (x *DeleteArticleRequest) ProtoReflect() protoreflect.Message {
+func (x *CreateAritcleRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_article_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -540,19 +780,19 @@ func (x *DeleteArticleRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteArticleRequest.ProtoReflect.Descriptor instead.
-func (*DeleteArticleRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use CreateAritcleRequest.ProtoReflect.Descriptor instead.
+func (*CreateAritcleRequest) Descriptor() ([]byte, []int) {
 	return file_article_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *DeleteArticleRequest) GetSlug() string {
+func (x *CreateAritcleRequest) GetArticle() *CreateAritcleRequest_Article {
 	if x != nil {
-		return x.Slug
+		return x.Article
 	}
-	return ""
+	return nil
 }
 
-type FavoriteArticleRequest struct {
+type GetArticleRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
@@ -560,8 +800,8 @@ type FavoriteArticleRequest struct {
 	Slug string `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
 }
 
-func (x *FavoriteArticleRequest) Reset() {
-	*x = FavoriteArticleRequest{}
+func (x *GetArticleRequest) Reset() {
+	*x = GetArticleRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_article_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -569,13 +809,13 @@ func (x *FavoriteArticleRequest) Reset() {
 	}
 }
 
-func (x *FavoriteArticleRequest) String() string {
+func (x *GetArticleRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FavoriteArticleRequest) ProtoMessage() {}
+func (*GetArticleRequest) ProtoMessage() {}
 
-func (x *FavoriteArticleRequest) ProtoReflect() protoreflect.Message {
+func (x *GetArticleRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_article_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -587,28 +827,29 @@ func (x *FavoriteArticleRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FavoriteArticleRequest.ProtoReflect.Descriptor instead.
-func (*FavoriteArticleRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use GetArticleRequest.ProtoReflect.Descriptor instead.
+func (*GetArticleRequest) Descriptor() ([]byte, []int) {
 	return file_article_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *FavoriteArticleRequest) GetSlug() string {
+func (x *GetArticleRequest) GetSlug() string {
 	if x != nil {
 		return x.Slug
 	}
 	return ""
 }
 
-type UnfavoriteArticleRequest struct {
+type GetArticleStatsRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
 	Slug string `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	Days int32  `protobuf:"varint,2,opt,name=days,proto3" json:"days,omitempty"`
 }
 
-func (x *UnfavoriteArticleRequest) Reset() {
-	*x = UnfavoriteArticleRequest{}
+func (x *GetArticleStatsRequest) Reset() {
+	*x = GetArticleStatsRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_article_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -616,13 +857,13 @@ func (x *UnfavoriteArticleRequest) Reset() {
 	}
 }
 
-func (x *UnfavoriteArticleRequest) String() string {
+func (x *GetArticleStatsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UnfavoriteArticleRequest) ProtoMessage() {}
+func (*GetArticleStatsRequest) ProtoMessage() {}
 
-func (x *UnfavoriteArticleRequest) ProtoReflect() protoreflect.Message {
+func (x *GetArticleStatsRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_article_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -634,29 +875,36 @@ func (x *UnfavoriteArticleRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UnfavoriteArticleRequest.ProtoReflect.Descriptor instead.
-func (*UnfavoriteArticleRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use GetArticleStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetArticleStatsRequest) Descriptor() ([]byte, []int) {
 	return file_article_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *UnfavoriteArticleRequest) GetSlug() string {
+func (x *GetArticleStatsRequest) GetSlug() string {
 	if x != nil {
 		return x.Slug
 	}
 	return ""
 }
 
-type CreateCommentRequest struct {
+func (x *GetArticleStatsRequest) GetDays() int32 {
+	if x != nil {
+		return x.Days
+	}
+	return 0
+}
+
+type GetRelatedArticlesRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Slug    string                        `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
-	Comment *CreateCommentRequest_Comment `protobuf:"bytes,2,opt,name=comment,proto3" json:"comment,omitempty"`
+	Slug  string `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	Limit int64  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
 }
 
-func (x *CreateCommentRequest) Reset() {
-	*x = CreateCommentRequest{}
+func (x *GetRelatedArticlesRequest) Reset() {
+	*x = GetRelatedArticlesRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_article_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -664,13 +912,13 @@ func (x *CreateCommentRequest) Reset() {
 	}
 }
 
-func (x *CreateCommentRequest) String() string {
+func (x *GetRelatedArticlesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateCommentRequest) ProtoMessage() {}
+func (*GetRelatedArticlesRequest) ProtoMessage() {}
 
-func (x *CreateCommentRequest) ProtoReflect() protoreflect.Message {
+func (x *GetRelatedArticlesRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_article_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -682,35 +930,41 @@ func (x *CreateCommentRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateCommentRequest.ProtoReflect.Descriptor instead.
-func (*CreateCommentRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use GetRelatedArticlesRequest.ProtoReflect.Descriptor instead.
+func (*GetRelatedArticlesRequest) Descriptor() ([]byte, []int) {
 	return file_article_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *CreateCommentRequest) GetSlug() string {
+func (x *GetRelatedArticlesRequest) GetSlug() string {
 	if x != nil {
 		return x.Slug
 	}
 	return ""
 }
 
-func (x *CreateCommentRequest) GetComment() *CreateCommentRequest_Comment {
+func (x *GetRelatedArticlesRequest) GetLimit() int64 {
 	if x != nil {
-		return x.Comment
+		return x.Limit
 	}
-	return nil
+	return 0
 }
 
-type GetCommentsRequest struct {
+type GetArticlesRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Slug string `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	Tag       string `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"`
+	Author    string `protobuf:"bytes,2,opt,name=author,proto3" json:"author,omitempty"`
+	Favorited string `protobuf:"bytes,3,opt,name=favorited,proto3" json:"favorited,omitempty"`
+	Limit     int64  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset    int64  `protobuf:"varint,5,opt,name=offset,proto3" json:"offset,omitempty"`
+	// newest, favorited, commented or trending. Unordered when empty.
+	Sort string `protobuf:"bytes,6,opt,name=sort,proto3" json:"sort,omitempty"`
 }
 
-func (x *GetCommentsRequest) Reset() {
-	*x = GetCommentsRequest{}
+func (x *GetArticlesRequest) Reset() {
+	*x = GetArticlesRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_article_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -718,13 +972,13 @@ func (x *GetCommentsRequest) Reset() {
 	}
 }
 
-func (x *GetCommentsRequest) String() string {
+func (x *GetArticlesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetCommentsRequest) ProtoMessage() {}
+func (*GetArticlesRequest) ProtoMessage() {}
 
-func (x *GetCommentsRequest) ProtoReflect() protoreflect.Message {
+func (x *GetArticlesRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_article_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -736,29 +990,68 @@ func (x *GetCommentsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetCommentsRequest.ProtoReflect.Descriptor instead.
-func (*GetCommentsRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use GetArticlesRequest.ProtoReflect.Descriptor instead.
+func (*GetArticlesRequest) Descriptor() ([]byte, []int) {
 	return file_article_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *GetCommentsRequest) GetSlug() string {
+func (x *GetArticlesRequest) GetTag() string {
 	if x != nil {
-		return x.Slug
+		return x.Tag
 	}
 	return ""
 }
 
-type DeleteCommentRequest struct {
+func (x *GetArticlesRequest) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *GetArticlesRequest) GetFavorited() string {
+	if x != nil {
+		return x.Favorited
+	}
+	return ""
+}
+
+func (x *GetArticlesRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetArticlesRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *GetArticlesRequest) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+type SearchArticlesRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Slug string `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
-	Id   string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Q         string `protobuf:"bytes,1,opt,name=q,proto3" json:"q,omitempty"`
+	Tag       string `protobuf:"bytes,2,opt,name=tag,proto3" json:"tag,omitempty"`
+	Author    string `protobuf:"bytes,3,opt,name=author,proto3" json:"author,omitempty"`
+	Favorited string `protobuf:"bytes,4,opt,name=favorited,proto3" json:"favorited,omitempty"`
+	Limit     int64  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset    int64  `protobuf:"varint,6,opt,name=offset,proto3" json:"offset,omitempty"`
 }
 
-func (x *DeleteCommentRequest) Reset() {
-	*x = DeleteCommentRequest{}
+func (x *SearchArticlesRequest) Reset() {
+	*x = SearchArticlesRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_article_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -766,13 +1059,13 @@ func (x *DeleteCommentRequest) Reset() {
 	}
 }
 
-func (x *DeleteCommentRequest) String() string {
+func (x *SearchArticlesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteCommentRequest) ProtoMessage() {}
+func (*SearchArticlesRequest) ProtoMessage() {}
 
-func (x *DeleteCommentRequest) ProtoReflect() protoreflect.Message {
+func (x *SearchArticlesRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_article_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -784,36 +1077,64 @@ func (x *DeleteCommentRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteCommentRequest.ProtoReflect.Descriptor instead.
-func (*DeleteCommentRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use SearchArticlesRequest.ProtoReflect.Descriptor instead.
+func (*SearchArticlesRequest) Descriptor() ([]byte, []int) {
 	return file_article_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *DeleteCommentRequest) GetSlug() string {
+func (x *SearchArticlesRequest) GetQ() string {
 	if x != nil {
-		return x.Slug
+		return x.Q
 	}
 	return ""
 }
 
-func (x *DeleteCommentRequest) GetId() string {
+func (x *SearchArticlesRequest) GetTag() string {
 	if x != nil {
-		return x.Id
+		return x.Tag
 	}
 	return ""
 }
 
-// response message
-type ArticleResponse struct {
+func (x *SearchArticlesRequest) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *SearchArticlesRequest) GetFavorited() string {
+	if x != nil {
+		return x.Favorited
+	}
+	return ""
+}
+
+func (x *SearchArticlesRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *SearchArticlesRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type GetFeedArticlesRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Article *Article `protobuf:"bytes,1,opt,name=article,proto3" json:"article,omitempty"`
+	Limit  int64 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset int64 `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
 }
 
-func (x *ArticleResponse) Reset() {
-	*x = ArticleResponse{}
+func (x *GetFeedArticlesRequest) Reset() {
+	*x = GetFeedArticlesRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_article_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -821,13 +1142,13 @@ func (x *ArticleResponse) Reset() {
 	}
 }
 
-func (x *ArticleResponse) String() string {
+func (x *GetFeedArticlesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ArticleResponse) ProtoMessage() {}
+func (*GetFeedArticlesRequest) ProtoMessage() {}
 
-func (x *ArticleResponse) ProtoReflect() protoreflect.Message {
+func (x *GetFeedArticlesRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_article_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -839,29 +1160,35 @@ func (x *ArticleResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ArticleResponse.ProtoReflect.Descriptor instead.
-func (*ArticleResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use GetFeedArticlesRequest.ProtoReflect.Descriptor instead.
+func (*GetFeedArticlesRequest) Descriptor() ([]byte, []int) {
 	return file_article_proto_rawDescGZIP(), []int{13}
 }
 
-func (x *ArticleResponse) GetArticle() *Article {
+func (x *GetFeedArticlesRequest) GetLimit() int64 {
 	if x != nil {
-		return x.Article
+		return x.Limit
 	}
-	return nil
+	return 0
 }
 
-type ArticlesResponse struct {
+func (x *GetFeedArticlesRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type UpdateArticleRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Articles      []*Article `protobuf:"bytes,1,rep,name=articles,proto3" json:"articles,omitempty"`
-	ArticlesCount int32      `protobuf:"varint,2,opt,name=articlesCount,proto3" json:"articlesCount,omitempty"`
+	Article *UpdateArticleRequest_Article `protobuf:"bytes,1,opt,name=article,proto3" json:"article,omitempty"`
 }
 
-func (x *ArticlesResponse) Reset() {
-	*x = ArticlesResponse{}
+func (x *UpdateArticleRequest) Reset() {
+	*x = UpdateArticleRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_article_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -869,13 +1196,13 @@ func (x *ArticlesResponse) Reset() {
 	}
 }
 
-func (x *ArticlesResponse) String() string {
+func (x *UpdateArticleRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ArticlesResponse) ProtoMessage() {}
+func (*UpdateArticleRequest) ProtoMessage() {}
 
-func (x *ArticlesResponse) ProtoReflect() protoreflect.Message {
+func (x *UpdateArticleRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_article_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -887,35 +1214,28 @@ func (x *ArticlesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ArticlesResponse.ProtoReflect.Descriptor instead.
-func (*ArticlesResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use UpdateArticleRequest.ProtoReflect.Descriptor instead.
+func (*UpdateArticleRequest) Descriptor() ([]byte, []int) {
 	return file_article_proto_rawDescGZIP(), []int{14}
 }
 
-func (x *ArticlesResponse) GetArticles() []*Article {
+func (x *UpdateArticleRequest) GetArticle() *UpdateArticleRequest_Article {
 	if x != nil {
-		return x.Articles
+		return x.Article
 	}
 	return nil
 }
 
-func (x *ArticlesResponse) GetArticlesCount() int32 {
-	if x != nil {
-		return x.ArticlesCount
-	}
-	return 0
-}
-
-type TagsResponse struct {
+type DeleteArticleRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Tags []string `protobuf:"bytes,1,rep,name=tags,proto3" json:"tags,omitempty"`
+	Slug string `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
 }
 
-func (x *TagsResponse) Reset() {
-	*x = TagsResponse{}
+func (x *DeleteArticleRequest) Reset() {
+	*x = DeleteArticleRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_article_proto_msgTypes[15]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -923,13 +1243,13 @@ func (x *TagsResponse) Reset() {
 	}
 }
 
-func (x *TagsResponse) String() string {
+func (x *DeleteArticleRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TagsResponse) ProtoMessage() {}
+func (*DeleteArticleRequest) ProtoMessage() {}
 
-func (x *TagsResponse) ProtoReflect() protoreflect.Message {
+func (x *DeleteArticleRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_article_proto_msgTypes[15]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -941,28 +1261,28 @@ func (x *TagsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TagsResponse.ProtoReflect.Descriptor instead.
-func (*TagsResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use DeleteArticleRequest.ProtoReflect.Descriptor instead.
+func (*DeleteArticleRequest) Descriptor() ([]byte, []int) {
 	return file_article_proto_rawDescGZIP(), []int{15}
 }
 
-func (x *TagsResponse) GetTags() []string {
+func (x *DeleteArticleRequest) GetSlug() string {
 	if x != nil {
-		return x.Tags
+		return x.Slug
 	}
-	return nil
+	return ""
 }
 
-type CommentResponse struct {
+type PublishArticleRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Comment *Comment `protobuf:"bytes,1,opt,name=comment,proto3" json:"comment,omitempty"`
+	Slug string `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
 }
 
-func (x *CommentResponse) Reset() {
-	*x = CommentResponse{}
+func (x *PublishArticleRequest) Reset() {
+	*x = PublishArticleRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_article_proto_msgTypes[16]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -970,13 +1290,13 @@ func (x *CommentResponse) Reset() {
 	}
 }
 
-func (x *CommentResponse) String() string {
+func (x *PublishArticleRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CommentResponse) ProtoMessage() {}
+func (*PublishArticleRequest) ProtoMessage() {}
 
-func (x *CommentResponse) ProtoReflect() protoreflect.Message {
+func (x *PublishArticleRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_article_proto_msgTypes[16]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -988,16 +1308,1407 @@ func (x *CommentResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CommentResponse.ProtoReflect.Descriptor instead.
-func (*CommentResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use PublishArticleRequest.ProtoReflect.Descriptor instead.
+func (*PublishArticleRequest) Descriptor() ([]byte, []int) {
 	return file_article_proto_rawDescGZIP(), []int{16}
 }
 
-func (x *CommentResponse) GetComment() *Comment {
+func (x *PublishArticleRequest) GetSlug() string {
 	if x != nil {
-		return x.Comment
+		return x.Slug
 	}
-	return nil
+	return ""
+}
+
+type UnpublishArticleRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Slug   string `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	Status string `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+}
+
+func (x *UnpublishArticleRequest) Reset() {
+	*x = UnpublishArticleRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_article_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UnpublishArticleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnpublishArticleRequest) ProtoMessage() {}
+
+func (x *UnpublishArticleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_article_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnpublishArticleRequest.ProtoReflect.Descriptor instead.
+func (*UnpublishArticleRequest) Descriptor() ([]byte, []int) {
+	return file_article_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *UnpublishArticleRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *UnpublishArticleRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type ScheduleArticleRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Slug      string `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	PublishAt string `protobuf:"bytes,2,opt,name=publishAt,proto3" json:"publishAt,omitempty"`
+}
+
+func (x *ScheduleArticleRequest) Reset() {
+	*x = ScheduleArticleRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_article_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ScheduleArticleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduleArticleRequest) ProtoMessage() {}
+
+func (x *ScheduleArticleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_article_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduleArticleRequest.ProtoReflect.Descriptor instead.
+func (*ScheduleArticleRequest) Descriptor() ([]byte, []int) {
+	return file_article_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ScheduleArticleRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *ScheduleArticleRequest) GetPublishAt() string {
+	if x != nil {
+		return x.PublishAt
+	}
+	return ""
+}
+
+type UnscheduleArticleRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Slug string `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+}
+
+func (x *UnscheduleArticleRequest) Reset() {
+	*x = UnscheduleArticleRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_article_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UnscheduleArticleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnscheduleArticleRequest) ProtoMessage() {}
+
+func (x *UnscheduleArticleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_article_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnscheduleArticleRequest.ProtoReflect.Descriptor instead.
+func (*UnscheduleArticleRequest) Descriptor() ([]byte, []int) {
+	return file_article_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *UnscheduleArticleRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+type ListMyDraftsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Limit  int64 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset int64 `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+}
+
+func (x *ListMyDraftsRequest) Reset() {
+	*x = ListMyDraftsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_article_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListMyDraftsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMyDraftsRequest) ProtoMessage() {}
+
+func (x *ListMyDraftsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_article_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMyDraftsRequest.ProtoReflect.Descriptor instead.
+func (*ListMyDraftsRequest) Descriptor() ([]byte, []int) {
+	return file_article_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ListMyDraftsRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListMyDraftsRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type ListArticleRevisionsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Slug string `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+}
+
+func (x *ListArticleRevisionsRequest) Reset() {
+	*x = ListArticleRevisionsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_article_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListArticleRevisionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListArticleRevisionsRequest) ProtoMessage() {}
+
+func (x *ListArticleRevisionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_article_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListArticleRevisionsRequest.ProtoReflect.Descriptor instead.
+func (*ListArticleRevisionsRequest) Descriptor() ([]byte, []int) {
+	return file_article_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ListArticleRevisionsRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+type GetArticleRevisionRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Slug   string `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	Number int32  `protobuf:"varint,2,opt,name=number,proto3" json:"number,omitempty"`
+}
+
+func (x *GetArticleRevisionRequest) Reset() {
+	*x = GetArticleRevisionRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_article_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetArticleRevisionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetArticleRevisionRequest) ProtoMessage() {}
+
+func (x *GetArticleRevisionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_article_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetArticleRevisionRequest.ProtoReflect.Descriptor instead.
+func (*GetArticleRevisionRequest) Descriptor() ([]byte, []int) {
+	return file_article_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *GetArticleRevisionRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *GetArticleRevisionRequest) GetNumber() int32 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+type DiffArticleRevisionsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Slug string `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	From int32  `protobuf:"varint,2,opt,name=from,proto3" json:"from,omitempty"`
+	To   int32  `protobuf:"varint,3,opt,name=to,proto3" json:"to,omitempty"`
+}
+
+func (x *DiffArticleRevisionsRequest) Reset() {
+	*x = DiffArticleRevisionsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_article_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DiffArticleRevisionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiffArticleRevisionsRequest) ProtoMessage() {}
+
+func (x *DiffArticleRevisionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_article_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiffArticleRevisionsRequest.ProtoReflect.Descriptor instead.
+func (*DiffArticleRevisionsRequest) Descriptor() ([]byte, []int) {
+	return file_article_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *DiffArticleRevisionsRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *DiffArticleRevisionsRequest) GetFrom() int32 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *DiffArticleRevisionsRequest) GetTo() int32 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+type RestoreArticleRevisionRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Slug   string `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	Number int32  `protobuf:"varint,2,opt,name=number,proto3" json:"number,omitempty"`
+}
+
+func (x *RestoreArticleRevisionRequest) Reset() {
+	*x = RestoreArticleRevisionRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_article_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RestoreArticleRevisionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreArticleRevisionRequest) ProtoMessage() {}
+
+func (x *RestoreArticleRevisionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_article_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreArticleRevisionRequest.ProtoReflect.Descriptor instead.
+func (*RestoreArticleRevisionRequest) Descriptor() ([]byte, []int) {
+	return file_article_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *RestoreArticleRevisionRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *RestoreArticleRevisionRequest) GetNumber() int32 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+type FavoriteArticleRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Slug string `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+}
+
+func (x *FavoriteArticleRequest) Reset() {
+	*x = FavoriteArticleRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_article_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FavoriteArticleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FavoriteArticleRequest) ProtoMessage() {}
+
+func (x *FavoriteArticleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_article_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FavoriteArticleRequest.ProtoReflect.Descriptor instead.
+func (*FavoriteArticleRequest) Descriptor() ([]byte, []int) {
+	return file_article_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *FavoriteArticleRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+type UnfavoriteArticleRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Slug string `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+}
+
+func (x *UnfavoriteArticleRequest) Reset() {
+	*x = UnfavoriteArticleRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_article_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UnfavoriteArticleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnfavoriteArticleRequest) ProtoMessage() {}
+
+func (x *UnfavoriteArticleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_article_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnfavoriteArticleRequest.ProtoReflect.Descriptor instead.
+func (*UnfavoriteArticleRequest) Descriptor() ([]byte, []int) {
+	return file_article_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *UnfavoriteArticleRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+type CreateCommentRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Slug    string                        `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	Comment *CreateCommentRequest_Comment `protobuf:"bytes,2,opt,name=comment,proto3" json:"comment,omitempty"`
+}
+
+func (x *CreateCommentRequest) Reset() {
+	*x = CreateCommentRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_article_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreateCommentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateCommentRequest) ProtoMessage() {}
+
+func (x *CreateCommentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_article_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateCommentRequest.ProtoReflect.Descriptor instead.
+func (*CreateCommentRequest) Descriptor() ([]byte, []int) {
+	return file_article_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *CreateCommentRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *CreateCommentRequest) GetComment() *CreateCommentRequest_Comment {
+	if x != nil {
+		return x.Comment
+	}
+	return nil
+}
+
+type GetCommentsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Slug   string `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	Limit  int64  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Cursor string `protobuf:"bytes,3,opt,name=cursor,proto3" json:"cursor,omitempty"`
+}
+
+func (x *GetCommentsRequest) Reset() {
+	*x = GetCommentsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_article_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetCommentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCommentsRequest) ProtoMessage() {}
+
+func (x *GetCommentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_article_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCommentsRequest.ProtoReflect.Descriptor instead.
+func (*GetCommentsRequest) Descriptor() ([]byte, []int) {
+	return file_article_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *GetCommentsRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *GetCommentsRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetCommentsRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+type UpdateCommentRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Slug    string                        `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	Id      string                        `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Comment *UpdateCommentRequest_Comment `protobuf:"bytes,3,opt,name=comment,proto3" json:"comment,omitempty"`
+}
+
+func (x *UpdateCommentRequest) Reset() {
+	*x = UpdateCommentRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_article_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdateCommentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateCommentRequest) ProtoMessage() {}
+
+func (x *UpdateCommentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_article_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateCommentRequest.ProtoReflect.Descriptor instead.
+func (*UpdateCommentRequest) Descriptor() ([]byte, []int) {
+	return file_article_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *UpdateCommentRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *UpdateCommentRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateCommentRequest) GetComment() *UpdateCommentRequest_Comment {
+	if x != nil {
+		return x.Comment
+	}
+	return nil
+}
+
+type DeleteCommentRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Slug string `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	Id   string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *DeleteCommentRequest) Reset() {
+	*x = DeleteCommentRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_article_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteCommentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteCommentRequest) ProtoMessage() {}
+
+func (x *DeleteCommentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_article_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteCommentRequest.ProtoReflect.Descriptor instead.
+func (*DeleteCommentRequest) Descriptor() ([]byte, []int) {
+	return file_article_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *DeleteCommentRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *DeleteCommentRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ReportArticleRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Slug   string `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	Reason string `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+}
+
+func (x *ReportArticleRequest) Reset() {
+	*x = ReportArticleRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_article_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReportArticleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportArticleRequest) ProtoMessage() {}
+
+func (x *ReportArticleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_article_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportArticleRequest.ProtoReflect.Descriptor instead.
+func (*ReportArticleRequest) Descriptor() ([]byte, []int) {
+	return file_article_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ReportArticleRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *ReportArticleRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type ReportCommentRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Slug   string `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	Id     string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Reason string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+}
+
+func (x *ReportCommentRequest) Reset() {
+	*x = ReportCommentRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_article_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReportCommentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportCommentRequest) ProtoMessage() {}
+
+func (x *ReportCommentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_article_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportCommentRequest.ProtoReflect.Descriptor instead.
+func (*ReportCommentRequest) Descriptor() ([]byte, []int) {
+	return file_article_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *ReportCommentRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *ReportCommentRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ReportCommentRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// response message
+type ArticleResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Article *Article `protobuf:"bytes,1,opt,name=article,proto3" json:"article,omitempty"`
+}
+
+func (x *ArticleResponse) Reset() {
+	*x = ArticleResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_article_proto_msgTypes[33]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ArticleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArticleResponse) ProtoMessage() {}
+
+func (x *ArticleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_article_proto_msgTypes[33]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArticleResponse.ProtoReflect.Descriptor instead.
+func (*ArticleResponse) Descriptor() ([]byte, []int) {
+	return file_article_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ArticleResponse) GetArticle() *Article {
+	if x != nil {
+		return x.Article
+	}
+	return nil
+}
+
+type ArticleStatsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Stats *ArticleStats `protobuf:"bytes,1,opt,name=stats,proto3" json:"stats,omitempty"`
+}
+
+func (x *ArticleStatsResponse) Reset() {
+	*x = ArticleStatsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_article_proto_msgTypes[34]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ArticleStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArticleStatsResponse) ProtoMessage() {}
+
+func (x *ArticleStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_article_proto_msgTypes[34]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArticleStatsResponse.ProtoReflect.Descriptor instead.
+func (*ArticleStatsResponse) Descriptor() ([]byte, []int) {
+	return file_article_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *ArticleStatsResponse) GetStats() *ArticleStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+type ArticlesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Articles      []*Article `protobuf:"bytes,1,rep,name=articles,proto3" json:"articles,omitempty"`
+	ArticlesCount int32      `protobuf:"varint,2,opt,name=articlesCount,proto3" json:"articlesCount,omitempty"`
+}
+
+func (x *ArticlesResponse) Reset() {
+	*x = ArticlesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_article_proto_msgTypes[35]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ArticlesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArticlesResponse) ProtoMessage() {}
+
+func (x *ArticlesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_article_proto_msgTypes[35]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArticlesResponse.ProtoReflect.Descriptor instead.
+func (*ArticlesResponse) Descriptor() ([]byte, []int) {
+	return file_article_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ArticlesResponse) GetArticles() []*Article {
+	if x != nil {
+		return x.Articles
+	}
+	return nil
+}
+
+func (x *ArticlesResponse) GetArticlesCount() int32 {
+	if x != nil {
+		return x.ArticlesCount
+	}
+	return 0
+}
+
+type ArticleRevisionResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Revision *ArticleRevision `protobuf:"bytes,1,opt,name=revision,proto3" json:"revision,omitempty"`
+}
+
+func (x *ArticleRevisionResponse) Reset() {
+	*x = ArticleRevisionResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_article_proto_msgTypes[36]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ArticleRevisionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArticleRevisionResponse) ProtoMessage() {}
+
+func (x *ArticleRevisionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_article_proto_msgTypes[36]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArticleRevisionResponse.ProtoReflect.Descriptor instead.
+func (*ArticleRevisionResponse) Descriptor() ([]byte, []int) {
+	return file_article_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *ArticleRevisionResponse) GetRevision() *ArticleRevision {
+	if x != nil {
+		return x.Revision
+	}
+	return nil
+}
+
+type ArticleRevisionsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Revisions      []*ArticleRevision `protobuf:"bytes,1,rep,name=revisions,proto3" json:"revisions,omitempty"`
+	RevisionsCount int32              `protobuf:"varint,2,opt,name=revisionsCount,proto3" json:"revisionsCount,omitempty"`
+}
+
+func (x *ArticleRevisionsResponse) Reset() {
+	*x = ArticleRevisionsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_article_proto_msgTypes[37]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ArticleRevisionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArticleRevisionsResponse) ProtoMessage() {}
+
+func (x *ArticleRevisionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_article_proto_msgTypes[37]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArticleRevisionsResponse.ProtoReflect.Descriptor instead.
+func (*ArticleRevisionsResponse) Descriptor() ([]byte, []int) {
+	return file_article_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *ArticleRevisionsResponse) GetRevisions() []*ArticleRevision {
+	if x != nil {
+		return x.Revisions
+	}
+	return nil
+}
+
+func (x *ArticleRevisionsResponse) GetRevisionsCount() int32 {
+	if x != nil {
+		return x.RevisionsCount
+	}
+	return 0
+}
+
+type ArticleDiffResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	From int32  `protobuf:"varint,1,opt,name=from,proto3" json:"from,omitempty"`
+	To   int32  `protobuf:"varint,2,opt,name=to,proto3" json:"to,omitempty"`
+	Diff string `protobuf:"bytes,3,opt,name=diff,proto3" json:"diff,omitempty"`
+}
+
+func (x *ArticleDiffResponse) Reset() {
+	*x = ArticleDiffResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_article_proto_msgTypes[38]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ArticleDiffResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArticleDiffResponse) ProtoMessage() {}
+
+func (x *ArticleDiffResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_article_proto_msgTypes[38]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArticleDiffResponse.ProtoReflect.Descriptor instead.
+func (*ArticleDiffResponse) Descriptor() ([]byte, []int) {
+	return file_article_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *ArticleDiffResponse) GetFrom() int32 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *ArticleDiffResponse) GetTo() int32 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+func (x *ArticleDiffResponse) GetDiff() string {
+	if x != nil {
+		return x.Diff
+	}
+	return ""
+}
+
+type SearchArticlesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Hits      []*ArticleSearchHit `protobuf:"bytes,1,rep,name=hits,proto3" json:"hits,omitempty"`
+	HitsCount int32               `protobuf:"varint,2,opt,name=hitsCount,proto3" json:"hitsCount,omitempty"`
+}
+
+func (x *SearchArticlesResponse) Reset() {
+	*x = SearchArticlesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_article_proto_msgTypes[39]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SearchArticlesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchArticlesResponse) ProtoMessage() {}
+
+func (x *SearchArticlesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_article_proto_msgTypes[39]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchArticlesResponse.ProtoReflect.Descriptor instead.
+func (*SearchArticlesResponse) Descriptor() ([]byte, []int) {
+	return file_article_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *SearchArticlesResponse) GetHits() []*ArticleSearchHit {
+	if x != nil {
+		return x.Hits
+	}
+	return nil
+}
+
+func (x *SearchArticlesResponse) GetHitsCount() int32 {
+	if x != nil {
+		return x.HitsCount
+	}
+	return 0
+}
+
+type GetTrendingTagsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Limit int64 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+}
+
+func (x *GetTrendingTagsRequest) Reset() {
+	*x = GetTrendingTagsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_article_proto_msgTypes[40]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetTrendingTagsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTrendingTagsRequest) ProtoMessage() {}
+
+func (x *GetTrendingTagsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_article_proto_msgTypes[40]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTrendingTagsRequest.ProtoReflect.Descriptor instead.
+func (*GetTrendingTagsRequest) Descriptor() ([]byte, []int) {
+	return file_article_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *GetTrendingTagsRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type TagsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Tags []string `protobuf:"bytes,1,rep,name=tags,proto3" json:"tags,omitempty"`
+}
+
+func (x *TagsResponse) Reset() {
+	*x = TagsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_article_proto_msgTypes[41]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TagsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TagsResponse) ProtoMessage() {}
+
+func (x *TagsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_article_proto_msgTypes[41]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TagsResponse.ProtoReflect.Descriptor instead.
+func (*TagsResponse) Descriptor() ([]byte, []int) {
+	return file_article_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *TagsResponse) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type CommentResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Comment *Comment `protobuf:"bytes,1,opt,name=comment,proto3" json:"comment,omitempty"`
+}
+
+func (x *CommentResponse) Reset() {
+	*x = CommentResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_article_proto_msgTypes[42]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CommentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommentResponse) ProtoMessage() {}
+
+func (x *CommentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_article_proto_msgTypes[42]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommentResponse.ProtoReflect.Descriptor instead.
+func (*CommentResponse) Descriptor() ([]byte, []int) {
+	return file_article_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *CommentResponse) GetComment() *Comment {
+	if x != nil {
+		return x.Comment
+	}
+	return nil
 }
 
 type CommentsResponse struct {
@@ -1005,13 +2716,15 @@ type CommentsResponse struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Comments []*Comment `protobuf:"bytes,1,rep,name=comments,proto3" json:"comments,omitempty"`
+	Comments      []*Comment `protobuf:"bytes,1,rep,name=comments,proto3" json:"comments,omitempty"`
+	CommentsCount int32      `protobuf:"varint,2,opt,name=commentsCount,proto3" json:"commentsCount,omitempty"`
+	NextCursor    string     `protobuf:"bytes,3,opt,name=nextCursor,proto3" json:"nextCursor,omitempty"`
 }
 
 func (x *CommentsResponse) Reset() {
 	*x = CommentsResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_article_proto_msgTypes[17]
+		mi := &file_article_proto_msgTypes[43]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1024,7 +2737,7 @@ func (x *CommentsResponse) String() string {
 func (*CommentsResponse) ProtoMessage() {}
 
 func (x *CommentsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_article_proto_msgTypes[17]
+	mi := &file_article_proto_msgTypes[43]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1037,7 +2750,7 @@ func (x *CommentsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CommentsResponse.ProtoReflect.Descriptor instead.
 func (*CommentsResponse) Descriptor() ([]byte, []int) {
-	return file_article_proto_rawDescGZIP(), []int{17}
+	return file_article_proto_rawDescGZIP(), []int{43}
 }
 
 func (x *CommentsResponse) GetComments() []*Comment {
@@ -1047,6 +2760,67 @@ func (x *CommentsResponse) GetComments() []*Comment {
 	return nil
 }
 
+func (x *CommentsResponse) GetCommentsCount() int32 {
+	if x != nil {
+		return x.CommentsCount
+	}
+	return 0
+}
+
+func (x *CommentsResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type ReportResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Report *Report `protobuf:"bytes,1,opt,name=report,proto3" json:"report,omitempty"`
+}
+
+func (x *ReportResponse) Reset() {
+	*x = ReportResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_article_proto_msgTypes[44]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReportResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportResponse) ProtoMessage() {}
+
+func (x *ReportResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_article_proto_msgTypes[44]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportResponse.ProtoReflect.Descriptor instead.
+func (*ReportResponse) Descriptor() ([]byte, []int) {
+	return file_article_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *ReportResponse) GetReport() *Report {
+	if x != nil {
+		return x.Report
+	}
+	return nil
+}
+
 type CreateAritcleRequest_Article struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -1056,12 +2830,13 @@ type CreateAritcleRequest_Article struct {
 	Description string   `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
 	Body        string   `protobuf:"bytes,3,opt,name=body,proto3" json:"body,omitempty"`
 	TagList     []string `protobuf:"bytes,4,rep,name=tagList,proto3" json:"tagList,omitempty"`
+	Status      string   `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
 }
 
 func (x *CreateAritcleRequest_Article) Reset() {
 	*x = CreateAritcleRequest_Article{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_article_proto_msgTypes[18]
+		mi := &file_article_proto_msgTypes[45]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1074,7 +2849,7 @@ func (x *CreateAritcleRequest_Article) String() string {
 func (*CreateAritcleRequest_Article) ProtoMessage() {}
 
 func (x *CreateAritcleRequest_Article) ProtoReflect() protoreflect.Message {
-	mi := &file_article_proto_msgTypes[18]
+	mi := &file_article_proto_msgTypes[45]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1087,7 +2862,7 @@ func (x *CreateAritcleRequest_Article) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateAritcleRequest_Article.ProtoReflect.Descriptor instead.
 func (*CreateAritcleRequest_Article) Descriptor() ([]byte, []int) {
-	return file_article_proto_rawDescGZIP(), []int{2, 0}
+	return file_article_proto_rawDescGZIP(), []int{7, 0}
 }
 
 func (x *CreateAritcleRequest_Article) GetTitle() string {
@@ -1113,9 +2888,16 @@ func (x *CreateAritcleRequest_Article) GetBody() string {
 
 func (x *CreateAritcleRequest_Article) GetTagList() []string {
 	if x != nil {
-		return x.TagList
+		return x.TagList
+	}
+	return nil
+}
+
+func (x *CreateAritcleRequest_Article) GetStatus() string {
+	if x != nil {
+		return x.Status
 	}
-	return nil
+	return ""
 }
 
 type UpdateArticleRequest_Article struct {
@@ -1132,7 +2914,7 @@ type UpdateArticleRequest_Article struct {
 func (x *UpdateArticleRequest_Article) Reset() {
 	*x = UpdateArticleRequest_Article{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_article_proto_msgTypes[19]
+		mi := &file_article_proto_msgTypes[46]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1145,7 +2927,7 @@ func (x *UpdateArticleRequest_Article) String() string {
 func (*UpdateArticleRequest_Article) ProtoMessage() {}
 
 func (x *UpdateArticleRequest_Article) ProtoReflect() protoreflect.Message {
-	mi := &file_article_proto_msgTypes[19]
+	mi := &file_article_proto_msgTypes[46]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1158,7 +2940,7 @@ func (x *UpdateArticleRequest_Article) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateArticleRequest_Article.ProtoReflect.Descriptor instead.
 func (*UpdateArticleRequest_Article) Descriptor() ([]byte, []int) {
-	return file_article_proto_rawDescGZIP(), []int{6, 0}
+	return file_article_proto_rawDescGZIP(), []int{14, 0}
 }
 
 func (x *UpdateArticleRequest_Article) GetTitle() string {
@@ -1194,13 +2976,14 @@ type CreateCommentRequest_Comment struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Body string `protobuf:"bytes,1,opt,name=body,proto3" json:"body,omitempty"`
+	Body     string `protobuf:"bytes,1,opt,name=body,proto3" json:"body,omitempty"`
+	ParentId string `protobuf:"bytes,2,opt,name=parentId,proto3" json:"parentId,omitempty"`
 }
 
 func (x *CreateCommentRequest_Comment) Reset() {
 	*x = CreateCommentRequest_Comment{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_article_proto_msgTypes[20]
+		mi := &file_article_proto_msgTypes[47]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1213,7 +2996,7 @@ func (x *CreateCommentRequest_Comment) String() string {
 func (*CreateCommentRequest_Comment) ProtoMessage() {}
 
 func (x *CreateCommentRequest_Comment) ProtoReflect() protoreflect.Message {
-	mi := &file_article_proto_msgTypes[20]
+	mi := &file_article_proto_msgTypes[47]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1226,7 +3009,7 @@ func (x *CreateCommentRequest_Comment) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateCommentRequest_Comment.ProtoReflect.Descriptor instead.
 func (*CreateCommentRequest_Comment) Descriptor() ([]byte, []int) {
-	return file_article_proto_rawDescGZIP(), []int{10, 0}
+	return file_article_proto_rawDescGZIP(), []int{27, 0}
 }
 
 func (x *CreateCommentRequest_Comment) GetBody() string {
@@ -1236,6 +3019,60 @@ func (x *CreateCommentRequest_Comment) GetBody() string {
 	return ""
 }
 
+func (x *CreateCommentRequest_Comment) GetParentId() string {
+	if x != nil {
+		return x.ParentId
+	}
+	return ""
+}
+
+type UpdateCommentRequest_Comment struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Body string `protobuf:"bytes,1,opt,name=body,proto3" json:"body,omitempty"`
+}
+
+func (x *UpdateCommentRequest_Comment) Reset() {
+	*x = UpdateCommentRequest_Comment{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_article_proto_msgTypes[48]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdateCommentRequest_Comment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateCommentRequest_Comment) ProtoMessage() {}
+
+func (x *UpdateCommentRequest_Comment) ProtoReflect() protoreflect.Message {
+	mi := &file_article_proto_msgTypes[48]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateCommentRequest_Comment.ProtoReflect.Descriptor instead.
+func (*UpdateCommentRequest_Comment) Descriptor() ([]byte, []int) {
+	return file_article_proto_rawDescGZIP(), []int{29, 0}
+}
+
+func (x *UpdateCommentRequest_Comment) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
 var File_article_proto protoreflect.FileDescriptor
 
 var file_article_proto_rawDesc = []byte{
@@ -1246,7 +3083,7 @@ var file_article_proto_rawDesc = []byte{
 	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
 	0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x0a, 0x75, 0x73, 0x65, 0x72, 0x2e, 0x70, 0x72,
 	0x6f, 0x74, 0x6f, 0x1a, 0x0b, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x22, 0xac, 0x02, 0x0a, 0x07, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x12, 0x12, 0x0a, 0x04,
+	0x22, 0x8a, 0x04, 0x0a, 0x07, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x12, 0x12, 0x0a, 0x04,
 	0x73, 0x6c, 0x75, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6c, 0x75, 0x67,
 	0x12, 0x14, 0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
 	0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69,
@@ -1264,179 +3101,499 @@ var file_article_proto_rawDesc = []byte{
 	0x6e, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x66, 0x61, 0x76, 0x6f, 0x72, 0x69,
 	0x74, 0x65, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x25, 0x0a, 0x06, 0x61, 0x75, 0x74, 0x68,
 	0x6f, 0x72, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x75, 0x73, 0x65, 0x72, 0x2e,
-	0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x52, 0x06, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x22,
-	0x90, 0x01, 0x0a, 0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69,
-	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x63,
-	0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
-	0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x75, 0x70, 0x64,
-	0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x75, 0x70,
-	0x64, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x18,
-	0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x12, 0x25, 0x0a, 0x06, 0x61,
-	0x75, 0x74, 0x68, 0x6f, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x75, 0x73,
-	0x65, 0x72, 0x2e, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x52, 0x06, 0x61, 0x75, 0x74, 0x68,
-	0x6f, 0x72, 0x22, 0xc8, 0x01, 0x0a, 0x14, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x41, 0x72, 0x69,
-	0x74, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x3f, 0x0a, 0x07, 0x61,
-	0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x61,
-	0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x41, 0x72, 0x69,
-	0x74, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x41, 0x72, 0x74, 0x69,
-	0x63, 0x6c, 0x65, 0x52, 0x07, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x1a, 0x6f, 0x0a, 0x07,
+	0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x52, 0x06, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x12,
+	0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x70, 0x75, 0x62, 0x6c, 0x69,
+	0x73, 0x68, 0x41, 0x74, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x70, 0x75, 0x62, 0x6c,
+	0x69, 0x73, 0x68, 0x41, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x62, 0x6f, 0x64, 0x79, 0x48, 0x74, 0x6d,
+	0x6c, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x62, 0x6f, 0x64, 0x79, 0x48, 0x74, 0x6d,
+	0x6c, 0x12, 0x2e, 0x0a, 0x12, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x54, 0x69, 0x6d, 0x65,
+	0x4d, 0x69, 0x6e, 0x75, 0x74, 0x65, 0x73, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x05, 0x52, 0x12, 0x72,
+	0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x54, 0x69, 0x6d, 0x65, 0x4d, 0x69, 0x6e, 0x75, 0x74, 0x65,
+	0x73, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x78, 0x63, 0x65, 0x72, 0x70, 0x74, 0x18, 0x0f, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x65, 0x78, 0x63, 0x65, 0x72, 0x70, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x62,
+	0x6f, 0x6f, 0x6b, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x64, 0x18, 0x10, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x0a, 0x62, 0x6f, 0x6f, 0x6b, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x64, 0x12, 0x20, 0x0a, 0x0b, 0x70,
+	0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x65, 0x64, 0x41, 0x74, 0x18, 0x11, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0b, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x65, 0x64, 0x41, 0x74, 0x22, 0x9e, 0x02,
+	0x0a, 0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x75, 0x70, 0x64, 0x61, 0x74,
+	0x65, 0x64, 0x41, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x75, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x12, 0x25, 0x0a, 0x06, 0x61, 0x75, 0x74,
+	0x68, 0x6f, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x75, 0x73, 0x65, 0x72,
+	0x2e, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x52, 0x06, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72,
+	0x12, 0x1a, 0x0a, 0x08, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x08, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x22, 0x0a, 0x0c,
+	0x72, 0x65, 0x70, 0x6c, 0x69, 0x65, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x0c, 0x72, 0x65, 0x70, 0x6c, 0x69, 0x65, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74,
+	0x12, 0x18, 0x0a, 0x07, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x07, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x65, 0x64,
+	0x69, 0x74, 0x65, 0x64, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x65, 0x64, 0x69, 0x74,
+	0x65, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x62, 0x6f, 0x64, 0x79, 0x48, 0x74, 0x6d, 0x6c, 0x18, 0x0a,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x62, 0x6f, 0x64, 0x79, 0x48, 0x74, 0x6d, 0x6c, 0x22, 0xd6,
+	0x01, 0x0a, 0x0f, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69,
+	0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x69,
+	0x74, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65,
+	0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x12, 0x25, 0x0a, 0x06, 0x65, 0x64, 0x69, 0x74, 0x6f, 0x72,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x75, 0x73, 0x65, 0x72, 0x2e, 0x50, 0x72,
+	0x6f, 0x66, 0x69, 0x6c, 0x65, 0x52, 0x06, 0x65, 0x64, 0x69, 0x74, 0x6f, 0x72, 0x12, 0x1c, 0x0a,
+	0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x62,
+	0x6f, 0x64, 0x79, 0x48, 0x74, 0x6d, 0x6c, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x62,
+	0x6f, 0x64, 0x79, 0x48, 0x74, 0x6d, 0x6c, 0x22, 0x77, 0x0a, 0x11, 0x44, 0x61, 0x69, 0x6c, 0x79,
+	0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x12, 0x0a, 0x04,
+	0x64, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x64, 0x61, 0x74, 0x65,
+	0x12, 0x14, 0x0a, 0x05, 0x76, 0x69, 0x65, 0x77, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x05, 0x76, 0x69, 0x65, 0x77, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x66, 0x61, 0x76, 0x6f, 0x72, 0x69,
+	0x74, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x66, 0x61, 0x76, 0x6f, 0x72,
+	0x69, 0x74, 0x65, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73,
+	0x22, 0xc2, 0x01, 0x0a, 0x0c, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74,
+	0x73, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x73, 0x6c, 0x75, 0x67, 0x12, 0x1e, 0x0a, 0x0a, 0x76, 0x69, 0x65, 0x77, 0x73, 0x43, 0x6f,
+	0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x76, 0x69, 0x65, 0x77, 0x73,
+	0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x26, 0x0a, 0x0e, 0x66, 0x61, 0x76, 0x6f, 0x72, 0x69, 0x74,
+	0x65, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x66,
+	0x61, 0x76, 0x6f, 0x72, 0x69, 0x74, 0x65, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x24, 0x0a,
+	0x0d, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x43, 0x6f,
+	0x75, 0x6e, 0x74, 0x12, 0x30, 0x0a, 0x05, 0x64, 0x61, 0x69, 0x6c, 0x79, 0x18, 0x05, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x44, 0x61, 0x69,
+	0x6c, 0x79, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x05,
+	0x64, 0x61, 0x69, 0x6c, 0x79, 0x22, 0x70, 0x0a, 0x10, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65,
+	0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x48, 0x69, 0x74, 0x12, 0x2a, 0x0a, 0x07, 0x61, 0x72, 0x74,
+	0x69, 0x63, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x61, 0x72, 0x74,
+	0x69, 0x63, 0x6c, 0x65, 0x2e, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x07, 0x61, 0x72,
+	0x74, 0x69, 0x63, 0x6c, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x6e, 0x69, 0x70, 0x70, 0x65, 0x74,
+	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x73, 0x6e, 0x69, 0x70, 0x70, 0x65, 0x74,
+	0x73, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x22, 0xf7, 0x01, 0x0a, 0x06, 0x52, 0x65, 0x70, 0x6f,
+	0x72, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02,
+	0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6f,
+	0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63,
+	0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73,
+	0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e,
+	0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x29, 0x0a, 0x08, 0x72, 0x65, 0x70, 0x6f,
+	0x72, 0x74, 0x65, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x75, 0x73, 0x65,
+	0x72, 0x2e, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x52, 0x08, 0x72, 0x65, 0x70, 0x6f, 0x72,
+	0x74, 0x65, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74,
+	0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41,
+	0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x72, 0x65, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x64, 0x41, 0x74, 0x18,
+	0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x72, 0x65, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x64, 0x41,
+	0x74, 0x22, 0xe1, 0x01, 0x0a, 0x14, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x41, 0x72, 0x69, 0x74,
+	0x63, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x3f, 0x0a, 0x07, 0x61, 0x72,
+	0x74, 0x69, 0x63, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x61, 0x72,
+	0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x41, 0x72, 0x69, 0x74,
+	0x63, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x41, 0x72, 0x74, 0x69, 0x63,
+	0x6c, 0x65, 0x52, 0x07, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x1a, 0x87, 0x01, 0x0a, 0x07,
 	0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65,
 	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x20, 0x0a,
 	0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01,
 	0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12,
 	0x12, 0x0a, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x62,
 	0x6f, 0x64, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x74, 0x61, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x18, 0x04,
-	0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x74, 0x61, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x22, 0x27, 0x0a,
-	0x11, 0x47, 0x65, 0x74, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x22, 0x8a, 0x01, 0x0a, 0x12, 0x47, 0x65, 0x74, 0x41, 0x72,
-	0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a,
-	0x03, 0x74, 0x61, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x74, 0x61, 0x67, 0x12,
-	0x16, 0x0a, 0x06, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x06, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x66, 0x61, 0x76, 0x6f, 0x72,
-	0x69, 0x74, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x66, 0x61, 0x76, 0x6f,
-	0x72, 0x69, 0x74, 0x65, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x04,
-	0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x6f,
-	0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x6f, 0x66, 0x66,
-	0x73, 0x65, 0x74, 0x22, 0x46, 0x0a, 0x16, 0x47, 0x65, 0x74, 0x46, 0x65, 0x65, 0x64, 0x41, 0x72,
-	0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a,
-	0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6c, 0x69,
-	0x6d, 0x69, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x03, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x22, 0xc2, 0x01, 0x0a, 0x14,
-	0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x12, 0x3f, 0x0a, 0x07, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e,
-	0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x2e, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x07, 0x61, 0x72,
-	0x74, 0x69, 0x63, 0x6c, 0x65, 0x1a, 0x69, 0x0a, 0x07, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65,
-	0x12, 0x14, 0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69,
-	0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x73,
-	0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x62, 0x6f, 0x64, 0x79,
-	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x12, 0x12, 0x0a, 0x04,
-	0x73, 0x6c, 0x75, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6c, 0x75, 0x67,
-	0x22, 0x2a, 0x0a, 0x14, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x74, 0x61, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x16, 0x0a,
+	0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x27, 0x0a, 0x11, 0x47, 0x65, 0x74, 0x41, 0x72, 0x74, 0x69,
+	0x63, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6c,
+	0x75, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x22, 0x40,
+	0x0a, 0x16, 0x47, 0x65, 0x74, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6c, 0x75, 0x67,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x12, 0x12, 0x0a, 0x04,
+	0x64, 0x61, 0x79, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x64, 0x61, 0x79, 0x73,
+	0x22, 0x45, 0x0a, 0x19, 0x47, 0x65, 0x74, 0x52, 0x65, 0x6c, 0x61, 0x74, 0x65, 0x64, 0x41, 0x72,
+	0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a,
+	0x04, 0x73, 0x6c, 0x75, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6c, 0x75,
+	0x67, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x22, 0x9e, 0x01, 0x0a, 0x12, 0x47, 0x65, 0x74, 0x41,
+	0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10,
+	0x0a, 0x03, 0x74, 0x61, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x74, 0x61, 0x67,
+	0x12, 0x16, 0x0a, 0x06, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x66, 0x61, 0x76, 0x6f,
+	0x72, 0x69, 0x74, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x66, 0x61, 0x76,
+	0x6f, 0x72, 0x69, 0x74, 0x65, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x16, 0x0a, 0x06,
+	0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x6f, 0x66,
+	0x66, 0x73, 0x65, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6f, 0x72, 0x74, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x73, 0x6f, 0x72, 0x74, 0x22, 0x9b, 0x01, 0x0a, 0x15, 0x53, 0x65, 0x61,
+	0x72, 0x63, 0x68, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x0c, 0x0a, 0x01, 0x71, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x01, 0x71,
+	0x12, 0x10, 0x0a, 0x03, 0x74, 0x61, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x74,
+	0x61, 0x67, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x66, 0x61,
+	0x76, 0x6f, 0x72, 0x69, 0x74, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x66,
+	0x61, 0x76, 0x6f, 0x72, 0x69, 0x74, 0x65, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69,
+	0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x16,
+	0x0a, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06,
+	0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x22, 0x46, 0x0a, 0x16, 0x47, 0x65, 0x74, 0x46, 0x65, 0x65,
+	0x64, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x22, 0xc2,
+	0x01, 0x0a, 0x14, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x3f, 0x0a, 0x07, 0x61, 0x72, 0x74, 0x69, 0x63,
+	0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63,
+	0x6c, 0x65, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52,
+	0x07, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x1a, 0x69, 0x0a, 0x07, 0x41, 0x72, 0x74, 0x69,
+	0x63, 0x6c, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73,
+	0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b,
+	0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x62,
+	0x6f, 0x64, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x12,
+	0x12, 0x0a, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73,
+	0x6c, 0x75, 0x67, 0x22, 0x2a, 0x0a, 0x14, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x41, 0x72, 0x74,
+	0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73,
+	0x6c, 0x75, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x22,
+	0x2b, 0x0a, 0x15, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c,
 	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6c, 0x75, 0x67,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x22, 0x2c, 0x0a, 0x16,
-	0x46, 0x61, 0x76, 0x6f, 0x72, 0x69, 0x74, 0x65, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x22, 0x2e, 0x0a, 0x18, 0x55, 0x6e,
-	0x66, 0x61, 0x76, 0x6f, 0x72, 0x69, 0x74, 0x65, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x22, 0x8a, 0x01, 0x0a, 0x14, 0x43,
-	0x72, 0x65, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x12, 0x3f, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x65,
-	0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63,
-	0x6c, 0x65, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x52,
-	0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x1a, 0x1d, 0x0a, 0x07, 0x43, 0x6f, 0x6d, 0x6d,
-	0x65, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x22, 0x28, 0x0a, 0x12, 0x47, 0x65, 0x74, 0x43, 0x6f,
-	0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x22, 0x45, 0x0a, 0x17,
+	0x55, 0x6e, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x12, 0x16, 0x0a, 0x06, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x22, 0x4a, 0x0a, 0x16, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x41,
+	0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a,
 	0x04, 0x73, 0x6c, 0x75, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6c, 0x75,
-	0x67, 0x22, 0x3a, 0x0a, 0x14, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x65,
-	0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6c, 0x75,
-	0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x12, 0x0e, 0x0a,
-	0x02, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0x3d, 0x0a,
-	0x0f, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x12, 0x2a, 0x0a, 0x07, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x10, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x41, 0x72, 0x74, 0x69,
-	0x63, 0x6c, 0x65, 0x52, 0x07, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x22, 0x66, 0x0a, 0x10,
-	0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x12, 0x2c, 0x0a, 0x08, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03,
-	0x28, 0x0b, 0x32, 0x10, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x41, 0x72, 0x74,
-	0x69, 0x63, 0x6c, 0x65, 0x52, 0x08, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x12, 0x24,
-	0x0a, 0x0d, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x43,
-	0x6f, 0x75, 0x6e, 0x74, 0x22, 0x22, 0x0a, 0x0c, 0x54, 0x61, 0x67, 0x73, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03,
-	0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x22, 0x3d, 0x0a, 0x0f, 0x43, 0x6f, 0x6d, 0x6d,
-	0x65, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2a, 0x0a, 0x07, 0x63,
-	0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x61,
-	0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x07,
-	0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x22, 0x40, 0x0a, 0x10, 0x43, 0x6f, 0x6d, 0x6d, 0x65,
+	0x67, 0x12, 0x1c, 0x0a, 0x09, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x41, 0x74, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x41, 0x74, 0x22,
+	0x2e, 0x0a, 0x18, 0x55, 0x6e, 0x73, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x41, 0x72, 0x74,
+	0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73,
+	0x6c, 0x75, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x22,
+	0x43, 0x0a, 0x13, 0x4c, 0x69, 0x73, 0x74, 0x4d, 0x79, 0x44, 0x72, 0x61, 0x66, 0x74, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x16, 0x0a, 0x06,
+	0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x6f, 0x66,
+	0x66, 0x73, 0x65, 0x74, 0x22, 0x31, 0x0a, 0x1b, 0x4c, 0x69, 0x73, 0x74, 0x41, 0x72, 0x74, 0x69,
+	0x63, 0x6c, 0x65, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x22, 0x47, 0x0a, 0x19, 0x47, 0x65, 0x74, 0x41, 0x72,
+	0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x12, 0x16, 0x0a, 0x06, 0x6e, 0x75, 0x6d, 0x62,
+	0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72,
+	0x22, 0x55, 0x0a, 0x1b, 0x44, 0x69, 0x66, 0x66, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52,
+	0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x12, 0x0a, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73,
+	0x6c, 0x75, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x0e, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x02, 0x74, 0x6f, 0x22, 0x4b, 0x0a, 0x1d, 0x52, 0x65, 0x73, 0x74, 0x6f,
+	0x72, 0x65, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f,
+	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6c, 0x75, 0x67,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x12, 0x16, 0x0a, 0x06,
+	0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x6e, 0x75,
+	0x6d, 0x62, 0x65, 0x72, 0x22, 0x2c, 0x0a, 0x16, 0x46, 0x61, 0x76, 0x6f, 0x72, 0x69, 0x74, 0x65,
+	0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12,
+	0x0a, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6c,
+	0x75, 0x67, 0x22, 0x2e, 0x0a, 0x18, 0x55, 0x6e, 0x66, 0x61, 0x76, 0x6f, 0x72, 0x69, 0x74, 0x65,
+	0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12,
+	0x0a, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6c,
+	0x75, 0x67, 0x22, 0xa6, 0x01, 0x0a, 0x14, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x6d,
+	0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73,
+	0x6c, 0x75, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x12,
+	0x3f, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x25, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e,
+	0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74,
+	0x1a, 0x39, 0x0a, 0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x62,
+	0x6f, 0x64, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x12,
+	0x1a, 0x0a, 0x08, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x22, 0x56, 0x0a, 0x12, 0x47,
+	0x65, 0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x73, 0x6c, 0x75, 0x67, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x63,
+	0x75, 0x72, 0x73, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63, 0x75, 0x72,
+	0x73, 0x6f, 0x72, 0x22, 0x9a, 0x01, 0x0a, 0x14, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x43, 0x6f,
+	0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04,
+	0x73, 0x6c, 0x75, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6c, 0x75, 0x67,
+	0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64,
+	0x12, 0x3f, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x25, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x55, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e,
+	0x74, 0x1a, 0x1d, 0x0a, 0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04,
+	0x62, 0x6f, 0x64, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x62, 0x6f, 0x64, 0x79,
+	0x22, 0x3a, 0x0a, 0x14, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e,
+	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6c, 0x75, 0x67,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x12, 0x0e, 0x0a, 0x02,
+	0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0x42, 0x0a, 0x14,
+	0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73,
+	0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e,
+	0x22, 0x52, 0x0a, 0x14, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e,
+	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6c, 0x75, 0x67,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x12, 0x0e, 0x0a, 0x02,
+	0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x16, 0x0a, 0x06,
+	0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65,
+	0x61, 0x73, 0x6f, 0x6e, 0x22, 0x3d, 0x0a, 0x0f, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2a, 0x0a, 0x07, 0x61, 0x72, 0x74, 0x69, 0x63,
+	0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63,
+	0x6c, 0x65, 0x2e, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x07, 0x61, 0x72, 0x74, 0x69,
+	0x63, 0x6c, 0x65, 0x22, 0x43, 0x0a, 0x14, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x53, 0x74,
+	0x61, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2b, 0x0a, 0x05, 0x73,
+	0x74, 0x61, 0x74, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x61, 0x72, 0x74,
+	0x69, 0x63, 0x6c, 0x65, 0x2e, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74,
+	0x73, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x73, 0x22, 0x66, 0x0a, 0x10, 0x41, 0x72, 0x74, 0x69,
+	0x63, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2c, 0x0a, 0x08,
+	0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10,
+	0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65,
+	0x52, 0x08, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x12, 0x24, 0x0a, 0x0d, 0x61, 0x72,
+	0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0d, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74,
+	0x22, 0x4f, 0x0a, 0x17, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x76, 0x69, 0x73,
+	0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x34, 0x0a, 0x08, 0x72,
+	0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e,
+	0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52,
+	0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f,
+	0x6e, 0x22, 0x7a, 0x0a, 0x18, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x76, 0x69,
+	0x73, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x36, 0x0a,
+	0x09, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x18, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x41, 0x72, 0x74, 0x69, 0x63,
+	0x6c, 0x65, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x09, 0x72, 0x65, 0x76, 0x69,
+	0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x26, 0x0a, 0x0e, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f,
+	0x6e, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x72,
+	0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0x4d, 0x0a,
+	0x13, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x44, 0x69, 0x66, 0x66, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x0e, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x74, 0x6f, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x69, 0x66, 0x66,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x64, 0x69, 0x66, 0x66, 0x22, 0x65, 0x0a, 0x16,
+	0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2d, 0x0a, 0x04, 0x68, 0x69, 0x74, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x41,
+	0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x48, 0x69, 0x74, 0x52,
+	0x04, 0x68, 0x69, 0x74, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x68, 0x69, 0x74, 0x73, 0x43, 0x6f, 0x75,
+	0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x68, 0x69, 0x74, 0x73, 0x43, 0x6f,
+	0x75, 0x6e, 0x74, 0x22, 0x2e, 0x0a, 0x16, 0x47, 0x65, 0x74, 0x54, 0x72, 0x65, 0x6e, 0x64, 0x69,
+	0x6e, 0x67, 0x54, 0x61, 0x67, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a,
+	0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6c, 0x69,
+	0x6d, 0x69, 0x74, 0x22, 0x22, 0x0a, 0x0c, 0x54, 0x61, 0x67, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x22, 0x3d, 0x0a, 0x0f, 0x43, 0x6f, 0x6d, 0x6d, 0x65,
+	0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2a, 0x0a, 0x07, 0x63, 0x6f,
+	0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x61, 0x72,
+	0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x07, 0x63,
+	0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x22, 0x86, 0x01, 0x0a, 0x10, 0x43, 0x6f, 0x6d, 0x6d, 0x65,
 	0x6e, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2c, 0x0a, 0x08, 0x63,
 	0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e,
 	0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x52,
-	0x08, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x32, 0xb8, 0x09, 0x0a, 0x08, 0x41, 0x72,
-	0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x12, 0x5e, 0x0a, 0x0d, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
-	0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x12, 0x1d, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c,
-	0x65, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x41, 0x72, 0x69, 0x74, 0x63, 0x6c, 0x65, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65,
-	0x2e, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x22, 0x14, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0e, 0x22, 0x09, 0x2f, 0x61, 0x72, 0x74, 0x69, 0x63,
-	0x6c, 0x65, 0x73, 0x3a, 0x01, 0x2a, 0x12, 0x65, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x46, 0x65, 0x65,
-	0x64, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x12, 0x1f, 0x2e, 0x61, 0x72, 0x74, 0x69,
-	0x63, 0x6c, 0x65, 0x2e, 0x47, 0x65, 0x74, 0x46, 0x65, 0x65, 0x64, 0x41, 0x72, 0x74, 0x69, 0x63,
-	0x6c, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x61, 0x72, 0x74,
-	0x69, 0x63, 0x6c, 0x65, 0x2e, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x16, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x10, 0x12, 0x0e, 0x2f,
-	0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x2f, 0x66, 0x65, 0x65, 0x64, 0x12, 0x5c, 0x0a,
-	0x0a, 0x47, 0x65, 0x74, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x12, 0x1a, 0x2e, 0x61, 0x72,
-	0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65,
+	0x08, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x24, 0x0a, 0x0d, 0x63, 0x6f, 0x6d,
+	0x6d, 0x65, 0x6e, 0x74, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x0d, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12,
+	0x1e, 0x0a, 0x0a, 0x6e, 0x65, 0x78, 0x74, 0x43, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0a, 0x6e, 0x65, 0x78, 0x74, 0x43, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x22,
+	0x39, 0x0a, 0x0e, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x27, 0x0a, 0x06, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0f, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x52, 0x65, 0x70, 0x6f,
+	0x72, 0x74, 0x52, 0x06, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x32, 0xa1, 0x18, 0x0a, 0x08, 0x41,
+	0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x12, 0x5e, 0x0a, 0x0d, 0x43, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x12, 0x1d, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63,
+	0x6c, 0x65, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x41, 0x72, 0x69, 0x74, 0x63, 0x6c, 0x65,
 	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c,
 	0x65, 0x2e, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x22, 0x14, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0e, 0x22, 0x09, 0x2f, 0x61, 0x72, 0x74, 0x69,
+	0x63, 0x6c, 0x65, 0x73, 0x3a, 0x01, 0x2a, 0x12, 0x65, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x46, 0x65,
+	0x65, 0x64, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x12, 0x1f, 0x2e, 0x61, 0x72, 0x74,
+	0x69, 0x63, 0x6c, 0x65, 0x2e, 0x47, 0x65, 0x74, 0x46, 0x65, 0x65, 0x64, 0x41, 0x72, 0x74, 0x69,
+	0x63, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x61, 0x72,
+	0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x16, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x10, 0x12, 0x0e,
+	0x2f, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x2f, 0x66, 0x65, 0x65, 0x64, 0x12, 0x6b,
+	0x0a, 0x0e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73,
+	0x12, 0x1e, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x53, 0x65, 0x61, 0x72, 0x63,
+	0x68, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x1f, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x53, 0x65, 0x61, 0x72, 0x63,
+	0x68, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
 	0x65, 0x22, 0x18, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x12, 0x12, 0x10, 0x2f, 0x61, 0x72, 0x74, 0x69,
-	0x63, 0x6c, 0x65, 0x73, 0x2f, 0x7b, 0x73, 0x6c, 0x75, 0x67, 0x7d, 0x12, 0x58, 0x0a, 0x0b, 0x47,
-	0x65, 0x74, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x12, 0x1b, 0x2e, 0x61, 0x72, 0x74,
-	0x69, 0x63, 0x6c, 0x65, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c,
-	0x65, 0x2e, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x22, 0x11, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0b, 0x12, 0x09, 0x2f, 0x61, 0x72, 0x74,
-	0x69, 0x63, 0x6c, 0x65, 0x73, 0x12, 0x6d, 0x0a, 0x0d, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x41,
-	0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x12, 0x1d, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65,
-	0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65,
+	0x63, 0x6c, 0x65, 0x73, 0x2f, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x12, 0x5c, 0x0a, 0x0a, 0x47,
+	0x65, 0x74, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x12, 0x1a, 0x2e, 0x61, 0x72, 0x74, 0x69,
+	0x63, 0x6c, 0x65, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65,
 	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e,
 	0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
-	0x23, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1d, 0x1a, 0x18, 0x2f, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c,
-	0x65, 0x73, 0x2f, 0x7b, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x73, 0x6c, 0x75, 0x67,
-	0x7d, 0x3a, 0x01, 0x2a, 0x12, 0x56, 0x0a, 0x0d, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x41, 0x72,
-	0x74, 0x69, 0x63, 0x6c, 0x65, 0x12, 0x1d, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e,
-	0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x1a, 0x0c, 0x2e, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x2e, 0x45, 0x6d, 0x70,
-	0x74, 0x79, 0x22, 0x18, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x12, 0x2a, 0x10, 0x2f, 0x61, 0x72, 0x74,
-	0x69, 0x63, 0x6c, 0x65, 0x73, 0x2f, 0x7b, 0x73, 0x6c, 0x75, 0x67, 0x7d, 0x12, 0x72, 0x0a, 0x0f,
-	0x46, 0x61, 0x76, 0x6f, 0x72, 0x69, 0x74, 0x65, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x12,
-	0x1f, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x46, 0x61, 0x76, 0x6f, 0x72, 0x69,
-	0x74, 0x65, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x18, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x41, 0x72, 0x74, 0x69, 0x63,
-	0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x24, 0x82, 0xd3, 0xe4, 0x93,
-	0x02, 0x1e, 0x22, 0x19, 0x2f, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x2f, 0x7b, 0x73,
-	0x6c, 0x75, 0x67, 0x7d, 0x2f, 0x66, 0x61, 0x76, 0x6f, 0x72, 0x69, 0x74, 0x65, 0x3a, 0x01, 0x2a,
-	0x12, 0x73, 0x0a, 0x11, 0x55, 0x6e, 0x66, 0x61, 0x76, 0x6f, 0x72, 0x69, 0x74, 0x65, 0x41, 0x72,
-	0x74, 0x69, 0x63, 0x6c, 0x65, 0x12, 0x21, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e,
-	0x55, 0x6e, 0x66, 0x61, 0x76, 0x6f, 0x72, 0x69, 0x74, 0x65, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c,
+	0x18, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x12, 0x12, 0x10, 0x2f, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c,
+	0x65, 0x73, 0x2f, 0x7b, 0x73, 0x6c, 0x75, 0x67, 0x7d, 0x12, 0x58, 0x0a, 0x0b, 0x47, 0x65, 0x74,
+	0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x12, 0x1b, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63,
+	0x6c, 0x65, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e,
+	0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x11, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0b, 0x12, 0x09, 0x2f, 0x61, 0x72, 0x74, 0x69, 0x63,
+	0x6c, 0x65, 0x73, 0x12, 0x6d, 0x0a, 0x0d, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x41, 0x72, 0x74,
+	0x69, 0x63, 0x6c, 0x65, 0x12, 0x1d, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x55,
+	0x70, 0x64, 0x61, 0x74, 0x65, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x41, 0x72,
+	0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x23, 0x82,
+	0xd3, 0xe4, 0x93, 0x02, 0x1d, 0x1a, 0x18, 0x2f, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73,
+	0x2f, 0x7b, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x73, 0x6c, 0x75, 0x67, 0x7d, 0x3a,
+	0x01, 0x2a, 0x12, 0x56, 0x0a, 0x0d, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x41, 0x72, 0x74, 0x69,
+	0x63, 0x6c, 0x65, 0x12, 0x1d, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x44, 0x65,
+	0x6c, 0x65, 0x74, 0x65, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x0c, 0x2e, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79,
+	0x22, 0x18, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x12, 0x2a, 0x10, 0x2f, 0x61, 0x72, 0x74, 0x69, 0x63,
+	0x6c, 0x65, 0x73, 0x2f, 0x7b, 0x73, 0x6c, 0x75, 0x67, 0x7d, 0x12, 0x6f, 0x0a, 0x0e, 0x50, 0x75,
+	0x62, 0x6c, 0x69, 0x73, 0x68, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x12, 0x1e, 0x2e, 0x61,
+	0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x41, 0x72,
+	0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x61,
+	0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x23, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1d, 0x22, 0x18,
+	0x2f, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x2f, 0x7b, 0x73, 0x6c, 0x75, 0x67, 0x7d,
+	0x2f, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x3a, 0x01, 0x2a, 0x12, 0x75, 0x0a, 0x10, 0x55,
+	0x6e, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x12,
+	0x20, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x55, 0x6e, 0x70, 0x75, 0x62, 0x6c,
+	0x69, 0x73, 0x68, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x18, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x41, 0x72, 0x74, 0x69,
+	0x63, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x25, 0x82, 0xd3, 0xe4,
+	0x93, 0x02, 0x1f, 0x22, 0x1a, 0x2f, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x2f, 0x7b,
+	0x73, 0x6c, 0x75, 0x67, 0x7d, 0x2f, 0x75, 0x6e, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x3a,
+	0x01, 0x2a, 0x12, 0x72, 0x0a, 0x0f, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x41, 0x72,
+	0x74, 0x69, 0x63, 0x6c, 0x65, 0x12, 0x1f, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e,
+	0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65,
+	0x2e, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x24, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1e, 0x1a, 0x19, 0x2f, 0x61, 0x72, 0x74, 0x69, 0x63,
+	0x6c, 0x65, 0x73, 0x2f, 0x7b, 0x73, 0x6c, 0x75, 0x67, 0x7d, 0x2f, 0x73, 0x63, 0x68, 0x65, 0x64,
+	0x75, 0x6c, 0x65, 0x3a, 0x01, 0x2a, 0x12, 0x73, 0x0a, 0x11, 0x55, 0x6e, 0x73, 0x63, 0x68, 0x65,
+	0x64, 0x75, 0x6c, 0x65, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x12, 0x21, 0x2e, 0x61, 0x72,
+	0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x55, 0x6e, 0x73, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65,
+	0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18,
+	0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x21, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1b,
+	0x2a, 0x19, 0x2f, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x2f, 0x7b, 0x73, 0x6c, 0x75,
+	0x67, 0x7d, 0x2f, 0x73, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x12, 0x5d, 0x0a, 0x0c, 0x4c,
+	0x69, 0x73, 0x74, 0x4d, 0x79, 0x44, 0x72, 0x61, 0x66, 0x74, 0x73, 0x12, 0x1c, 0x2e, 0x61, 0x72,
+	0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x4d, 0x79, 0x44, 0x72, 0x61, 0x66,
+	0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x61, 0x72, 0x74, 0x69,
+	0x63, 0x6c, 0x65, 0x2e, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x14, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0e, 0x12, 0x0c, 0x2f, 0x75,
+	0x73, 0x65, 0x72, 0x2f, 0x64, 0x72, 0x61, 0x66, 0x74, 0x73, 0x12, 0x83, 0x01, 0x0a, 0x14, 0x4c,
+	0x69, 0x73, 0x74, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69,
+	0x6f, 0x6e, 0x73, 0x12, 0x24, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x4c, 0x69,
+	0x73, 0x74, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f,
+	0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x61, 0x72, 0x74, 0x69,
+	0x63, 0x6c, 0x65, 0x2e, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x76, 0x69, 0x73,
+	0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x22, 0x82, 0xd3,
+	0xe4, 0x93, 0x02, 0x1c, 0x12, 0x1a, 0x2f, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x2f,
+	0x7b, 0x73, 0x6c, 0x75, 0x67, 0x7d, 0x2f, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73,
+	0x12, 0x87, 0x01, 0x0a, 0x12, 0x47, 0x65, 0x74, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52,
+	0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x22, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c,
+	0x65, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x76, 0x69,
+	0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x61, 0x72,
+	0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x76,
+	0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x2b, 0x82,
+	0xd3, 0xe4, 0x93, 0x02, 0x25, 0x12, 0x23, 0x2f, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73,
+	0x2f, 0x7b, 0x73, 0x6c, 0x75, 0x67, 0x7d, 0x2f, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e,
+	0x73, 0x2f, 0x7b, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x7d, 0x12, 0x79, 0x0a, 0x14, 0x44, 0x69,
+	0x66, 0x66, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f,
+	0x6e, 0x73, 0x12, 0x24, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x44, 0x69, 0x66,
+	0x66, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63,
+	0x6c, 0x65, 0x2e, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x44, 0x69, 0x66, 0x66, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1d, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x17, 0x12, 0x15,
+	0x2f, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x2f, 0x7b, 0x73, 0x6c, 0x75, 0x67, 0x7d,
+	0x2f, 0x64, 0x69, 0x66, 0x66, 0x12, 0x92, 0x01, 0x0a, 0x16, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72,
+	0x65, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e,
+	0x12, 0x26, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x52, 0x65, 0x73, 0x74, 0x6f,
+	0x72, 0x65, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f,
+	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63,
+	0x6c, 0x65, 0x2e, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x36, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x30, 0x22, 0x2b, 0x2f, 0x61, 0x72, 0x74,
+	0x69, 0x63, 0x6c, 0x65, 0x73, 0x2f, 0x7b, 0x73, 0x6c, 0x75, 0x67, 0x7d, 0x2f, 0x72, 0x65, 0x76,
+	0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x2f, 0x7b, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x7d, 0x2f,
+	0x72, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x3a, 0x01, 0x2a, 0x12, 0x71, 0x0a, 0x0f, 0x47, 0x65,
+	0x74, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x1f, 0x2e,
+	0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x72, 0x74, 0x69, 0x63,
+	0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d,
+	0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65,
+	0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1e, 0x82,
+	0xd3, 0xe4, 0x93, 0x02, 0x18, 0x12, 0x16, 0x2f, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73,
+	0x2f, 0x7b, 0x73, 0x6c, 0x75, 0x67, 0x7d, 0x2f, 0x73, 0x74, 0x61, 0x74, 0x73, 0x12, 0x75, 0x0a,
+	0x12, 0x47, 0x65, 0x74, 0x52, 0x65, 0x6c, 0x61, 0x74, 0x65, 0x64, 0x41, 0x72, 0x74, 0x69, 0x63,
+	0x6c, 0x65, 0x73, 0x12, 0x22, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x47, 0x65,
+	0x74, 0x52, 0x65, 0x6c, 0x61, 0x74, 0x65, 0x64, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c,
+	0x65, 0x2e, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x20, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1a, 0x12, 0x18, 0x2f, 0x61, 0x72, 0x74,
+	0x69, 0x63, 0x6c, 0x65, 0x73, 0x2f, 0x7b, 0x73, 0x6c, 0x75, 0x67, 0x7d, 0x2f, 0x72, 0x65, 0x6c,
+	0x61, 0x74, 0x65, 0x64, 0x12, 0x72, 0x0a, 0x0f, 0x46, 0x61, 0x76, 0x6f, 0x72, 0x69, 0x74, 0x65,
+	0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x12, 0x1f, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c,
+	0x65, 0x2e, 0x46, 0x61, 0x76, 0x6f, 0x72, 0x69, 0x74, 0x65, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c,
 	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63,
 	0x6c, 0x65, 0x2e, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x22, 0x21, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1b, 0x2a, 0x19, 0x2f, 0x61, 0x72, 0x74,
+	0x73, 0x65, 0x22, 0x24, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1e, 0x22, 0x19, 0x2f, 0x61, 0x72, 0x74,
 	0x69, 0x63, 0x6c, 0x65, 0x73, 0x2f, 0x7b, 0x73, 0x6c, 0x75, 0x67, 0x7d, 0x2f, 0x66, 0x61, 0x76,
-	0x6f, 0x72, 0x69, 0x74, 0x65, 0x12, 0x3d, 0x0a, 0x07, 0x47, 0x65, 0x74, 0x54, 0x61, 0x67, 0x73,
-	0x12, 0x0c, 0x2e, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x15,
-	0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x54, 0x61, 0x67, 0x73, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x0d, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x07, 0x12, 0x05, 0x2f,
-	0x74, 0x61, 0x67, 0x73, 0x12, 0x6e, 0x0a, 0x0d, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x43, 0x6f,
-	0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x1d, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e,
-	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x43,
-	0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x24,
-	0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1e, 0x22, 0x19, 0x2f, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65,
-	0x73, 0x2f, 0x7b, 0x73, 0x6c, 0x75, 0x67, 0x7d, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74,
-	0x73, 0x3a, 0x01, 0x2a, 0x12, 0x68, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x65,
-	0x6e, 0x74, 0x73, 0x12, 0x1b, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x47, 0x65,
-	0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x19, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x65,
-	0x6e, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x21, 0x82, 0xd3, 0xe4,
-	0x93, 0x02, 0x1b, 0x12, 0x19, 0x2f, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x2f, 0x7b,
-	0x73, 0x6c, 0x75, 0x67, 0x7d, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x64,
+	0x6f, 0x72, 0x69, 0x74, 0x65, 0x3a, 0x01, 0x2a, 0x12, 0x73, 0x0a, 0x11, 0x55, 0x6e, 0x66, 0x61,
+	0x76, 0x6f, 0x72, 0x69, 0x74, 0x65, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x12, 0x21, 0x2e,
+	0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x55, 0x6e, 0x66, 0x61, 0x76, 0x6f, 0x72, 0x69,
+	0x74, 0x65, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x18, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x41, 0x72, 0x74, 0x69, 0x63,
+	0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x21, 0x82, 0xd3, 0xe4, 0x93,
+	0x02, 0x1b, 0x2a, 0x19, 0x2f, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x2f, 0x7b, 0x73,
+	0x6c, 0x75, 0x67, 0x7d, 0x2f, 0x66, 0x61, 0x76, 0x6f, 0x72, 0x69, 0x74, 0x65, 0x12, 0x3d, 0x0a,
+	0x07, 0x47, 0x65, 0x74, 0x54, 0x61, 0x67, 0x73, 0x12, 0x0c, 0x2e, 0x65, 0x6d, 0x70, 0x74, 0x79,
+	0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x15, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65,
+	0x2e, 0x54, 0x61, 0x67, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x0d, 0x82,
+	0xd3, 0xe4, 0x93, 0x02, 0x07, 0x12, 0x05, 0x2f, 0x74, 0x61, 0x67, 0x73, 0x12, 0x61, 0x0a, 0x0f,
+	0x47, 0x65, 0x74, 0x54, 0x72, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x54, 0x61, 0x67, 0x73, 0x12,
+	0x1f, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x47, 0x65, 0x74, 0x54, 0x72, 0x65,
+	0x6e, 0x64, 0x69, 0x6e, 0x67, 0x54, 0x61, 0x67, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x15, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x54, 0x61, 0x67, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x16, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x10, 0x12,
+	0x0e, 0x2f, 0x74, 0x61, 0x67, 0x73, 0x2f, 0x74, 0x72, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x12,
+	0x6e, 0x0a, 0x0d, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74,
+	0x12, 0x1d, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x18, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e,
+	0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x24, 0x82, 0xd3, 0xe4, 0x93, 0x02,
+	0x1e, 0x22, 0x19, 0x2f, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x2f, 0x7b, 0x73, 0x6c,
+	0x75, 0x67, 0x7d, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x3a, 0x01, 0x2a, 0x12,
+	0x68, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x1b,
+	0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x47, 0x65, 0x74, 0x43, 0x6f, 0x6d, 0x6d,
+	0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x61, 0x72,
+	0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x21, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1b, 0x12, 0x19,
+	0x2f, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x2f, 0x7b, 0x73, 0x6c, 0x75, 0x67, 0x7d,
+	0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x73, 0x0a, 0x0d, 0x55, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x1d, 0x2e, 0x61, 0x72, 0x74,
+	0x69, 0x63, 0x6c, 0x65, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x65,
+	0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x61, 0x72, 0x74, 0x69,
+	0x63, 0x6c, 0x65, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x22, 0x29, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x23, 0x1a, 0x1e, 0x2f, 0x61, 0x72,
+	0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x2f, 0x7b, 0x73, 0x6c, 0x75, 0x67, 0x7d, 0x2f, 0x63, 0x6f,
+	0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x2f, 0x7b, 0x69, 0x64, 0x7d, 0x3a, 0x01, 0x2a, 0x12, 0x64,
 	0x0a, 0x0d, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x12,
 	0x1d, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65,
 	0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0c,
 	0x2e, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x26, 0x82, 0xd3,
-	0xe4, 0x93, 0x02, 0x20, 0x12, 0x1e, 0x2f, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x2f,
+	0xe4, 0x93, 0x02, 0x20, 0x2a, 0x1e, 0x2f, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x2f,
 	0x7b, 0x73, 0x6c, 0x75, 0x67, 0x7d, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x2f,
-	0x7b, 0x69, 0x64, 0x7d, 0x42, 0x09, 0x5a, 0x07, 0x2e, 0x3b, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
-	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x7b, 0x69, 0x64, 0x7d, 0x12, 0x6c, 0x0a, 0x0d, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x41, 0x72,
+	0x74, 0x69, 0x63, 0x6c, 0x65, 0x12, 0x1d, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e,
+	0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x41, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x52,
+	0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x23, 0x82,
+	0xd3, 0xe4, 0x93, 0x02, 0x1d, 0x22, 0x18, 0x2f, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73,
+	0x2f, 0x7b, 0x73, 0x6c, 0x75, 0x67, 0x7d, 0x2f, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x73, 0x3a,
+	0x01, 0x2a, 0x12, 0x7a, 0x0a, 0x0d, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x43, 0x6f, 0x6d, 0x6d,
+	0x65, 0x6e, 0x74, 0x12, 0x1d, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x52, 0x65,
+	0x70, 0x6f, 0x72, 0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x17, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x52, 0x65, 0x70,
+	0x6f, 0x72, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x31, 0x82, 0xd3, 0xe4,
+	0x93, 0x02, 0x2b, 0x22, 0x26, 0x2f, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x2f, 0x7b,
+	0x73, 0x6c, 0x75, 0x67, 0x7d, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x2f, 0x7b,
+	0x69, 0x64, 0x7d, 0x2f, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x73, 0x3a, 0x01, 0x2a, 0x42, 0x09,
+	0x5a, 0x07, 0x2e, 0x3b, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
 }
 
 var (
@@ -1451,71 +3608,141 @@ func file_article_proto_rawDescGZIP() []byte {
 	return file_article_proto_rawDescData
 }
 
-var file_article_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
+var file_article_proto_msgTypes = make([]protoimpl.MessageInfo, 49)
 var file_article_proto_goTypes = []interface{}{
-	(*Article)(nil),                      // 0: article.Article
-	(*Comment)(nil),                      // 1: article.Comment
-	(*CreateAritcleRequest)(nil),         // 2: article.CreateAritcleRequest
-	(*GetArticleRequest)(nil),            // 3: article.GetArticleRequest
-	(*GetArticlesRequest)(nil),           // 4: article.GetArticlesRequest
-	(*GetFeedArticlesRequest)(nil),       // 5: article.GetFeedArticlesRequest
-	(*UpdateArticleRequest)(nil),         // 6: article.UpdateArticleRequest
-	(*DeleteArticleRequest)(nil),         // 7: article.DeleteArticleRequest
-	(*FavoriteArticleRequest)(nil),       // 8: article.FavoriteArticleRequest
-	(*UnfavoriteArticleRequest)(nil),     // 9: article.UnfavoriteArticleRequest
-	(*CreateCommentRequest)(nil),         // 10: article.CreateCommentRequest
-	(*GetCommentsRequest)(nil),           // 11: article.GetCommentsRequest
-	(*DeleteCommentRequest)(nil),         // 12: article.DeleteCommentRequest
-	(*ArticleResponse)(nil),              // 13: article.ArticleResponse
-	(*ArticlesResponse)(nil),             // 14: article.ArticlesResponse
-	(*TagsResponse)(nil),                 // 15: article.TagsResponse
-	(*CommentResponse)(nil),              // 16: article.CommentResponse
-	(*CommentsResponse)(nil),             // 17: article.CommentsResponse
-	(*CreateAritcleRequest_Article)(nil), // 18: article.CreateAritcleRequest.Article
-	(*UpdateArticleRequest_Article)(nil), // 19: article.UpdateArticleRequest.Article
-	(*CreateCommentRequest_Comment)(nil), // 20: article.CreateCommentRequest.Comment
-	(*Profile)(nil),                      // 21: user.Profile
-	(*Empty)(nil),                        // 22: empty.Empty
+	(*Article)(nil),                       // 0: article.Article
+	(*Comment)(nil),                       // 1: article.Comment
+	(*ArticleRevision)(nil),               // 2: article.ArticleRevision
+	(*DailyArticleStats)(nil),             // 3: article.DailyArticleStats
+	(*ArticleStats)(nil),                  // 4: article.ArticleStats
+	(*ArticleSearchHit)(nil),              // 5: article.ArticleSearchHit
+	(*Report)(nil),                        // 6: article.Report
+	(*CreateAritcleRequest)(nil),          // 7: article.CreateAritcleRequest
+	(*GetArticleRequest)(nil),             // 8: article.GetArticleRequest
+	(*GetArticleStatsRequest)(nil),        // 9: article.GetArticleStatsRequest
+	(*GetRelatedArticlesRequest)(nil),     // 10: article.GetRelatedArticlesRequest
+	(*GetArticlesRequest)(nil),            // 11: article.GetArticlesRequest
+	(*SearchArticlesRequest)(nil),         // 12: article.SearchArticlesRequest
+	(*GetFeedArticlesRequest)(nil),        // 13: article.GetFeedArticlesRequest
+	(*UpdateArticleRequest)(nil),          // 14: article.UpdateArticleRequest
+	(*DeleteArticleRequest)(nil),          // 15: article.DeleteArticleRequest
+	(*PublishArticleRequest)(nil),         // 16: article.PublishArticleRequest
+	(*UnpublishArticleRequest)(nil),       // 17: article.UnpublishArticleRequest
+	(*ScheduleArticleRequest)(nil),        // 18: article.ScheduleArticleRequest
+	(*UnscheduleArticleRequest)(nil),      // 19: article.UnscheduleArticleRequest
+	(*ListMyDraftsRequest)(nil),           // 20: article.ListMyDraftsRequest
+	(*ListArticleRevisionsRequest)(nil),   // 21: article.ListArticleRevisionsRequest
+	(*GetArticleRevisionRequest)(nil),     // 22: article.GetArticleRevisionRequest
+	(*DiffArticleRevisionsRequest)(nil),   // 23: article.DiffArticleRevisionsRequest
+	(*RestoreArticleRevisionRequest)(nil), // 24: article.RestoreArticleRevisionRequest
+	(*FavoriteArticleRequest)(nil),        // 25: article.FavoriteArticleRequest
+	(*UnfavoriteArticleRequest)(nil),      // 26: article.UnfavoriteArticleRequest
+	(*CreateCommentRequest)(nil),          // 27: article.CreateCommentRequest
+	(*GetCommentsRequest)(nil),            // 28: article.GetCommentsRequest
+	(*UpdateCommentRequest)(nil),          // 29: article.UpdateCommentRequest
+	(*DeleteCommentRequest)(nil),          // 30: article.DeleteCommentRequest
+	(*ReportArticleRequest)(nil),          // 31: article.ReportArticleRequest
+	(*ReportCommentRequest)(nil),          // 32: article.ReportCommentRequest
+	(*ArticleResponse)(nil),               // 33: article.ArticleResponse
+	(*ArticleStatsResponse)(nil),          // 34: article.ArticleStatsResponse
+	(*ArticlesResponse)(nil),              // 35: article.ArticlesResponse
+	(*ArticleRevisionResponse)(nil),       // 36: article.ArticleRevisionResponse
+	(*ArticleRevisionsResponse)(nil),      // 37: article.ArticleRevisionsResponse
+	(*ArticleDiffResponse)(nil),           // 38: article.ArticleDiffResponse
+	(*SearchArticlesResponse)(nil),        // 39: article.SearchArticlesResponse
+	(*GetTrendingTagsRequest)(nil),        // 40: article.GetTrendingTagsRequest
+	(*TagsResponse)(nil),                  // 41: article.TagsResponse
+	(*CommentResponse)(nil),               // 42: article.CommentResponse
+	(*CommentsResponse)(nil),              // 43: article.CommentsResponse
+	(*ReportResponse)(nil),                // 44: article.ReportResponse
+	(*CreateAritcleRequest_Article)(nil),  // 45: article.CreateAritcleRequest.Article
+	(*UpdateArticleRequest_Article)(nil),  // 46: article.UpdateArticleRequest.Article
+	(*CreateCommentRequest_Comment)(nil),  // 47: article.CreateCommentRequest.Comment
+	(*UpdateCommentRequest_Comment)(nil),  // 48: article.UpdateCommentRequest.Comment
+	(*Profile)(nil),                       // 49: user.Profile
+	(*Empty)(nil),                         // 50: empty.Empty
 }
 var file_article_proto_depIdxs = []int32{
-	21, // 0: article.Article.author:type_name -> user.Profile
-	21, // 1: article.Comment.author:type_name -> user.Profile
-	18, // 2: article.CreateAritcleRequest.article:type_name -> article.CreateAritcleRequest.Article
-	19, // 3: article.UpdateArticleRequest.article:type_name -> article.UpdateArticleRequest.Article
-	20, // 4: article.CreateCommentRequest.comment:type_name -> article.CreateCommentRequest.Comment
-	0,  // 5: article.ArticleResponse.article:type_name -> article.Article
-	0,  // 6: article.ArticlesResponse.articles:type_name -> article.Article
-	1,  // 7: article.CommentResponse.comment:type_name -> article.Comment
-	1,  // 8: article.CommentsResponse.comments:type_name -> article.Comment
-	2,  // 9: article.Articles.CreateArticle:input_type -> article.CreateAritcleRequest
-	5,  // 10: article.Articles.GetFeedArticles:input_type -> article.GetFeedArticlesRequest
-	3,  // 11: article.Articles.GetArticle:input_type -> article.GetArticleRequest
-	4,  // 12: article.Articles.GetArticles:input_type -> article.GetArticlesRequest
-	6,  // 13: article.Articles.UpdateArticle:input_type -> article.UpdateArticleRequest
-	7,  // 14: article.Articles.DeleteArticle:input_type -> article.DeleteArticleRequest
-	8,  // 15: article.Articles.FavoriteArticle:input_type -> article.FavoriteArticleRequest
-	9,  // 16: article.Articles.UnfavoriteArticle:input_type -> article.UnfavoriteArticleRequest
-	22, // 17: article.Articles.GetTags:input_type -> empty.Empty
-	10, // 18: article.Articles.CreateComment:input_type -> article.CreateCommentRequest
-	11, // 19: article.Articles.GetComments:input_type -> article.GetCommentsRequest
-	12, // 20: article.Articles.DeleteComment:input_type -> article.DeleteCommentRequest
-	13, // 21: article.Articles.CreateArticle:output_type -> article.ArticleResponse
-	14, // 22: article.Articles.GetFeedArticles:output_type -> article.ArticlesResponse
-	13, // 23: article.Articles.GetArticle:output_type -> article.ArticleResponse
-	14, // 24: article.Articles.GetArticles:output_type -> article.ArticlesResponse
-	13, // 25: article.Articles.UpdateArticle:output_type -> article.ArticleResponse
-	22, // 26: article.Articles.DeleteArticle:output_type -> empty.Empty
-	13, // 27: article.Articles.FavoriteArticle:output_type -> article.ArticleResponse
-	13, // 28: article.Articles.UnfavoriteArticle:output_type -> article.ArticleResponse
-	15, // 29: article.Articles.GetTags:output_type -> article.TagsResponse
-	16, // 30: article.Articles.CreateComment:output_type -> article.CommentResponse
-	17, // 31: article.Articles.GetComments:output_type -> article.CommentsResponse
-	22, // 32: article.Articles.DeleteComment:output_type -> empty.Empty
-	21, // [21:33] is the sub-list for method output_type
-	9,  // [9:21] is the sub-list for method input_type
-	9,  // [9:9] is the sub-list for extension type_name
-	9,  // [9:9] is the sub-list for extension extendee
-	0,  // [0:9] is the sub-list for field type_name
+	49, // 0: article.Article.author:type_name -> user.Profile
+	49, // 1: article.Comment.author:type_name -> user.Profile
+	49, // 2: article.ArticleRevision.editor:type_name -> user.Profile
+	3,  // 3: article.ArticleStats.daily:type_name -> article.DailyArticleStats
+	0,  // 4: article.ArticleSearchHit.article:type_name -> article.Article
+	49, // 5: article.Report.reporter:type_name -> user.Profile
+	45, // 6: article.CreateAritcleRequest.article:type_name -> article.CreateAritcleRequest.Article
+	46, // 7: article.UpdateArticleRequest.article:type_name -> article.UpdateArticleRequest.Article
+	47, // 8: article.CreateCommentRequest.comment:type_name -> article.CreateCommentRequest.Comment
+	48, // 9: article.UpdateCommentRequest.comment:type_name -> article.UpdateCommentRequest.Comment
+	0,  // 10: article.ArticleResponse.article:type_name -> article.Article
+	4,  // 11: article.ArticleStatsResponse.stats:type_name -> article.ArticleStats
+	0,  // 12: article.ArticlesResponse.articles:type_name -> article.Article
+	2,  // 13: article.ArticleRevisionResponse.revision:type_name -> article.ArticleRevision
+	2,  // 14: article.ArticleRevisionsResponse.revisions:type_name -> article.ArticleRevision
+	5,  // 15: article.SearchArticlesResponse.hits:type_name -> article.ArticleSearchHit
+	1,  // 16: article.CommentResponse.comment:type_name -> article.Comment
+	1,  // 17: article.CommentsResponse.comments:type_name -> article.Comment
+	6,  // 18: article.ReportResponse.report:type_name -> article.Report
+	7,  // 19: article.Articles.CreateArticle:input_type -> article.CreateAritcleRequest
+	13, // 20: article.Articles.GetFeedArticles:input_type -> article.GetFeedArticlesRequest
+	12, // 21: article.Articles.SearchArticles:input_type -> article.SearchArticlesRequest
+	8,  // 22: article.Articles.GetArticle:input_type -> article.GetArticleRequest
+	11, // 23: article.Articles.GetArticles:input_type -> article.GetArticlesRequest
+	14, // 24: article.Articles.UpdateArticle:input_type -> article.UpdateArticleRequest
+	15, // 25: article.Articles.DeleteArticle:input_type -> article.DeleteArticleRequest
+	16, // 26: article.Articles.PublishArticle:input_type -> article.PublishArticleRequest
+	17, // 27: article.Articles.UnpublishArticle:input_type -> article.UnpublishArticleRequest
+	18, // 28: article.Articles.ScheduleArticle:input_type -> article.ScheduleArticleRequest
+	19, // 29: article.Articles.UnscheduleArticle:input_type -> article.UnscheduleArticleRequest
+	20, // 30: article.Articles.ListMyDrafts:input_type -> article.ListMyDraftsRequest
+	21, // 31: article.Articles.ListArticleRevisions:input_type -> article.ListArticleRevisionsRequest
+	22, // 32: article.Articles.GetArticleRevision:input_type -> article.GetArticleRevisionRequest
+	23, // 33: article.Articles.DiffArticleRevisions:input_type -> article.DiffArticleRevisionsRequest
+	24, // 34: article.Articles.RestoreArticleRevision:input_type -> article.RestoreArticleRevisionRequest
+	9,  // 35: article.Articles.GetArticleStats:input_type -> article.GetArticleStatsRequest
+	10, // 36: article.Articles.GetRelatedArticles:input_type -> article.GetRelatedArticlesRequest
+	25, // 37: article.Articles.FavoriteArticle:input_type -> article.FavoriteArticleRequest
+	26, // 38: article.Articles.UnfavoriteArticle:input_type -> article.UnfavoriteArticleRequest
+	50, // 39: article.Articles.GetTags:input_type -> empty.Empty
+	40, // 40: article.Articles.GetTrendingTags:input_type -> article.GetTrendingTagsRequest
+	27, // 41: article.Articles.CreateComment:input_type -> article.CreateCommentRequest
+	28, // 42: article.Articles.GetComments:input_type -> article.GetCommentsRequest
+	29, // 43: article.Articles.UpdateComment:input_type -> article.UpdateCommentRequest
+	30, // 44: article.Articles.DeleteComment:input_type -> article.DeleteCommentRequest
+	31, // 45: article.Articles.ReportArticle:input_type -> article.ReportArticleRequest
+	32, // 46: article.Articles.ReportComment:input_type -> article.ReportCommentRequest
+	33, // 47: article.Articles.CreateArticle:output_type -> article.ArticleResponse
+	35, // 48: article.Articles.GetFeedArticles:output_type -> article.ArticlesResponse
+	39, // 49: article.Articles.SearchArticles:output_type -> article.SearchArticlesResponse
+	33, // 50: article.Articles.GetArticle:output_type -> article.ArticleResponse
+	35, // 51: article.Articles.GetArticles:output_type -> article.ArticlesResponse
+	33, // 52: article.Articles.UpdateArticle:output_type -> article.ArticleResponse
+	50, // 53: article.Articles.DeleteArticle:output_type -> empty.Empty
+	33, // 54: article.Articles.PublishArticle:output_type -> article.ArticleResponse
+	33, // 55: article.Articles.UnpublishArticle:output_type -> article.ArticleResponse
+	33, // 56: article.Articles.ScheduleArticle:output_type -> article.ArticleResponse
+	33, // 57: article.Articles.UnscheduleArticle:output_type -> article.ArticleResponse
+	35, // 58: article.Articles.ListMyDrafts:output_type -> article.ArticlesResponse
+	37, // 59: article.Articles.ListArticleRevisions:output_type -> article.ArticleRevisionsResponse
+	36, // 60: article.Articles.GetArticleRevision:output_type -> article.ArticleRevisionResponse
+	38, // 61: article.Articles.DiffArticleRevisions:output_type -> article.ArticleDiffResponse
+	33, // 62: article.Articles.RestoreArticleRevision:output_type -> article.ArticleResponse
+	34, // 63: article.Articles.GetArticleStats:output_type -> article.ArticleStatsResponse
+	35, // 64: article.Articles.GetRelatedArticles:output_type -> article.ArticlesResponse
+	33, // 65: article.Articles.FavoriteArticle:output_type -> article.ArticleResponse
+	33, // 66: article.Articles.UnfavoriteArticle:output_type -> article.ArticleResponse
+	41, // 67: article.Articles.GetTags:output_type -> article.TagsResponse
+	41, // 68: article.Articles.GetTrendingTags:output_type -> article.TagsResponse
+	42, // 69: article.Articles.CreateComment:output_type -> article.CommentResponse
+	43, // 70: article.Articles.GetComments:output_type -> article.CommentsResponse
+	42, // 71: article.Articles.UpdateComment:output_type -> article.CommentResponse
+	50, // 72: article.Articles.DeleteComment:output_type -> empty.Empty
+	44, // 73: article.Articles.ReportArticle:output_type -> article.ReportResponse
+	44, // 74: article.Articles.ReportComment:output_type -> article.ReportResponse
+	47, // [47:75] is the sub-list for method output_type
+	19, // [19:47] is the sub-list for method input_type
+	19, // [19:19] is the sub-list for extension type_name
+	19, // [19:19] is the sub-list for extension extendee
+	0,  // [0:19] is the sub-list for field type_name
 }
 
 func init() { file_article_proto_init() }
@@ -1538,8 +3765,200 @@ func file_article_proto_init() {
 				return nil
 			}
 		}
-		file_article_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Comment); i {
+		file_article_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Comment); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_article_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ArticleRevision); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_article_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DailyArticleStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_article_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ArticleStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_article_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ArticleSearchHit); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_article_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Report); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_article_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateAritcleRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_article_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetArticleRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_article_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetArticleStatsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_article_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetRelatedArticlesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_article_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetArticlesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_article_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SearchArticlesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_article_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetFeedArticlesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_article_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UpdateArticleRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_article_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeleteArticleRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_article_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PublishArticleRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_article_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UnpublishArticleRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1550,8 +3969,8 @@ func file_article_proto_init() {
 				return nil
 			}
 		}
-		file_article_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CreateAritcleRequest); i {
+		file_article_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ScheduleArticleRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1562,8 +3981,8 @@ func file_article_proto_init() {
 				return nil
 			}
 		}
-		file_article_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetArticleRequest); i {
+		file_article_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UnscheduleArticleRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1574,8 +3993,8 @@ func file_article_proto_init() {
 				return nil
 			}
 		}
-		file_article_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetArticlesRequest); i {
+		file_article_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListMyDraftsRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1586,8 +4005,8 @@ func file_article_proto_init() {
 				return nil
 			}
 		}
-		file_article_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetFeedArticlesRequest); i {
+		file_article_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListArticleRevisionsRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1598,8 +4017,8 @@ func file_article_proto_init() {
 				return nil
 			}
 		}
-		file_article_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*UpdateArticleRequest); i {
+		file_article_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetArticleRevisionRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1610,8 +4029,8 @@ func file_article_proto_init() {
 				return nil
 			}
 		}
-		file_article_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*DeleteArticleRequest); i {
+		file_article_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DiffArticleRevisionsRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1622,7 +4041,19 @@ func file_article_proto_init() {
 				return nil
 			}
 		}
-		file_article_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+		file_article_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RestoreArticleRevisionRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_article_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*FavoriteArticleRequest); i {
 			case 0:
 				return &v.state
@@ -1634,7 +4065,7 @@ func file_article_proto_init() {
 				return nil
 			}
 		}
-		file_article_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+		file_article_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*UnfavoriteArticleRequest); i {
 			case 0:
 				return &v.state
@@ -1646,7 +4077,7 @@ func file_article_proto_init() {
 				return nil
 			}
 		}
-		file_article_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+		file_article_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*CreateCommentRequest); i {
 			case 0:
 				return &v.state
@@ -1658,7 +4089,7 @@ func file_article_proto_init() {
 				return nil
 			}
 		}
-		file_article_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+		file_article_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*GetCommentsRequest); i {
 			case 0:
 				return &v.state
@@ -1670,7 +4101,19 @@ func file_article_proto_init() {
 				return nil
 			}
 		}
-		file_article_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+		file_article_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UpdateCommentRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_article_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*DeleteCommentRequest); i {
 			case 0:
 				return &v.state
@@ -1682,7 +4125,31 @@ func file_article_proto_init() {
 				return nil
 			}
 		}
-		file_article_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+		file_article_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReportArticleRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_article_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReportCommentRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_article_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ArticleResponse); i {
 			case 0:
 				return &v.state
@@ -1694,7 +4161,19 @@ func file_article_proto_init() {
 				return nil
 			}
 		}
-		file_article_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+		file_article_proto_msgTypes[34].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ArticleStatsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_article_proto_msgTypes[35].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ArticlesResponse); i {
 			case 0:
 				return &v.state
@@ -1706,7 +4185,67 @@ func file_article_proto_init() {
 				return nil
 			}
 		}
-		file_article_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+		file_article_proto_msgTypes[36].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ArticleRevisionResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_article_proto_msgTypes[37].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ArticleRevisionsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_article_proto_msgTypes[38].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ArticleDiffResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_article_proto_msgTypes[39].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SearchArticlesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_article_proto_msgTypes[40].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetTrendingTagsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_article_proto_msgTypes[41].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*TagsResponse); i {
 			case 0:
 				return &v.state
@@ -1718,7 +4257,7 @@ func file_article_proto_init() {
 				return nil
 			}
 		}
-		file_article_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+		file_article_proto_msgTypes[42].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*CommentResponse); i {
 			case 0:
 				return &v.state
@@ -1730,7 +4269,7 @@ func file_article_proto_init() {
 				return nil
 			}
 		}
-		file_article_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+		file_article_proto_msgTypes[43].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*CommentsResponse); i {
 			case 0:
 				return &v.state
@@ -1742,7 +4281,19 @@ func file_article_proto_init() {
 				return nil
 			}
 		}
-		file_article_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+		file_article_proto_msgTypes[44].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReportResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_article_proto_msgTypes[45].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*CreateAritcleRequest_Article); i {
 			case 0:
 				return &v.state
@@ -1754,7 +4305,7 @@ func file_article_proto_init() {
 				return nil
 			}
 		}
-		file_article_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+		file_article_proto_msgTypes[46].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*UpdateArticleRequest_Article); i {
 			case 0:
 				return &v.state
@@ -1766,7 +4317,7 @@ func file_article_proto_init() {
 				return nil
 			}
 		}
-		file_article_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+		file_article_proto_msgTypes[47].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*CreateCommentRequest_Comment); i {
 			case 0:
 				return &v.state
@@ -1778,6 +4329,18 @@ func file_article_proto_init() {
 				return nil
 			}
 		}
+		file_article_proto_msgTypes[48].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UpdateCommentRequest_Comment); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -1785,7 +4348,7 @@ func file_article_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_article_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   21,
+			NumMessages:   49,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -1813,16 +4376,32 @@ const _ = grpc.SupportPackageIsVersion6
 type ArticlesClient interface {
 	CreateArticle(ctx context.Context, in *CreateAritcleRequest, opts ...grpc.CallOption) (*ArticleResponse, error)
 	GetFeedArticles(ctx context.Context, in *GetFeedArticlesRequest, opts ...grpc.CallOption) (*ArticlesResponse, error)
+	SearchArticles(ctx context.Context, in *SearchArticlesRequest, opts ...grpc.CallOption) (*SearchArticlesResponse, error)
 	GetArticle(ctx context.Context, in *GetArticleRequest, opts ...grpc.CallOption) (*ArticleResponse, error)
 	GetArticles(ctx context.Context, in *GetArticlesRequest, opts ...grpc.CallOption) (*ArticlesResponse, error)
 	UpdateArticle(ctx context.Context, in *UpdateArticleRequest, opts ...grpc.CallOption) (*ArticleResponse, error)
 	DeleteArticle(ctx context.Context, in *DeleteArticleRequest, opts ...grpc.CallOption) (*Empty, error)
+	PublishArticle(ctx context.Context, in *PublishArticleRequest, opts ...grpc.CallOption) (*ArticleResponse, error)
+	UnpublishArticle(ctx context.Context, in *UnpublishArticleRequest, opts ...grpc.CallOption) (*ArticleResponse, error)
+	ScheduleArticle(ctx context.Context, in *ScheduleArticleRequest, opts ...grpc.CallOption) (*ArticleResponse, error)
+	UnscheduleArticle(ctx context.Context, in *UnscheduleArticleRequest, opts ...grpc.CallOption) (*ArticleResponse, error)
+	ListMyDrafts(ctx context.Context, in *ListMyDraftsRequest, opts ...grpc.CallOption) (*ArticlesResponse, error)
+	ListArticleRevisions(ctx context.Context, in *ListArticleRevisionsRequest, opts ...grpc.CallOption) (*ArticleRevisionsResponse, error)
+	GetArticleRevision(ctx context.Context, in *GetArticleRevisionRequest, opts ...grpc.CallOption) (*ArticleRevisionResponse, error)
+	DiffArticleRevisions(ctx context.Context, in *DiffArticleRevisionsRequest, opts ...grpc.CallOption) (*ArticleDiffResponse, error)
+	RestoreArticleRevision(ctx context.Context, in *RestoreArticleRevisionRequest, opts ...grpc.CallOption) (*ArticleResponse, error)
+	GetArticleStats(ctx context.Context, in *GetArticleStatsRequest, opts ...grpc.CallOption) (*ArticleStatsResponse, error)
+	GetRelatedArticles(ctx context.Context, in *GetRelatedArticlesRequest, opts ...grpc.CallOption) (*ArticlesResponse, error)
 	FavoriteArticle(ctx context.Context, in *FavoriteArticleRequest, opts ...grpc.CallOption) (*ArticleResponse, error)
 	UnfavoriteArticle(ctx context.Context, in *UnfavoriteArticleRequest, opts ...grpc.CallOption) (*ArticleResponse, error)
 	GetTags(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*TagsResponse, error)
+	GetTrendingTags(ctx context.Context, in *GetTrendingTagsRequest, opts ...grpc.CallOption) (*TagsResponse, error)
 	CreateComment(ctx context.Context, in *CreateCommentRequest, opts ...grpc.CallOption) (*CommentResponse, error)
 	GetComments(ctx context.Context, in *GetCommentsRequest, opts ...grpc.CallOption) (*CommentsResponse, error)
+	UpdateComment(ctx context.Context, in *UpdateCommentRequest, opts ...grpc.CallOption) (*CommentResponse, error)
 	DeleteComment(ctx context.Context, in *DeleteCommentRequest, opts ...grpc.CallOption) (*Empty, error)
+	ReportArticle(ctx context.Context, in *ReportArticleRequest, opts ...grpc.CallOption) (*ReportResponse, error)
+	ReportComment(ctx context.Context, in *ReportCommentRequest, opts ...grpc.CallOption) (*ReportResponse, error)
 }
 
 type articlesClient struct {
@@ -1851,6 +4430,15 @@ func (c *articlesClient) GetFeedArticles(ctx context.Context, in *GetFeedArticle
 	return out, nil
 }
 
+func (c *articlesClient) SearchArticles(ctx context.Context, in *SearchArticlesRequest, opts ...grpc.CallOption) (*SearchArticlesResponse, error) {
+	out := new(SearchArticlesResponse)
+	err := c.cc.Invoke(ctx, "/article.Articles/SearchArticles", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *articlesClient) GetArticle(ctx context.Context, in *GetArticleRequest, opts ...grpc.CallOption) (*ArticleResponse, error) {
 	out := new(ArticleResponse)
 	err := c.cc.Invoke(ctx, "/article.Articles/GetArticle", in, out, opts...)
@@ -1887,6 +4475,105 @@ func (c *articlesClient) DeleteArticle(ctx context.Context, in *DeleteArticleReq
 	return out, nil
 }
 
+func (c *articlesClient) PublishArticle(ctx context.Context, in *PublishArticleRequest, opts ...grpc.CallOption) (*ArticleResponse, error) {
+	out := new(ArticleResponse)
+	err := c.cc.Invoke(ctx, "/article.Articles/PublishArticle", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articlesClient) UnpublishArticle(ctx context.Context, in *UnpublishArticleRequest, opts ...grpc.CallOption) (*ArticleResponse, error) {
+	out := new(ArticleResponse)
+	err := c.cc.Invoke(ctx, "/article.Articles/UnpublishArticle", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articlesClient) ScheduleArticle(ctx context.Context, in *ScheduleArticleRequest, opts ...grpc.CallOption) (*ArticleResponse, error) {
+	out := new(ArticleResponse)
+	err := c.cc.Invoke(ctx, "/article.Articles/ScheduleArticle", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articlesClient) UnscheduleArticle(ctx context.Context, in *UnscheduleArticleRequest, opts ...grpc.CallOption) (*ArticleResponse, error) {
+	out := new(ArticleResponse)
+	err := c.cc.Invoke(ctx, "/article.Articles/UnscheduleArticle", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articlesClient) ListMyDrafts(ctx context.Context, in *ListMyDraftsRequest, opts ...grpc.CallOption) (*ArticlesResponse, error) {
+	out := new(ArticlesResponse)
+	err := c.cc.Invoke(ctx, "/article.Articles/ListMyDrafts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articlesClient) ListArticleRevisions(ctx context.Context, in *ListArticleRevisionsRequest, opts ...grpc.CallOption) (*ArticleRevisionsResponse, error) {
+	out := new(ArticleRevisionsResponse)
+	err := c.cc.Invoke(ctx, "/article.Articles/ListArticleRevisions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articlesClient) GetArticleRevision(ctx context.Context, in *GetArticleRevisionRequest, opts ...grpc.CallOption) (*ArticleRevisionResponse, error) {
+	out := new(ArticleRevisionResponse)
+	err := c.cc.Invoke(ctx, "/article.Articles/GetArticleRevision", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articlesClient) DiffArticleRevisions(ctx context.Context, in *DiffArticleRevisionsRequest, opts ...grpc.CallOption) (*ArticleDiffResponse, error) {
+	out := new(ArticleDiffResponse)
+	err := c.cc.Invoke(ctx, "/article.Articles/DiffArticleRevisions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articlesClient) RestoreArticleRevision(ctx context.Context, in *RestoreArticleRevisionRequest, opts ...grpc.CallOption) (*ArticleResponse, error) {
+	out := new(ArticleResponse)
+	err := c.cc.Invoke(ctx, "/article.Articles/RestoreArticleRevision", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articlesClient) GetArticleStats(ctx context.Context, in *GetArticleStatsRequest, opts ...grpc.CallOption) (*ArticleStatsResponse, error) {
+	out := new(ArticleStatsResponse)
+	err := c.cc.Invoke(ctx, "/article.Articles/GetArticleStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articlesClient) GetRelatedArticles(ctx context.Context, in *GetRelatedArticlesRequest, opts ...grpc.CallOption) (*ArticlesResponse, error) {
+	out := new(ArticlesResponse)
+	err := c.cc.Invoke(ctx, "/article.Articles/GetRelatedArticles", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *articlesClient) FavoriteArticle(ctx context.Context, in *FavoriteArticleRequest, opts ...grpc.CallOption) (*ArticleResponse, error) {
 	out := new(ArticleResponse)
 	err := c.cc.Invoke(ctx, "/article.Articles/FavoriteArticle", in, out, opts...)
@@ -1914,6 +4601,15 @@ func (c *articlesClient) GetTags(ctx context.Context, in *Empty, opts ...grpc.Ca
 	return out, nil
 }
 
+func (c *articlesClient) GetTrendingTags(ctx context.Context, in *GetTrendingTagsRequest, opts ...grpc.CallOption) (*TagsResponse, error) {
+	out := new(TagsResponse)
+	err := c.cc.Invoke(ctx, "/article.Articles/GetTrendingTags", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *articlesClient) CreateComment(ctx context.Context, in *CreateCommentRequest, opts ...grpc.CallOption) (*CommentResponse, error) {
 	out := new(CommentResponse)
 	err := c.cc.Invoke(ctx, "/article.Articles/CreateComment", in, out, opts...)
@@ -1932,6 +4628,15 @@ func (c *articlesClient) GetComments(ctx context.Context, in *GetCommentsRequest
 	return out, nil
 }
 
+func (c *articlesClient) UpdateComment(ctx context.Context, in *UpdateCommentRequest, opts ...grpc.CallOption) (*CommentResponse, error) {
+	out := new(CommentResponse)
+	err := c.cc.Invoke(ctx, "/article.Articles/UpdateComment", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *articlesClient) DeleteComment(ctx context.Context, in *DeleteCommentRequest, opts ...grpc.CallOption) (*Empty, error) {
 	out := new(Empty)
 	err := c.cc.Invoke(ctx, "/article.Articles/DeleteComment", in, out, opts...)
@@ -1941,20 +4646,54 @@ func (c *articlesClient) DeleteComment(ctx context.Context, in *DeleteCommentReq
 	return out, nil
 }
 
+func (c *articlesClient) ReportArticle(ctx context.Context, in *ReportArticleRequest, opts ...grpc.CallOption) (*ReportResponse, error) {
+	out := new(ReportResponse)
+	err := c.cc.Invoke(ctx, "/article.Articles/ReportArticle", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articlesClient) ReportComment(ctx context.Context, in *ReportCommentRequest, opts ...grpc.CallOption) (*ReportResponse, error) {
+	out := new(ReportResponse)
+	err := c.cc.Invoke(ctx, "/article.Articles/ReportComment", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ArticlesServer is the server API for Articles service.
 type ArticlesServer interface {
 	CreateArticle(context.Context, *CreateAritcleRequest) (*ArticleResponse, error)
 	GetFeedArticles(context.Context, *GetFeedArticlesRequest) (*ArticlesResponse, error)
+	SearchArticles(context.Context, *SearchArticlesRequest) (*SearchArticlesResponse, error)
 	GetArticle(context.Context, *GetArticleRequest) (*ArticleResponse, error)
 	GetArticles(context.Context, *GetArticlesRequest) (*ArticlesResponse, error)
 	UpdateArticle(context.Context, *UpdateArticleRequest) (*ArticleResponse, error)
 	DeleteArticle(context.Context, *DeleteArticleRequest) (*Empty, error)
+	PublishArticle(context.Context, *PublishArticleRequest) (*ArticleResponse, error)
+	UnpublishArticle(context.Context, *UnpublishArticleRequest) (*ArticleResponse, error)
+	ScheduleArticle(context.Context, *ScheduleArticleRequest) (*ArticleResponse, error)
+	UnscheduleArticle(context.Context, *UnscheduleArticleRequest) (*ArticleResponse, error)
+	ListMyDrafts(context.Context, *ListMyDraftsRequest) (*ArticlesResponse, error)
+	ListArticleRevisions(context.Context, *ListArticleRevisionsRequest) (*ArticleRevisionsResponse, error)
+	GetArticleRevision(context.Context, *GetArticleRevisionRequest) (*ArticleRevisionResponse, error)
+	DiffArticleRevisions(context.Context, *DiffArticleRevisionsRequest) (*ArticleDiffResponse, error)
+	RestoreArticleRevision(context.Context, *RestoreArticleRevisionRequest) (*ArticleResponse, error)
+	GetArticleStats(context.Context, *GetArticleStatsRequest) (*ArticleStatsResponse, error)
+	GetRelatedArticles(context.Context, *GetRelatedArticlesRequest) (*ArticlesResponse, error)
 	FavoriteArticle(context.Context, *FavoriteArticleRequest) (*ArticleResponse, error)
 	UnfavoriteArticle(context.Context, *UnfavoriteArticleRequest) (*ArticleResponse, error)
 	GetTags(context.Context, *Empty) (*TagsResponse, error)
+	GetTrendingTags(context.Context, *GetTrendingTagsRequest) (*TagsResponse, error)
 	CreateComment(context.Context, *CreateCommentRequest) (*CommentResponse, error)
 	GetComments(context.Context, *GetCommentsRequest) (*CommentsResponse, error)
+	UpdateComment(context.Context, *UpdateCommentRequest) (*CommentResponse, error)
 	DeleteComment(context.Context, *DeleteCommentRequest) (*Empty, error)
+	ReportArticle(context.Context, *ReportArticleRequest) (*ReportResponse, error)
+	ReportComment(context.Context, *ReportCommentRequest) (*ReportResponse, error)
 }
 
 // UnimplementedArticlesServer can be embedded to have forward compatible implementations.
@@ -1967,6 +4706,9 @@ func (*UnimplementedArticlesServer) CreateArticle(context.Context, *CreateAritcl
 func (*UnimplementedArticlesServer) GetFeedArticles(context.Context, *GetFeedArticlesRequest) (*ArticlesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetFeedArticles not implemented")
 }
+func (*UnimplementedArticlesServer) SearchArticles(context.Context, *SearchArticlesRequest) (*SearchArticlesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchArticles not implemented")
+}
 func (*UnimplementedArticlesServer) GetArticle(context.Context, *GetArticleRequest) (*ArticleResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetArticle not implemented")
 }
@@ -1979,6 +4721,39 @@ func (*UnimplementedArticlesServer) UpdateArticle(context.Context, *UpdateArticl
 func (*UnimplementedArticlesServer) DeleteArticle(context.Context, *DeleteArticleRequest) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteArticle not implemented")
 }
+func (*UnimplementedArticlesServer) PublishArticle(context.Context, *PublishArticleRequest) (*ArticleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PublishArticle not implemented")
+}
+func (*UnimplementedArticlesServer) UnpublishArticle(context.Context, *UnpublishArticleRequest) (*ArticleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnpublishArticle not implemented")
+}
+func (*UnimplementedArticlesServer) ScheduleArticle(context.Context, *ScheduleArticleRequest) (*ArticleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ScheduleArticle not implemented")
+}
+func (*UnimplementedArticlesServer) UnscheduleArticle(context.Context, *UnscheduleArticleRequest) (*ArticleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnscheduleArticle not implemented")
+}
+func (*UnimplementedArticlesServer) ListMyDrafts(context.Context, *ListMyDraftsRequest) (*ArticlesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMyDrafts not implemented")
+}
+func (*UnimplementedArticlesServer) ListArticleRevisions(context.Context, *ListArticleRevisionsRequest) (*ArticleRevisionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListArticleRevisions not implemented")
+}
+func (*UnimplementedArticlesServer) GetArticleRevision(context.Context, *GetArticleRevisionRequest) (*ArticleRevisionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetArticleRevision not implemented")
+}
+func (*UnimplementedArticlesServer) DiffArticleRevisions(context.Context, *DiffArticleRevisionsRequest) (*ArticleDiffResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DiffArticleRevisions not implemented")
+}
+func (*UnimplementedArticlesServer) RestoreArticleRevision(context.Context, *RestoreArticleRevisionRequest) (*ArticleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreArticleRevision not implemented")
+}
+func (*UnimplementedArticlesServer) GetArticleStats(context.Context, *GetArticleStatsRequest) (*ArticleStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetArticleStats not implemented")
+}
+func (*UnimplementedArticlesServer) GetRelatedArticles(context.Context, *GetRelatedArticlesRequest) (*ArticlesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRelatedArticles not implemented")
+}
 func (*UnimplementedArticlesServer) FavoriteArticle(context.Context, *FavoriteArticleRequest) (*ArticleResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method FavoriteArticle not implemented")
 }
@@ -1988,15 +4763,27 @@ func (*UnimplementedArticlesServer) UnfavoriteArticle(context.Context, *Unfavori
 func (*UnimplementedArticlesServer) GetTags(context.Context, *Empty) (*TagsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetTags not implemented")
 }
+func (*UnimplementedArticlesServer) GetTrendingTags(context.Context, *GetTrendingTagsRequest) (*TagsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTrendingTags not implemented")
+}
 func (*UnimplementedArticlesServer) CreateComment(context.Context, *CreateCommentRequest) (*CommentResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateComment not implemented")
 }
 func (*UnimplementedArticlesServer) GetComments(context.Context, *GetCommentsRequest) (*CommentsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetComments not implemented")
 }
+func (*UnimplementedArticlesServer) UpdateComment(context.Context, *UpdateCommentRequest) (*CommentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateComment not implemented")
+}
 func (*UnimplementedArticlesServer) DeleteComment(context.Context, *DeleteCommentRequest) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteComment not implemented")
 }
+func (*UnimplementedArticlesServer) ReportArticle(context.Context, *ReportArticleRequest) (*ReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportArticle not implemented")
+}
+func (*UnimplementedArticlesServer) ReportComment(context.Context, *ReportCommentRequest) (*ReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportComment not implemented")
+}
 
 func RegisterArticlesServer(s *grpc.Server, srv ArticlesServer) {
 	s.RegisterService(&_Articles_serviceDesc, srv)
@@ -2038,6 +4825,24 @@ func _Articles_GetFeedArticles_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Articles_SearchArticles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchArticlesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticlesServer).SearchArticles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/article.Articles/SearchArticles",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticlesServer).SearchArticles(ctx, req.(*SearchArticlesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Articles_GetArticle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetArticleRequest)
 	if err := dec(in); err != nil {
@@ -2110,6 +4915,204 @@ func _Articles_DeleteArticle_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Articles_PublishArticle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PublishArticleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticlesServer).PublishArticle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/article.Articles/PublishArticle",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticlesServer).PublishArticle(ctx, req.(*PublishArticleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Articles_UnpublishArticle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnpublishArticleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticlesServer).UnpublishArticle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/article.Articles/UnpublishArticle",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticlesServer).UnpublishArticle(ctx, req.(*UnpublishArticleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Articles_ScheduleArticle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScheduleArticleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticlesServer).ScheduleArticle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/article.Articles/ScheduleArticle",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticlesServer).ScheduleArticle(ctx, req.(*ScheduleArticleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Articles_UnscheduleArticle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnscheduleArticleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticlesServer).UnscheduleArticle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/article.Articles/UnscheduleArticle",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticlesServer).UnscheduleArticle(ctx, req.(*UnscheduleArticleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Articles_ListMyDrafts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMyDraftsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticlesServer).ListMyDrafts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/article.Articles/ListMyDrafts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticlesServer).ListMyDrafts(ctx, req.(*ListMyDraftsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Articles_ListArticleRevisions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListArticleRevisionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticlesServer).ListArticleRevisions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/article.Articles/ListArticleRevisions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticlesServer).ListArticleRevisions(ctx, req.(*ListArticleRevisionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Articles_GetArticleRevision_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetArticleRevisionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticlesServer).GetArticleRevision(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/article.Articles/GetArticleRevision",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticlesServer).GetArticleRevision(ctx, req.(*GetArticleRevisionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Articles_DiffArticleRevisions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiffArticleRevisionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticlesServer).DiffArticleRevisions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/article.Articles/DiffArticleRevisions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticlesServer).DiffArticleRevisions(ctx, req.(*DiffArticleRevisionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Articles_RestoreArticleRevision_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreArticleRevisionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticlesServer).RestoreArticleRevision(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/article.Articles/RestoreArticleRevision",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticlesServer).RestoreArticleRevision(ctx, req.(*RestoreArticleRevisionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Articles_GetArticleStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetArticleStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticlesServer).GetArticleStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/article.Articles/GetArticleStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticlesServer).GetArticleStats(ctx, req.(*GetArticleStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Articles_GetRelatedArticles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRelatedArticlesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticlesServer).GetRelatedArticles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/article.Articles/GetRelatedArticles",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticlesServer).GetRelatedArticles(ctx, req.(*GetRelatedArticlesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Articles_FavoriteArticle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(FavoriteArticleRequest)
 	if err := dec(in); err != nil {
@@ -2164,6 +5167,24 @@ func _Articles_GetTags_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Articles_GetTrendingTags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTrendingTagsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticlesServer).GetTrendingTags(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/article.Articles/GetTrendingTags",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticlesServer).GetTrendingTags(ctx, req.(*GetTrendingTagsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Articles_CreateComment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CreateCommentRequest)
 	if err := dec(in); err != nil {
@@ -2200,6 +5221,24 @@ func _Articles_GetComments_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Articles_UpdateComment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateCommentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticlesServer).UpdateComment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/article.Articles/UpdateComment",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticlesServer).UpdateComment(ctx, req.(*UpdateCommentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Articles_DeleteComment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DeleteCommentRequest)
 	if err := dec(in); err != nil {
@@ -2218,6 +5257,42 @@ func _Articles_DeleteComment_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Articles_ReportArticle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportArticleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticlesServer).ReportArticle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/article.Articles/ReportArticle",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticlesServer).ReportArticle(ctx, req.(*ReportArticleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Articles_ReportComment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportCommentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticlesServer).ReportComment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/article.Articles/ReportComment",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticlesServer).ReportComment(ctx, req.(*ReportCommentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Articles_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "article.Articles",
 	HandlerType: (*ArticlesServer)(nil),
@@ -2230,6 +5305,10 @@ var _Articles_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetFeedArticles",
 			Handler:    _Articles_GetFeedArticles_Handler,
 		},
+		{
+			MethodName: "SearchArticles",
+			Handler:    _Articles_SearchArticles_Handler,
+		},
 		{
 			MethodName: "GetArticle",
 			Handler:    _Articles_GetArticle_Handler,
@@ -2246,6 +5325,50 @@ var _Articles_serviceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteArticle",
 			Handler:    _Articles_DeleteArticle_Handler,
 		},
+		{
+			MethodName: "PublishArticle",
+			Handler:    _Articles_PublishArticle_Handler,
+		},
+		{
+			MethodName: "UnpublishArticle",
+			Handler:    _Articles_UnpublishArticle_Handler,
+		},
+		{
+			MethodName: "ScheduleArticle",
+			Handler:    _Articles_ScheduleArticle_Handler,
+		},
+		{
+			MethodName: "UnscheduleArticle",
+			Handler:    _Articles_UnscheduleArticle_Handler,
+		},
+		{
+			MethodName: "ListMyDrafts",
+			Handler:    _Articles_ListMyDrafts_Handler,
+		},
+		{
+			MethodName: "ListArticleRevisions",
+			Handler:    _Articles_ListArticleRevisions_Handler,
+		},
+		{
+			MethodName: "GetArticleRevision",
+			Handler:    _Articles_GetArticleRevision_Handler,
+		},
+		{
+			MethodName: "DiffArticleRevisions",
+			Handler:    _Articles_DiffArticleRevisions_Handler,
+		},
+		{
+			MethodName: "RestoreArticleRevision",
+			Handler:    _Articles_RestoreArticleRevision_Handler,
+		},
+		{
+			MethodName: "GetArticleStats",
+			Handler:    _Articles_GetArticleStats_Handler,
+		},
+		{
+			MethodName: "GetRelatedArticles",
+			Handler:    _Articles_GetRelatedArticles_Handler,
+		},
 		{
 			MethodName: "FavoriteArticle",
 			Handler:    _Articles_FavoriteArticle_Handler,
@@ -2258,6 +5381,10 @@ var _Articles_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetTags",
 			Handler:    _Articles_GetTags_Handler,
 		},
+		{
+			MethodName: "GetTrendingTags",
+			Handler:    _Articles_GetTrendingTags_Handler,
+		},
 		{
 			MethodName: "CreateComment",
 			Handler:    _Articles_CreateComment_Handler,
@@ -2266,10 +5393,22 @@ var _Articles_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetComments",
 			Handler:    _Articles_GetComments_Handler,
 		},
+		{
+			MethodName: "UpdateComment",
+			Handler:    _Articles_UpdateComment_Handler,
+		},
 		{
 			MethodName: "DeleteComment",
 			Handler:    _Articles_DeleteComment_Handler,
 		},
+		{
+			MethodName: "ReportArticle",
+			Handler:    _Articles_ReportArticle_Handler,
+		},
+		{
+			MethodName: "ReportComment",
+			Handler:    _Articles_ReportComment_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "article.proto",
@@ -0,0 +1,1727 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.21.0
+// 	protoc        v3.11.4
+// source: readinglist.proto
+
+package proto
+
+import (
+	context "context"
+	proto "github.com/golang/protobuf/proto"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+type ReadingList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id            string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	IsDefault     bool     `protobuf:"varint,3,opt,name=isDefault,proto3" json:"isDefault,omitempty"`
+	Shared        bool     `protobuf:"varint,4,opt,name=shared,proto3" json:"shared,omitempty"`
+	ShareUrl      string   `protobuf:"bytes,5,opt,name=shareUrl,proto3" json:"shareUrl,omitempty"`
+	ArticlesCount int32    `protobuf:"varint,6,opt,name=articlesCount,proto3" json:"articlesCount,omitempty"`
+	Owner         *Profile `protobuf:"bytes,7,opt,name=owner,proto3" json:"owner,omitempty"`
+	CreatedAt     string   `protobuf:"bytes,8,opt,name=createdAt,proto3" json:"createdAt,omitempty"`
+	UpdatedAt     string   `protobuf:"bytes,9,opt,name=updatedAt,proto3" json:"updatedAt,omitempty"`
+}
+
+func (x *ReadingList) Reset() {
+	*x = ReadingList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_readinglist_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReadingList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadingList) ProtoMessage() {}
+
+func (x *ReadingList) ProtoReflect() protoreflect.Message {
+	mi := &file_readinglist_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadingList.ProtoReflect.Descriptor instead.
+func (*ReadingList) Descriptor() ([]byte, []int) {
+	return file_readinglist_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ReadingList) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ReadingList) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ReadingList) GetIsDefault() bool {
+	if x != nil {
+		return x.IsDefault
+	}
+	return false
+}
+
+func (x *ReadingList) GetShared() bool {
+	if x != nil {
+		return x.Shared
+	}
+	return false
+}
+
+func (x *ReadingList) GetShareUrl() string {
+	if x != nil {
+		return x.ShareUrl
+	}
+	return ""
+}
+
+func (x *ReadingList) GetArticlesCount() int32 {
+	if x != nil {
+		return x.ArticlesCount
+	}
+	return 0
+}
+
+func (x *ReadingList) GetOwner() *Profile {
+	if x != nil {
+		return x.Owner
+	}
+	return nil
+}
+
+func (x *ReadingList) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *ReadingList) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+// request message
+type CreateReadingListRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ReadingList *CreateReadingListRequest_ReadingList `protobuf:"bytes,1,opt,name=readingList,proto3" json:"readingList,omitempty"`
+}
+
+func (x *CreateReadingListRequest) Reset() {
+	*x = CreateReadingListRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_readinglist_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreateReadingListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateReadingListRequest) ProtoMessage() {}
+
+func (x *CreateReadingListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_readinglist_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateReadingListRequest.ProtoReflect.Descriptor instead.
+func (*CreateReadingListRequest) Descriptor() ([]byte, []int) {
+	return file_readinglist_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateReadingListRequest) GetReadingList() *CreateReadingListRequest_ReadingList {
+	if x != nil {
+		return x.ReadingList
+	}
+	return nil
+}
+
+type GetReadingListRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id     string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Limit  int64  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset int64  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+}
+
+func (x *GetReadingListRequest) Reset() {
+	*x = GetReadingListRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_readinglist_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetReadingListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetReadingListRequest) ProtoMessage() {}
+
+func (x *GetReadingListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_readinglist_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetReadingListRequest.ProtoReflect.Descriptor instead.
+func (*GetReadingListRequest) Descriptor() ([]byte, []int) {
+	return file_readinglist_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetReadingListRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetReadingListRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetReadingListRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type UpdateReadingListRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ReadingList *UpdateReadingListRequest_ReadingList `protobuf:"bytes,1,opt,name=readingList,proto3" json:"readingList,omitempty"`
+}
+
+func (x *UpdateReadingListRequest) Reset() {
+	*x = UpdateReadingListRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_readinglist_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdateReadingListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateReadingListRequest) ProtoMessage() {}
+
+func (x *UpdateReadingListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_readinglist_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateReadingListRequest.ProtoReflect.Descriptor instead.
+func (*UpdateReadingListRequest) Descriptor() ([]byte, []int) {
+	return file_readinglist_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *UpdateReadingListRequest) GetReadingList() *UpdateReadingListRequest_ReadingList {
+	if x != nil {
+		return x.ReadingList
+	}
+	return nil
+}
+
+type DeleteReadingListRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *DeleteReadingListRequest) Reset() {
+	*x = DeleteReadingListRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_readinglist_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteReadingListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteReadingListRequest) ProtoMessage() {}
+
+func (x *DeleteReadingListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_readinglist_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteReadingListRequest.ProtoReflect.Descriptor instead.
+func (*DeleteReadingListRequest) Descriptor() ([]byte, []int) {
+	return file_readinglist_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DeleteReadingListRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ShareReadingListRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *ShareReadingListRequest) Reset() {
+	*x = ShareReadingListRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_readinglist_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ShareReadingListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShareReadingListRequest) ProtoMessage() {}
+
+func (x *ShareReadingListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_readinglist_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShareReadingListRequest.ProtoReflect.Descriptor instead.
+func (*ShareReadingListRequest) Descriptor() ([]byte, []int) {
+	return file_readinglist_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ShareReadingListRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type AddToReadingListRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id   string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Slug string `protobuf:"bytes,2,opt,name=slug,proto3" json:"slug,omitempty"`
+}
+
+func (x *AddToReadingListRequest) Reset() {
+	*x = AddToReadingListRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_readinglist_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AddToReadingListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddToReadingListRequest) ProtoMessage() {}
+
+func (x *AddToReadingListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_readinglist_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddToReadingListRequest.ProtoReflect.Descriptor instead.
+func (*AddToReadingListRequest) Descriptor() ([]byte, []int) {
+	return file_readinglist_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *AddToReadingListRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AddToReadingListRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+type RemoveFromReadingListRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id   string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Slug string `protobuf:"bytes,2,opt,name=slug,proto3" json:"slug,omitempty"`
+}
+
+func (x *RemoveFromReadingListRequest) Reset() {
+	*x = RemoveFromReadingListRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_readinglist_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RemoveFromReadingListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveFromReadingListRequest) ProtoMessage() {}
+
+func (x *RemoveFromReadingListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_readinglist_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveFromReadingListRequest.ProtoReflect.Descriptor instead.
+func (*RemoveFromReadingListRequest) Descriptor() ([]byte, []int) {
+	return file_readinglist_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RemoveFromReadingListRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *RemoveFromReadingListRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+type MoveInReadingListRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id       string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Slug     string `protobuf:"bytes,2,opt,name=slug,proto3" json:"slug,omitempty"`
+	Position int32  `protobuf:"varint,3,opt,name=position,proto3" json:"position,omitempty"`
+}
+
+func (x *MoveInReadingListRequest) Reset() {
+	*x = MoveInReadingListRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_readinglist_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MoveInReadingListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MoveInReadingListRequest) ProtoMessage() {}
+
+func (x *MoveInReadingListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_readinglist_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MoveInReadingListRequest.ProtoReflect.Descriptor instead.
+func (*MoveInReadingListRequest) Descriptor() ([]byte, []int) {
+	return file_readinglist_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *MoveInReadingListRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *MoveInReadingListRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *MoveInReadingListRequest) GetPosition() int32 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+type GetSharedReadingListRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Token  string `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Limit  int64  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset int64  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+}
+
+func (x *GetSharedReadingListRequest) Reset() {
+	*x = GetSharedReadingListRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_readinglist_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetSharedReadingListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSharedReadingListRequest) ProtoMessage() {}
+
+func (x *GetSharedReadingListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_readinglist_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSharedReadingListRequest.ProtoReflect.Descriptor instead.
+func (*GetSharedReadingListRequest) Descriptor() ([]byte, []int) {
+	return file_readinglist_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetSharedReadingListRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *GetSharedReadingListRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetSharedReadingListRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+// response message
+type ReadingListResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ReadingList *ReadingList `protobuf:"bytes,1,opt,name=readingList,proto3" json:"readingList,omitempty"`
+	Articles    []*Article   `protobuf:"bytes,2,rep,name=articles,proto3" json:"articles,omitempty"`
+}
+
+func (x *ReadingListResponse) Reset() {
+	*x = ReadingListResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_readinglist_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReadingListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadingListResponse) ProtoMessage() {}
+
+func (x *ReadingListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_readinglist_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadingListResponse.ProtoReflect.Descriptor instead.
+func (*ReadingListResponse) Descriptor() ([]byte, []int) {
+	return file_readinglist_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ReadingListResponse) GetReadingList() *ReadingList {
+	if x != nil {
+		return x.ReadingList
+	}
+	return nil
+}
+
+func (x *ReadingListResponse) GetArticles() []*Article {
+	if x != nil {
+		return x.Articles
+	}
+	return nil
+}
+
+type ReadingListsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ReadingLists []*ReadingList `protobuf:"bytes,1,rep,name=readingLists,proto3" json:"readingLists,omitempty"`
+}
+
+func (x *ReadingListsResponse) Reset() {
+	*x = ReadingListsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_readinglist_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReadingListsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadingListsResponse) ProtoMessage() {}
+
+func (x *ReadingListsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_readinglist_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadingListsResponse.ProtoReflect.Descriptor instead.
+func (*ReadingListsResponse) Descriptor() ([]byte, []int) {
+	return file_readinglist_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ReadingListsResponse) GetReadingLists() []*ReadingList {
+	if x != nil {
+		return x.ReadingLists
+	}
+	return nil
+}
+
+type CreateReadingListRequest_ReadingList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (x *CreateReadingListRequest_ReadingList) Reset() {
+	*x = CreateReadingListRequest_ReadingList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_readinglist_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreateReadingListRequest_ReadingList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateReadingListRequest_ReadingList) ProtoMessage() {}
+
+func (x *CreateReadingListRequest_ReadingList) ProtoReflect() protoreflect.Message {
+	mi := &file_readinglist_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateReadingListRequest_ReadingList.ProtoReflect.Descriptor instead.
+func (*CreateReadingListRequest_ReadingList) Descriptor() ([]byte, []int) {
+	return file_readinglist_proto_rawDescGZIP(), []int{1, 0}
+}
+
+func (x *CreateReadingListRequest_ReadingList) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type UpdateReadingListRequest_ReadingList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id   string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (x *UpdateReadingListRequest_ReadingList) Reset() {
+	*x = UpdateReadingListRequest_ReadingList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_readinglist_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdateReadingListRequest_ReadingList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateReadingListRequest_ReadingList) ProtoMessage() {}
+
+func (x *UpdateReadingListRequest_ReadingList) ProtoReflect() protoreflect.Message {
+	mi := &file_readinglist_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateReadingListRequest_ReadingList.ProtoReflect.Descriptor instead.
+func (*UpdateReadingListRequest_ReadingList) Descriptor() ([]byte, []int) {
+	return file_readinglist_proto_rawDescGZIP(), []int{3, 0}
+}
+
+func (x *UpdateReadingListRequest_ReadingList) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateReadingListRequest_ReadingList) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+var File_readinglist_proto protoreflect.FileDescriptor
+
+var file_readinglist_proto_rawDesc = []byte{
+	0x0a, 0x11, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x6c, 0x69, 0x73, 0x74, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x12, 0x0b, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x6c, 0x69, 0x73, 0x74,
+	0x1a, 0x1c, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x61, 0x6e, 0x6e,
+	0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x0a,
+	0x75, 0x73, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x0d, 0x61, 0x72, 0x74, 0x69,
+	0x63, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x0b, 0x65, 0x6d, 0x70, 0x74, 0x79,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x8a, 0x02, 0x0a, 0x0b, 0x52, 0x65, 0x61, 0x64, 0x69,
+	0x6e, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x69, 0x73,
+	0x44, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x69,
+	0x73, 0x44, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x68, 0x61, 0x72,
+	0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x73, 0x68, 0x61, 0x72, 0x65, 0x64,
+	0x12, 0x1a, 0x0a, 0x08, 0x73, 0x68, 0x61, 0x72, 0x65, 0x55, 0x72, 0x6c, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x08, 0x73, 0x68, 0x61, 0x72, 0x65, 0x55, 0x72, 0x6c, 0x12, 0x24, 0x0a, 0x0d,
+	0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x0d, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x43, 0x6f, 0x75,
+	0x6e, 0x74, 0x12, 0x23, 0x0a, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0d, 0x2e, 0x75, 0x73, 0x65, 0x72, 0x2e, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65,
+	0x52, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x64, 0x41, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64,
+	0x41, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65,
+	0x64, 0x41, 0x74, 0x22, 0x92, 0x01, 0x0a, 0x18, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65,
+	0x61, 0x64, 0x69, 0x6e, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x53, 0x0a, 0x0b, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x31, 0x2e, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x6c,
+	0x69, 0x73, 0x74, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e,
+	0x67, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x52, 0x65, 0x61,
+	0x64, 0x69, 0x6e, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x0b, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e,
+	0x67, 0x4c, 0x69, 0x73, 0x74, 0x1a, 0x21, 0x0a, 0x0b, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67,
+	0x4c, 0x69, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0x55, 0x0a, 0x15, 0x47, 0x65, 0x74, 0x52,
+	0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69,
+	0x64, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65,
+	0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x22,
+	0xa2, 0x01, 0x0a, 0x18, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e,
+	0x67, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x53, 0x0a, 0x0b,
+	0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x31, 0x2e, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x6c, 0x69, 0x73, 0x74, 0x2e,
+	0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x4c, 0x69, 0x73,
+	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67,
+	0x4c, 0x69, 0x73, 0x74, 0x52, 0x0b, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x4c, 0x69, 0x73,
+	0x74, 0x1a, 0x31, 0x0a, 0x0b, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x4c, 0x69, 0x73, 0x74,
+	0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64,
+	0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x6e, 0x61, 0x6d, 0x65, 0x22, 0x2a, 0x0a, 0x18, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65,
+	0x61, 0x64, 0x69, 0x6e, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64,
+	0x22, 0x29, 0x0a, 0x17, 0x53, 0x68, 0x61, 0x72, 0x65, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67,
+	0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0x3d, 0x0a, 0x17, 0x41,
+	0x64, 0x64, 0x54, 0x6f, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x22, 0x42, 0x0a, 0x1c, 0x52, 0x65,
+	0x6d, 0x6f, 0x76, 0x65, 0x46, 0x72, 0x6f, 0x6d, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x4c,
+	0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6c,
+	0x75, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x22, 0x5a,
+	0x0a, 0x18, 0x4d, 0x6f, 0x76, 0x65, 0x49, 0x6e, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x4c,
+	0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6c,
+	0x75, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x12, 0x1a,
+	0x0a, 0x08, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x08, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x61, 0x0a, 0x1b, 0x47, 0x65,
+	0x74, 0x53, 0x68, 0x61, 0x72, 0x65, 0x64, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x4c, 0x69,
+	0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x6b,
+	0x65, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x12,
+	0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05,
+	0x6c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x22, 0x7f, 0x0a,
+	0x13, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3a, 0x0a, 0x0b, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x4c,
+	0x69, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x72, 0x65, 0x61, 0x64,
+	0x69, 0x6e, 0x67, 0x6c, 0x69, 0x73, 0x74, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x4c,
+	0x69, 0x73, 0x74, 0x52, 0x0b, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x4c, 0x69, 0x73, 0x74,
+	0x12, 0x2c, 0x0a, 0x08, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x10, 0x2e, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x2e, 0x41, 0x72, 0x74,
+	0x69, 0x63, 0x6c, 0x65, 0x52, 0x08, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x22, 0x54,
+	0x0a, 0x14, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3c, 0x0a, 0x0c, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e,
+	0x67, 0x4c, 0x69, 0x73, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x72,
+	0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x6c, 0x69, 0x73, 0x74, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x69,
+	0x6e, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x0c, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x4c,
+	0x69, 0x73, 0x74, 0x73, 0x32, 0xb9, 0x0b, 0x0a, 0x0c, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67,
+	0x4c, 0x69, 0x73, 0x74, 0x73, 0x12, 0x7c, 0x0a, 0x11, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52,
+	0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x25, 0x2e, 0x72, 0x65, 0x61,
+	0x64, 0x69, 0x6e, 0x67, 0x6c, 0x69, 0x73, 0x74, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52,
+	0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x20, 0x2e, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x6c, 0x69, 0x73, 0x74, 0x2e,
+	0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x22, 0x1e, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x18, 0x22, 0x13, 0x2f, 0x75, 0x73,
+	0x65, 0x72, 0x2f, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x2d, 0x6c, 0x69, 0x73, 0x74, 0x73,
+	0x3a, 0x01, 0x2a, 0x12, 0x60, 0x0a, 0x10, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x61, 0x64, 0x69,
+	0x6e, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x73, 0x12, 0x0c, 0x2e, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x2e,
+	0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x21, 0x2e, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x6c,
+	0x69, 0x73, 0x74, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x73,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1b, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x15,
+	0x12, 0x13, 0x2f, 0x75, 0x73, 0x65, 0x72, 0x2f, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x2d,
+	0x6c, 0x69, 0x73, 0x74, 0x73, 0x12, 0x78, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x52, 0x65, 0x61, 0x64,
+	0x69, 0x6e, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x22, 0x2e, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e,
+	0x67, 0x6c, 0x69, 0x73, 0x74, 0x2e, 0x47, 0x65, 0x74, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67,
+	0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x72, 0x65,
+	0x61, 0x64, 0x69, 0x6e, 0x67, 0x6c, 0x69, 0x73, 0x74, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e,
+	0x67, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x20, 0x82,
+	0xd3, 0xe4, 0x93, 0x02, 0x1a, 0x12, 0x18, 0x2f, 0x75, 0x73, 0x65, 0x72, 0x2f, 0x72, 0x65, 0x61,
+	0x64, 0x69, 0x6e, 0x67, 0x2d, 0x6c, 0x69, 0x73, 0x74, 0x73, 0x2f, 0x7b, 0x69, 0x64, 0x7d, 0x12,
+	0x8d, 0x01, 0x0a, 0x11, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e,
+	0x67, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x25, 0x2e, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x6c,
+	0x69, 0x73, 0x74, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e,
+	0x67, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x72,
+	0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x6c, 0x69, 0x73, 0x74, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x69,
+	0x6e, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x2f,
+	0x82, 0xd3, 0xe4, 0x93, 0x02, 0x29, 0x1a, 0x24, 0x2f, 0x75, 0x73, 0x65, 0x72, 0x2f, 0x72, 0x65,
+	0x61, 0x64, 0x69, 0x6e, 0x67, 0x2d, 0x6c, 0x69, 0x73, 0x74, 0x73, 0x2f, 0x7b, 0x72, 0x65, 0x61,
+	0x64, 0x69, 0x6e, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x2e, 0x69, 0x64, 0x7d, 0x3a, 0x01, 0x2a, 0x12,
+	0x6a, 0x0a, 0x11, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67,
+	0x4c, 0x69, 0x73, 0x74, 0x12, 0x25, 0x2e, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x6c, 0x69,
+	0x73, 0x74, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67,
+	0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0c, 0x2e, 0x65, 0x6d,
+	0x70, 0x74, 0x79, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x20, 0x82, 0xd3, 0xe4, 0x93, 0x02,
+	0x1a, 0x2a, 0x18, 0x2f, 0x75, 0x73, 0x65, 0x72, 0x2f, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67,
+	0x2d, 0x6c, 0x69, 0x73, 0x74, 0x73, 0x2f, 0x7b, 0x69, 0x64, 0x7d, 0x12, 0x85, 0x01, 0x0a, 0x10,
+	0x53, 0x68, 0x61, 0x72, 0x65, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x4c, 0x69, 0x73, 0x74,
+	0x12, 0x24, 0x2e, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x6c, 0x69, 0x73, 0x74, 0x2e, 0x53,
+	0x68, 0x61, 0x72, 0x65, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67,
+	0x6c, 0x69, 0x73, 0x74, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x4c, 0x69, 0x73, 0x74,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x29, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x23,
+	0x22, 0x1e, 0x2f, 0x75, 0x73, 0x65, 0x72, 0x2f, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x2d,
+	0x6c, 0x69, 0x73, 0x74, 0x73, 0x2f, 0x7b, 0x69, 0x64, 0x7d, 0x2f, 0x73, 0x68, 0x61, 0x72, 0x65,
+	0x3a, 0x01, 0x2a, 0x12, 0x84, 0x01, 0x0a, 0x12, 0x55, 0x6e, 0x73, 0x68, 0x61, 0x72, 0x65, 0x52,
+	0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x24, 0x2e, 0x72, 0x65, 0x61,
+	0x64, 0x69, 0x6e, 0x67, 0x6c, 0x69, 0x73, 0x74, 0x2e, 0x53, 0x68, 0x61, 0x72, 0x65, 0x52, 0x65,
+	0x61, 0x64, 0x69, 0x6e, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x20, 0x2e, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x6c, 0x69, 0x73, 0x74, 0x2e, 0x52,
+	0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x26, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x20, 0x2a, 0x1e, 0x2f, 0x75, 0x73, 0x65,
+	0x72, 0x2f, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x2d, 0x6c, 0x69, 0x73, 0x74, 0x73, 0x2f,
+	0x7b, 0x69, 0x64, 0x7d, 0x2f, 0x73, 0x68, 0x61, 0x72, 0x65, 0x12, 0x88, 0x01, 0x0a, 0x10, 0x41,
+	0x64, 0x64, 0x54, 0x6f, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x12,
+	0x24, 0x2e, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x6c, 0x69, 0x73, 0x74, 0x2e, 0x41, 0x64,
+	0x64, 0x54, 0x6f, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x6c,
+	0x69, 0x73, 0x74, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x2c, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x26, 0x22,
+	0x21, 0x2f, 0x75, 0x73, 0x65, 0x72, 0x2f, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x2d, 0x6c,
+	0x69, 0x73, 0x74, 0x73, 0x2f, 0x7b, 0x69, 0x64, 0x7d, 0x2f, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c,
+	0x65, 0x73, 0x3a, 0x01, 0x2a, 0x12, 0x96, 0x01, 0x0a, 0x15, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65,
+	0x46, 0x72, 0x6f, 0x6d, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x12,
+	0x29, 0x2e, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x6c, 0x69, 0x73, 0x74, 0x2e, 0x52, 0x65,
+	0x6d, 0x6f, 0x76, 0x65, 0x46, 0x72, 0x6f, 0x6d, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x4c,
+	0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x72, 0x65, 0x61,
+	0x64, 0x69, 0x6e, 0x67, 0x6c, 0x69, 0x73, 0x74, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67,
+	0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x30, 0x82, 0xd3,
+	0xe4, 0x93, 0x02, 0x2a, 0x2a, 0x28, 0x2f, 0x75, 0x73, 0x65, 0x72, 0x2f, 0x72, 0x65, 0x61, 0x64,
+	0x69, 0x6e, 0x67, 0x2d, 0x6c, 0x69, 0x73, 0x74, 0x73, 0x2f, 0x7b, 0x69, 0x64, 0x7d, 0x2f, 0x61,
+	0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x2f, 0x7b, 0x73, 0x6c, 0x75, 0x67, 0x7d, 0x12, 0x9a,
+	0x01, 0x0a, 0x11, 0x4d, 0x6f, 0x76, 0x65, 0x49, 0x6e, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67,
+	0x4c, 0x69, 0x73, 0x74, 0x12, 0x25, 0x2e, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x6c, 0x69,
+	0x73, 0x74, 0x2e, 0x4d, 0x6f, 0x76, 0x65, 0x49, 0x6e, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67,
+	0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x72, 0x65,
+	0x61, 0x64, 0x69, 0x6e, 0x67, 0x6c, 0x69, 0x73, 0x74, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e,
+	0x67, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x3c, 0x82,
+	0xd3, 0xe4, 0x93, 0x02, 0x36, 0x1a, 0x31, 0x2f, 0x75, 0x73, 0x65, 0x72, 0x2f, 0x72, 0x65, 0x61,
+	0x64, 0x69, 0x6e, 0x67, 0x2d, 0x6c, 0x69, 0x73, 0x74, 0x73, 0x2f, 0x7b, 0x69, 0x64, 0x7d, 0x2f,
+	0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x2f, 0x7b, 0x73, 0x6c, 0x75, 0x67, 0x7d, 0x2f,
+	0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x3a, 0x01, 0x2a, 0x12, 0x82, 0x01, 0x0a, 0x14,
+	0x47, 0x65, 0x74, 0x53, 0x68, 0x61, 0x72, 0x65, 0x64, 0x52, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67,
+	0x4c, 0x69, 0x73, 0x74, 0x12, 0x28, 0x2e, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x6c, 0x69,
+	0x73, 0x74, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x68, 0x61, 0x72, 0x65, 0x64, 0x52, 0x65, 0x61, 0x64,
+	0x69, 0x6e, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20,
+	0x2e, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x6c, 0x69, 0x73, 0x74, 0x2e, 0x52, 0x65, 0x61,
+	0x64, 0x69, 0x6e, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x1e, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x18, 0x12, 0x16, 0x2f, 0x72, 0x65, 0x61, 0x64, 0x69,
+	0x6e, 0x67, 0x2d, 0x6c, 0x69, 0x73, 0x74, 0x73, 0x2f, 0x7b, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x7d,
+	0x42, 0x09, 0x5a, 0x07, 0x2e, 0x3b, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
+}
+
+var (
+	file_readinglist_proto_rawDescOnce sync.Once
+	file_readinglist_proto_rawDescData = file_readinglist_proto_rawDesc
+)
+
+func file_readinglist_proto_rawDescGZIP() []byte {
+	file_readinglist_proto_rawDescOnce.Do(func() {
+		file_readinglist_proto_rawDescData = protoimpl.X.CompressGZIP(file_readinglist_proto_rawDescData)
+	})
+	return file_readinglist_proto_rawDescData
+}
+
+var file_readinglist_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_readinglist_proto_goTypes = []interface{}{
+	(*ReadingList)(nil),                          // 0: readinglist.ReadingList
+	(*CreateReadingListRequest)(nil),             // 1: readinglist.CreateReadingListRequest
+	(*GetReadingListRequest)(nil),                // 2: readinglist.GetReadingListRequest
+	(*UpdateReadingListRequest)(nil),             // 3: readinglist.UpdateReadingListRequest
+	(*DeleteReadingListRequest)(nil),             // 4: readinglist.DeleteReadingListRequest
+	(*ShareReadingListRequest)(nil),              // 5: readinglist.ShareReadingListRequest
+	(*AddToReadingListRequest)(nil),              // 6: readinglist.AddToReadingListRequest
+	(*RemoveFromReadingListRequest)(nil),         // 7: readinglist.RemoveFromReadingListRequest
+	(*MoveInReadingListRequest)(nil),             // 8: readinglist.MoveInReadingListRequest
+	(*GetSharedReadingListRequest)(nil),          // 9: readinglist.GetSharedReadingListRequest
+	(*ReadingListResponse)(nil),                  // 10: readinglist.ReadingListResponse
+	(*ReadingListsResponse)(nil),                 // 11: readinglist.ReadingListsResponse
+	(*CreateReadingListRequest_ReadingList)(nil), // 12: readinglist.CreateReadingListRequest.ReadingList
+	(*UpdateReadingListRequest_ReadingList)(nil), // 13: readinglist.UpdateReadingListRequest.ReadingList
+	(*Profile)(nil),                              // 14: user.Profile
+	(*Article)(nil),                              // 15: article.Article
+	(*Empty)(nil),                                // 16: empty.Empty
+}
+var file_readinglist_proto_depIdxs = []int32{
+	14, // 0: readinglist.ReadingList.owner:type_name -> user.Profile
+	12, // 1: readinglist.CreateReadingListRequest.readingList:type_name -> readinglist.CreateReadingListRequest.ReadingList
+	13, // 2: readinglist.UpdateReadingListRequest.readingList:type_name -> readinglist.UpdateReadingListRequest.ReadingList
+	0,  // 3: readinglist.ReadingListResponse.readingList:type_name -> readinglist.ReadingList
+	15, // 4: readinglist.ReadingListResponse.articles:type_name -> article.Article
+	0,  // 5: readinglist.ReadingListsResponse.readingLists:type_name -> readinglist.ReadingList
+	1,  // 6: readinglist.ReadingLists.CreateReadingList:input_type -> readinglist.CreateReadingListRequest
+	16, // 7: readinglist.ReadingLists.ListReadingLists:input_type -> empty.Empty
+	2,  // 8: readinglist.ReadingLists.GetReadingList:input_type -> readinglist.GetReadingListRequest
+	3,  // 9: readinglist.ReadingLists.UpdateReadingList:input_type -> readinglist.UpdateReadingListRequest
+	4,  // 10: readinglist.ReadingLists.DeleteReadingList:input_type -> readinglist.DeleteReadingListRequest
+	5,  // 11: readinglist.ReadingLists.ShareReadingList:input_type -> readinglist.ShareReadingListRequest
+	5,  // 12: readinglist.ReadingLists.UnshareReadingList:input_type -> readinglist.ShareReadingListRequest
+	6,  // 13: readinglist.ReadingLists.AddToReadingList:input_type -> readinglist.AddToReadingListRequest
+	7,  // 14: readinglist.ReadingLists.RemoveFromReadingList:input_type -> readinglist.RemoveFromReadingListRequest
+	8,  // 15: readinglist.ReadingLists.MoveInReadingList:input_type -> readinglist.MoveInReadingListRequest
+	9,  // 16: readinglist.ReadingLists.GetSharedReadingList:input_type -> readinglist.GetSharedReadingListRequest
+	10, // 17: readinglist.ReadingLists.CreateReadingList:output_type -> readinglist.ReadingListResponse
+	11, // 18: readinglist.ReadingLists.ListReadingLists:output_type -> readinglist.ReadingListsResponse
+	10, // 19: readinglist.ReadingLists.GetReadingList:output_type -> readinglist.ReadingListResponse
+	10, // 20: readinglist.ReadingLists.UpdateReadingList:output_type -> readinglist.ReadingListResponse
+	16, // 21: readinglist.ReadingLists.DeleteReadingList:output_type -> empty.Empty
+	10, // 22: readinglist.ReadingLists.ShareReadingList:output_type -> readinglist.ReadingListResponse
+	10, // 23: readinglist.ReadingLists.UnshareReadingList:output_type -> readinglist.ReadingListResponse
+	10, // 24: readinglist.ReadingLists.AddToReadingList:output_type -> readinglist.ReadingListResponse
+	10, // 25: readinglist.ReadingLists.RemoveFromReadingList:output_type -> readinglist.ReadingListResponse
+	10, // 26: readinglist.ReadingLists.MoveInReadingList:output_type -> readinglist.ReadingListResponse
+	10, // 27: readinglist.ReadingLists.GetSharedReadingList:output_type -> readinglist.ReadingListResponse
+	17, // [17:28] is the sub-list for method output_type
+	6,  // [6:17] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_readinglist_proto_init() }
+func file_readinglist_proto_init() {
+	if File_readinglist_proto != nil {
+		return
+	}
+	file_user_proto_init()
+	file_article_proto_init()
+	file_empty_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_readinglist_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReadingList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_readinglist_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateReadingListRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_readinglist_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetReadingListRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_readinglist_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UpdateReadingListRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_readinglist_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeleteReadingListRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_readinglist_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ShareReadingListRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_readinglist_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AddToReadingListRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_readinglist_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RemoveFromReadingListRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_readinglist_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MoveInReadingListRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_readinglist_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetSharedReadingListRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_readinglist_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReadingListResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_readinglist_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReadingListsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_readinglist_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateReadingListRequest_ReadingList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_readinglist_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UpdateReadingListRequest_ReadingList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_readinglist_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_readinglist_proto_goTypes,
+		DependencyIndexes: file_readinglist_proto_depIdxs,
+		MessageInfos:      file_readinglist_proto_msgTypes,
+	}.Build()
+	File_readinglist_proto = out.File
+	file_readinglist_proto_rawDesc = nil
+	file_readinglist_proto_goTypes = nil
+	file_readinglist_proto_depIdxs = nil
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// ReadingListsClient is the client API for ReadingLists service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ReadingListsClient interface {
+	CreateReadingList(ctx context.Context, in *CreateReadingListRequest, opts ...grpc.CallOption) (*ReadingListResponse, error)
+	ListReadingLists(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ReadingListsResponse, error)
+	GetReadingList(ctx context.Context, in *GetReadingListRequest, opts ...grpc.CallOption) (*ReadingListResponse, error)
+	UpdateReadingList(ctx context.Context, in *UpdateReadingListRequest, opts ...grpc.CallOption) (*ReadingListResponse, error)
+	DeleteReadingList(ctx context.Context, in *DeleteReadingListRequest, opts ...grpc.CallOption) (*Empty, error)
+	ShareReadingList(ctx context.Context, in *ShareReadingListRequest, opts ...grpc.CallOption) (*ReadingListResponse, error)
+	UnshareReadingList(ctx context.Context, in *ShareReadingListRequest, opts ...grpc.CallOption) (*ReadingListResponse, error)
+	AddToReadingList(ctx context.Context, in *AddToReadingListRequest, opts ...grpc.CallOption) (*ReadingListResponse, error)
+	RemoveFromReadingList(ctx context.Context, in *RemoveFromReadingListRequest, opts ...grpc.CallOption) (*ReadingListResponse, error)
+	MoveInReadingList(ctx context.Context, in *MoveInReadingListRequest, opts ...grpc.CallOption) (*ReadingListResponse, error)
+	GetSharedReadingList(ctx context.Context, in *GetSharedReadingListRequest, opts ...grpc.CallOption) (*ReadingListResponse, error)
+}
+
+type readingListsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewReadingListsClient(cc grpc.ClientConnInterface) ReadingListsClient {
+	return &readingListsClient{cc}
+}
+
+func (c *readingListsClient) CreateReadingList(ctx context.Context, in *CreateReadingListRequest, opts ...grpc.CallOption) (*ReadingListResponse, error) {
+	out := new(ReadingListResponse)
+	err := c.cc.Invoke(ctx, "/readinglist.ReadingLists/CreateReadingList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *readingListsClient) ListReadingLists(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ReadingListsResponse, error) {
+	out := new(ReadingListsResponse)
+	err := c.cc.Invoke(ctx, "/readinglist.ReadingLists/ListReadingLists", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *readingListsClient) GetReadingList(ctx context.Context, in *GetReadingListRequest, opts ...grpc.CallOption) (*ReadingListResponse, error) {
+	out := new(ReadingListResponse)
+	err := c.cc.Invoke(ctx, "/readinglist.ReadingLists/GetReadingList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *readingListsClient) UpdateReadingList(ctx context.Context, in *UpdateReadingListRequest, opts ...grpc.CallOption) (*ReadingListResponse, error) {
+	out := new(ReadingListResponse)
+	err := c.cc.Invoke(ctx, "/readinglist.ReadingLists/UpdateReadingList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *readingListsClient) DeleteReadingList(ctx context.Context, in *DeleteReadingListRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/readinglist.ReadingLists/DeleteReadingList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *readingListsClient) ShareReadingList(ctx context.Context, in *ShareReadingListRequest, opts ...grpc.CallOption) (*ReadingListResponse, error) {
+	out := new(ReadingListResponse)
+	err := c.cc.Invoke(ctx, "/readinglist.ReadingLists/ShareReadingList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *readingListsClient) UnshareReadingList(ctx context.Context, in *ShareReadingListRequest, opts ...grpc.CallOption) (*ReadingListResponse, error) {
+	out := new(ReadingListResponse)
+	err := c.cc.Invoke(ctx, "/readinglist.ReadingLists/UnshareReadingList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *readingListsClient) AddToReadingList(ctx context.Context, in *AddToReadingListRequest, opts ...grpc.CallOption) (*ReadingListResponse, error) {
+	out := new(ReadingListResponse)
+	err := c.cc.Invoke(ctx, "/readinglist.ReadingLists/AddToReadingList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *readingListsClient) RemoveFromReadingList(ctx context.Context, in *RemoveFromReadingListRequest, opts ...grpc.CallOption) (*ReadingListResponse, error) {
+	out := new(ReadingListResponse)
+	err := c.cc.Invoke(ctx, "/readinglist.ReadingLists/RemoveFromReadingList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *readingListsClient) MoveInReadingList(ctx context.Context, in *MoveInReadingListRequest, opts ...grpc.CallOption) (*ReadingListResponse, error) {
+	out := new(ReadingListResponse)
+	err := c.cc.Invoke(ctx, "/readinglist.ReadingLists/MoveInReadingList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *readingListsClient) GetSharedReadingList(ctx context.Context, in *GetSharedReadingListRequest, opts ...grpc.CallOption) (*ReadingListResponse, error) {
+	out := new(ReadingListResponse)
+	err := c.cc.Invoke(ctx, "/readinglist.ReadingLists/GetSharedReadingList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReadingListsServer is the server API for ReadingLists service.
+type ReadingListsServer interface {
+	CreateReadingList(context.Context, *CreateReadingListRequest) (*ReadingListResponse, error)
+	ListReadingLists(context.Context, *Empty) (*ReadingListsResponse, error)
+	GetReadingList(context.Context, *GetReadingListRequest) (*ReadingListResponse, error)
+	UpdateReadingList(context.Context, *UpdateReadingListRequest) (*ReadingListResponse, error)
+	DeleteReadingList(context.Context, *DeleteReadingListRequest) (*Empty, error)
+	ShareReadingList(context.Context, *ShareReadingListRequest) (*ReadingListResponse, error)
+	UnshareReadingList(context.Context, *ShareReadingListRequest) (*ReadingListResponse, error)
+	AddToReadingList(context.Context, *AddToReadingListRequest) (*ReadingListResponse, error)
+	RemoveFromReadingList(context.Context, *RemoveFromReadingListRequest) (*ReadingListResponse, error)
+	MoveInReadingList(context.Context, *MoveInReadingListRequest) (*ReadingListResponse, error)
+	GetSharedReadingList(context.Context, *GetSharedReadingListRequest) (*ReadingListResponse, error)
+}
+
+// UnimplementedReadingListsServer can be embedded to have forward compatible implementations.
+type UnimplementedReadingListsServer struct {
+}
+
+func (*UnimplementedReadingListsServer) CreateReadingList(context.Context, *CreateReadingListRequest) (*ReadingListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateReadingList not implemented")
+}
+func (*UnimplementedReadingListsServer) ListReadingLists(context.Context, *Empty) (*ReadingListsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListReadingLists not implemented")
+}
+func (*UnimplementedReadingListsServer) GetReadingList(context.Context, *GetReadingListRequest) (*ReadingListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReadingList not implemented")
+}
+func (*UnimplementedReadingListsServer) UpdateReadingList(context.Context, *UpdateReadingListRequest) (*ReadingListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateReadingList not implemented")
+}
+func (*UnimplementedReadingListsServer) DeleteReadingList(context.Context, *DeleteReadingListRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteReadingList not implemented")
+}
+func (*UnimplementedReadingListsServer) ShareReadingList(context.Context, *ShareReadingListRequest) (*ReadingListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ShareReadingList not implemented")
+}
+func (*UnimplementedReadingListsServer) UnshareReadingList(context.Context, *ShareReadingListRequest) (*ReadingListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnshareReadingList not implemented")
+}
+func (*UnimplementedReadingListsServer) AddToReadingList(context.Context, *AddToReadingListRequest) (*ReadingListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddToReadingList not implemented")
+}
+func (*UnimplementedReadingListsServer) RemoveFromReadingList(context.Context, *RemoveFromReadingListRequest) (*ReadingListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveFromReadingList not implemented")
+}
+func (*UnimplementedReadingListsServer) MoveInReadingList(context.Context, *MoveInReadingListRequest) (*ReadingListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MoveInReadingList not implemented")
+}
+func (*UnimplementedReadingListsServer) GetSharedReadingList(context.Context, *GetSharedReadingListRequest) (*ReadingListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSharedReadingList not implemented")
+}
+
+func RegisterReadingListsServer(s *grpc.Server, srv ReadingListsServer) {
+	s.RegisterService(&_ReadingLists_serviceDesc, srv)
+}
+
+func _ReadingLists_CreateReadingList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateReadingListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReadingListsServer).CreateReadingList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/readinglist.ReadingLists/CreateReadingList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReadingListsServer).CreateReadingList(ctx, req.(*CreateReadingListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReadingLists_ListReadingLists_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReadingListsServer).ListReadingLists(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/readinglist.ReadingLists/ListReadingLists",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReadingListsServer).ListReadingLists(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReadingLists_GetReadingList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReadingListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReadingListsServer).GetReadingList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/readinglist.ReadingLists/GetReadingList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReadingListsServer).GetReadingList(ctx, req.(*GetReadingListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReadingLists_UpdateReadingList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateReadingListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReadingListsServer).UpdateReadingList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/readinglist.ReadingLists/UpdateReadingList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReadingListsServer).UpdateReadingList(ctx, req.(*UpdateReadingListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReadingLists_DeleteReadingList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteReadingListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReadingListsServer).DeleteReadingList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/readinglist.ReadingLists/DeleteReadingList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReadingListsServer).DeleteReadingList(ctx, req.(*DeleteReadingListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReadingLists_ShareReadingList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShareReadingListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReadingListsServer).ShareReadingList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/readinglist.ReadingLists/ShareReadingList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReadingListsServer).ShareReadingList(ctx, req.(*ShareReadingListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReadingLists_UnshareReadingList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShareReadingListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReadingListsServer).UnshareReadingList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/readinglist.ReadingLists/UnshareReadingList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReadingListsServer).UnshareReadingList(ctx, req.(*ShareReadingListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReadingLists_AddToReadingList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddToReadingListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReadingListsServer).AddToReadingList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/readinglist.ReadingLists/AddToReadingList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReadingListsServer).AddToReadingList(ctx, req.(*AddToReadingListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReadingLists_RemoveFromReadingList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveFromReadingListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReadingListsServer).RemoveFromReadingList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/readinglist.ReadingLists/RemoveFromReadingList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReadingListsServer).RemoveFromReadingList(ctx, req.(*RemoveFromReadingListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReadingLists_MoveInReadingList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MoveInReadingListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReadingListsServer).MoveInReadingList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/readinglist.ReadingLists/MoveInReadingList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReadingListsServer).MoveInReadingList(ctx, req.(*MoveInReadingListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReadingLists_GetSharedReadingList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSharedReadingListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReadingListsServer).GetSharedReadingList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/readinglist.ReadingLists/GetSharedReadingList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReadingListsServer).GetSharedReadingList(ctx, req.(*GetSharedReadingListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _ReadingLists_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "readinglist.ReadingLists",
+	HandlerType: (*ReadingListsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateReadingList",
+			Handler:    _ReadingLists_CreateReadingList_Handler,
+		},
+		{
+			MethodName: "ListReadingLists",
+			Handler:    _ReadingLists_ListReadingLists_Handler,
+		},
+		{
+			MethodName: "GetReadingList",
+			Handler:    _ReadingLists_GetReadingList_Handler,
+		},
+		{
+			MethodName: "UpdateReadingList",
+			Handler:    _ReadingLists_UpdateReadingList_Handler,
+		},
+		{
+			MethodName: "DeleteReadingList",
+			Handler:    _ReadingLists_DeleteReadingList_Handler,
+		},
+		{
+			MethodName: "ShareReadingList",
+			Handler:    _ReadingLists_ShareReadingList_Handler,
+		},
+		{
+			MethodName: "UnshareReadingList",
+			Handler:    _ReadingLists_UnshareReadingList_Handler,
+		},
+		{
+			MethodName: "AddToReadingList",
+			Handler:    _ReadingLists_AddToReadingList_Handler,
+		},
+		{
+			MethodName: "RemoveFromReadingList",
+			Handler:    _ReadingLists_RemoveFromReadingList_Handler,
+		},
+		{
+			MethodName: "MoveInReadingList",
+			Handler:    _ReadingLists_MoveInReadingList_Handler,
+		},
+		{
+			MethodName: "GetSharedReadingList",
+			Handler:    _ReadingLists_GetSharedReadingList_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "readinglist.proto",
+}
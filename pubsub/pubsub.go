@@ -0,0 +1,153 @@
+// Package pubsub provides an in-process publish/subscribe hub. It keeps
+// recent events so that subscribers can resume from the last event they saw.
+package pubsub
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrEventsLost is returned when events after the last seen one are no longer kept
+	ErrEventsLost = errors.New("events after the last seen event are no longer available")
+
+	// ErrSlowSubscriber is the reason a subscription is closed when it falls behind
+	ErrSlowSubscriber = errors.New("subscriber fell behind")
+)
+
+// Event is a published event
+type Event struct {
+	ID    uint64
+	Topic string
+	Time  time.Time
+	Data  interface{}
+}
+
+// Hub delivers events to subscribers of their topic
+type Hub struct {
+	mu      sync.Mutex
+	nextID  uint64
+	history []Event // ring buffer of the recent events
+	head    int     // index of the oldest event in history
+	size    int
+	subs    map[*Subscription]struct{}
+}
+
+// NewHub returns a hub keeping the last historySize events.
+// Event ids start from the current time, so ids seen before a restart
+// are older than any kept event and their subscribers get ErrEventsLost.
+func NewHub(historySize int) *Hub {
+	return &Hub{
+		nextID:  uint64(time.Now().UnixNano()),
+		history: make([]Event, historySize),
+		subs:    map[*Subscription]struct{}{},
+	}
+}
+
+// Publish sends data to the subscribers of topic. Subscribers whose buffer
+// is full are closed with ErrSlowSubscriber instead of blocking the publisher.
+func (h *Hub) Publish(topic string, data interface{}) Event {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	e := Event{ID: h.nextID, Topic: topic, Time: time.Now(), Data: data}
+	h.nextID++
+
+	if len(h.history) > 0 {
+		if h.size < len(h.history) {
+			h.history[(h.head+h.size)%len(h.history)] = e
+			h.size++
+		} else {
+			h.history[h.head] = e
+			h.head = (h.head + 1) % len(h.history)
+		}
+	}
+
+	for s := range h.subs {
+		if !s.topics[topic] {
+			continue
+		}
+
+		select {
+		case s.c <- e:
+		default:
+			s.err = ErrSlowSubscriber
+			h.remove(s)
+		}
+	}
+
+	return e
+}
+
+// Subscribe subscribes to topics. Kept events published after lastID are
+// delivered first; zero lastID receives new events only. buffer is the number
+// of events which may wait for the subscriber before it is closed.
+func (h *Hub) Subscribe(topics []string, lastID uint64, buffer int) (*Subscription, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := &Subscription{hub: h, topics: map[string]bool{}}
+	for _, t := range topics {
+		s.topics[t] = true
+	}
+
+	var missed []Event
+	if lastID != 0 {
+		oldest := h.nextID - uint64(h.size)
+		if lastID+1 < oldest || lastID >= h.nextID {
+			return nil, ErrEventsLost
+		}
+
+		for i := 0; i < h.size; i++ {
+			e := h.history[(h.head+i)%len(h.history)]
+			if e.ID > lastID && s.topics[e.Topic] {
+				missed = append(missed, e)
+			}
+		}
+	}
+
+	s.c = make(chan Event, buffer+len(missed))
+	for _, e := range missed {
+		s.c <- e
+	}
+	h.subs[s] = struct{}{}
+
+	return s, nil
+}
+
+// remove closes the subscription. h.mu must be held.
+func (h *Hub) remove(s *Subscription) {
+	if _, ok := h.subs[s]; !ok {
+		return
+	}
+	delete(h.subs, s)
+	close(s.c)
+}
+
+// Subscription receives events of its topics
+type Subscription struct {
+	hub    *Hub
+	topics map[string]bool
+	c      chan Event
+	err    error
+}
+
+// Events returns the channel of events. It is closed when the subscription is closed.
+func (s *Subscription) Events() <-chan Event {
+	return s.c
+}
+
+// Err returns why the hub closed the subscription
+func (s *Subscription) Err() error {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	return s.err
+}
+
+// Close unsubscribes
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
@@ -0,0 +1,122 @@
+package pubsub
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func receive(s *Subscription) []interface{} {
+	var data []interface{}
+	for {
+		select {
+		case e, ok := <-s.Events():
+			if !ok {
+				return data
+			}
+			data = append(data, e.Data)
+		default:
+			return data
+		}
+	}
+}
+
+func TestPublish(t *testing.T) {
+	h := NewHub(10)
+
+	a, err := h.Subscribe([]string{"a"}, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	ab, err := h.Subscribe([]string{"a", "b"}, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ab.Close()
+
+	e1 := h.Publish("a", 1)
+	e2 := h.Publish("b", 2)
+	h.Publish("c", 3)
+
+	assert.True(t, e1.ID < e2.ID)
+	assert.Equal(t, []interface{}{1}, receive(a))
+	assert.Equal(t, []interface{}{1, 2}, receive(ab))
+
+	a.Close()
+	a.Close()
+	h.Publish("a", 4)
+	_, ok := <-a.Events()
+	assert.False(t, ok, "closed subscription receives nothing")
+	assert.NoError(t, a.Err())
+}
+
+func TestResume(t *testing.T) {
+	h := NewHub(3)
+
+	var ids []uint64
+	for i := 1; i <= 5; i++ {
+		topic := "a"
+		if i == 4 {
+			topic = "b"
+		}
+		ids = append(ids, h.Publish(topic, i).ID)
+	}
+
+	tests := []struct {
+		title    string
+		lastID   uint64
+		expected []interface{}
+		hasError bool
+	}{
+		{"resume from kept event", ids[2], []interface{}{5}, false},
+		{"resume just before kept events", ids[1], []interface{}{3, 5}, false},
+		{"resume from the latest event", ids[4], nil, false},
+		{"resume from evicted event", ids[0], nil, true},
+		{"resume from before restart", ids[0] - 100, nil, true},
+		{"resume from unknown event", ids[4] + 1, nil, true},
+	}
+
+	for _, tt := range tests {
+		s, err := h.Subscribe([]string{"a"}, tt.lastID, 1)
+		if tt.hasError {
+			if err == nil {
+				t.Errorf("%q expected to fail, but succeeded.", tt.title)
+			}
+			assert.Equal(t, ErrEventsLost, err, tt.title)
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%q expected to succeed, but failed. %v", tt.title, err)
+			continue
+		}
+
+		assert.Equal(t, tt.expected, receive(s), tt.title)
+		s.Close()
+	}
+}
+
+func TestSlowSubscriber(t *testing.T) {
+	h := NewHub(10)
+
+	slow, err := h.Subscribe([]string{"a"}, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fast, err := h.Subscribe([]string{"a"}, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fast.Close()
+
+	for i := 1; i <= 3; i++ {
+		h.Publish("a", i)
+	}
+
+	assert.Equal(t, []interface{}{1, 2}, receive(slow))
+	assert.Equal(t, ErrSlowSubscriber, slow.Err())
+	assert.Equal(t, []interface{}{1, 2, 3}, receive(fast))
+}
@@ -0,0 +1,162 @@
+// Package related ranks the articles related to an article by the tags they
+// share, the users who favorited both and the similarity of their text, and
+// caches the rankings.
+package related
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Weights of each signal, which are scaled to [0, 1] first
+const (
+	TagWeight      = 0.4
+	FavoriteWeight = 0.35
+	TextWeight     = 0.25
+)
+
+// Signals are what another article has in common with an article
+type Signals struct {
+	SharedTags  int     // tags both articles have
+	CoFavorites int     // users who favorited both articles
+	Text        float64 // text similarity, only comparable among the candidates
+}
+
+// Score is how much an article is related
+type Score struct {
+	ArticleID uint
+	Value     float64
+}
+
+// Rank scores the candidates related to an article with the numbers of tags
+// and favorites, the most related first. Candidates with nothing in common
+// are left out.
+func Rank(candidates map[uint]Signals, tags, favorites int) []Score {
+	var maxText float64
+	for _, s := range candidates {
+		if s.Text > maxText {
+			maxText = s.Text
+		}
+	}
+
+	scores := make([]Score, 0, len(candidates))
+	for id, s := range candidates {
+		var v float64
+		if tags > 0 {
+			v += TagWeight * ratio(float64(s.SharedTags), float64(tags))
+		}
+		if favorites > 0 {
+			v += FavoriteWeight * ratio(float64(s.CoFavorites), float64(favorites))
+		}
+		if maxText > 0 {
+			v += TextWeight * ratio(s.Text, maxText)
+		}
+
+		if v > 0 {
+			scores = append(scores, Score{ArticleID: id, Value: v})
+		}
+	}
+
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Value != scores[j].Value {
+			return scores[i].Value > scores[j].Value
+		}
+		return scores[i].ArticleID > scores[j].ArticleID
+	})
+	return scores
+}
+
+// ratio returns n/d capped at 1
+func ratio(n, d float64) float64 {
+	if n >= d {
+		return 1
+	}
+	return n / d
+}
+
+type entry struct {
+	scores  []Score
+	expires time.Time
+}
+
+// Cache keeps the rankings of articles for a while. Invalidating articles
+// bumps its generation, so that rankings computed before are not stored. It is
+// safe for concurrent use.
+type Cache struct {
+	ttl  time.Duration
+	size int
+
+	mu         sync.Mutex
+	generation uint64
+	entries    map[uint]entry
+}
+
+// NewCache returns an empty cache which keeps up to size rankings for ttl
+func NewCache(ttl time.Duration, size int) *Cache {
+	return &Cache{ttl: ttl, size: size, entries: map[uint]entry{}}
+}
+
+// Get returns the ranking of the article if it is cached. On a miss, the
+// generation is to be passed to Set with the computed ranking.
+func (c *Cache) Get(articleID uint, now time.Time) (scores []Score, generation uint64, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[articleID]
+	if !ok || !now.Before(e.expires) {
+		return nil, c.generation, false
+	}
+	return e.scores, c.generation, true
+}
+
+// Set caches the ranking of the article unless articles have been invalidated
+// since the generation was got
+func (c *Cache) Set(articleID uint, scores []Score, generation uint64, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	if _, ok := c.entries[articleID]; !ok && len(c.entries) >= c.size {
+		for id, e := range c.entries {
+			if !now.Before(e.expires) {
+				delete(c.entries, id)
+			}
+		}
+		if len(c.entries) >= c.size {
+			c.entries = map[uint]entry{}
+		}
+	}
+
+	c.entries[articleID] = entry{scores: scores, expires: now.Add(c.ttl)}
+}
+
+// Invalidate drops the rankings of the articles and the rankings which
+// include them
+func (c *Cache) Invalidate(articleIDs ...uint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+
+	changed := make(map[uint]bool, len(articleIDs))
+	for _, id := range articleIDs {
+		changed[id] = true
+	}
+
+	for id, e := range c.entries {
+		if changed[id] {
+			delete(c.entries, id)
+			continue
+		}
+		for _, s := range e.scores {
+			if changed[s.ArticleID] {
+				delete(c.entries, id)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package related
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRank(t *testing.T) {
+	candidates := map[uint]Signals{
+		1: {SharedTags: 2},
+		2: {SharedTags: 1, CoFavorites: 4},
+		3: {Text: 2.5},
+		4: {Text: 5},
+		5: {},
+	}
+
+	scores := Rank(candidates, 2, 4)
+	ids := make([]uint, 0, len(scores))
+	for _, s := range scores {
+		ids = append(ids, s.ArticleID)
+	}
+	assert.Equal(t, []uint{2, 1, 4, 3}, ids, "nothing in common is left out")
+
+	if assert.Len(t, scores, 4) {
+		assert.InDelta(t, TagWeight/2+FavoriteWeight, scores[0].Value, 1e-9)
+		assert.InDelta(t, TagWeight, scores[1].Value, 1e-9)
+		assert.InDelta(t, TextWeight, scores[2].Value, 1e-9)
+		assert.InDelta(t, TextWeight/2, scores[3].Value, 1e-9)
+	}
+
+	// an article without tags or favorites is ranked by text only
+	scores = Rank(map[uint]Signals{1: {SharedTags: 1}, 2: {Text: 1}}, 0, 0)
+	if assert.Len(t, scores, 1) {
+		assert.Equal(t, uint(2), scores[0].ArticleID)
+	}
+}
+
+func TestCache(t *testing.T) {
+	c := NewCache(time.Minute, 2)
+	now := time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+	scores := []Score{{ArticleID: 2, Value: 1}}
+
+	_, gen, ok := c.Get(1, now)
+	assert.False(t, ok)
+	c.Set(1, scores, gen, now)
+
+	got, _, ok := c.Get(1, now.Add(30*time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, scores, got)
+
+	_, _, ok = c.Get(1, now.Add(time.Minute))
+	assert.False(t, ok, "expired")
+
+	// rankings computed before invalidating are not stored
+	_, gen, _ = c.Get(7, now)
+	c.Invalidate(5)
+	c.Set(7, scores, gen, now)
+	_, gen, ok = c.Get(7, now)
+	assert.False(t, ok)
+
+	// expired entries make room first
+	c.Set(1, scores, gen, now)
+	c.Set(2, scores, gen, now.Add(-time.Minute))
+	c.Set(3, scores, gen, now)
+	_, _, ok = c.Get(1, now)
+	assert.True(t, ok)
+	_, _, ok = c.Get(3, now)
+	assert.True(t, ok)
+
+	// a full cache starts over
+	c.Set(4, scores, gen, now)
+	_, _, ok = c.Get(1, now)
+	assert.False(t, ok)
+	_, _, ok = c.Get(4, now)
+	assert.True(t, ok)
+
+	// invalidating drops the article and the rankings including it
+	_, gen, _ = c.Get(5, now)
+	c.Set(5, []Score{{ArticleID: 6, Value: 1}}, gen, now)
+	c.Invalidate(2)
+	_, _, ok = c.Get(4, now)
+	assert.False(t, ok)
+	_, _, ok = c.Get(5, now)
+	assert.True(t, ok)
+	c.Invalidate(5)
+	_, _, ok = c.Get(5, now)
+	assert.False(t, ok)
+}
@@ -0,0 +1,336 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/standard"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search/highlight/highlighter/html"
+	"github.com/blevesearch/bleve/v2/search/query"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+)
+
+const (
+	articleType   = "article"
+	articlePrefix = articleType + "/"
+)
+
+// boosts weights matches on each full-text field of articles
+var boosts = map[string]float64{
+	"title":       3,
+	"tags":        2,
+	"description": 1.5,
+	"body":        1,
+}
+
+// highlighted are the fields snippets are made from, in order
+var highlighted = []string{"title", "description", "body"}
+
+// maxSimilarTerms is how many of the most frequent terms of an article are
+// looked up to find similar articles
+const maxSimilarTerms = 25
+
+// articleDocument is an article in the index
+type articleDocument struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Body        string   `json:"body"`
+	Tags        []string `json:"tags"`
+	Author      string   `json:"author"`
+	AuthorID    string   `json:"author_id"`
+}
+
+// Type implements mapping.Classifier
+func (articleDocument) Type() string {
+	return articleType
+}
+
+func articleMapping() *mapping.DocumentMapping {
+	// tags are searched as words and filtered by exact name
+	tagText := bleve.NewTextFieldMapping()
+	tagKeyword := keywordField()
+	tagKeyword.Name = "tag"
+
+	doc := bleve.NewDocumentStaticMapping()
+	doc.AddFieldMappingsAt("title", textField())
+	doc.AddFieldMappingsAt("description", textField())
+	doc.AddFieldMappingsAt("body", textField())
+	doc.AddFieldMappingsAt("tags", tagText, tagKeyword)
+	doc.AddFieldMappingsAt("author", keywordField())
+	doc.AddFieldMappingsAt("author_id", keywordField())
+	return doc
+}
+
+func articleDocID(id uint) string {
+	return articlePrefix + formatID(id)
+}
+
+// UpdateArticle indexes the article if it is listed, otherwise removes it from the index.
+// The article must be loaded with its author and tags.
+func (i *Index) UpdateArticle(a *model.Article) error {
+	if !a.IsListed() {
+		return i.DeleteArticle(a.ID)
+	}
+
+	tags := make([]string, 0, len(a.Tags))
+	for _, t := range a.Tags {
+		tags = append(tags, t.Name)
+	}
+
+	return i.idx.Index(articleDocID(a.ID), articleDocument{
+		Title:       a.Title,
+		Description: a.Description,
+		Body:        a.PlainBody(),
+		Tags:        tags,
+		Author:      a.Author.Username,
+		AuthorID:    formatID(a.UserID),
+	})
+}
+
+// DeleteArticle removes the article from the index
+func (i *Index) DeleteArticle(id uint) error {
+	return i.idx.Delete(articleDocID(id))
+}
+
+// ArticleQuery is a search request for articles.
+// Text may contain "quoted phrases" and prefix* terms, all of which must match.
+type ArticleQuery struct {
+	Text            string
+	Tag             string
+	Author          string
+	ArticleIDs      []uint // nil means no restriction
+	ExcludedAuthors []uint
+	Limit           int
+	Offset          int
+}
+
+// ArticleHit is a matched article
+type ArticleHit struct {
+	ArticleID uint
+	Score     float64
+	Snippets  []string
+}
+
+// ArticleResult is the result of an article search
+type ArticleResult struct {
+	Hits  []ArticleHit
+	Total int
+}
+
+// SearchArticles searches articles by relevance. Snippets are HTML with matches in <mark>.
+func (i *Index) SearchArticles(q ArticleQuery) (*ArticleResult, error) {
+	terms := parse(q.Text)
+	if len(terms) == 0 {
+		return nil, errors.New("empty query")
+	}
+
+	bq := bleve.NewBooleanQuery()
+	bq.AddMust(terms...)
+
+	if q.Tag != "" {
+		tq := bleve.NewTermQuery(q.Tag)
+		tq.SetField("tag")
+		bq.AddMust(tq)
+	}
+
+	if q.Author != "" {
+		tq := bleve.NewTermQuery(q.Author)
+		tq.SetField("author")
+		bq.AddMust(tq)
+	}
+
+	if q.ArticleIDs != nil {
+		ids := make([]string, 0, len(q.ArticleIDs))
+		for _, id := range q.ArticleIDs {
+			ids = append(ids, articleDocID(id))
+		}
+		bq.AddMust(bleve.NewDocIDQuery(ids))
+	}
+
+	for _, id := range q.ExcludedAuthors {
+		tq := bleve.NewTermQuery(formatID(id))
+		tq.SetField("author_id")
+		bq.AddMustNot(tq)
+	}
+
+	req := bleve.NewSearchRequestOptions(bq, q.Limit, q.Offset, false)
+	req.Highlight = bleve.NewHighlightWithStyle(html.Name)
+	for _, f := range highlighted {
+		req.Highlight.AddField(f)
+	}
+
+	res, err := i.idx.Search(req)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]ArticleHit, 0, len(res.Hits))
+	for _, h := range res.Hits {
+		id, err := hitArticleID(h.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		var snippets []string
+		for _, f := range highlighted {
+			snippets = append(snippets, h.Fragments[f]...)
+		}
+
+		hits = append(hits, ArticleHit{ArticleID: id, Score: h.Score, Snippets: snippets})
+	}
+
+	return &ArticleResult{Hits: hits, Total: int(res.Total)}, nil
+}
+
+// SimilarArticles returns up to limit other articles which share the most
+// frequent terms of the article, the most similar first. Scores are only
+// comparable within a result.
+func (i *Index) SimilarArticles(a *model.Article, limit int) ([]ArticleHit, error) {
+	analyzer := i.idx.Mapping().AnalyzerNamed(standard.Name)
+	if analyzer == nil {
+		return nil, errors.New("standard analyzer not found")
+	}
+
+	freqs := map[string]int{}
+	for _, text := range []string{a.Title, a.Description, a.PlainBody()} {
+		for _, tok := range analyzer.Analyze([]byte(text)) {
+			freqs[string(tok.Term)]++
+		}
+	}
+	for _, t := range a.Tags {
+		freqs[strings.ToLower(t.Name)]++
+	}
+
+	terms := topTerms(freqs, maxSimilarTerms)
+	if len(terms) == 0 {
+		return []ArticleHit{}, nil
+	}
+
+	var qs []query.Query
+	for _, term := range terms {
+		term := term
+		qs = append(qs, anyField(func() fieldQuery {
+			return bleve.NewTermQuery(term)
+		}))
+	}
+
+	bq := bleve.NewBooleanQuery()
+	bq.AddMust(bleve.NewDisjunctionQuery(qs...))
+	bq.AddMustNot(bleve.NewDocIDQuery([]string{articleDocID(a.ID)}))
+
+	res, err := i.idx.Search(bleve.NewSearchRequestOptions(bq, limit, 0, false))
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]ArticleHit, 0, len(res.Hits))
+	for _, h := range res.Hits {
+		id, err := hitArticleID(h.ID)
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, ArticleHit{ArticleID: id, Score: h.Score})
+	}
+
+	return hits, nil
+}
+
+// topTerms returns up to n of the most frequent terms
+func topTerms(freqs map[string]int, n int) []string {
+	terms := make([]string, 0, len(freqs))
+	for t := range freqs {
+		terms = append(terms, t)
+	}
+
+	sort.Slice(terms, func(i, j int) bool {
+		if freqs[terms[i]] != freqs[terms[j]] {
+			return freqs[terms[i]] > freqs[terms[j]]
+		}
+		return terms[i] < terms[j]
+	})
+
+	if len(terms) > n {
+		terms = terms[:n]
+	}
+	return terms
+}
+
+func hitArticleID(docID string) (uint, error) {
+	id, err := strconv.ParseUint(strings.TrimPrefix(docID, articlePrefix), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid document id %q: %w", docID, err)
+	}
+	return uint(id), nil
+}
+
+// parse turns the search text into queries of which all must match
+func parse(text string) []query.Query {
+	var qs []query.Query
+
+	for text = strings.TrimSpace(text); text != ""; text = strings.TrimSpace(text) {
+		// "quoted phrase"
+		if text[0] == '"' {
+			end := strings.IndexByte(text[1:], '"')
+			if end < 0 {
+				end = len(text) - 1
+			}
+			phrase := strings.TrimSpace(text[1 : end+1])
+			text = text[min(end+2, len(text)):]
+
+			if phrase != "" {
+				qs = append(qs, anyField(func() fieldQuery {
+					return bleve.NewMatchPhraseQuery(phrase)
+				}))
+			}
+			continue
+		}
+
+		end := strings.IndexAny(text, " \t\n\"")
+		if end < 0 {
+			end = len(text)
+		}
+		word := text[:end]
+		text = text[end:]
+
+		// prefix*
+		if prefix := strings.TrimRight(word, "*"); prefix != word {
+			if prefix != "" {
+				prefix = strings.ToLower(prefix)
+				qs = append(qs, anyField(func() fieldQuery {
+					return bleve.NewPrefixQuery(prefix)
+				}))
+			}
+			continue
+		}
+
+		qs = append(qs, anyField(func() fieldQuery {
+			return bleve.NewMatchQuery(word)
+		}))
+	}
+
+	return qs
+}
+
+// fieldQuery is a query which can be limited to a field and boosted
+type fieldQuery interface {
+	query.Query
+	query.FieldableQuery
+	query.BoostableQuery
+}
+
+// anyField matches the query on any of the full-text fields of articles
+func anyField(newQuery func() fieldQuery) query.Query {
+	var qs []query.Query
+	for _, f := range []string{"title", "tags", "description", "body"} {
+		q := newQuery()
+		q.SetField(f)
+		q.SetBoost(boosts[f])
+		qs = append(qs, q)
+	}
+	return bleve.NewDisjunctionQuery(qs...)
+}
@@ -0,0 +1,162 @@
+// Package search provides an embedded full-text index of articles and users
+package search
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/custom"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/keyword"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/standard"
+	"github.com/blevesearch/bleve/v2/analysis/token/lowercase"
+	"github.com/blevesearch/bleve/v2/analysis/tokenizer/single"
+	"github.com/blevesearch/bleve/v2/mapping"
+)
+
+// mappingVersion is increased when the mapping changes.
+// Indexes of other versions are rebuilt from the database.
+const mappingVersion = "2"
+
+// usernameAnalyzer indexes a username as one lowercased term
+const usernameAnalyzer = "username"
+
+var (
+	// syncedUntilKey stores the time the index was last synchronized with the database
+	syncedUntilKey = []byte("synced_until")
+
+	// mappingVersionKey stores the mappingVersion the index was created with
+	mappingVersionKey = []byte("mapping_version")
+)
+
+// Index is a full-text index of listed articles and users
+type Index struct {
+	idx bleve.Index
+}
+
+// Open opens the index at path, creating it if it doesn't exist.
+// An empty path opens an index in memory.
+func Open(path string) (*Index, error) {
+	if path == "" {
+		return create(path)
+	}
+
+	idx, err := bleve.Open(path)
+	if errors.Is(err, bleve.ErrorIndexPathDoesNotExist) {
+		return create(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := idx.GetInternal(mappingVersionKey)
+	if err != nil {
+		idx.Close()
+		return nil, err
+	}
+
+	if string(v) != mappingVersion {
+		idx.Close()
+		if err := os.RemoveAll(path); err != nil {
+			return nil, err
+		}
+		return create(path)
+	}
+
+	return &Index{idx: idx}, nil
+}
+
+// create creates an empty index, which the sync fills from the beginning
+func create(path string) (*Index, error) {
+	m, err := newMapping()
+	if err != nil {
+		return nil, err
+	}
+
+	var idx bleve.Index
+	if path == "" {
+		idx, err = bleve.NewMemOnly(m)
+	} else {
+		idx, err = bleve.New(path, m)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := idx.SetInternal(mappingVersionKey, []byte(mappingVersion)); err != nil {
+		idx.Close()
+		return nil, err
+	}
+
+	return &Index{idx: idx}, nil
+}
+
+func newMapping() (mapping.IndexMapping, error) {
+	m := bleve.NewIndexMapping()
+	m.DefaultMapping = bleve.NewDocumentDisabledMapping()
+	m.DefaultAnalyzer = standard.Name
+
+	err := m.AddCustomAnalyzer(usernameAnalyzer, map[string]interface{}{
+		"type":          custom.Name,
+		"tokenizer":     single.Name,
+		"token_filters": []string{lowercase.Name},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m.AddDocumentMapping(articleType, articleMapping())
+	m.AddDocumentMapping(userType, userMapping())
+
+	return m, nil
+}
+
+// textField is a full-text field which can be highlighted
+func textField() *mapping.FieldMapping {
+	f := bleve.NewTextFieldMapping()
+	f.Analyzer = standard.Name
+	f.Store = true
+	f.IncludeTermVectors = true
+	return f
+}
+
+// keywordField is an exact value to filter by
+func keywordField() *mapping.FieldMapping {
+	f := bleve.NewTextFieldMapping()
+	f.Analyzer = keyword.Name
+	f.Store = false
+	f.IncludeInAll = false
+	return f
+}
+
+// Close closes the index
+func (i *Index) Close() error {
+	return i.idx.Close()
+}
+
+// SyncedUntil returns the time the index was last synchronized with the database
+func (i *Index) SyncedUntil() (time.Time, error) {
+	b, err := i.idx.GetInternal(syncedUntilKey)
+	if err != nil || len(b) == 0 {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339Nano, string(b))
+}
+
+// SetSyncedUntil records the time the index was synchronized with the database
+func (i *Index) SetSyncedUntil(t time.Time) error {
+	return i.idx.SetInternal(syncedUntilKey, []byte(t.Format(time.RFC3339Nano)))
+}
+
+func formatID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
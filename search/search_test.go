@@ -0,0 +1,231 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func newArticle(id, userID uint, username, title, body string, tags ...string) *model.Article {
+	a := &model.Article{
+		Model:  gorm.Model{ID: id},
+		Title:  title,
+		Body:   body,
+		UserID: userID,
+		Author: model.User{Username: username},
+		Status: model.ArticlePublished,
+	}
+	for _, t := range tags {
+		a.Tags = append(a.Tags, model.Tag{Name: t})
+	}
+	a.RenderBody()
+	return a
+}
+
+func TestSearchArticles(t *testing.T) {
+	idx, err := Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+
+	articles := []*model.Article{
+		newArticle(1, 1, "foo", "Learning Go", "Go is a **programming language** made at Google.", "go"),
+		newArticle(2, 1, "foo", "Cooking pasta", "Boil water and add the pasta. Go slowly.", "food"),
+		newArticle(3, 2, "bar", "Programming in Rust", "Rust is a language empowering everyone.", "rust"),
+		newArticle(4, 2, "bar", "Language models", "A note on programming language design.", "go"),
+	}
+	for _, a := range articles {
+		if err := idx.UpdateArticle(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		title    string
+		query    ArticleQuery
+		expected []uint
+		hasError bool
+	}{
+		{"word", ArticleQuery{Text: "pasta"}, []uint{2}, false},
+		{"all words must match", ArticleQuery{Text: "language rust"}, []uint{3}, false},
+		{"title is ranked first", ArticleQuery{Text: "rust"}, []uint{3}, false},
+		{"phrase", ArticleQuery{Text: `"programming language"`}, []uint{1, 4}, false},
+		{"prefix", ArticleQuery{Text: "progr*"}, []uint{3, 1, 4}, false},
+		{"tag", ArticleQuery{Text: "language", Tag: "go"}, []uint{1, 4}, false},
+		{"author", ArticleQuery{Text: "language", Author: "bar"}, []uint{3, 4}, false},
+		{"article ids", ArticleQuery{Text: "language", ArticleIDs: []uint{1}}, []uint{1}, false},
+		{"no article ids", ArticleQuery{Text: "language", ArticleIDs: []uint{}}, []uint{}, false},
+		{"excluded author", ArticleQuery{Text: "language", ExcludedAuthors: []uint{2}}, []uint{1}, false},
+		{"empty query", ArticleQuery{Text: "  "}, nil, true},
+	}
+
+	for _, tt := range tests {
+		tt.query.Limit = 10
+		res, err := idx.SearchArticles(tt.query)
+		if tt.hasError {
+			if err == nil {
+				t.Errorf("%q expected to fail, but succeeded.", tt.title)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%q expected to succeed, but failed. %v", tt.title, err)
+			continue
+		}
+
+		ids := make([]uint, 0, len(res.Hits))
+		for _, h := range res.Hits {
+			ids = append(ids, h.ArticleID)
+		}
+		assert.ElementsMatch(t, tt.expected, ids, tt.title)
+		assert.Equal(t, len(tt.expected), res.Total, tt.title)
+	}
+
+	// ranking and snippets
+	res, err := idx.SearchArticles(ArticleQuery{Text: "programming", Limit: 10})
+	if assert.NoError(t, err) && assert.Len(t, res.Hits, 3) {
+		assert.Equal(t, uint(3), res.Hits[0].ArticleID, "match in title ranks first")
+		assert.Contains(t, res.Hits[0].Snippets[0], "<mark>Programming</mark>")
+	}
+
+	// unlisted articles are removed
+	articles[1].Status = model.ArticleDraft
+	assert.NoError(t, idx.UpdateArticle(articles[1]))
+	res, err = idx.SearchArticles(ArticleQuery{Text: "pasta", Limit: 10})
+	if assert.NoError(t, err) {
+		assert.Len(t, res.Hits, 0)
+	}
+
+	now := time.Now().Truncate(time.Second)
+	assert.NoError(t, idx.SetSyncedUntil(now))
+	synced, err := idx.SyncedUntil()
+	if assert.NoError(t, err) {
+		assert.True(t, now.Equal(synced))
+	}
+}
+
+func TestSimilarArticles(t *testing.T) {
+	idx, err := Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+
+	articles := []*model.Article{
+		newArticle(1, 1, "foo", "Learning Go", "Go is a programming language. Go has goroutines and channels.", "go"),
+		newArticle(2, 1, "foo", "Cooking pasta", "Boil water and add the pasta.", "food"),
+		newArticle(3, 2, "bar", "Goroutines in Go", "Goroutines and channels make concurrent Go programs simple.", "go"),
+		newArticle(4, 2, "bar", "Programming in Rust", "Rust is a programming language.", "rust"),
+	}
+	for _, a := range articles {
+		if err := idx.UpdateArticle(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	hits, err := idx.SimilarArticles(articles[0], 10)
+	if assert.NoError(t, err) && assert.Len(t, hits, 2) {
+		assert.Equal(t, uint(3), hits[0].ArticleID, "most terms in common")
+		assert.Equal(t, uint(4), hits[1].ArticleID)
+		assert.Greater(t, hits[0].Score, hits[1].Score)
+	}
+
+	hits, err = idx.SimilarArticles(articles[1], 10)
+	if assert.NoError(t, err) {
+		assert.Len(t, hits, 0, "nothing in common")
+	}
+
+	// stop words alone find nothing
+	hits, err = idx.SimilarArticles(newArticle(5, 1, "foo", "The", "and a is"), 10)
+	if assert.NoError(t, err) {
+		assert.Len(t, hits, 0)
+	}
+}
+
+func TestSearchUsers(t *testing.T) {
+	idx, err := Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+
+	now := time.Now()
+	later := now.Add(time.Hour)
+	earlier := now.Add(-time.Hour)
+
+	users := []*model.User{
+		{Model: gorm.Model{ID: 1}, Username: "alice", Bio: "Gopher and gardener", Status: model.StatusActive},
+		{Model: gorm.Model{ID: 2}, Username: "alicia", Bio: "Writes about Rust", Status: model.StatusActive},
+		{Model: gorm.Model{ID: 3}, Username: "Bob", Bio: "I like posts by alice", Status: model.StatusActive},
+		{Model: gorm.Model{ID: 4}, Username: "alina", Status: model.StatusSuspended, SuspendedUntil: &later},
+		{Model: gorm.Model{ID: 5}, Username: "alibaba", Status: model.StatusSuspended, SuspendedUntil: &earlier},
+		{Model: gorm.Model{ID: 6}, Username: "alien", Status: model.StatusBanned},
+		{Model: gorm.Model{ID: 7}, Username: "alfred", Status: model.StatusSuspended},
+	}
+	for _, u := range users {
+		if err := idx.UpdateUser(u); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		title    string
+		query    UserQuery
+		expected []uint
+		first    uint
+		hasError bool
+	}{
+		{"exact username ranks first", UserQuery{Text: "alice"}, []uint{1, 2, 3}, 1, false},
+		{"case insensitive", UserQuery{Text: "BOB"}, []uint{3}, 3, false},
+		{"typo", UserQuery{Text: "alcia"}, []uint{2}, 2, false},
+		{"bio", UserQuery{Text: "rust"}, []uint{2}, 2, false},
+		{"autocomplete", UserQuery{Text: "ali", Autocomplete: true}, []uint{1, 2, 5}, 5, false},
+		{"autocomplete ignores bio", UserQuery{Text: "gopher", Autocomplete: true}, []uint{}, 0, false},
+		{"excluded users", UserQuery{Text: "ali", Autocomplete: true, ExcludedUsers: []uint{5}}, []uint{1, 2}, 1, false},
+		{"empty query", UserQuery{Text: " "}, nil, 0, true},
+	}
+
+	for _, tt := range tests {
+		tt.query.Now = now
+		tt.query.Limit = 10
+		res, err := idx.SearchUsers(tt.query)
+		if tt.hasError {
+			if err == nil {
+				t.Errorf("%q expected to fail, but succeeded.", tt.title)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%q expected to succeed, but failed. %v", tt.title, err)
+			continue
+		}
+
+		ids := make([]uint, 0, len(res.Hits))
+		for _, h := range res.Hits {
+			ids = append(ids, h.UserID)
+		}
+		assert.ElementsMatch(t, tt.expected, ids, tt.title)
+		if len(ids) > 0 {
+			assert.Equal(t, tt.first, ids[0], tt.title)
+		}
+	}
+
+	res, err := idx.SearchUsers(UserQuery{Text: "bo", Autocomplete: true, Now: now, Limit: 5})
+	if assert.NoError(t, err) && assert.Len(t, res.Hits, 1) {
+		assert.Equal(t, "Bob", res.Hits[0].Username)
+	}
+
+	// banned users are removed
+	users[0].Status = model.StatusBanned
+	assert.NoError(t, idx.UpdateUser(users[0]))
+	res, err = idx.SearchUsers(UserQuery{Text: "alice", Autocomplete: true, Now: now, Limit: 5})
+	if assert.NoError(t, err) {
+		assert.Len(t, res.Hits, 0)
+	}
+}
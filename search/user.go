@@ -0,0 +1,165 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search/query"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	"github.com/raahii/golang-grpc-realworld-example/policy"
+)
+
+const (
+	userType   = "user"
+	userPrefix = userType + "/"
+)
+
+// userDocument is a user in the index
+type userDocument struct {
+	Username       string     `json:"username"`
+	Bio            string     `json:"bio"`
+	SuspendedUntil *time.Time `json:"suspended_until"`
+}
+
+// Type implements mapping.Classifier
+func (userDocument) Type() string {
+	return userType
+}
+
+func userMapping() *mapping.DocumentMapping {
+	username := bleve.NewTextFieldMapping()
+	username.Analyzer = usernameAnalyzer
+	username.Store = true
+
+	doc := bleve.NewDocumentStaticMapping()
+	doc.AddFieldMappingsAt("username", username)
+	doc.AddFieldMappingsAt("bio", textField())
+	doc.AddFieldMappingsAt("suspended_until", bleve.NewDateTimeFieldMapping())
+	return doc
+}
+
+func userDocID(id uint) string {
+	return userPrefix + formatID(id)
+}
+
+// UpdateUser indexes the user. Banned and indefinitely suspended users are removed from the index.
+func (i *Index) UpdateUser(u *model.User) error {
+	if policy.IsBanned(u) || (u.Status == model.StatusSuspended && u.SuspendedUntil == nil) {
+		return i.idx.Delete(userDocID(u.ID))
+	}
+
+	doc := userDocument{Username: u.Username, Bio: u.Bio}
+	if u.Status == model.StatusSuspended {
+		doc.SuspendedUntil = u.SuspendedUntil
+	}
+
+	return i.idx.Index(userDocID(u.ID), doc)
+}
+
+// UserQuery is a search request for users
+type UserQuery struct {
+	Text string
+
+	// Autocomplete matches username prefixes only
+	Autocomplete bool
+
+	ExcludedUsers []uint
+	Now           time.Time
+	Limit         int
+	Offset        int
+}
+
+// UserHit is a matched user
+type UserHit struct {
+	UserID   uint
+	Username string
+	Score    float64
+}
+
+// UserResult is the result of a user search
+type UserResult struct {
+	Hits  []UserHit
+	Total int
+}
+
+// SearchUsers searches users by username and bio. Exact usernames rank first,
+// then username prefixes, usernames within a few typos and matches in bio.
+// Users suspended at q.Now are left out.
+func (i *Index) SearchUsers(q UserQuery) (*UserResult, error) {
+	text := strings.ToLower(strings.TrimSpace(q.Text))
+	if text == "" {
+		return nil, errors.New("empty query")
+	}
+
+	exact := bleve.NewTermQuery(text)
+	exact.SetField("username")
+	exact.SetBoost(10)
+
+	prefix := bleve.NewPrefixQuery(text)
+	prefix.SetField("username")
+	prefix.SetBoost(5)
+
+	qs := []query.Query{exact, prefix}
+	if !q.Autocomplete {
+		fuzzy := bleve.NewFuzzyQuery(text)
+		fuzzy.SetField("username")
+		fuzzy.SetFuzziness(fuzziness(text))
+		fuzzy.SetBoost(2)
+
+		bio := bleve.NewMatchQuery(q.Text)
+		bio.SetField("bio")
+		bio.SetFuzziness(1)
+
+		qs = append(qs, fuzzy, bio)
+	}
+
+	bq := bleve.NewBooleanQuery()
+	bq.AddMust(bleve.NewDisjunctionQuery(qs...))
+
+	if len(q.ExcludedUsers) > 0 {
+		ids := make([]string, 0, len(q.ExcludedUsers))
+		for _, id := range q.ExcludedUsers {
+			ids = append(ids, userDocID(id))
+		}
+		bq.AddMustNot(bleve.NewDocIDQuery(ids))
+	}
+
+	suspended := bleve.NewDateRangeQuery(q.Now, time.Time{})
+	suspended.SetField("suspended_until")
+	bq.AddMustNot(suspended)
+
+	req := bleve.NewSearchRequestOptions(bq, q.Limit, q.Offset, false)
+	req.Fields = []string{"username"}
+	req.SortBy([]string{"-_score", "username"})
+
+	res, err := i.idx.Search(req)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]UserHit, 0, len(res.Hits))
+	for _, h := range res.Hits {
+		id, err := strconv.ParseUint(strings.TrimPrefix(h.ID, userPrefix), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid document id %q: %w", h.ID, err)
+		}
+
+		username, _ := h.Fields["username"].(string)
+		hits = append(hits, UserHit{UserID: uint(id), Username: username, Score: h.Score})
+	}
+
+	return &UserResult{Hits: hits, Total: int(res.Total)}, nil
+}
+
+// fuzziness allows more typos in longer words
+func fuzziness(s string) int {
+	if len([]rune(s)) <= 4 {
+		return 1
+	}
+	return 2
+}
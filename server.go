@@ -1,16 +1,23 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"os"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	grpc_recovery "github.com/grpc-ecosystem/go-grpc-middleware/recovery"
+	"github.com/raahii/golang-grpc-realworld-example/auth"
 	"github.com/raahii/golang-grpc-realworld-example/db"
 	"github.com/raahii/golang-grpc-realworld-example/handler"
+	"github.com/raahii/golang-grpc-realworld-example/job"
+	"github.com/raahii/golang-grpc-realworld-example/mail"
+	"github.com/raahii/golang-grpc-realworld-example/oidc"
 	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+	"github.com/raahii/golang-grpc-realworld-example/search"
 	"github.com/raahii/golang-grpc-realworld-example/store"
 	"github.com/rs/zerolog"
 	"google.golang.org/grpc"
@@ -18,6 +25,33 @@ import (
 
 const (
 	port = ":50051"
+
+	// publishInterval is how often scheduled articles are checked
+	publishInterval = 30 * time.Second
+
+	// indexSyncInterval is how often the search index picks up changed articles
+	indexSyncInterval = 30 * time.Second
+
+	// webhookInterval is how often due webhook deliveries are sent
+	webhookInterval = 10 * time.Second
+
+	// outboxPruneInterval is how often old dispatched events are deleted
+	outboxPruneInterval = time.Hour
+
+	// defaultSearchIndexPath is where the search index is kept unless $SEARCH_INDEX_PATH is set
+	defaultSearchIndexPath = "search.bleve"
+
+	// defaultMailDir is where emails are written unless $MAIL_DIR is set
+	defaultMailDir = "mail"
+
+	// digestInterval is how often due digests are sent
+	digestInterval = 15 * time.Minute
+
+	// viewFlushInterval is how often buffered article views are written
+	viewFlushInterval = 10 * time.Second
+
+	// trendingInterval is how often trending scores of articles are recomputed
+	trendingInterval = 10 * time.Minute
 )
 
 func main() {
@@ -40,8 +74,49 @@ func main() {
 
 	us := store.NewUserStore(d)
 	as := store.NewArticleStore(d)
+	ob := store.NewOutboxStore(d)
+	auth.SetAPITokenVerifier(us)
 
-	h := handler.New(&l, us, as)
+	idp := oidc.NewProviderFromEnv()
+	if idp == nil {
+		l.Info().Msg("$OIDC_ISSUER is not set, external login is disabled")
+	}
+
+	indexPath := os.Getenv("SEARCH_INDEX_PATH")
+	if indexPath == "" {
+		indexPath = defaultSearchIndexPath
+	}
+	idx, err := search.Open(indexPath)
+	if err != nil {
+		l.Fatal().Err(err).Msg("failed to open the search index")
+	}
+	defer idx.Close()
+
+	mailDir := os.Getenv("MAIL_DIR")
+	if mailDir == "" {
+		mailDir = defaultMailDir
+	}
+	mailer, err := mail.NewFileMailer(mailDir)
+	if err != nil {
+		l.Fatal().Err(err).Msg("failed to create the mail directory")
+	}
+
+	h := handler.New(&l, us, as, ob, idp, idx, mailer)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	jobs := job.NewRunner(&l)
+	jobs.Add("publish scheduled articles", publishInterval, h.PublishScheduledArticles)
+	jobs.Add("sync search index", indexSyncInterval, h.SyncSearchIndex)
+	jobs.Add("deliver webhooks", webhookInterval, h.DeliverWebhooks)
+	jobs.Add("prune outbox", outboxPruneInterval, h.PruneOutbox)
+	jobs.Add("send digests", digestInterval, h.SendDigests)
+	jobs.Add("flush article views", viewFlushInterval, h.FlushArticleViews)
+	jobs.Add("rank trending articles", trendingInterval, h.RankTrendingArticles)
+	jobs.Start(ctx)
+
+	go h.RelayEvents(ctx)
 
 	lis, err := net.Listen("tcp", port)
 	if err != nil {
@@ -52,9 +127,17 @@ func main() {
 		grpc_middleware.WithUnaryServerChain(
 			grpc_recovery.UnaryServerInterceptor(),
 		),
+		grpc_middleware.WithStreamServerChain(
+			grpc_recovery.StreamServerInterceptor(),
+		),
 	)
 	pb.RegisterUsersServer(s, h)
 	pb.RegisterArticlesServer(s, h)
+	pb.RegisterAdminServer(s, h)
+	pb.RegisterNotificationsServer(s, h)
+	pb.RegisterEventsServer(s, h)
+	pb.RegisterWebhooksServer(s, h)
+	pb.RegisterReadingListsServer(s, h)
 	l.Info().Str("port", port).Msg("starting server")
 	if err := s.Serve(lis); err != nil {
 		l.Panic().Err(fmt.Errorf("failed to serve: %w", err))
@@ -1,8 +1,12 @@
 package store
 
 import (
+	"time"
+
 	"github.com/jinzhu/gorm"
+	"github.com/raahii/golang-grpc-realworld-example/markdown"
 	"github.com/raahii/golang-grpc-realworld-example/model"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
 )
 
 // ArticleStore is data access struct for user
@@ -27,22 +31,149 @@ func (s *ArticleStore) GetByID(id uint) (*model.Article, error) {
 	return &m, nil
 }
 
-// Create creates an article
+// Create creates an article and its first revision
 func (s *ArticleStore) Create(m *model.Article) error {
-	return s.db.Create(&m).Error
+	m.RenderBody()
+
+	// status is left blank for the default of published
+	if m.PublishedAt == nil && (m.Status == "" || m.Status == model.ArticlePublished) {
+		now := time.Now()
+		m.PublishedAt = &now
+	}
+
+	tx := s.db.Begin()
+
+	if err := tx.Create(m).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	rev := model.NewArticleRevision(m, m.UserID)
+	rev.Number = 1
+	if err := tx.Create(&rev).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if m.IsListed() {
+		if err := addArticleEvent(tx, model.EventArticlePublished, m.UserID, m); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// addArticleEvent records a domain event about the article by actorID in tx
+func addArticleEvent(tx *gorm.DB, typ string, actorID uint, m *model.Article) error {
+	return addEvent(tx, typ, actorID, func(e *pb.DomainEvent) {
+		e.ArticleId = uint64(m.ID)
+		e.UserId = uint64(m.UserID)
+	})
 }
 func Create(m *model.Article) string {
 	return "just for testing"
 }
 
-// Update updates an article
-func (s *ArticleStore) Update(m *model.Article) error {
-	return s.db.Model(&m).Update(&m).Error
+// Update saves title, description and body of an article
+// and records the new content as a revision by editorID
+func (s *ArticleStore) Update(m *model.Article, editorID uint) error {
+	tx := s.db.Begin()
+
+	// lock the article so that concurrent updates are numbered in order
+	var old model.Article
+	err := tx.Set("gorm:query_option", "FOR UPDATE").First(&old, m.ID).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var n int32
+	err = tx.Model(&model.ArticleRevision{}).
+		Where("article_id = ?", m.ID).
+		Select("COALESCE(MAX(number), 0)").
+		Row().Scan(&n)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// articles written before revisions were recorded keep their original content first
+	if n == 0 {
+		base := model.NewArticleRevision(&old, old.UserID)
+		base.Number = 1
+		base.CreatedAt = old.UpdatedAt
+		if err := tx.Create(&base).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+		n = 1
+	}
+
+	// a map updates blank fields as well, which restored revisions may have
+	m.RenderBody()
+	err = tx.Model(m).Updates(map[string]interface{}{
+		"title":       m.Title,
+		"description": m.Description,
+		"body":        m.Body,
+		"body_html":   m.BodyHTML,
+	}).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	rev := model.NewArticleRevision(m, editorID)
+	rev.Number = n + 1
+	if err := tx.Create(&rev).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if m.IsListed() {
+		if err := addArticleEvent(tx, model.EventArticleUpdated, editorID, m); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// GetArticleRevisions returns revisions of an article, latest first
+func (s *ArticleStore) GetArticleRevisions(m *model.Article) ([]model.ArticleRevision, error) {
+	var rs []model.ArticleRevision
+	err := s.db.Preload("Editor").
+		Where("article_id = ?", m.ID).
+		Order("number desc").
+		Find(&rs).Error
+
+	return rs, err
+}
+
+// GetArticleRevision finds a revision of an article by its number
+func (s *ArticleStore) GetArticleRevision(m *model.Article, number int32) (*model.ArticleRevision, error) {
+	var r model.ArticleRevision
+	err := s.db.Preload("Editor").
+		Where("article_id = ? AND number = ?", m.ID, number).
+		First(&r).Error
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
 }
 
-// GetArticles get global articles
-func (s *ArticleStore) GetArticles(tagName, username string, favoritedBy *model.User, limit, offset int64) ([]model.Article, error) {
-	d := s.db.Preload("Author")
+// GetArticles get global articles except for the ones written by mutedUserIDs,
+// in the order of sort, which is one of model.Sort*, or unordered when empty
+func (s *ArticleStore) GetArticles(tagName, username string, favoritedBy *model.User, mutedUserIDs []uint, sort string, limit, offset int64) ([]model.Article, error) {
+	d := s.db.Preload("Author").
+		Where("articles.status = ? AND articles.hidden = ?", model.ArticlePublished, false)
+
+	// muted authors query
+	if len(mutedUserIDs) > 0 {
+		d = d.Where("articles.user_id not in (?)", mutedUserIDs)
+	}
 
 	// author query (has one)
 	if username != "" {
@@ -78,6 +209,20 @@ func (s *ArticleStore) GetArticles(tagName, username string, favoritedBy *model.
 		d = d.Where("id in (?)", ids)
 	}
 
+	switch sort {
+	case model.SortNewest:
+		d = d.Order("articles.published_at desc, articles.id desc")
+	case model.SortFavorited:
+		d = d.Order("articles.favorites_count desc, articles.id desc")
+	case model.SortCommented:
+		d = d.Order("(SELECT COUNT(*) FROM comments WHERE comments.article_id = articles.id " +
+			"AND comments.hidden = false AND comments.deleted_at IS NULL) desc, articles.id desc")
+	case model.SortTrending:
+		d = d.Select("articles.*").
+			Joins("left join article_trends on article_trends.article_id = articles.id").
+			Order("coalesce(article_trends.score, 0) desc, articles.id desc")
+	}
+
 	// offset query, limit query
 	d = d.Offset(offset).Limit(limit)
 
@@ -87,10 +232,16 @@ func (s *ArticleStore) GetArticles(tagName, username string, favoritedBy *model.
 	return as, err
 }
 
+// feed narrows a query down to listed articles of the users
+func (s *ArticleStore) feed(userIDs []uint) *gorm.DB {
+	return s.db.Preload("Author").
+		Where("user_id in (?)", userIDs).
+		Where("status = ? AND hidden = ?", model.ArticlePublished, false)
+}
+
 // GetFeedArticles returns following users' articles
 func (s *ArticleStore) GetFeedArticles(userIDs []uint, limit, offset int64) ([]model.Article, error) {
-	d := s.db.Preload("Author").
-		Where("user_id in (?)", userIDs)
+	d := s.feed(userIDs)
 
 	// offset query, limit query
 	d = d.Offset(offset).Limit(limit)
@@ -101,6 +252,182 @@ func (s *ArticleStore) GetFeedArticles(userIDs []uint, limit, offset int64) ([]m
 	return as, err
 }
 
+// GetTopFeedArticlesSince returns up to limit following users' articles
+// published after t, the most favorited first
+func (s *ArticleStore) GetTopFeedArticlesSince(userIDs []uint, t time.Time, limit int64) ([]model.Article, error) {
+	var as []model.Article
+	err := s.feed(userIDs).
+		Where("published_at > ?", t).
+		Order("favorites_count desc, id desc").
+		Limit(limit).
+		Find(&as).Error
+
+	return as, err
+}
+
+// GetListedArticlesByIDs returns listed articles of ids in no particular order
+func (s *ArticleStore) GetListedArticlesByIDs(ids []uint) ([]model.Article, error) {
+	var as []model.Article
+	err := s.db.Preload("Author").Preload("Tags").
+		Where("id in (?)", ids).
+		Where("status = ? AND hidden = ?", model.ArticlePublished, false).
+		Find(&as).Error
+
+	return as, err
+}
+
+// GetArticlesChangedSince returns articles updated or deleted at or after t,
+// including deleted ones, in batches of limit ordered by id after afterID
+func (s *ArticleStore) GetArticlesChangedSince(t time.Time, afterID uint, limit int) ([]model.Article, error) {
+	var as []model.Article
+	err := s.db.Unscoped().Preload("Author").Preload("Tags").
+		Where("updated_at >= ? OR deleted_at >= ?", t, t).
+		Where("id > ?", afterID).
+		Order("id").
+		Limit(limit).
+		Find(&as).Error
+
+	return as, err
+}
+
+// GetFavoriteArticleIDs returns ids of the articles favorited by u
+func (s *ArticleStore) GetFavoriteArticleIDs(u *model.User) ([]uint, error) {
+	ids := []uint{}
+	err := s.db.Table("favorite_articles").
+		Where("user_id = ?", u.ID).
+		Pluck("article_id", &ids).Error
+
+	return ids, err
+}
+
+// UpdateStatus changes the status of an article and cancels its scheduled
+// publication. Listing an article publishes it.
+func (s *ArticleStore) UpdateStatus(m *model.Article, status string) error {
+	fields := map[string]interface{}{
+		"status":     status,
+		"publish_at": nil,
+	}
+	// articles keep the time they were first published
+	publishedAt := m.PublishedAt
+	if status == model.ArticlePublished && publishedAt == nil {
+		now := time.Now()
+		publishedAt = &now
+		fields["published_at"] = now
+	}
+
+	tx := s.db.Begin()
+
+	err := tx.Model(m).Updates(fields).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	changed := *m
+	changed.Status = status
+	if !m.IsListed() && changed.IsListed() {
+		if err := addArticleEvent(tx, model.EventArticlePublished, m.UserID, m); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	m.Status = status
+	m.PublishAt = nil
+	m.PublishedAt = publishedAt
+	return nil
+}
+
+// SchedulePublish sets the time to publish a draft. nil cancels the schedule.
+func (s *ArticleStore) SchedulePublish(m *model.Article, t *time.Time) error {
+	var v interface{}
+	if t != nil {
+		v = *t
+	}
+
+	if err := s.db.Model(m).Update("publish_at", v).Error; err != nil {
+		return err
+	}
+
+	m.PublishAt = t
+	return nil
+}
+
+// PublishDueArticles publishes up to limit drafts scheduled at or before now.
+// Due rows are locked with SKIP LOCKED, so the scheduler can run on several
+// servers at once and each article is published by exactly one of them.
+func (s *ArticleStore) PublishDueArticles(now time.Time, limit int) ([]model.Article, error) {
+	tx := s.db.Begin()
+
+	var as []model.Article
+	err := tx.Set("gorm:query_option", "FOR UPDATE SKIP LOCKED").
+		Where("status = ? AND publish_at <= ?", model.ArticleDraft, now).
+		Order("publish_at").
+		Limit(limit).
+		Find(&as).Error
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if len(as) == 0 {
+		return as, tx.Commit().Error
+	}
+
+	ids := make([]uint, 0, len(as))
+	for _, a := range as {
+		ids = append(ids, a.ID)
+	}
+
+	err = tx.Model(&model.Article{}).
+		Where("id in (?)", ids).
+		Updates(map[string]interface{}{
+			"status":       model.ArticlePublished,
+			"publish_at":   nil,
+			"published_at": now,
+		}).Error
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	for i := range as {
+		as[i].Status = model.ArticlePublished
+		as[i].PublishAt = nil
+		as[i].PublishedAt = &now
+
+		if !as[i].IsListed() {
+			continue
+		}
+		if err := addArticleEvent(tx, model.EventArticlePublished, as[i].UserID, &as[i]); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return as, nil
+}
+
+// GetDrafts returns drafts of the user, recently updated first
+func (s *ArticleStore) GetDrafts(u *model.User, limit, offset int64) ([]model.Article, error) {
+	var as []model.Article
+	err := s.db.Preload("Author").Preload("Tags").
+		Where("user_id = ? AND status = ?", u.ID, model.ArticleDraft).
+		Order("updated_at desc").
+		Offset(offset).Limit(limit).
+		Find(&as).Error
+
+	return as, err
+}
+
 // Delete deletes an article
 func (s *ArticleStore) Delete(m *model.Article) error {
 	return s.db.Delete(m).Error
@@ -123,13 +450,13 @@ func (s *ArticleStore) IsFavorited(a *model.Article, u *model.User) (bool, error
 	return count > 0, nil
 }
 
-// AddFavorite favorite an article
+// AddFavorite favorite an article. It does nothing when the article is
+// already favorited by the user.
 func (s *ArticleStore) AddFavorite(a *model.Article, u *model.User) error {
 	tx := s.db.Begin()
 
-	err := tx.Model(a).Association("FavoritedUsers").
-		Append(u).Error
-	if err != nil {
+	added, err := favorite(tx, a, u)
+	if err != nil || !added {
 		tx.Rollback()
 		return err
 	}
@@ -141,19 +468,31 @@ func (s *ArticleStore) AddFavorite(a *model.Article, u *model.User) error {
 		return err
 	}
 
-	tx.Commit()
+	if err := addDailyFavorites(tx, a.ID, 1); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := addArticleEvent(tx, model.EventArticleFavorited, u.ID, a); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
 	a.FavoritesCount++
 
 	return nil
 }
 
-// DeleteFavorite unfavorite an article
+// DeleteFavorite unfavorite an article. It does nothing when the article is
+// not favorited by the user.
 func (s *ArticleStore) DeleteFavorite(a *model.Article, u *model.User) error {
 	tx := s.db.Begin()
 
-	err := tx.Model(a).Association("FavoritedUsers").
-		Delete(u).Error
-	if err != nil {
+	deleted, err := unfavorite(tx, a, u)
+	if err != nil || !deleted {
 		tx.Rollback()
 		return err
 	}
@@ -165,38 +504,172 @@ func (s *ArticleStore) DeleteFavorite(a *model.Article, u *model.User) error {
 		return err
 	}
 
-	tx.Commit()
+	if err := addDailyFavorites(tx, a.ID, -1); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
 	a.FavoritesCount--
 
 	return nil
 }
 
-// GetTags creates a article tag
+// favorite adds the article to the favorites of the user in tx. It returns
+// false when the user already favorited the article.
+func favorite(tx *gorm.DB, a *model.Article, u *model.User) (bool, error) {
+	var count int
+	err := tx.Table("favorite_articles").
+		Where("article_id = ? AND user_id = ?", a.ID, u.ID).
+		Count(&count).Error
+	if err != nil || count > 0 {
+		return false, err
+	}
+
+	err = tx.Model(a).Association("FavoritedUsers").Append(u).Error
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// unfavorite deletes the article from the favorites of the user in tx. It
+// returns false when the user did not favorite the article.
+func unfavorite(tx *gorm.DB, a *model.Article, u *model.User) (bool, error) {
+	res := tx.Exec("DELETE FROM favorite_articles WHERE article_id = ? AND user_id = ?", a.ID, u.ID)
+	if res.Error != nil || res.RowsAffected == 0 {
+		return false, res.Error
+	}
+
+	return true, nil
+}
+
+// GetTags returns tags of published articles
 func (s *ArticleStore) GetTags() ([]model.Tag, error) {
+	published := s.db.Table("article_tags").
+		Select("article_tags.tag_id").
+		Joins("join articles on articles.id = article_tags.article_id").
+		Where("articles.status = ? AND articles.hidden = ? AND articles.deleted_at IS NULL",
+			model.ArticlePublished, false).
+		SubQuery()
+
 	var tags []model.Tag
-	if err := s.db.Find(&tags).Error; err != nil {
+	if err := s.db.Where("id in ?", published).Find(&tags).Error; err != nil {
 		return tags, err
 	}
 	return tags, nil
 }
 
+// GetTrendingTags returns up to limit tags of listed articles, ordered by the
+// sum of the trending scores of their articles
+func (s *ArticleStore) GetTrendingTags(limit int64) ([]model.Tag, error) {
+	var tags []model.Tag
+	err := s.db.Select("tags.*").
+		Joins("join article_tags on article_tags.tag_id = tags.id").
+		Joins("join articles on articles.id = article_tags.article_id").
+		Joins("join article_trends on article_trends.article_id = articles.id").
+		Where("articles.status = ? AND articles.hidden = ? AND articles.deleted_at IS NULL",
+			model.ArticlePublished, false).
+		Group("tags.id").
+		Order("sum(article_trends.score) desc, tags.id").
+		Limit(limit).
+		Find(&tags).Error
+
+	return tags, err
+}
+
 // CreateComment creates a comment of the article
 func (s *ArticleStore) CreateComment(m *model.Comment) error {
-	return s.db.Create(&m).Error
+	m.RenderBody()
+
+	tx := s.db.Begin()
+
+	if err := tx.Create(m).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err := addEvent(tx, model.EventCommentCreated, m.UserID, func(e *pb.DomainEvent) {
+		e.ArticleId = uint64(m.ArticleID)
+		e.CommentId = uint64(m.ID)
+	})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
 }
 
-// GetComments gets coments of the article
-func (s *ArticleStore) GetComments(m *model.Article) ([]model.Comment, error) {
+// comments narrows a query down to visible comments of the article
+// except for the ones written by mutedUserIDs
+func (s *ArticleStore) comments(m *model.Article, mutedUserIDs []uint) *gorm.DB {
+	d := s.db.Model(&model.Comment{}).
+		Where("article_id = ? AND hidden = ?", m.ID, false)
+
+	if len(mutedUserIDs) > 0 {
+		d = d.Where("user_id not in (?)", mutedUserIDs)
+	}
+
+	return d
+}
+
+// GetComments gets coments of the article except for the ones written by mutedUserIDs,
+// oldest first. Only comments after afterID are returned when it is not zero.
+func (s *ArticleStore) GetComments(m *model.Article, mutedUserIDs []uint, afterID uint, limit int64) ([]model.Comment, error) {
+	d := s.comments(m, mutedUserIDs).Preload("Author")
+
+	if afterID != 0 {
+		d = d.Where("id > ?", afterID)
+	}
+
 	var cs []model.Comment
-	err := s.db.Preload("Author").
-		Where("article_id = ?", m.ID).
-		Find(&cs).Error
+	err := d.Order("id").Limit(limit).Find(&cs).Error
 	if err != nil {
 		return cs, err
 	}
 	return cs, nil
 }
 
+// CountComments counts comments of the article except for the ones written by mutedUserIDs
+func (s *ArticleStore) CountComments(m *model.Article, mutedUserIDs []uint) (int, error) {
+	var count int
+	err := s.comments(m, mutedUserIDs).Count(&count).Error
+	return count, err
+}
+
+// GetReplyCounts counts replies to each of the comments except for the ones written by mutedUserIDs
+func (s *ArticleStore) GetReplyCounts(m *model.Article, mutedUserIDs []uint, ids []uint) (map[uint]int32, error) {
+	counts := map[uint]int32{}
+	if len(ids) == 0 {
+		return counts, nil
+	}
+
+	rows, err := s.comments(m, mutedUserIDs).
+		Select("parent_id, count(*)").
+		Where("parent_id in (?)", ids).
+		Group("parent_id").
+		Rows()
+	if err != nil {
+		return counts, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uint
+		var count int32
+		if err := rows.Scan(&id, &count); err != nil {
+			return counts, err
+		}
+		counts[id] = count
+	}
+
+	return counts, rows.Err()
+}
+
 // GetCommentByID finds an comment from id
 func (s *ArticleStore) GetCommentByID(id uint) (*model.Comment, error) {
 	var m model.Comment
@@ -207,7 +680,161 @@ func (s *ArticleStore) GetCommentByID(id uint) (*model.Comment, error) {
 	return &m, nil
 }
 
-// DeleteComment deletes an comment
+// UpdateComment replaces the body of a comment and keeps the previous one as a revision
+func (s *ArticleStore) UpdateComment(m *model.Comment, body string) error {
+	tx := s.db.Begin()
+
+	rev := model.CommentRevision{CommentID: m.ID, Body: m.Body}
+	if err := tx.Create(&rev).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	now := time.Now()
+	html := markdown.Render(body)
+	err := tx.Model(m).Updates(map[string]interface{}{
+		"body":      body,
+		"body_html": html,
+		"edited_at": now,
+	}).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	m.Body = body
+	m.BodyHTML = html
+	m.EditedAt = &now
+
+	return nil
+}
+
+// GetCommentRevisions returns previous versions of the comment, oldest first
+func (s *ArticleStore) GetCommentRevisions(m *model.Comment) ([]model.CommentRevision, error) {
+	var rs []model.CommentRevision
+	err := s.db.Where("comment_id = ?", m.ID).
+		Order("created_at, id").
+		Find(&rs).Error
+	return rs, err
+}
+
+// DeleteComment deletes an comment. A comment with replies is replaced
+// with a tombstone to keep the thread intact.
 func (s *ArticleStore) DeleteComment(m *model.Comment) error {
-	return s.db.Delete(m).Error
+	return deleteComment(s.db, m)
+}
+
+func deleteComment(d *gorm.DB, m *model.Comment) error {
+	var replies int
+	err := d.Model(&model.Comment{}).
+		Where("parent_id = ?", m.ID).
+		Count(&replies).Error
+	if err != nil {
+		return err
+	}
+
+	if replies == 0 {
+		return d.Delete(m).Error
+	}
+
+	html := markdown.Render(model.CommentTombstone)
+	err = d.Model(m).Updates(map[string]interface{}{
+		"body":      model.CommentTombstone,
+		"body_html": html,
+		"deleted":   true,
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	m.Body = model.CommentTombstone
+	m.BodyHTML = html
+	m.Deleted = true
+
+	return nil
+}
+
+// moderationQueue selects the articles and comments which are hidden or have
+// open reports, newest first
+const moderationQueue = `
+SELECT ? AS content_type, id, created_at FROM articles
+WHERE deleted_at IS NULL AND (hidden OR id IN (
+	SELECT article_id FROM reports
+	WHERE content_type = ? AND status = ? AND deleted_at IS NULL
+))
+UNION ALL
+SELECT ? AS content_type, id, created_at FROM comments
+WHERE deleted_at IS NULL AND (hidden OR id IN (
+	SELECT comment_id FROM reports
+	WHERE content_type = ? AND status = ? AND deleted_at IS NULL
+))
+ORDER BY created_at DESC, content_type DESC, id DESC
+LIMIT ? OFFSET ?`
+
+// GetModerationQueue returns articles and comments to be reviewed, those
+// hidden or with open reports, newest first
+func (s *ArticleStore) GetModerationQueue(limit, offset int64) ([]model.ModerationItem, error) {
+	var rows []struct {
+		ContentType string
+		ID          uint
+	}
+	err := s.db.Raw(moderationQueue,
+		model.ContentArticle, model.ContentArticle, model.ReportOpen,
+		model.ContentComment, model.ContentComment, model.ReportOpen,
+		limit, offset,
+	).Scan(&rows).Error
+	if err != nil {
+		return []model.ModerationItem{}, err
+	}
+
+	var articleIDs, commentIDs []uint
+	for _, r := range rows {
+		if r.ContentType == model.ContentComment {
+			commentIDs = append(commentIDs, r.ID)
+		} else {
+			articleIDs = append(articleIDs, r.ID)
+		}
+	}
+
+	articles := make(map[uint]*model.Article, len(articleIDs))
+	if len(articleIDs) > 0 {
+		var as []model.Article
+		err = s.db.Preload("Author").Where("id IN (?)", articleIDs).Find(&as).Error
+		if err != nil {
+			return []model.ModerationItem{}, err
+		}
+		for i := range as {
+			articles[as[i].ID] = &as[i]
+		}
+	}
+
+	comments := make(map[uint]*model.Comment, len(commentIDs))
+	if len(commentIDs) > 0 {
+		var cs []model.Comment
+		err = s.db.Preload("Author").Where("id IN (?)", commentIDs).Find(&cs).Error
+		if err != nil {
+			return []model.ModerationItem{}, err
+		}
+		for i := range cs {
+			comments[cs[i].ID] = &cs[i]
+		}
+	}
+
+	items := make([]model.ModerationItem, 0, len(rows))
+	for _, r := range rows {
+		// content removed in between is left out
+		if r.ContentType == model.ContentComment {
+			if c, ok := comments[r.ID]; ok {
+				items = append(items, model.ModerationItem{Comment: c})
+			}
+		} else if a, ok := articles[r.ID]; ok {
+			items = append(items, model.ModerationItem{Article: a})
+		}
+	}
+
+	return items, nil
 }
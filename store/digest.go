@@ -0,0 +1,59 @@
+package store
+
+import (
+	"time"
+
+	"github.com/raahii/golang-grpc-realworld-example/model"
+)
+
+// ClaimDigestRecipients returns up to limit users whose digest is due at now
+// and marks their digests sent, so that each digest is sent by one server at
+// most once. The returned users keep the time of their previous digest.
+func (s *UserStore) ClaimDigestRecipients(now time.Time, limit int) ([]model.User, error) {
+	tx := s.db.Begin()
+
+	day := now.Add(-24 * time.Hour)
+	week := now.Add(-7 * 24 * time.Hour)
+
+	var us []model.User
+	err := tx.Set("gorm:query_option", "FOR UPDATE SKIP LOCKED").
+		Where("status <> ?", model.StatusBanned).
+		Where("(digest_frequency = ? AND (digest_sent_at IS NULL OR digest_sent_at <= ?)) OR "+
+			"(digest_frequency = ? AND (digest_sent_at IS NULL OR digest_sent_at <= ?))",
+			model.DigestDaily, day, model.DigestWeekly, week).
+		Order("id").
+		Limit(limit).
+		Find(&us).Error
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if len(us) == 0 {
+		return us, tx.Commit().Error
+	}
+
+	ids := make([]uint, 0, len(us))
+	for _, u := range us {
+		ids = append(ids, u.ID)
+	}
+
+	err = tx.Model(&model.User{}).
+		Where("id in (?)", ids).
+		UpdateColumn("digest_sent_at", now).Error
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return us, tx.Commit().Error
+}
+
+// UpdateDigest saves the digest frequency of the user and when the current
+// digest period started
+func (s *UserStore) UpdateDigest(m *model.User) error {
+	return s.db.Model(m).Updates(map[string]interface{}{
+		"digest_frequency": m.DigestFrequency,
+		"digest_sent_at":   m.DigestSentAt,
+	}).Error
+}
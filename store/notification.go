@@ -0,0 +1,167 @@
+package store
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+)
+
+// notifications narrows a query down to the notifications of the user,
+// leaving out the ones about deleted articles
+func (s *UserStore) notifications(u *model.User) *gorm.DB {
+	return s.db.Model(&model.Notification{}).
+		Where("recipient_id = ?", u.ID).
+		Where("article_id = 0 OR article_id IN (?)",
+			s.db.Table("articles").Select("id").Where("deleted_at IS NULL").SubQuery())
+}
+
+// CreateNotification stores the notification. It is coalesced into the unread
+// notification of the same recipient, type and article if there is one, which
+// then counts one more actor unless n.ActorID was counted already.
+func (s *UserStore) CreateNotification(n *model.Notification) error {
+	tx := s.db.Begin()
+
+	var current model.Notification
+	err := tx.Set("gorm:query_option", "FOR UPDATE").
+		Where("recipient_id = ? AND type = ? AND article_id = ? AND read_at IS NULL",
+			n.RecipientID, n.Type, n.ArticleID).
+		Order("id desc").
+		First(&current).Error
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		tx.Rollback()
+		return err
+	}
+
+	if gorm.IsRecordNotFoundError(err) {
+		n.ActorsCount = 1
+		if err := tx.Create(n).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		err := tx.Create(&model.NotificationActor{NotificationID: n.ID, UserID: n.ActorID}).Error
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := addNotificationEvent(tx, n.ActorID, n.ID); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		return tx.Commit().Error
+	}
+
+	var count int
+	err = tx.Model(&model.NotificationActor{}).
+		Where("notification_id = ? AND user_id = ?", current.ID, n.ActorID).
+		Count(&count).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"actor_id":   n.ActorID,
+		"updated_at": now,
+	}
+	if n.CommentID != 0 {
+		updates["comment_id"] = n.CommentID
+	}
+
+	if count == 0 {
+		err := tx.Create(&model.NotificationActor{NotificationID: current.ID, UserID: n.ActorID}).Error
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		updates["actors_count"] = gorm.Expr("actors_count + ?", 1)
+	}
+
+	if err := tx.Model(&current).UpdateColumns(updates).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := addNotificationEvent(tx, n.ActorID, current.ID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	n.ID = current.ID
+	n.CreatedAt = current.CreatedAt
+	n.UpdatedAt = now
+	n.ActorsCount = current.ActorsCount
+	if count == 0 {
+		n.ActorsCount++
+	}
+
+	return nil
+}
+
+// addNotificationEvent records that the notification was created or updated in tx
+func addNotificationEvent(tx *gorm.DB, actorID, notificationID uint) error {
+	return addEvent(tx, model.EventNotificationCreated, actorID, func(e *pb.DomainEvent) {
+		e.NotificationId = uint64(notificationID)
+	})
+}
+
+// GetNotificationByID finds a notification with its actor and article
+func (s *UserStore) GetNotificationByID(id uint) (*model.Notification, error) {
+	var n model.Notification
+	err := s.db.Preload("Actor").Preload("Article").Find(&n, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// GetNotifications returns notifications of the user, the most recently updated first,
+// and the number of all matched notifications
+func (s *UserStore) GetNotifications(u *model.User, unreadOnly bool, limit, offset int64) ([]model.Notification, int, error) {
+	d := s.notifications(u)
+	if unreadOnly {
+		d = d.Where("read_at IS NULL")
+	}
+
+	var count int
+	if err := d.Count(&count).Error; err != nil {
+		return []model.Notification{}, 0, err
+	}
+
+	var ns []model.Notification
+	err := d.Preload("Actor").Preload("Article").
+		Order("updated_at desc, id desc").
+		Offset(offset).Limit(limit).
+		Find(&ns).Error
+
+	return ns, count, err
+}
+
+// CountUnreadNotifications returns the number of unread notifications of the user
+func (s *UserStore) CountUnreadNotifications(u *model.User) (int, error) {
+	var count int
+	err := s.notifications(u).Where("read_at IS NULL").Count(&count).Error
+	return count, err
+}
+
+// MarkNotificationsRead marks notifications of ids as read. nil ids marks all
+// notifications of the user. Reading does not change updated_at, so the order
+// of notifications is kept.
+func (s *UserStore) MarkNotificationsRead(u *model.User, ids []uint) error {
+	d := s.db.Model(&model.Notification{}).
+		Where("recipient_id = ? AND read_at IS NULL", u.ID)
+	if ids != nil {
+		d = d.Where("id IN (?)", ids)
+	}
+
+	return d.UpdateColumn("read_at", time.Now()).Error
+}
@@ -0,0 +1,139 @@
+package store
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
+)
+
+// OutboxStore is data access struct for domain events
+type OutboxStore struct {
+	db *gorm.DB
+}
+
+// NewOutboxStore returns a new OutboxStore
+func NewOutboxStore(db *gorm.DB) *OutboxStore {
+	return &OutboxStore{
+		db: db,
+	}
+}
+
+// addEvent records a domain event in tx, so that it is dispatched
+// if and only if the change it describes is committed
+func addEvent(tx *gorm.DB, typ string, actorID uint, fill func(e *pb.DomainEvent)) error {
+	e, err := model.NewOutboxEvent(typ, actorID, fill)
+	if err != nil {
+		return err
+	}
+	return tx.Create(e).Error
+}
+
+// Claim returns up to limit events due at now, the oldest first. They are
+// leased until now+lease, so that the other servers don't dispatch them
+// meanwhile; rows are locked with SKIP LOCKED while claiming.
+func (s *OutboxStore) Claim(now time.Time, lease time.Duration, limit int) ([]model.OutboxEvent, error) {
+	tx := s.db.Begin()
+
+	var es []model.OutboxEvent
+	err := tx.Set("gorm:query_option", "FOR UPDATE SKIP LOCKED").
+		Where("next_attempt_at <= ?", now).
+		Order("id").
+		Limit(limit).
+		Find(&es).Error
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if len(es) == 0 {
+		return es, tx.Commit().Error
+	}
+
+	ids := make([]uint, 0, len(es))
+	for _, e := range es {
+		ids = append(ids, e.ID)
+	}
+
+	err = tx.Model(&model.OutboxEvent{}).
+		Where("id in (?)", ids).
+		UpdateColumn("next_attempt_at", now.Add(lease)).Error
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return es, tx.Commit().Error
+}
+
+// Update saves the result of dispatching the event
+func (s *OutboxStore) Update(e *model.OutboxEvent) error {
+	return s.db.Model(&model.OutboxEvent{}).Where("id = ?", e.ID).Updates(map[string]interface{}{
+		"attempts":        e.Attempts,
+		"next_attempt_at": e.NextAttemptAt,
+		"last_error":      e.LastError,
+		"dispatched_at":   e.DispatchedAt,
+	}).Error
+}
+
+// HasReceipt returns whether the subscriber has handled the event
+func (s *OutboxStore) HasReceipt(eventID, subscriber string) (bool, error) {
+	var count int
+	err := s.db.Model(&model.OutboxReceipt{}).
+		Where("event_id = ? AND subscriber = ?", eventID, subscriber).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// CreateReceipt records that the subscriber has handled the event
+func (s *OutboxStore) CreateReceipt(eventID, subscriber string) error {
+	return s.db.Create(&model.OutboxReceipt{EventID: eventID, Subscriber: subscriber}).Error
+}
+
+// LastIDBefore returns the id of the last event recorded before t, or 0
+// if there is none
+func (s *OutboxStore) LastIDBefore(t time.Time) (uint, error) {
+	var e model.OutboxEvent
+	err := s.db.Select("id").Where("created_at < ?", t).Order("id desc").First(&e).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return 0, nil
+	}
+	return e.ID, err
+}
+
+// After returns up to limit events recorded after the event of id, the oldest first
+func (s *OutboxStore) After(id uint, limit int) ([]model.OutboxEvent, error) {
+	var es []model.OutboxEvent
+	err := s.db.Where("id > ?", id).Order("id").Limit(limit).Find(&es).Error
+	return es, err
+}
+
+// Find returns the events of ids which exist, the oldest first
+func (s *OutboxStore) Find(ids []uint) ([]model.OutboxEvent, error) {
+	var es []model.OutboxEvent
+	err := s.db.Where("id in (?)", ids).Order("id").Find(&es).Error
+	return es, err
+}
+
+// Prune deletes events dispatched before t and their receipts
+func (s *OutboxStore) Prune(t time.Time) error {
+	tx := s.db.Begin()
+
+	old := tx.Model(&model.OutboxEvent{}).
+		Select("event_id").
+		Where("dispatched_at < ?", t).
+		SubQuery()
+
+	if err := tx.Where("event_id in ?", old).Delete(&model.OutboxReceipt{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Where("dispatched_at < ?", t).Delete(&model.OutboxEvent{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
@@ -0,0 +1,299 @@
+package store
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+)
+
+// listedItems narrows a query on reading list items down to listed articles
+func listedItems(d *gorm.DB) *gorm.DB {
+	return d.Joins("join articles on articles.id = reading_list_items.article_id").
+		Where("articles.status = ? AND articles.hidden = ? AND articles.deleted_at IS NULL",
+			model.ArticlePublished, false)
+}
+
+// GetDefaultReadingList returns the default reading list of the user,
+// creating it on first use
+func (s *ArticleStore) GetDefaultReadingList(u *model.User) (*model.ReadingList, error) {
+	tx := s.db.Begin()
+
+	// lock the user so that concurrent requests create one default list
+	err := tx.Set("gorm:query_option", "FOR UPDATE").First(&model.User{}, u.ID).Error
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	l := model.ReadingList{UserID: u.ID, Name: model.DefaultReadingListName, IsDefault: true}
+	err = tx.Where("user_id = ? AND is_default = ?", u.ID, true).FirstOrCreate(&l).Error
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return &l, s.countReadingListArticles([]*model.ReadingList{&l})
+}
+
+// GetReadingLists returns reading lists of the user, the default one first
+func (s *ArticleStore) GetReadingLists(u *model.User) ([]model.ReadingList, error) {
+	if _, err := s.GetDefaultReadingList(u); err != nil {
+		return nil, err
+	}
+
+	var ls []model.ReadingList
+	err := s.db.Where("user_id = ?", u.ID).Order("is_default desc, id").Find(&ls).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ptrs := make([]*model.ReadingList, 0, len(ls))
+	for i := range ls {
+		ptrs = append(ptrs, &ls[i])
+	}
+
+	return ls, s.countReadingListArticles(ptrs)
+}
+
+// GetReadingListByID finds a reading list from id
+func (s *ArticleStore) GetReadingListByID(id uint) (*model.ReadingList, error) {
+	var l model.ReadingList
+	if err := s.db.Find(&l, id).Error; err != nil {
+		return nil, err
+	}
+	return &l, s.countReadingListArticles([]*model.ReadingList{&l})
+}
+
+// GetReadingListByShareToken finds a shared reading list from its share token
+func (s *ArticleStore) GetReadingListByShareToken(token string) (*model.ReadingList, error) {
+	var l model.ReadingList
+	if err := s.db.Preload("User").Where("share_token = ?", token).First(&l).Error; err != nil {
+		return nil, err
+	}
+	return &l, s.countReadingListArticles([]*model.ReadingList{&l})
+}
+
+// countReadingListArticles fills the number of listed articles in the lists
+func (s *ArticleStore) countReadingListArticles(ls []*model.ReadingList) error {
+	ids := make([]uint, 0, len(ls))
+	for _, l := range ls {
+		ids = append(ids, l.ID)
+	}
+
+	var counts []struct {
+		ReadingListID uint
+		Count         int32
+	}
+	err := listedItems(s.db.Table("reading_list_items")).
+		Select("reading_list_items.reading_list_id, count(*) as count").
+		Where("reading_list_items.reading_list_id in (?)", ids).
+		Group("reading_list_items.reading_list_id").
+		Scan(&counts).Error
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[uint]int32, len(counts))
+	for _, c := range counts {
+		byID[c.ReadingListID] = c.Count
+	}
+	for _, l := range ls {
+		l.ArticlesCount = byID[l.ID]
+	}
+
+	return nil
+}
+
+// CreateReadingList creates a reading list
+func (s *ArticleStore) CreateReadingList(l *model.ReadingList) error {
+	return s.db.Create(l).Error
+}
+
+// UpdateReadingList updates the name and share token of a reading list
+func (s *ArticleStore) UpdateReadingList(l *model.ReadingList) error {
+	return s.db.Model(&model.ReadingList{}).Where("id = ?", l.ID).Updates(map[string]interface{}{
+		"name":        l.Name,
+		"share_token": l.ShareToken,
+	}).Error
+}
+
+// DeleteReadingList deletes a reading list and its items
+func (s *ArticleStore) DeleteReadingList(l *model.ReadingList) error {
+	tx := s.db.Begin()
+
+	err := tx.Where("reading_list_id = ?", l.ID).Delete(&model.ReadingListItem{}).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// the share token is released for good
+	err = tx.Model(&model.ReadingList{}).Where("id = ?", l.ID).Update("share_token", nil).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Delete(l).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// GetReadingListArticles returns listed articles of a reading list in order
+func (s *ArticleStore) GetReadingListArticles(l *model.ReadingList, limit, offset int64) ([]model.Article, error) {
+	var items []model.ReadingListItem
+	err := listedItems(s.db.Select("reading_list_items.*")).
+		Preload("Article").Preload("Article.Author").Preload("Article.Tags").
+		Where("reading_list_items.reading_list_id = ?", l.ID).
+		Order("reading_list_items.position").
+		Offset(offset).Limit(limit).
+		Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	as := make([]model.Article, 0, len(items))
+	for _, it := range items {
+		as = append(as, it.Article)
+	}
+
+	return as, nil
+}
+
+// AddToReadingList appends an article to a reading list. Adding an article
+// already in the list does nothing.
+func (s *ArticleStore) AddToReadingList(l *model.ReadingList, a *model.Article) error {
+	tx := s.db.Begin()
+
+	// lock the list so that concurrent additions get distinct positions
+	err := tx.Set("gorm:query_option", "FOR UPDATE").First(&model.ReadingList{}, l.ID).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var last struct{ Position *int }
+	err = tx.Table("reading_list_items").
+		Select("max(position) as position").
+		Where("reading_list_id = ?", l.ID).
+		Scan(&last).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	position := 0
+	if last.Position != nil {
+		position = *last.Position + 1
+	}
+
+	item := model.ReadingListItem{ReadingListID: l.ID, ArticleID: a.ID}
+	err = tx.Where(item).Attrs(model.ReadingListItem{Position: position}).FirstOrCreate(&item).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Model(&model.ReadingList{}).Where("id = ?", l.ID).Update("updated_at", gorm.NowFunc()).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// RemoveFromReadingList removes an article from a reading list
+func (s *ArticleStore) RemoveFromReadingList(l *model.ReadingList, a *model.Article) error {
+	return s.db.Where("reading_list_id = ? AND article_id = ?", l.ID, a.ID).
+		Delete(&model.ReadingListItem{}).Error
+}
+
+// MoveInReadingList moves an article of a reading list to the position, the
+// zero based index among the listed articles. Positions past the end move it
+// to the end. Items of unlisted articles stay where they are relative to the
+// others.
+func (s *ArticleStore) MoveInReadingList(l *model.ReadingList, a *model.Article, position int) error {
+	tx := s.db.Begin()
+
+	err := tx.Set("gorm:query_option", "FOR UPDATE").First(&model.ReadingList{}, l.ID).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var items []model.ReadingListItem
+	err = tx.Where("reading_list_id = ?", l.ID).Order("position").Find(&items).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var listed []uint
+	err = listedItems(tx.Table("reading_list_items")).
+		Where("reading_list_items.reading_list_id = ? AND reading_list_items.article_id <> ?", l.ID, a.ID).
+		Order("reading_list_items.position").
+		Pluck("reading_list_items.article_id", &listed).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// the article goes before the listed article now at the position
+	order := make([]uint, 0, len(items))
+	found := false
+	for _, it := range items {
+		if it.ArticleID == a.ID {
+			found = true
+			continue
+		}
+		if position < len(listed) && it.ArticleID == listed[position] {
+			order = append(order, a.ID)
+		}
+		order = append(order, it.ArticleID)
+	}
+	if !found {
+		tx.Rollback()
+		return gorm.ErrRecordNotFound
+	}
+	if position >= len(listed) {
+		order = append(order, a.ID)
+	}
+
+	for i, articleID := range order {
+		err := tx.Model(&model.ReadingListItem{}).
+			Where("reading_list_id = ? AND article_id = ?", l.ID, articleID).
+			Update("position", i).Error
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// IsBookmarked returns whether the article is in any reading list of the user
+func (s *ArticleStore) IsBookmarked(a *model.Article, u *model.User) (bool, error) {
+	if a == nil || u == nil {
+		return false, nil
+	}
+
+	var count int
+	err := s.db.Table("reading_list_items").
+		Joins("join reading_lists on reading_lists.id = reading_list_items.reading_list_id").
+		Where("reading_lists.user_id = ? AND reading_lists.deleted_at IS NULL", u.ID).
+		Where("reading_list_items.article_id = ?", a.ID).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
@@ -0,0 +1,53 @@
+package store
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+)
+
+// GetSharedTagCounts counts the tags each other article shares with the
+// article. Up to limit articles sharing the most tags are returned.
+func (s *ArticleStore) GetSharedTagCounts(a *model.Article, limit int64) (map[uint]int, error) {
+	return countByArticle(s.db.Table("article_tags t1").
+		Select("t2.article_id, count(*)").
+		Joins("join article_tags t2 on t2.tag_id = t1.tag_id").
+		Where("t1.article_id = ? AND t2.article_id <> ?", a.ID, a.ID).
+		Group("t2.article_id").
+		Order("count(*) desc, t2.article_id desc").
+		Limit(limit))
+}
+
+// GetCoFavoriteCounts counts the users who favorited both the article and
+// each other article. Up to limit articles with the most of such users are
+// returned.
+func (s *ArticleStore) GetCoFavoriteCounts(a *model.Article, limit int64) (map[uint]int, error) {
+	return countByArticle(s.db.Table("favorite_articles f1").
+		Select("f2.article_id, count(*)").
+		Joins("join favorite_articles f2 on f2.user_id = f1.user_id").
+		Where("f1.article_id = ? AND f2.article_id <> ?", a.ID, a.ID).
+		Group("f2.article_id").
+		Order("count(*) desc, f2.article_id desc").
+		Limit(limit))
+}
+
+// countByArticle reads rows of article ids and counts
+func countByArticle(d *gorm.DB) (map[uint]int, error) {
+	counts := map[uint]int{}
+
+	rows, err := d.Rows()
+	if err != nil {
+		return counts, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uint
+		var count int
+		if err := rows.Scan(&id, &count); err != nil {
+			return counts, err
+		}
+		counts[id] = count
+	}
+
+	return counts, rows.Err()
+}
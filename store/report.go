@@ -0,0 +1,186 @@
+package store
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+)
+
+// reportedContent narrows a query down to the reports of the same content as r
+func reportedContent(d *gorm.DB, r *model.Report) *gorm.DB {
+	return d.Where("content_type = ? AND article_id = ? AND comment_id = ?",
+		r.ContentType, r.ArticleID, r.CommentID)
+}
+
+// contentTable returns the table of the content a report points to
+func contentTable(contentType string) string {
+	if contentType == model.ContentComment {
+		return "comments"
+	}
+	return "articles"
+}
+
+// contentID returns the id of the content a report points to
+func contentID(contentType string, articleID, commentID uint) uint {
+	if contentType == model.ContentComment {
+		return commentID
+	}
+	return articleID
+}
+
+// hiddenColumns returns the columns to update when content is hidden or shown.
+// Tables updated without models don't get updated_at, which the search index
+// sync relies on to notice the change.
+func hiddenColumns(hidden bool) map[string]interface{} {
+	return map[string]interface{}{
+		"hidden":     hidden,
+		"updated_at": time.Now(),
+	}
+}
+
+// HasOpenReport returns whether the reporter has an open report on the same content as r
+func (s *ArticleStore) HasOpenReport(r *model.Report) (bool, error) {
+	var count int
+	err := reportedContent(s.db.Model(&model.Report{}), r).
+		Where("reporter_id = ? AND status = ?", r.ReporterID, model.ReportOpen).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// CreateReport creates a report and hides the content once it has
+// threshold open reports. Zero threshold never hides content.
+func (s *ArticleStore) CreateReport(r *model.Report, threshold int) (hidden bool, err error) {
+	tx := s.db.Begin()
+
+	if err := tx.Create(r).Error; err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	var count int
+	err = reportedContent(tx.Model(&model.Report{}), r).
+		Where("status = ?", model.ReportOpen).
+		Count(&count).Error
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	if threshold > 0 && count >= threshold {
+		err = tx.Table(contentTable(r.ContentType)).
+			Where("id = ?", contentID(r.ContentType, r.ArticleID, r.CommentID)).
+			Updates(hiddenColumns(true)).Error
+		if err != nil {
+			tx.Rollback()
+			return false, err
+		}
+		hidden = true
+	}
+
+	return hidden, tx.Commit().Error
+}
+
+// GetReportByID finds a report from id
+func (s *ArticleStore) GetReportByID(id uint) (*model.Report, error) {
+	var m model.Report
+	err := s.db.Preload("Reporter").Find(&m, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// GetReports returns reports with the status, newest first. Empty status matches all reports.
+func (s *ArticleStore) GetReports(status string, limit, offset int64) ([]model.Report, int, error) {
+	d := s.db.Model(&model.Report{})
+	if status != "" {
+		d = d.Where("status = ?", status)
+	}
+
+	var count int
+	if err := d.Count(&count).Error; err != nil {
+		return []model.Report{}, 0, err
+	}
+
+	var rs []model.Report
+	err := d.Preload("Reporter").
+		Order("created_at desc").
+		Offset(offset).Limit(limit).
+		Find(&rs).Error
+
+	return rs, count, err
+}
+
+// ResolveReports applies the moderator's decision to the reported content,
+// resolves all of its open reports and records the decision
+func (s *ArticleStore) ResolveReports(r *model.Report, a *model.ModerationAction) error {
+	tx := s.db.Begin()
+
+	content := tx.Table(contentTable(a.ContentType)).
+		Where("id = ?", contentID(a.ContentType, a.ArticleID, a.CommentID))
+
+	var err error
+	switch a.Action {
+	case model.ActionDismiss:
+		err = content.Updates(hiddenColumns(false)).Error
+	case model.ActionHide:
+		err = content.Updates(hiddenColumns(true)).Error
+	case model.ActionRemove:
+		if a.ContentType == model.ContentComment {
+			err = deleteComment(tx, &model.Comment{Model: gorm.Model{ID: a.CommentID}})
+		} else {
+			err = content.Update("deleted_at", time.Now()).Error
+		}
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	now := time.Now()
+	err = reportedContent(tx.Model(&model.Report{}), r).
+		Where("status = ?", model.ReportOpen).
+		Updates(map[string]interface{}{
+			"status":      a.ReportStatus(),
+			"resolved_at": now,
+		}).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Create(a).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	r.Status = a.ReportStatus()
+	r.ResolvedAt = &now
+
+	return nil
+}
+
+// CreateModerationAction records a moderator's decision
+func (s *ArticleStore) CreateModerationAction(a *model.ModerationAction) error {
+	return s.db.Create(a).Error
+}
+
+// GetModerationActions returns the audit log of moderators' decisions, newest first
+func (s *ArticleStore) GetModerationActions(limit, offset int64) ([]model.ModerationAction, error) {
+	var as []model.ModerationAction
+	err := s.db.Preload("Moderator").
+		Order("created_at desc").
+		Offset(offset).Limit(limit).
+		Find(&as).Error
+
+	return as, err
+}
@@ -0,0 +1,98 @@
+package store
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/raahii/golang-grpc-realworld-example/model"
+)
+
+// AddArticleViews adds the views to the daily stats of the articles
+func (s *ArticleStore) AddArticleViews(stats []model.ArticleDailyStat) error {
+	tx := s.db.Begin()
+
+	for _, st := range stats {
+		err := tx.Exec("INSERT INTO article_daily_stats (article_id, date, views, favorites) VALUES (?, ?, ?, 0) "+
+			"ON DUPLICATE KEY UPDATE views = views + VALUES(views)",
+			st.ArticleID, st.Date, st.Views).Error
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// addDailyFavorites adds delta to the favorites of the article today in the transaction
+func addDailyFavorites(tx *gorm.DB, articleID uint, delta int64) error {
+	return tx.Exec("INSERT INTO article_daily_stats (article_id, date, views, favorites) VALUES (?, ?, 0, ?) "+
+		"ON DUPLICATE KEY UPDATE favorites = favorites + VALUES(favorites)",
+		articleID, time.Now().UTC().Format(model.DateLayout), delta).Error
+}
+
+// GetArticleDailyStats returns the daily stats of the article since the
+// day, the oldest first. Days without views or favorites are left out.
+func (s *ArticleStore) GetArticleDailyStats(a *model.Article, since string) ([]model.ArticleDailyStat, error) {
+	var stats []model.ArticleDailyStat
+	err := s.db.Where("article_id = ? AND date >= ?", a.ID, since).
+		Order("date").
+		Find(&stats).Error
+	return stats, err
+}
+
+// CountArticleViews returns the total number of views of the article
+func (s *ArticleStore) CountArticleViews(a *model.Article) (int64, error) {
+	var total struct{ Views int64 }
+	err := s.db.Model(&model.ArticleDailyStat{}).
+		Select("coalesce(sum(views), 0) as views").
+		Where("article_id = ?", a.ID).
+		Scan(&total).Error
+	return total.Views, err
+}
+
+// GetCommentTimes returns the creation times of the visible comments of the
+// article created at or after t
+func (s *ArticleStore) GetCommentTimes(a *model.Article, t time.Time) ([]time.Time, error) {
+	var ts []time.Time
+	err := s.db.Model(&model.Comment{}).
+		Where("article_id = ? AND hidden = ? AND created_at >= ?", a.ID, false, t).
+		Pluck("created_at", &ts).Error
+	return ts, err
+}
+
+// GetDailyStatsSince returns the daily stats of all articles since the day
+func (s *ArticleStore) GetDailyStatsSince(since string) ([]model.ArticleDailyStat, error) {
+	var stats []model.ArticleDailyStat
+	err := s.db.Where("date >= ?", since).Find(&stats).Error
+	return stats, err
+}
+
+// GetCommentsSince returns the article ids and creation times of the visible
+// comments created at or after t
+func (s *ArticleStore) GetCommentsSince(t time.Time) ([]model.Comment, error) {
+	var cs []model.Comment
+	err := s.db.Select("article_id, created_at").
+		Where("hidden = ? AND created_at >= ?", false, t).
+		Find(&cs).Error
+	return cs, err
+}
+
+// ReplaceArticleTrends replaces all trending scores with the ones given
+func (s *ArticleStore) ReplaceArticleTrends(trends []model.ArticleTrend) error {
+	tx := s.db.Begin()
+
+	if err := tx.Delete(&model.ArticleTrend{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, t := range trends {
+		if err := tx.Create(&t).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
@@ -1,8 +1,14 @@
 package store
 
 import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/jinzhu/gorm"
 	"github.com/raahii/golang-grpc-realworld-example/model"
+	pb "github.com/raahii/golang-grpc-realworld-example/proto"
 )
 
 // UserStore is data access struct for user
@@ -49,9 +55,11 @@ func (s *UserStore) Create(m *model.User) error {
 	return s.db.Create(m).Error
 }
 
-// Update update all of user fields
+// Update update all of user fields except for the follow counters
 func (s *UserStore) Update(m *model.User) error {
-	return s.db.Model(m).Update(m).Error
+	return s.db.Model(m).
+		Omit("followers_count", "following_count").
+		Update(m).Error
 }
 
 // IsFollowing returns whether user A follows user B or not
@@ -73,12 +81,121 @@ func (s *UserStore) IsFollowing(a *model.User, b *model.User) (bool, error) {
 
 // Follow create follow relashionship to User B from user A
 func (s *UserStore) Follow(a *model.User, b *model.User) error {
-	return s.db.Model(a).Association("Follows").Append(b).Error
+	tx := s.db.Begin()
+
+	created, err := follow(tx, a, b)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if created {
+		err := addEvent(tx, model.EventUserFollowed, a.ID, func(e *pb.DomainEvent) {
+			e.UserId = uint64(b.ID)
+		})
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	if created {
+		a.FollowingCount++
+		b.FollowersCount++
+	}
+
+	return nil
 }
 
 // Unfollow delete follow relashionship to User B from user A
 func (s *UserStore) Unfollow(a *model.User, b *model.User) error {
-	return s.db.Model(a).Association("Follows").Delete(b).Error
+	tx := s.db.Begin()
+
+	deleted, err := unfollow(tx, a, b)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	if deleted {
+		a.FollowingCount--
+		b.FollowersCount--
+	}
+
+	return nil
+}
+
+// follow creates follow relationship to user B from user A in tx and
+// updates the counters. It returns false when A already follows B.
+func follow(tx *gorm.DB, a *model.User, b *model.User) (bool, error) {
+	var count int
+	err := tx.Table("follows").
+		Where("from_user_id = ? AND to_user_id = ?", a.ID, b.ID).
+		Count(&count).Error
+	if err != nil || count > 0 {
+		return false, err
+	}
+
+	err = tx.Model(a).Association("Follows").Append(b).Error
+	if err != nil {
+		return false, err
+	}
+
+	return true, updateFollowCounters(tx, a, b, 1)
+}
+
+// unfollow deletes follow relationship to user B from user A in tx and
+// updates the counters. It returns false when A does not follow B.
+func unfollow(tx *gorm.DB, a *model.User, b *model.User) (bool, error) {
+	res := tx.Exec("DELETE FROM follows WHERE from_user_id = ? AND to_user_id = ?", a.ID, b.ID)
+	if res.Error != nil || res.RowsAffected == 0 {
+		return false, res.Error
+	}
+
+	return true, updateFollowCounters(tx, a, b, -1)
+}
+
+func updateFollowCounters(tx *gorm.DB, a *model.User, b *model.User, delta int) error {
+	err := tx.Model(&model.User{}).Where("id = ?", a.ID).
+		UpdateColumn("following_count", gorm.Expr("following_count + ?", delta)).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Model(&model.User{}).Where("id = ?", b.ID).
+		UpdateColumn("followers_count", gorm.Expr("followers_count + ?", delta)).Error
+}
+
+// GetFollowers returns users following the user
+func (s *UserStore) GetFollowers(m *model.User, limit, offset int64) ([]model.User, error) {
+	var us []model.User
+	err := s.db.Joins("join follows on follows.from_user_id = users.id").
+		Where("follows.to_user_id = ?", m.ID).
+		Order("users.id").
+		Offset(offset).Limit(limit).
+		Find(&us).Error
+
+	return us, err
+}
+
+// GetFollowing returns users the user follows
+func (s *UserStore) GetFollowing(m *model.User, limit, offset int64) ([]model.User, error) {
+	var us []model.User
+	err := s.db.Joins("join follows on follows.to_user_id = users.id").
+		Where("follows.from_user_id = ?", m.ID).
+		Order("users.id").
+		Offset(offset).Limit(limit).
+		Find(&us).Error
+
+	return us, err
 }
 
 // GetFollowingUserIDs returns user ids current user follows
@@ -101,3 +218,337 @@ func (s *UserStore) GetFollowingUserIDs(m *model.User) ([]uint, error) {
 
 	return ids, nil
 }
+
+// IsBlocking returns whether user A blocks user B or not
+func (s *UserStore) IsBlocking(a *model.User, b *model.User) (bool, error) {
+	if a == nil || b == nil {
+		return false, nil
+	}
+
+	var count int
+	err := s.db.Table("blocks").
+		Where("from_user_id = ? AND to_user_id = ?", a.ID, b.ID).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// IsBlockedBetween returns whether either user A or user B blocks the other
+func (s *UserStore) IsBlockedBetween(a *model.User, b *model.User) (bool, error) {
+	var count int
+	err := s.db.Table("blocks").
+		Where("(from_user_id = ? AND to_user_id = ?) OR (from_user_id = ? AND to_user_id = ?)",
+			a.ID, b.ID, b.ID, a.ID).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// Block creates block relationship to user B from user A
+// and removes follow relationships between them
+func (s *UserStore) Block(a *model.User, b *model.User) error {
+	tx := s.db.Begin()
+
+	err := tx.Model(a).Association("Blocks").Append(b).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	unfollowedB, err := unfollow(tx, a, b)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	unfollowedA, err := unfollow(tx, b, a)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	if unfollowedB {
+		a.FollowingCount--
+		b.FollowersCount--
+	}
+	if unfollowedA {
+		b.FollowingCount--
+		a.FollowersCount--
+	}
+
+	return nil
+}
+
+// Unblock deletes block relationship to user B from user A
+func (s *UserStore) Unblock(a *model.User, b *model.User) error {
+	return s.db.Model(a).Association("Blocks").Delete(b).Error
+}
+
+// GetBlockedUsers returns users the user blocks
+func (s *UserStore) GetBlockedUsers(m *model.User) ([]model.User, error) {
+	var us []model.User
+	err := s.db.Model(m).Association("Blocks").Find(&us).Error
+	return us, err
+}
+
+// IsMuting returns whether user A mutes user B or not
+func (s *UserStore) IsMuting(a *model.User, b *model.User) (bool, error) {
+	if a == nil || b == nil {
+		return false, nil
+	}
+
+	var count int
+	err := s.db.Table("mutes").
+		Where("from_user_id = ? AND to_user_id = ?", a.ID, b.ID).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// Mute creates mute relationship to user B from user A
+func (s *UserStore) Mute(a *model.User, b *model.User) error {
+	return s.db.Model(a).Association("Mutes").Append(b).Error
+}
+
+// Unmute deletes mute relationship to user B from user A
+func (s *UserStore) Unmute(a *model.User, b *model.User) error {
+	return s.db.Model(a).Association("Mutes").Delete(b).Error
+}
+
+// GetMutedUsers returns users the user mutes
+func (s *UserStore) GetMutedUsers(m *model.User) ([]model.User, error) {
+	var us []model.User
+	err := s.db.Model(m).Association("Mutes").Find(&us).Error
+	return us, err
+}
+
+// GetMutedUserIDs returns user ids the user mutes. m may be nil for guests.
+func (s *UserStore) GetMutedUserIDs(m *model.User) ([]uint, error) {
+	if m == nil {
+		return []uint{}, nil
+	}
+
+	var ids []uint
+	err := s.db.Table("mutes").
+		Where("from_user_id = ?", m.ID).
+		Pluck("to_user_id", &ids).Error
+	if err != nil {
+		return []uint{}, err
+	}
+
+	return ids, nil
+}
+
+// GetBlockRelatedUserIDs returns ids of the users m blocks and the users blocking m.
+// m may be nil for guests.
+func (s *UserStore) GetBlockRelatedUserIDs(m *model.User) ([]uint, error) {
+	if m == nil {
+		return []uint{}, nil
+	}
+
+	var blocking, blockedBy []uint
+	err := s.db.Table("blocks").
+		Where("from_user_id = ?", m.ID).
+		Pluck("to_user_id", &blocking).Error
+	if err != nil {
+		return []uint{}, err
+	}
+
+	err = s.db.Table("blocks").
+		Where("to_user_id = ?", m.ID).
+		Pluck("from_user_id", &blockedBy).Error
+	if err != nil {
+		return []uint{}, err
+	}
+
+	return append(blocking, blockedBy...), nil
+}
+
+// GetUsersByIDs returns users of ids in no particular order
+func (s *UserStore) GetUsersByIDs(ids []uint) ([]model.User, error) {
+	var us []model.User
+	err := s.db.Where("id in (?)", ids).Find(&us).Error
+	return us, err
+}
+
+// GetUsersChangedSince returns users updated at or after t
+// in batches of limit ordered by id after afterID
+func (s *UserStore) GetUsersChangedSince(t time.Time, afterID uint, limit int) ([]model.User, error) {
+	var us []model.User
+	err := s.db.
+		Where("updated_at >= ?", t).
+		Where("id > ?", afterID).
+		Order("id").
+		Limit(limit).
+		Find(&us).Error
+
+	return us, err
+}
+
+// GetByIdentity finds a user linked to the external identity
+func (s *UserStore) GetByIdentity(provider, subject string) (*model.User, error) {
+	var i model.Identity
+	err := s.db.Preload("User").
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&i).Error
+	if err != nil {
+		return nil, err
+	}
+	return &i.User, nil
+}
+
+// CreateIdentity links an external identity to an existing user
+func (s *UserStore) CreateIdentity(m *model.Identity) error {
+	return s.db.Create(m).Error
+}
+
+// CreateWithIdentity creates a user and links the external identity to it
+func (s *UserStore) CreateWithIdentity(u *model.User, i *model.Identity) error {
+	tx := s.db.Begin()
+
+	if err := tx.Create(u).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	i.UserID = u.ID
+	if err := tx.Create(i).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// AllocateUsername returns base, or base followed by the smallest number, which is not taken yet
+func (s *UserStore) AllocateUsername(base string) (string, error) {
+	var taken []string
+	err := s.db.Model(&model.User{}).
+		Where("username = ? OR username LIKE ?", base, base+"%").
+		Pluck("username", &taken).Error
+	if err != nil {
+		return "", err
+	}
+
+	used := make(map[string]bool, len(taken))
+	for _, u := range taken {
+		used[u] = true
+	}
+
+	name := base
+	for i := 1; used[name]; i++ {
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+
+	return name, nil
+}
+
+// CreateAPIToken creates a personal access token
+func (s *UserStore) CreateAPIToken(m *model.APIToken) error {
+	return s.db.Create(m).Error
+}
+
+// GetAPITokens returns the personal access tokens of the user
+func (s *UserStore) GetAPITokens(u *model.User) ([]model.APIToken, error) {
+	var ts []model.APIToken
+	err := s.db.Where("user_id = ?", u.ID).
+		Order("created_at desc").
+		Find(&ts).Error
+	if err != nil {
+		return ts, err
+	}
+	return ts, nil
+}
+
+// GetAPITokenByID finds a personal access token from id
+func (s *UserStore) GetAPITokenByID(id uint) (*model.APIToken, error) {
+	var m model.APIToken
+	if err := s.db.Find(&m, id).Error; err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// RevokeAPIToken revokes a personal access token
+func (s *UserStore) RevokeAPIToken(m *model.APIToken) error {
+	return s.db.Delete(m).Error
+}
+
+// apiTokenTouchInterval throttles writes of last used timestamps
+const apiTokenTouchInterval = time.Minute
+
+// VerifyAPIToken returns the owner and the scopes of a valid personal access token
+func (s *UserStore) VerifyAPIToken(plain string) (uint, []string, error) {
+	var m model.APIToken
+	err := s.db.Where("prefix = ?", model.APITokenPrefixOf(plain)).First(&m).Error
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(m.Hash), []byte(model.HashAPIToken(plain))) != 1 {
+		return 0, nil, errors.New("token mismatch")
+	}
+
+	now := time.Now()
+	if m.IsExpired(now) {
+		return 0, nil, errors.New("token expired")
+	}
+
+	if m.LastUsedAt == nil || now.Sub(*m.LastUsedAt) > apiTokenTouchInterval {
+		err := s.db.Model(&m).UpdateColumn("last_used_at", now).Error
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+
+	return m.UserID, m.ScopeList(), nil
+}
+
+// GetUsers returns users filtered by role and status, and the number of all matched users
+func (s *UserStore) GetUsers(role, status string, limit, offset int64) ([]model.User, int, error) {
+	d := s.db.Model(&model.User{})
+
+	if role != "" {
+		d = d.Where("role = ?", role)
+	}
+
+	if status != "" {
+		d = d.Where("status = ?", status)
+	}
+
+	var count int
+	if err := d.Count(&count).Error; err != nil {
+		return []model.User{}, 0, err
+	}
+
+	var us []model.User
+	err := d.Order("id").Offset(offset).Limit(limit).Find(&us).Error
+
+	return us, count, err
+}
+
+// UpdateStatus saves the account status of the user
+func (s *UserStore) UpdateStatus(m *model.User) error {
+	return s.db.Model(m).Updates(map[string]interface{}{
+		"status":          m.Status,
+		"suspended_until": m.SuspendedUntil,
+	}).Error
+}
+
+// UpdateRole saves the role of the user
+func (s *UserStore) UpdateRole(m *model.User) error {
+	return s.db.Model(m).Update("role", m.Role).Error
+}
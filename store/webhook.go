@@ -0,0 +1,223 @@
+package store
+
+import (
+	"time"
+
+	"github.com/raahii/golang-grpc-realworld-example/model"
+)
+
+// CreateWebhook creates a webhook
+func (s *UserStore) CreateWebhook(w *model.Webhook) error {
+	return s.db.Create(w).Error
+}
+
+// GetWebhooks returns webhooks of the user
+func (s *UserStore) GetWebhooks(u *model.User) ([]model.Webhook, error) {
+	var ws []model.Webhook
+	err := s.db.Where("user_id = ?", u.ID).Order("id").Find(&ws).Error
+	return ws, err
+}
+
+// GetWebhookByID finds a webhook from id
+func (s *UserStore) GetWebhookByID(id uint) (*model.Webhook, error) {
+	var w model.Webhook
+	if err := s.db.Find(&w, id).Error; err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// DeleteWebhook deletes a webhook and gives up its pending deliveries
+func (s *UserStore) DeleteWebhook(w *model.Webhook) error {
+	tx := s.db.Begin()
+
+	if err := tx.Delete(w).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err := tx.Model(&model.WebhookDelivery{}).
+		Where("webhook_id = ? AND status = ?", w.ID, model.DeliveryPending).
+		Updates(map[string]interface{}{
+			"status":          model.DeliveryFailed,
+			"next_attempt_at": nil,
+			"last_error":      "webhook was deleted",
+		}).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// GetWebhooksFor returns webhooks receiving the event about the user,
+// which are the user's own and global ones
+func (s *UserStore) GetWebhooksFor(u *model.User, event string) ([]model.Webhook, error) {
+	var ws []model.Webhook
+	err := s.db.Where("user_id = ? OR global = ?", u.ID, true).Find(&ws).Error
+	if err != nil {
+		return nil, err
+	}
+
+	subscribed := make([]model.Webhook, 0, len(ws))
+	for _, w := range ws {
+		if w.Subscribes(event) {
+			subscribed = append(subscribed, w)
+		}
+	}
+
+	return subscribed, nil
+}
+
+// EnqueueDeliveries queues the event for the webhooks. Webhooks which
+// already have a delivery of eventID are skipped, so it can be retried.
+func (s *UserStore) EnqueueDeliveries(ws []model.Webhook, eventID, event, payload string) error {
+	if len(ws) == 0 {
+		return nil
+	}
+
+	tx := s.db.Begin()
+
+	now := time.Now()
+	for _, w := range ws {
+		var count int
+		err := tx.Model(&model.WebhookDelivery{}).
+			Where("webhook_id = ? AND event_id = ?", w.ID, eventID).
+			Count(&count).Error
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+
+		d := model.WebhookDelivery{
+			WebhookID:     w.ID,
+			EventID:       eventID,
+			Event:         event,
+			Payload:       payload,
+			Status:        model.DeliveryPending,
+			NextAttemptAt: &now,
+		}
+		if err := tx.Create(&d).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// ClaimDueDeliveries returns up to limit pending deliveries due at now with
+// their webhooks. They are leased until now+lease, so that the other servers
+// don't send them meanwhile; rows are locked with SKIP LOCKED while claiming.
+func (s *UserStore) ClaimDueDeliveries(now time.Time, lease time.Duration, limit int) ([]model.WebhookDelivery, error) {
+	tx := s.db.Begin()
+
+	var ds []model.WebhookDelivery
+	err := tx.Set("gorm:query_option", "FOR UPDATE SKIP LOCKED").
+		Where("status = ? AND next_attempt_at <= ?", model.DeliveryPending, now).
+		Order("next_attempt_at").
+		Limit(limit).
+		Find(&ds).Error
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if len(ds) == 0 {
+		return ds, tx.Commit().Error
+	}
+
+	ids := make([]uint, 0, len(ds))
+	for _, d := range ds {
+		ids = append(ids, d.ID)
+	}
+
+	err = tx.Model(&model.WebhookDelivery{}).
+		Where("id in (?)", ids).
+		UpdateColumn("next_attempt_at", now.Add(lease)).Error
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	// webhooks deleted meanwhile are left out
+	var ws []model.Webhook
+	ids = ids[:0]
+	for _, d := range ds {
+		ids = append(ids, d.WebhookID)
+	}
+	if err := s.db.Where("id in (?)", ids).Find(&ws).Error; err != nil {
+		return nil, err
+	}
+
+	hooks := make(map[uint]model.Webhook, len(ws))
+	for _, w := range ws {
+		hooks[w.ID] = w
+	}
+	for i := range ds {
+		ds[i].Webhook = hooks[ds[i].WebhookID]
+	}
+
+	return ds, nil
+}
+
+// UpdateDelivery saves the result of an attempt of the delivery
+func (s *UserStore) UpdateDelivery(d *model.WebhookDelivery) error {
+	// not through the model, which would save the preloaded webhook too
+	return s.db.Model(&model.WebhookDelivery{}).Where("id = ?", d.ID).Updates(map[string]interface{}{
+		"status":          d.Status,
+		"attempts":        d.Attempts,
+		"next_attempt_at": d.NextAttemptAt,
+		"response_status": d.ResponseStatus,
+		"last_error":      d.LastError,
+		"delivered_at":    d.DeliveredAt,
+	}).Error
+}
+
+// GetWebhookDeliveries returns deliveries of the webhook filtered by status,
+// the newest first, and the number of all matched deliveries
+func (s *UserStore) GetWebhookDeliveries(w *model.Webhook, status string, limit, offset int64) ([]model.WebhookDelivery, int, error) {
+	d := s.db.Model(&model.WebhookDelivery{}).Where("webhook_id = ?", w.ID)
+	if status != "" {
+		d = d.Where("status = ?", status)
+	}
+
+	var count int
+	if err := d.Count(&count).Error; err != nil {
+		return []model.WebhookDelivery{}, 0, err
+	}
+
+	var ds []model.WebhookDelivery
+	err := d.Order("id desc").Offset(offset).Limit(limit).Find(&ds).Error
+
+	return ds, count, err
+}
+
+// GetWebhookDeliveryByID finds a webhook delivery from id
+func (s *UserStore) GetWebhookDeliveryByID(id uint) (*model.WebhookDelivery, error) {
+	var d model.WebhookDelivery
+	if err := s.db.Find(&d, id).Error; err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// ReplayDelivery queues a given up delivery again with a fresh set of attempts
+func (s *UserStore) ReplayDelivery(d *model.WebhookDelivery) error {
+	now := time.Now()
+	d.Status = model.DeliveryPending
+	d.Attempts = 0
+	d.NextAttemptAt = &now
+	d.LastError = ""
+	d.ResponseStatus = 0
+
+	return s.UpdateDelivery(d)
+}
@@ -0,0 +1,85 @@
+// Package trending scores articles by their recent activity. Every view,
+// favorite and comment adds a weight that halves every HalfLife, so recent
+// activity counts more than old activity.
+package trending
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// HalfLife is how long it takes for activity to count half as much
+	HalfLife = 24 * time.Hour
+
+	// Window is how far back activity is taken into account
+	Window = 7 * 24 * time.Hour
+)
+
+// Weights of each kind of activity
+const (
+	ViewWeight     = 1.0
+	FavoriteWeight = 5.0
+	CommentWeight  = 3.0
+)
+
+// Decay returns the factor of activity of the age. Activity in the future
+// counts as now.
+func Decay(age time.Duration) float64 {
+	if age < 0 {
+		age = 0
+	}
+	return math.Exp2(-float64(age) / float64(HalfLife))
+}
+
+// Score is the trending score of an article
+type Score struct {
+	ArticleID uint
+	Value     float64
+}
+
+// Scores sums up the activity of articles as of a time
+type Scores struct {
+	now    time.Time
+	values map[uint]float64
+}
+
+// NewScores returns empty scores as of now
+func NewScores(now time.Time) *Scores {
+	return &Scores{now: now, values: map[uint]float64{}}
+}
+
+// AddDaily adds the views and the net favorites of an article on a day.
+// They are taken to have happened at noon, or now for today.
+func (s *Scores) AddDaily(articleID uint, day time.Time, views, favorites int64) {
+	t := day.Add(12 * time.Hour)
+	if t.After(s.now) {
+		t = s.now
+	}
+	v := ViewWeight*float64(views) + FavoriteWeight*float64(favorites)
+	s.values[articleID] += v * Decay(s.now.Sub(t))
+}
+
+// AddComment adds a comment on an article written at t
+func (s *Scores) AddComment(articleID uint, t time.Time) {
+	s.values[articleID] += CommentWeight * Decay(s.now.Sub(t))
+}
+
+// Ranked returns the articles with positive scores, the highest first
+func (s *Scores) Ranked() []Score {
+	ss := make([]Score, 0, len(s.values))
+	for id, v := range s.values {
+		if v > 0 {
+			ss = append(ss, Score{ArticleID: id, Value: v})
+		}
+	}
+
+	sort.Slice(ss, func(i, j int) bool {
+		if ss[i].Value != ss[j].Value {
+			return ss[i].Value > ss[j].Value
+		}
+		return ss[i].ArticleID > ss[j].ArticleID
+	})
+	return ss
+}
@@ -0,0 +1,55 @@
+package trending
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecay(t *testing.T) {
+	assert.InDelta(t, 1, Decay(0), 1e-9)
+	assert.InDelta(t, 1, Decay(-time.Hour), 1e-9, "future")
+	assert.InDelta(t, 0.5, Decay(HalfLife), 1e-9)
+	assert.InDelta(t, 0.25, Decay(2*HalfLife), 1e-9)
+}
+
+func TestScores(t *testing.T) {
+	now := time.Date(2020, 4, 8, 6, 0, 0, 0, time.UTC)
+	today := time.Date(2020, 4, 8, 0, 0, 0, 0, time.UTC)
+
+	s := NewScores(now)
+
+	// today counts as now
+	s.AddDaily(1, today, 10, 0)
+	// noon of 2 days ago is 42 hours ago
+	s.AddDaily(2, today.AddDate(0, 0, -2), 100, 4)
+	s.AddComment(3, now.Add(-HalfLife))
+	s.AddComment(3, now.Add(-2*HalfLife))
+	// more unfavorites than favorites
+	s.AddDaily(4, today, 1, -1)
+
+	ranked := s.Ranked()
+	if assert.Len(t, ranked, 3) {
+		assert.Equal(t, uint(2), ranked[0].ArticleID)
+		assert.InDelta(t, 120*Decay(42*time.Hour), ranked[0].Value, 1e-9)
+		assert.Equal(t, uint(1), ranked[1].ArticleID)
+		assert.InDelta(t, 10, ranked[1].Value, 1e-9)
+		assert.Equal(t, uint(3), ranked[2].ArticleID)
+		assert.InDelta(t, CommentWeight*0.75, ranked[2].Value, 1e-9)
+	}
+}
+
+func TestRankedTies(t *testing.T) {
+	now := time.Date(2020, 4, 8, 6, 0, 0, 0, time.UTC)
+
+	s := NewScores(now)
+	s.AddComment(1, now)
+	s.AddComment(2, now)
+
+	ranked := s.Ranked()
+	if assert.Len(t, ranked, 2) {
+		assert.Equal(t, uint(2), ranked[0].ArticleID, "newer article first")
+		assert.Equal(t, uint(1), ranked[1].ArticleID)
+	}
+}
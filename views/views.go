@@ -0,0 +1,130 @@
+// Package views counts article views in memory until they are flushed to the
+// database. Repeated views by the same viewer within a window are counted
+// once, and views by bots are ignored.
+package views
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// botPatterns are parts of the user agents of crawlers, link previewers,
+// monitors and scripts, in lower case
+var botPatterns = []string{
+	"bot", "crawl", "spider", "slurp", "scrapy", "mediapartners",
+	"facebookexternalhit", "embedly", "preview", "headless", "lighthouse",
+	"pingdom", "uptime", "monitor", "curl", "wget", "python-requests",
+}
+
+// IsBot returns whether the user agent belongs to a bot. Requests without a
+// user agent are treated as bots.
+func IsBot(userAgent string) bool {
+	ua := strings.ToLower(strings.TrimSpace(userAgent))
+	if ua == "" {
+		return true
+	}
+	for _, p := range botPatterns {
+		if strings.Contains(ua, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Count is the number of views of an article on a day
+type Count struct {
+	ArticleID uint
+	Day       time.Time // midnight UTC
+	Views     int64
+}
+
+type seenKey struct {
+	articleID uint
+	viewer    string
+}
+
+type countKey struct {
+	articleID uint
+	day       time.Time
+}
+
+// Counter buffers deduplicated views. It is safe for concurrent use.
+type Counter struct {
+	window time.Duration
+
+	mu      sync.Mutex
+	seen    map[seenKey]time.Time
+	pending map[countKey]int64
+}
+
+// NewCounter returns a counter counting a viewer once per article within window
+func NewCounter(window time.Duration) *Counter {
+	return &Counter{
+		window:  window,
+		seen:    make(map[seenKey]time.Time),
+		pending: make(map[countKey]int64),
+	}
+}
+
+// Day truncates t to the day it is in, in UTC
+func Day(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+// Record counts a view of the article by viewer at now, unless the viewer
+// viewed it within the window. It returns whether the view was counted.
+func (c *Counter) Record(articleID uint, viewer string, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	k := seenKey{articleID, viewer}
+	if last, ok := c.seen[k]; ok && now.Sub(last) < c.window {
+		return false
+	}
+	c.seen[k] = now
+	c.pending[countKey{articleID, Day(now)}]++
+
+	return true
+}
+
+// Take returns the buffered counts and empties the buffer. Viewers last seen
+// before the window are forgotten, so that memory stays bounded by the
+// number of viewers within a window.
+func (c *Counter) Take(now time.Time) []Count {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, t := range c.seen {
+		if now.Sub(t) >= c.window {
+			delete(c.seen, k)
+		}
+	}
+
+	cs := make([]Count, 0, len(c.pending))
+	for k, n := range c.pending {
+		cs = append(cs, Count{ArticleID: k.articleID, Day: k.day, Views: n})
+	}
+	c.pending = make(map[countKey]int64)
+
+	// a stable order keeps the row locks of concurrent flushes in order
+	sort.Slice(cs, func(i, j int) bool {
+		if cs[i].ArticleID != cs[j].ArticleID {
+			return cs[i].ArticleID < cs[j].ArticleID
+		}
+		return cs[i].Day.Before(cs[j].Day)
+	})
+
+	return cs
+}
+
+// Restore puts back counts taken by Take which failed to be flushed
+func (c *Counter) Restore(cs []Count) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, ct := range cs {
+		c.pending[countKey{ct.ArticleID, ct.Day}] += ct.Views
+	}
+}
@@ -0,0 +1,59 @@
+package views
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsBot(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		bot       bool
+	}{
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/14.0 Safari/605.1.15", false},
+		{"grpc-go/1.29.1", false},
+		{"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", true},
+		{"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) HeadlessChrome/90.0.4430.0 Safari/537.36", true},
+		{"facebookexternalhit/1.1", true},
+		{"curl/7.68.0", true},
+		{"", true},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.bot, IsBot(tt.userAgent), tt.userAgent)
+	}
+}
+
+func TestCounter(t *testing.T) {
+	c := NewCounter(30 * time.Minute)
+	now := time.Date(2020, 4, 1, 23, 50, 0, 0, time.UTC)
+
+	assert.True(t, c.Record(1, "user:1", now))
+	assert.False(t, c.Record(1, "user:1", now.Add(10*time.Minute)), "within the window")
+	assert.True(t, c.Record(1, "ip:127.0.0.1", now.Add(10*time.Minute)), "another viewer")
+	assert.True(t, c.Record(2, "user:1", now.Add(10*time.Minute)), "another article")
+	assert.True(t, c.Record(1, "user:1", now.Add(30*time.Minute)), "after the window")
+
+	// views after midnight count for the next day
+	cs := c.Take(now.Add(30 * time.Minute))
+	day := time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, []Count{
+		{ArticleID: 1, Day: day, Views: 1},
+		{ArticleID: 1, Day: day.AddDate(0, 0, 1), Views: 2},
+		{ArticleID: 2, Day: day.AddDate(0, 0, 1), Views: 1},
+	}, cs)
+	assert.Empty(t, c.Take(now.Add(30*time.Minute)))
+
+	// failed flushes are retried with the next counts
+	c.Restore(cs[:1])
+	assert.True(t, c.Record(1, "user:2", now.Add(time.Minute)))
+	assert.Equal(t, []Count{{ArticleID: 1, Day: day, Views: 2}}, c.Take(now.Add(time.Minute)))
+
+	// viewers are forgotten after the window
+	c.Take(now.Add(2 * time.Hour))
+	c.mu.Lock()
+	assert.Empty(t, c.seen)
+	c.mu.Unlock()
+}
@@ -0,0 +1,234 @@
+// Package webhook signs and sends webhook deliveries
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Headers of deliveries
+const (
+	SignatureHeader = "X-Realworld-Signature"
+	EventHeader     = "X-Realworld-Event"
+	DeliveryHeader  = "X-Realworld-Delivery"
+)
+
+const (
+	// SecretPrefix is the prefix of signing secrets
+	SecretPrefix = "whsec_"
+
+	// MaxAttempts is the number of attempts before a delivery is given up
+	MaxAttempts = 8
+
+	baseBackoff = 30 * time.Second
+	maxBackoff  = 6 * time.Hour
+)
+
+// GenerateSecret generates a new signing secret
+func GenerateSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return SecretPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Sign returns the signature header of body sent at t. It has the form
+// t=<unix time>,v1=<hex HMAC-SHA256 of "<unix time>.<body>">, so that
+// receivers can reject replayed deliveries by their timestamp.
+func Sign(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", ts, mac(secret, ts, body))
+}
+
+// Verify checks the signature header of body, which must have been sent
+// within tolerance of now
+func Verify(secret, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	var ts, sig string
+	for _, kv := range strings.Split(header, ",") {
+		switch {
+		case strings.HasPrefix(kv, "t="):
+			ts = kv[2:]
+		case strings.HasPrefix(kv, "v1="):
+			sig = kv[3:]
+		}
+	}
+
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || sig == "" {
+		return errors.New("malformed signature")
+	}
+
+	if d := now.Sub(time.Unix(sec, 0)); d > tolerance || d < -tolerance {
+		return errors.New("signature timestamp is out of tolerance")
+	}
+
+	if !hmac.Equal([]byte(sig), []byte(mac(secret, ts, body))) {
+		return errors.New("signature mismatch")
+	}
+
+	return nil
+}
+
+func mac(secret, ts string, body []byte) string {
+	m := hmac.New(sha256.New, []byte(secret))
+	m.Write([]byte(ts))
+	m.Write([]byte("."))
+	m.Write(body)
+	return hex.EncodeToString(m.Sum(nil))
+}
+
+// Backoff returns how long to wait before the next attempt after the
+// attempt-th one failed. It doubles from 30 seconds up to 6 hours.
+func Backoff(attempt int) time.Duration {
+	d := baseBackoff
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return d
+}
+
+// Delivery is a request to send
+type Delivery struct {
+	ID     string
+	Event  string
+	URL    string
+	Secret string
+	Body   []byte
+}
+
+// ErrForbiddenAddress is returned when a delivery would connect to a
+// loopback, private or otherwise non-public address
+var ErrForbiddenAddress = errors.New("webhook receivers must have a public address")
+
+// nonPublicNets are special-purpose ranges not covered by the net.IP methods
+var nonPublicNets = []*net.IPNet{
+	mustParseCIDR("0.0.0.0/8"),     // "this" network
+	mustParseCIDR("100.64.0.0/10"), // carrier-grade NAT
+	mustParseCIDR("192.0.0.0/24"),  // IETF protocol assignments
+	mustParseCIDR("198.18.0.0/15"), // benchmarking
+}
+
+func mustParseCIDR(s string) *net.IPNet {
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// IsPublicIP returns whether ip is a public unicast address, which is neither
+// loopback, private, link-local, unspecified nor multicast
+func IsPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, n := range nonPublicNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// publicOnly is a net.Dialer control refusing non-public addresses. It runs
+// on the resolved address of every connection, so it also covers redirects
+// and DNS rebinding.
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !IsPublicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+	return nil
+}
+
+// Sender sends deliveries
+type Sender struct {
+	client *http.Client
+}
+
+// NewSender returns a sender timing out after timeout. It refuses to connect
+// to non-public addresses, so that webhooks cannot reach internal services.
+func NewSender(timeout time.Duration) *Sender {
+	return newSender(timeout, publicOnly)
+}
+
+// NewInsecureSender returns a sender which also connects to loopback and
+// private addresses. It is meant for tests with local receivers.
+func NewInsecureSender(timeout time.Duration) *Sender {
+	return newSender(timeout, nil)
+}
+
+func newSender(timeout time.Duration, control func(network, address string, c syscall.RawConn) error) *Sender {
+	dialer := &net.Dialer{
+		Timeout:   timeout,
+		KeepAlive: 30 * time.Second,
+		Control:   control,
+	}
+
+	// no proxy, since the address checked must be the receiver's
+	transport := &http.Transport{
+		DialContext:           dialer.DialContext,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+
+	return &Sender{client: &http.Client{Timeout: timeout, Transport: transport}}
+}
+
+// Send posts the delivery and returns the response status code.
+// It fails unless the receiver responds with 2xx.
+func (s *Sender) Send(ctx context.Context, d *Delivery) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, d.URL, bytes.NewReader(d.Body))
+	if err != nil {
+		return 0, err
+	}
+	req = req.WithContext(ctx)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "realworld-webhook")
+	req.Header.Set(EventHeader, d.Event)
+	req.Header.Set(DeliveryHeader, d.ID)
+	req.Header.Set(SignatureHeader, Sign(d.Secret, time.Now(), d.Body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// drain a little of the body so the connection can be reused
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("receiver responded with %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,145 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	now := time.Now()
+	body := []byte(`{"type":"user.followed"}`)
+	header := Sign("secret", now, body)
+
+	tests := []struct {
+		title    string
+		secret   string
+		header   string
+		body     []byte
+		now      time.Time
+		hasError bool
+	}{
+		{"valid signature: success", "secret", header, body, now, false},
+		{"other secret: failed", "other", header, body, now, true},
+		{"tampered body: failed", "secret", header, []byte(`{"type":"x"}`), now, true},
+		{"too old: failed", "secret", header, body, now.Add(10 * time.Minute), true},
+		{"malformed header: failed", "secret", "v1=abc", body, now, true},
+	}
+
+	for _, tt := range tests {
+		err := Verify(tt.secret, tt.header, tt.body, tt.now, 5*time.Minute)
+		if tt.hasError {
+			if err == nil {
+				t.Errorf("%q expected to fail, but succeeded.", tt.title)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%q expected to succeed, but failed. %v", tt.title, err)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, Backoff(1))
+	assert.Equal(t, time.Minute, Backoff(2))
+	assert.Equal(t, 4*time.Minute, Backoff(4))
+	assert.Equal(t, 6*time.Hour, Backoff(20))
+}
+
+func TestSend(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, strings.HasPrefix(secret, SecretPrefix))
+
+	var received []string
+	code := http.StatusNoContent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if err := Verify(secret, r.Header.Get(SignatureHeader), body, time.Now(), time.Minute); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		received = append(received, r.Header.Get(EventHeader)+" "+r.Header.Get(DeliveryHeader)+" "+string(body))
+		w.WriteHeader(code)
+	}))
+	defer srv.Close()
+
+	s := NewInsecureSender(time.Second)
+	d := &Delivery{ID: "1", Event: "user.followed", URL: srv.URL, Secret: secret, Body: []byte(`{}`)}
+
+	status, err := s.Send(context.Background(), d)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, status)
+	assert.Equal(t, []string{"user.followed 1 {}"}, received)
+
+	code = http.StatusInternalServerError
+	status, err = s.Send(context.Background(), d)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusInternalServerError, status)
+
+	d.Secret = "wrong"
+	status, err = s.Send(context.Background(), d)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	srv.Close()
+	_, err = s.Send(context.Background(), d)
+	assert.Error(t, err)
+}
+
+func TestSenderRefusesPrivateAddresses(t *testing.T) {
+	received := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = true
+	}))
+	defer srv.Close()
+
+	d := &Delivery{ID: "1", Event: "user.followed", URL: srv.URL, Secret: "secret", Body: []byte(`{}`)}
+
+	_, err := NewSender(time.Second).Send(context.Background(), d)
+	assert.True(t, errors.Is(err, ErrForbiddenAddress), "%v", err)
+	assert.False(t, received)
+
+	_, err = NewInsecureSender(time.Second).Send(context.Background(), d)
+	assert.NoError(t, err)
+	assert.True(t, received)
+}
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip     string
+		public bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"fd00::1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"224.0.0.1", false},
+		{"100.64.0.1", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.public, IsPublicIP(net.ParseIP(tt.ip)), tt.ip)
+	}
+}